@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/spf13/afero"
 )
@@ -13,20 +15,25 @@ import (
 type FilesystemWriter struct {
 	dir string
 	fs  afero.Fs
+
+	mu sync.Mutex
+	// written holds the filenames written by this writer, and not since removed.
+	written map[string]struct{}
 }
 
 // NewFilesystemWriter creates an artifact writer which writes to the filesystem.
 func NewFilesystemWriter(opts ...FilesystemWriterOption) (*FilesystemWriter, error) {
-	w := FilesystemWriter{
-		dir: resolveFullPath(DefaultArtifactsDir),
-		fs:  afero.NewOsFs(),
+	w := &FilesystemWriter{
+		dir:     resolveFullPath(DefaultArtifactsDir),
+		fs:      afero.NewOsFs(),
+		written: map[string]struct{}{},
 	}
 
 	for _, opt := range opts {
-		opt(&w)
+		opt(w)
 	}
 
-	return &w, nil
+	return w, nil
 }
 
 // WithDirectory sets the artifacts directory to dir unless it's empty, in which case
@@ -51,6 +58,14 @@ func (w *FilesystemWriter) WriteFile(filename string, contents io.Reader) (strin
 		//coverage:ignore
 		return fullFilePath, fmt.Errorf("could not write file to artifacts directory: %v", err)
 	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written == nil {
+		w.written = map[string]struct{}{}
+	}
+	w.written[filename] = struct{}{}
+
 	return fullFilePath, nil
 }
 
@@ -65,7 +80,30 @@ func (w *FilesystemWriter) Exists(filename string) (bool, error) {
 func (w *FilesystemWriter) Remove(filename string) error {
 	fullFilePath := filepath.Join(w.Path(), filename)
 
-	return w.fs.Remove(fullFilePath)
+	if err := w.fs.Remove(fullFilePath); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.written, filename)
+
+	return nil
+}
+
+// Written returns the sorted filenames that have been written by this writer, and
+// not since removed.
+func (w *FilesystemWriter) Written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	names := make([]string, 0, len(w.written))
+	for name := range w.written {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
 }
 
 // Path is the full artifacts path.
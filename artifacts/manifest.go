@@ -0,0 +1,151 @@
+package artifacts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// ManifestFilename is the name of the manifest of digests of the files written by a FilesystemWriter.
+	ManifestFilename = "artifacts-manifest.json"
+	// ManifestSignatureFilename is the name of the base64 encoded signature of the manifest.
+	ManifestSignatureFilename = ManifestFilename + ".sig"
+)
+
+// Manifest records the SHA-256 digest of each file written to an artifacts directory.
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry is the digest of a single file in an artifacts directory.
+type ManifestEntry struct {
+	Filename string `json:"filename"`
+	Digest   string `json:"digest"`
+	Size     int64  `json:"size"`
+}
+
+// WriteManifest writes a Manifest of every file this writer has written to the artifacts
+// directory. Digests are calculated from the files as they are on disk, so that files
+// written outside of WriteFile after being created with it are accounted for. If signer
+// is not nil, the manifest's signature is written alongside it. Otherwise, any existing
+// signature is removed, because it would no longer match.
+func (w *FilesystemWriter) WriteManifest(signer Signer) error {
+	manifest := Manifest{Files: []ManifestEntry{}}
+	for _, name := range w.Written() {
+		if name == ManifestFilename || name == ManifestSignatureFilename {
+			continue
+		}
+
+		entry, err := digestFile(w.fs, filepath.Join(w.Path(), name))
+		if err != nil {
+			return fmt.Errorf("could not calculate digest of %s: %w", name, err)
+		}
+		entry.Filename = name
+		manifest.Files = append(manifest.Files, entry)
+	}
+
+	// calling MarshalIndent so the json file written to disk is human-readable when opened
+	manifestJSON, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not marshal artifacts manifest: %w", err)
+	}
+
+	if _, err := w.WriteFile(ManifestFilename, bytes.NewReader(manifestJSON)); err != nil {
+		return err
+	}
+
+	if signer == nil {
+		exists, err := w.Exists(ManifestSignatureFilename)
+		if err != nil || !exists {
+			return err
+		}
+		return w.Remove(ManifestSignatureFilename)
+	}
+
+	signature, err := signer.Sign(manifestJSON)
+	if err != nil {
+		return fmt.Errorf("could not sign artifacts manifest: %w", err)
+	}
+
+	_, err = w.WriteFile(ManifestSignatureFilename, strings.NewReader(base64.StdEncoding.EncodeToString(signature)))
+	return err
+}
+
+// VerifyManifest confirms that every file in the Manifest in dir matches its recorded
+// digest. Files may be in subdirectories of dir, but not outside of it. If verifier is not
+// nil, the manifest must also have a valid signature. Files in dir that are not in the
+// manifest are not checked. All mismatches are returned together.
+func VerifyManifest(dir string, verifier Verifier) (*Manifest, error) {
+	manifestJSON, err := os.ReadFile(filepath.Join(dir, ManifestFilename))
+	if err != nil {
+		return nil, fmt.Errorf("could not read artifacts manifest: %w", err)
+	}
+
+	if verifier != nil {
+		encoded, err := os.ReadFile(filepath.Join(dir, ManifestSignatureFilename))
+		if err != nil {
+			return nil, fmt.Errorf("could not read artifacts manifest signature: %w", err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode artifacts manifest signature: %w", err)
+		}
+		if err := verifier.Verify(manifestJSON, signature); err != nil {
+			return nil, fmt.Errorf("artifacts manifest signature could not be verified: %w", err)
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestJSON, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse artifacts manifest: %w", err)
+	}
+
+	var errs []error
+	fs := afero.NewOsFs()
+	for _, want := range manifest.Files {
+		if !filepath.IsLocal(want.Filename) {
+			errs = append(errs, fmt.Errorf("%s: unexpected path in artifacts manifest", want.Filename))
+			continue
+		}
+
+		got, err := digestFile(fs, filepath.Join(dir, want.Filename))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", want.Filename, err))
+			continue
+		}
+		if got.Digest != want.Digest || got.Size != want.Size {
+			errs = append(errs, fmt.Errorf("%s: digest %s does not match %s in the artifacts manifest", want.Filename, got.Digest, want.Digest))
+		}
+	}
+
+	return &manifest, errors.Join(errs...)
+}
+
+// digestFile returns a ManifestEntry with the digest and size of the file at path.
+func digestFile(fs afero.Fs, path string) (ManifestEntry, error) {
+	f, err := fs.Open(path)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return ManifestEntry{}, err
+	}
+
+	return ManifestEntry{Digest: "sha256:" + hex.EncodeToString(h.Sum(nil)), Size: size}, nil
+}
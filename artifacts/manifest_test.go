@@ -0,0 +1,220 @@
+package artifacts
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// pemKeyPair returns the PEM encoded PKCS#8 private key and PKIX public key for key.
+func pemKeyPair(key any, public any) ([]byte, []byte) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	Expect(err).ToNot(HaveOccurred())
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+}
+
+var _ = Describe("Artifacts Manifest", func() {
+	var (
+		dir string
+		aw  *FilesystemWriter
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+
+		var err error
+		aw, err = NewFilesystemWriter(WithDirectory(dir))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = aw.WriteFile("results.json", strings.NewReader(`{"passed": true}`))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = aw.WriteFile("cert-image.json", strings.NewReader(`{}`))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("when tracking the files that have been written", func() {
+		It("should list them in order, and forget removed files", func() {
+			_, err := aw.WriteFile("removed.txt", strings.NewReader("removed"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.Written()).To(Equal([]string{"cert-image.json", "removed.txt", "results.json"}))
+
+			Expect(aw.Remove("removed.txt")).To(Succeed())
+			Expect(aw.Written()).To(Equal([]string{"cert-image.json", "results.json"}))
+		})
+	})
+
+	Context("when the manifest is not signed", func() {
+		BeforeEach(func() {
+			Expect(aw.WriteManifest(nil)).To(Succeed())
+		})
+
+		It("should verify the unmodified artifacts", func() {
+			manifest, err := VerifyManifest(dir, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Files).To(HaveLen(2))
+			Expect(manifest.Files[1].Filename).To(Equal("results.json"))
+			Expect(manifest.Files[1].Digest).To(HavePrefix("sha256:"))
+			Expect(manifest.Files[1].Size).To(BeEquivalentTo(len(`{"passed": true}`)))
+		})
+
+		It("should verify artifacts written to a subdirectory", func() {
+			_, err := NewSubdirectoryWriter(aw, "catalog/memcached-operator").WriteFile("catalog.json", strings.NewReader(`{}`))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.WriteManifest(nil)).To(Succeed())
+
+			manifest, err := VerifyManifest(dir, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(manifest.Files).To(ContainElement(HaveField("Filename", "catalog/memcached-operator/catalog.json")))
+		})
+
+		It("should fail when an artifact is outside of the artifacts directory", func() {
+			for _, filename := range []string{"../results.json", "/etc/passwd"} {
+				manifestJSON, err := json.Marshal(Manifest{Files: []ManifestEntry{{Filename: filename}}})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.WriteFile(filepath.Join(dir, ManifestFilename), manifestJSON, 0o644)).To(Succeed())
+
+				_, err = VerifyManifest(dir, nil)
+				Expect(err).To(MatchError(ContainSubstring(filename + ": unexpected path in artifacts manifest")))
+			}
+		})
+
+		It("should account for changes made to a file after it was written", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "results.json"), []byte(`{"passed": false}`), 0o644)).To(Succeed())
+			Expect(aw.WriteManifest(nil)).To(Succeed())
+			_, err := VerifyManifest(dir, nil)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should fail when an artifact has been modified", func() {
+			Expect(os.WriteFile(filepath.Join(dir, "results.json"), []byte(`{"passed": false}`), 0o644)).To(Succeed())
+			_, err := VerifyManifest(dir, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("results.json: digest"))
+		})
+
+		It("should fail when an artifact has been removed", func() {
+			Expect(os.Remove(filepath.Join(dir, "cert-image.json"))).To(Succeed())
+			_, err := VerifyManifest(dir, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cert-image.json"))
+		})
+
+		It("should fail when a signature is required", func() {
+			public, key, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			_, publicPEM := pemKeyPair(key, public)
+			verifier, err := NewVerifierFromPEM(publicPEM)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = VerifyManifest(dir, verifier)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not read artifacts manifest signature"))
+		})
+	})
+
+	Context("when the manifest is signed", func() {
+		var verifier Verifier
+
+		BeforeEach(func() {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			private, public := pemKeyPair(key, key.Public())
+
+			signer, err := NewSignerFromPEM(private)
+			Expect(err).ToNot(HaveOccurred())
+			verifier, err = NewVerifierFromPEM(public)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(aw.WriteManifest(signer)).To(Succeed())
+			Expect(filepath.Join(dir, ManifestSignatureFilename)).To(BeARegularFile())
+		})
+
+		It("should verify the signature", func() {
+			_, err := VerifyManifest(dir, verifier)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should fail when the manifest has been modified", func() {
+			Expect(os.WriteFile(filepath.Join(dir, ManifestFilename), []byte(`{"files": []}`), 0o644)).To(Succeed())
+			_, err := VerifyManifest(dir, verifier)
+			Expect(err).To(MatchError(ErrInvalidSignature))
+		})
+
+		It("should remove the signature when the manifest is rewritten without a signer", func() {
+			Expect(aw.WriteManifest(nil)).To(Succeed())
+			Expect(filepath.Join(dir, ManifestSignatureFilename)).ToNot(BeAnExistingFile())
+		})
+	})
+})
+
+var _ = Describe("Manifest Signing Keys", func() {
+	data := []byte("artifacts manifest")
+
+	DescribeTable("signing and verifying with supported key types",
+		func(generate func() (any, any)) {
+			private, public := pemKeyPair(generate())
+
+			signer, err := NewSignerFromPEM(private)
+			Expect(err).ToNot(HaveOccurred())
+			verifier, err := NewVerifierFromPEM(public)
+			Expect(err).ToNot(HaveOccurred())
+
+			signature, err := signer.Sign(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(verifier.Verify(data, signature)).To(Succeed())
+			Expect(verifier.Verify([]byte("something else"), signature)).To(MatchError(ErrInvalidSignature))
+		},
+		Entry("ECDSA", func() (any, any) {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			return key, key.Public()
+		}),
+		Entry("RSA", func() (any, any) {
+			key, err := rsa.GenerateKey(rand.Reader, 2048)
+			Expect(err).ToNot(HaveOccurred())
+			return key, key.Public()
+		}),
+		Entry("Ed25519", func() (any, any) {
+			public, key, err := ed25519.GenerateKey(rand.Reader)
+			Expect(err).ToNot(HaveOccurred())
+			return key, public
+		}),
+	)
+
+	It("should accept an EC private key", func() {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		der, err := x509.MarshalECPrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = NewSignerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject encrypted keys", func() {
+		_, err := NewSignerFromPEM(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: []byte("encrypted")}))
+		Expect(err).To(MatchError(ContainSubstring("encrypted signing keys are not supported")))
+	})
+
+	It("should reject data that is not PEM encoded", func() {
+		_, err := NewSignerFromPEM([]byte("not a key"))
+		Expect(err).To(HaveOccurred())
+		_, err = NewVerifierFromPEM([]byte("not a key"))
+		Expect(err).To(HaveOccurred())
+	})
+})
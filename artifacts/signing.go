@@ -0,0 +1,132 @@
+package artifacts
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Signer signs the artifacts manifest.
+type Signer interface {
+	Sign(data []byte) ([]byte, error)
+}
+
+// Verifier verifies the signature of the artifacts manifest.
+type Verifier interface {
+	Verify(data []byte, signature []byte) error
+}
+
+// ErrInvalidSignature is returned when a signature does not match the signed data.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// NewSignerFromPEM returns a Signer using the unencrypted ECDSA, RSA, or Ed25519 private
+// key in pemBytes. ECDSA and RSA keys sign the SHA-256 digest of the data, so that
+// signatures made with ECDSA keys can also be verified with cosign verify-blob.
+func NewSignerFromPEM(pemBytes []byte) (Signer, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data was found in the signing key")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" || block.Headers["Proc-Type"] != "" {
+		return nil, errors.New("encrypted signing keys are not supported")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signing key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return &keySigner{key: k.(crypto.Signer)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", key)
+	}
+}
+
+// NewVerifierFromPEM returns a Verifier using the ECDSA, RSA, or Ed25519 public key, or the
+// certificate containing one, in pemBytes.
+func NewVerifierFromPEM(pemBytes []byte) (Verifier, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data was found in the public key")
+	}
+
+	var key any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse certificate: %w", err)
+		}
+		key = cert.PublicKey
+	default:
+		var err error
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse public key: %w", err)
+		}
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		return &keyVerifier{key: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// keySigner signs with a private key held in memory.
+type keySigner struct {
+	key crypto.Signer
+}
+
+func (s *keySigner) Sign(data []byte) ([]byte, error) {
+	if _, ok := s.key.(ed25519.PrivateKey); ok {
+		return s.key.Sign(rand.Reader, data, crypto.Hash(0))
+	}
+
+	digest := sha256.Sum256(data)
+	return s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// keyVerifier verifies signatures made by a keySigner.
+type keyVerifier struct {
+	key crypto.PublicKey
+}
+
+func (v *keyVerifier) Verify(data []byte, signature []byte) error {
+	digest := sha256.Sum256(data)
+
+	var ok bool
+	switch k := v.key.(type) {
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, digest[:], signature)
+	case *rsa.PublicKey:
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		ok = ed25519.Verify(k, data, signature)
+	}
+
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
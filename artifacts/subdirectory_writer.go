@@ -0,0 +1,23 @@
+package artifacts
+
+import (
+	"io"
+	"path"
+)
+
+// SubdirectoryWriter implements an ArtifactWriter that writes to a subdirectory of
+// another ArtifactWriter.
+type SubdirectoryWriter struct {
+	w   ArtifactWriter
+	dir string
+}
+
+// NewSubdirectoryWriter creates an artifact writer which writes to dir, using w.
+func NewSubdirectoryWriter(w ArtifactWriter, dir string) *SubdirectoryWriter {
+	return &SubdirectoryWriter{w: w, dir: dir}
+}
+
+// WriteFile places contents into the subdirectory at filename.
+func (w *SubdirectoryWriter) WriteFile(filename string, contents io.Reader) (string, error) {
+	return w.w.WriteFile(path.Join(w.dir, filename), contents)
+}
@@ -0,0 +1,39 @@
+package artifacts
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subdirectory Artifact Writer", func() {
+	Context("With a Subdirectory Artifact Writer", func() {
+		var (
+			mw *MapWriter
+			aw *SubdirectoryWriter
+		)
+
+		BeforeEach(func() {
+			var err error
+			mw, err = NewMapWriter()
+			Expect(err).ToNot(HaveOccurred())
+			aw = NewSubdirectoryWriter(mw, "subdir")
+		})
+
+		It("Should write to the input filename in the subdirectory", func() {
+			fullpath, err := aw.WriteFile("testfile.txt", bytes.NewBufferString("testcontents"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fullpath).To(Equal("subdir/testfile.txt"))
+			Expect(mw.Files()).To(HaveKey("subdir/testfile.txt"))
+		})
+
+		It("Should return the errors of the underlying writer", func() {
+			_, err := aw.WriteFile("testfile.txt", bytes.NewBufferString("testcontents"))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = aw.WriteFile("testfile.txt", bytes.NewBufferString("rejected"))
+			Expect(err).To(Equal(ErrFileAlreadyExists))
+		})
+	})
+})
@@ -0,0 +1,166 @@
+// Package catalog runs preflight's Catalog Policy against a file-based catalog, in a catalog
+// image or a directory.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	goruntime "runtime"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+)
+
+type Option = func(*catalogCheck)
+
+// NewCheck is a check runner that executes the Catalog Policy against catalog, which is either
+// a catalog image, or a directory containing a file-based catalog.
+func NewCheck(catalog string, opts ...Option) *catalogCheck {
+	c := &catalogCheck{
+		catalog: catalog,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Run executes the check and returns the results. Calls should add a relevant ArtifactWriter to
+// the context if they wish to work with artifact files written by checks.
+func (c catalogCheck) Run(ctx context.Context) (certification.Results, error) {
+	err := c.resolve(ctx)
+	if err != nil {
+		return certification.Results{}, err
+	}
+
+	registriesConfig, err := registries.New(c.registriesConf, c.certsDir, c.credentialHelpers)
+	if err != nil {
+		return certification.Results{}, err
+	}
+	if registriesConfig != nil {
+		ctx = registries.ContextWithConfig(ctx, registriesConfig)
+	}
+
+	eng, err := c.engine(ctx)
+	if err != nil {
+		//coverage:ignore
+		return certification.Results{}, err
+	}
+
+	if err := eng.ExecuteChecks(ctx); err != nil {
+		return certification.Results{}, err
+	}
+
+	return eng.Results(ctx), nil
+}
+
+// checkEngine executes checks, and returns their results.
+type checkEngine interface {
+	ExecuteChecks(context.Context) error
+	Results(context.Context) certification.Results
+}
+
+// engine returns the engine that executes the checks against the catalog, which is only pulled
+// if it is not a directory.
+func (c catalogCheck) engine(ctx context.Context) (checkEngine, error) {
+	if isDir(c.catalog) {
+		eng, err := engine.NewForDirectory(c.checks, c.catalog)
+		return &eng, err
+	}
+
+	// catalog images are not scanned for packages, as the checks only read their catalog.
+	eng, err := engine.New(ctx, c.checks, nil, runtime.Config{
+		Image:        c.catalog,
+		DockerConfig: c.dockerConfigFilePath,
+		Scratch:      true,
+		Insecure:     c.insecure,
+		Platform:     goruntime.GOARCH,
+	})
+	return &eng, err
+}
+
+func (c *catalogCheck) resolve(ctx context.Context) error {
+	if c.resolved {
+		return nil
+	}
+
+	if c.catalog == "" {
+		return preflighterr.ErrImageEmpty
+	}
+
+	c.policy = policy.PolicyCatalog
+	newChecks, err := engine.InitializeCatalogChecks(ctx, c.policy, engine.CatalogCheckConfig{
+		DockerConfig: c.dockerConfigFilePath,
+		Insecure:     c.insecure,
+	})
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("%w: %s", preflighterr.ErrCannotInitializeChecks, err)
+	}
+	c.checks = newChecks
+	c.resolved = true
+
+	return nil
+}
+
+// List the available catalog checks.
+func (c catalogCheck) List(ctx context.Context) (policy.Policy, []check.Check, error) {
+	return c.policy, c.checks, c.resolve(ctx)
+}
+
+// isDir returns true if path is an existing directory, rather than a catalog image.
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// WithDockerConfigJSONFromFile is a path to credentials necessary to pull the catalog image,
+// and look up its bundle images.
+func WithDockerConfigJSONFromFile(path string) Option {
+	return func(cc *catalogCheck) {
+		cc.dockerConfigFilePath = path
+	}
+}
+
+// WithInsecureConnection allows for preflight to connect to an insecure registry
+// to pull images.
+func WithInsecureConnection() Option {
+	return func(cc *catalogCheck) {
+		cc.insecure = true
+	}
+}
+
+// WithRegistriesConfig configures how images are pulled from their registries. registriesConf
+// is a containers registries.conf file (version 2), whose mirrors, blocked registries, and
+// insecure settings are used. certsDir contains the certificates for each registry, laid out
+// like /etc/containers/certs.d. credentialHelpers maps a registry to the docker credential
+// helper holding its credentials, e.g. quay.io to secretservice. Each may be empty.
+func WithRegistriesConfig(registriesConf string, certsDir string, credentialHelpers map[string]string) Option {
+	return func(cc *catalogCheck) {
+		cc.registriesConf = registriesConf
+		cc.certsDir = certsDir
+		cc.credentialHelpers = credentialHelpers
+	}
+}
+
+type catalogCheck struct {
+	// required
+	catalog string
+	// optional
+	dockerConfigFilePath string
+	insecure             bool
+	registriesConf       string
+	certsDir             string
+	credentialHelpers    map[string]string
+	checks               []check.Check
+	resolved             bool
+	policy               policy.Policy
+}
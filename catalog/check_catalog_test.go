@@ -0,0 +1,63 @@
+package catalog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+)
+
+var _ = Describe("Catalog Check initialization", func() {
+	When("Using options to initialize a check", func() {
+		It("Should properly store the options with their correct values", func() {
+			c := NewCheck("quay.io/example/catalog:latest",
+				WithDockerConfigJSONFromFile("dockerconfigfilepath"),
+				WithInsecureConnection(),
+				WithRegistriesConfig("/etc/containers/registries.conf", "/etc/containers/certs.d", map[string]string{"quay.io": "secretservice"}),
+			)
+			Expect(c.catalog).To(Equal("quay.io/example/catalog:latest"))
+			Expect(c.dockerConfigFilePath).To(Equal("dockerconfigfilepath"))
+			Expect(c.insecure).To(BeTrue())
+			Expect(c.registriesConf).To(Equal("/etc/containers/registries.conf"))
+			Expect(c.certsDir).To(Equal("/etc/containers/certs.d"))
+			Expect(c.credentialHelpers).To(Equal(map[string]string{"quay.io": "secretservice"}))
+		})
+	})
+})
+
+var _ = Describe("Catalog Check Execution", func() {
+	It("Should resolve the checks of the catalog policy", func() {
+		policy, checks, err := NewCheck("quay.io/example/catalog:latest").List(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(policy).To(Equal("catalog"))
+		Expect(checks).To(HaveLen(3))
+	})
+
+	It("Should fail to resolve checks without a catalog", func() {
+		_, _, err := NewCheck("").List(context.TODO())
+		Expect(err).To(MatchError(preflighterr.ErrImageEmpty))
+	})
+
+	It("Should run the checks against a catalog in a directory", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte("schema: olm.package\nname: memcached-operator\ndefaultChannel: stable\n"), 0o644)).To(Succeed())
+
+		results, err := NewCheck(dir).Run(context.TODO())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(results.TestedImage).To(Equal(dir))
+		Expect(results.PassedOverall).To(BeFalse())
+		Expect(results.Failed).To(HaveLen(1))
+		Expect(results.Failed[0].Name()).To(Equal("ValidateCatalogSchema"))
+		Expect(results.Failed[0].Findings).To(ConsistOf("the default channel stable of package memcached-operator does not exist"))
+		Expect(results.Passed).To(HaveLen(2))
+	})
+
+	It("Should fail to run if the catalog image cannot be pulled", func() {
+		_, err := NewCheck("localhost:1/example/catalog:latest", WithInsecureConnection()).Run(context.TODO())
+		Expect(err).To(HaveOccurred())
+	})
+})
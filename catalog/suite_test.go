@@ -0,0 +1,13 @@
+package catalog
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestCatalogLib(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "lib catalog suite")
+}
@@ -12,6 +12,9 @@ type openshiftClusterVersion = runtime.OpenshiftClusterVersion
 type Result struct {
 	check.Check
 	ElapsedTime time.Duration
+	// Findings are what the check reported finding in the image, e.g. the
+	// files that caused it to fail.
+	Findings []string
 	// Err contains the error a check itself throws if it failed to run.
 	// If populated, the expectation is that this Result is in the
 	// Results{}.Errors slice.
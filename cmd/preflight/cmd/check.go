@@ -10,8 +10,8 @@ import (
 func checkCmd() *cobra.Command {
 	checkCmd := &cobra.Command{
 		Use:   "check",
-		Short: "Run checks for an operator or container",
-		Long:  "This command will allow you to execute the Red Hat Certification tests for an operator or a container.",
+		Short: "Run checks for an operator, container or catalog",
+		Long:  "This command will allow you to execute the Red Hat Certification tests for an operator, a container or a file-based catalog.",
 	}
 
 	viper := viper.Instance()
@@ -23,8 +23,32 @@ func checkCmd() *cobra.Command {
 	checkCmd.PersistentFlags().String("artifacts", "", "Where check-specific artifacts will be written. (env: PFLT_ARTIFACTS)")
 	_ = viper.BindPFlag("artifacts", checkCmd.PersistentFlags().Lookup("artifacts"))
 
+	checkCmd.PersistentFlags().String("registries-conf", "", "Path to a containers registries.conf file. Its mirrors, blocked registries and insecure settings\n"+
+		"are used when pulling images. (env: PFLT_REGISTRIES_CONF)")
+	_ = viper.BindPFlag("registries_conf", checkCmd.PersistentFlags().Lookup("registries-conf"))
+
+	checkCmd.PersistentFlags().String("certs-dir", "", "Path to a directory of per-registry certificates, laid out like /etc/containers/certs.d.\n"+
+		"(env: PFLT_CERTS_DIR)")
+	_ = viper.BindPFlag("certs_dir", checkCmd.PersistentFlags().Lookup("certs-dir"))
+
+	checkCmd.PersistentFlags().String("signing-key", "", "Path to a PEM encoded private key used to sign the manifest of artifacts written by this execution.\n"+
+		"The manifest is written without a signature if this is not set. (env: PFLT_SIGNING_KEY)")
+	_ = viper.BindPFlag("signing_key", checkCmd.PersistentFlags().Lookup("signing-key"))
+
+	checkCmd.PersistentFlags().String("otel-endpoint", "", "URL of an OTLP/HTTP collector to export traces and metrics to, e.g. http://localhost:4318.\n"+
+		"(env: PFLT_OTEL_ENDPOINT)")
+	_ = viper.BindPFlag("otel_endpoint", checkCmd.PersistentFlags().Lookup("otel-endpoint"))
+
+	checkCmd.PersistentFlags().Bool("trace-file", false, "Write traces and metrics as JSON to the artifacts directory. (env: PFLT_TRACE_FILE)")
+	_ = viper.BindPFlag("trace_file", checkCmd.PersistentFlags().Lookup("trace-file"))
+
+	checkCmd.PersistentFlags().StringSlice("rules", nil, "Path to a rules file whose rules are run as checks after the policy's checks. May be repeated.\n"+
+		"Not supported by check catalog. (env: PFLT_RULES, separated by spaces)")
+	_ = viper.BindPFlag("rules", checkCmd.PersistentFlags().Lookup("rules"))
+
 	checkCmd.AddCommand(checkOperatorCmd(cli.RunPreflight))
 	checkCmd.AddCommand(checkContainerCmd(cli.RunPreflight))
+	checkCmd.AddCommand(checkCatalogCmd(cli.RunPreflight))
 
 	return checkCmd
 }
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/catalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+func checkCatalogCmd(runpreflight runPreflight) *cobra.Command {
+	checkCatalogCmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Run checks for a file-based catalog",
+		Long: "This command will validate a file-based catalog, in a catalog image or a directory: the schema of its blobs,\n" +
+			"the upgrade graph of its channels, and that the image of each of its bundles is pinned to a digest and exists.",
+		Args: checkCatalogPositionalArgs,
+		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
+		Example: fmt.Sprintf("  %s\n  %s", "preflight check catalog quay.io/repo-name/operator-catalog:version", "preflight check catalog ./catalog"),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			bindFlags(cmd, catalogFlagKeys)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return checkCatalogRunE(cmd, args, runpreflight)
+		},
+	}
+
+	checkCatalogCmd.Flags().Bool("insecure", false, "Use insecure protocol for the registries the catalog and its bundle images are in. Default is False.")
+
+	return checkCatalogCmd
+}
+
+// catalogFlagKeys are the configuration keys of the catalog command's flags.
+var catalogFlagKeys = map[string]string{
+	"insecure": "insecure",
+}
+
+// checkCatalogRunE executes checkCatalog using the user args to inform the execution.
+func checkCatalogRunE(cmd *cobra.Command, args []string, runpreflight runPreflight) (err error) {
+	ctx := cmd.Context()
+	logger, err := logr.FromContext(ctx)
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("invalid logging configuration")
+	}
+
+	logger.Info("certification library version", "version", version.Version.String())
+	catalogRef := args[0]
+
+	// Render the Viper configuration as a runtime.Config
+	cfg, err := runtime.NewConfigFrom(*viper.Instance())
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// rules check the image or bundle under test, which a catalog is not.
+	if len(cfg.Rules) > 0 {
+		return fmt.Errorf("rules cannot be used with check catalog, as they only check containers and operators")
+	}
+
+	ctx = network.ContextWithPolicy(ctx, cfg.Network)
+
+	ctx, finishTelemetry, err := setupTelemetry(ctx, cfg, "check catalog", attribute.String("catalog", catalogRef))
+	if err != nil {
+		return err
+	}
+	defer func() { finishTelemetry(err) }()
+
+	signer, err := loadSigner(cfg.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	ctx, artifactsWriter, err := configureArtifactsWriter(ctx, cfg.Artifacts)
+	if err != nil {
+		//coverage:ignore
+		return err
+	}
+
+	formatter, err := formatters.NewByName(formatters.DefaultFormat)
+	if err != nil {
+		//coverage:ignore
+		return err
+	}
+
+	checkcatalog := catalog.NewCheck(catalogRef, generateCatalogCheckOptions(cfg)...)
+
+	cmd.SilenceUsage = true
+	if err := runpreflight(
+		ctx,
+		checkcatalog.Run,
+		cli.CheckConfig{
+			IncludeJUnitResults: cfg.WriteJUnit,
+			SubmitResults:       false, // catalog results are not submitted.
+		},
+		formatter,
+		&runtime.ResultWriterFile{},
+		&lib.NoopSubmitter{},
+	); err != nil {
+		return err
+	}
+
+	return writeArtifactsManifest(ctx, artifactsWriter, signer)
+}
+
+func checkCatalogPositionalArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("a catalog image or directory positional argument is required")
+	}
+
+	return nil
+}
+
+// generateCatalogCheckOptions returns options to be used with CatalogCheck based on cfg.
+func generateCatalogCheckOptions(cfg *runtime.Config) []catalog.Option {
+	opts := []catalog.Option{
+		catalog.WithDockerConfigJSONFromFile(cfg.DockerConfig),
+		catalog.WithRegistriesConfig(cfg.RegistriesConf, cfg.CertsDir, cfg.CredentialHelpers),
+	}
+
+	if cfg.Insecure {
+		opts = append(opts, catalog.WithInsecureConnection())
+	}
+
+	return opts
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+var _ = Describe("Check Catalog", func() {
+	BeforeEach(createAndCleanupDirForArtifactsAndLogs)
+
+	Context("when running the check catalog subcommand", func() {
+		Context("without the catalog being provided", func() {
+			It("should return an error", func() {
+				out, err := executeCommand(checkCatalogCmd(mockRunPreflightReturnNil))
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("a catalog image or directory positional argument is required"))
+			})
+		})
+
+		Context("with a catalog directory", func() {
+			It("should succeed", func() {
+				_, err := executeCommandWithLogger(checkCatalogCmd(mockRunPreflightReturnNil), logr.Discard(), GinkgoT().TempDir())
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("should return the error of the run", func() {
+				_, err := executeCommandWithLogger(checkCatalogCmd(mockRunPreflightReturnErr), logr.Discard(), GinkgoT().TempDir())
+				Expect(err).To(MatchError("random error"))
+			})
+		})
+
+		Context("with rules", func() {
+			BeforeEach(func() {
+				viper.Instance().Set("rules", []string{"/etc/preflight/rules.yaml"})
+				DeferCleanup(viper.Instance().Set, "rules", []string(nil))
+			})
+			It("should return an error rather than ignore them", func() {
+				_, err := executeCommandWithLogger(checkCatalogCmd(mockRunPreflightReturnNil), logr.Discard(), GinkgoT().TempDir())
+				Expect(err).To(MatchError("rules cannot be used with check catalog, as they only check containers and operators"))
+			})
+		})
+
+		Context("with the insecure flag", func() {
+			BeforeEach(func() {
+				DeferCleanup(viper.Instance().Set, "insecure", false)
+			})
+			It("should bind it to the insecure configuration", func() {
+				_, err := executeCommandWithLogger(checkCatalogCmd(mockRunPreflightReturnNil), logr.Discard(), GinkgoT().TempDir(), "--insecure")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(viper.Instance().GetBool("insecure")).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when generating the catalog check options", func() {
+		It("should include the insecure option when Insecure is true", func() {
+			baseOpts := generateCatalogCheckOptions(&runtime.Config{})
+			opts := generateCatalogCheckOptions(&runtime.Config{Insecure: true})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+})
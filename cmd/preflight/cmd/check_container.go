@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -19,6 +20,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
@@ -28,7 +30,9 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
@@ -59,6 +63,10 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 	flags.BoolVarP(&submit, "submit", "s", false, "submit check container results to Red Hat")
 	_ = viper.BindPFlag("submit", flags.Lookup("submit"))
 
+	flags.Bool("dry-run", false, "Prepare the submission without submitting it, writing the requests that would be sent to Red Hat\n"+
+		"to the artifacts directory. Only read-only requests are made. Requires --submit. (env: PFLT_DRY_RUN)")
+	_ = viper.BindPFlag("dry_run", flags.Lookup("dry-run"))
+
 	flags.Bool("insecure", false, "Use insecure protocol for the registry. Default is False. Cannot be used with submit.")
 	_ = viper.BindPFlag("insecure", flags.Lookup("insecure"))
 
@@ -87,6 +95,33 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 		"URL paramater. This value may differ from the component PID on the overview page. (env: PFLT_CERTIFICATION_COMPONENT_ID)"))
 	_ = viper.BindPFlag("certification_component_id", flags.Lookup("certification-component-id"))
 
+	flags.Bool("scan-secrets", false, "Scan every layer of the image for secrets, such as private keys and credentials. Findings are warnings,\n"+
+		"and are not part of certification, so this cannot be used with submit or offline. (env: PFLT_SCAN_SECRETS)")
+	_ = viper.BindPFlag("scan_secrets", flags.Lookup("scan-secrets"))
+
+	flags.String("secret-rules", "", "Path to a ruleset that customizes the secret scan. Requires --scan-secrets. (env: PFLT_SECRET_RULES)")
+	_ = viper.BindPFlag("secret_rules", flags.Lookup("secret-rules"))
+
+	flags.Bool("audit-file-permissions", false, "Audit the image for setuid and setgid files not installed by a package, world-writable directories\n"+
+		"without the sticky bit, and files with capabilities. Findings are warnings, and are not part of certification,\n"+
+		"so this cannot be used with submit or offline. (env: PFLT_AUDIT_FILE_PERMISSIONS)")
+	_ = viper.BindPFlag("audit_file_permissions", flags.Lookup("audit-file-permissions"))
+
+	flags.StringSlice("allowed-file-capabilities", nil, "Globs of paths, e.g. opt/app/bin/*, of files that may carry capabilities.\n"+
+		"Requires --audit-file-permissions. (env: PFLT_ALLOWED_FILE_CAPABILITIES)")
+	_ = viper.BindPFlag("allowed_file_capabilities", flags.Lookup("allowed-file-capabilities"))
+
+	flags.Bool("check-arbitrary-uid", false, "Check that the directories the image writes to can be written to by the arbitrary UID that OpenShift\n"+
+		"assigns to containers. Findings are warnings, and are not part of certification, so this cannot be used with submit or offline.\n"+
+		"(env: PFLT_CHECK_ARBITRARY_UID)")
+	_ = viper.BindPFlag("check_arbitrary_uid", flags.Lookup("check-arbitrary-uid"))
+
+	flags.Bool("analyze-layers", false, "Analyze the bytes each layer of the image adds, removes and overwrites, its package manager caches,\n"+
+		"and the files duplicated across its layers, writing the analysis to the artifacts directory. Findings are\n"+
+		"warnings unless a threshold in the layer_analysis config fails, and are not part of certification, so this\n"+
+		"cannot be used with submit or offline. (env: PFLT_ANALYZE_LAYERS)")
+	_ = viper.BindPFlag("analyze_layers", flags.Lookup("analyze-layers"))
+
 	flags.String("platform", rt.GOARCH, "Architecture of image to pull. Defaults to runtime platform.")
 	_ = viper.BindPFlag("platform", flags.Lookup("platform"))
 
@@ -98,7 +133,7 @@ func checkContainerCmd(runpreflight runPreflight) *cobra.Command {
 }
 
 // checkContainerRunE executes checkContainer using the user args to inform the execution.
-func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPreflight) error {
+func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPreflight) (err error) {
 	ctx := cmd.Context()
 	logger, err := logr.FromContext(ctx)
 	if err != nil {
@@ -135,11 +170,61 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 
 	cfg.Image = containerImage
 
+	// plugin, rule, and opt-in checks are not part of the Red Hat policy, and so must not be submitted,
+	// nor written to the artifacts tar that is submitted from a connected host.
+	if cfg.Submit || cfg.Offline {
+		flag := "--submit"
+		if cfg.Offline {
+			flag = "--offline"
+		}
+		for _, option := range []struct {
+			name    string
+			enabled bool
+		}{
+			{"plugins", len(cfg.Plugins) > 0},
+			{"rules", len(cfg.Rules) > 0},
+			{"the secret scan", cfg.ScanSecrets},
+			{"the file permissions audit", cfg.AuditFilePermissions},
+			{"the arbitrary UID check", cfg.CheckArbitraryUID},
+			{"the layer analysis", cfg.AnalyzeLayers},
+		} {
+			if option.enabled {
+				return fmt.Errorf("%s cannot be used with %s, as only the checks of the container policy are part of certification", option.name, flag)
+			}
+		}
+	}
+	if cfg.SecretRules != "" && !cfg.ScanSecrets {
+		return errors.New("a secret ruleset requires --scan-secrets")
+	}
+	if len(cfg.AllowedFileCapabilities) > 0 && !cfg.AuditFilePermissions {
+		return errors.New("allowed file capabilities require --audit-file-permissions")
+	}
+
+	ctx, finishTelemetry, err := setupTelemetry(ctx, cfg, "check container", attribute.String("image", containerImage))
+	if err != nil {
+		return err
+	}
+	defer func() { finishTelemetry(err) }()
+
+	// the registry settings are needed to look up the image's platforms, as well as by the checks.
+	registriesConfig, err := registries.New(cfg.RegistriesConf, cfg.CertsDir, cfg.CredentialHelpers)
+	if err != nil {
+		return err
+	}
+	ctx = registries.ContextWithConfig(ctx, registriesConfig)
+	ctx = network.ContextWithPolicy(ctx, cfg.Network)
+	cmd.SetContext(ctx)
+
 	containerImagePlatforms, err := platformsToBeProcessed(cmd, cfg)
 	if err != nil {
 		return err
 	}
 
+	signer, err := loadSigner(cfg.SigningKey)
+	if err != nil {
+		return err
+	}
+
 	for _, platform := range containerImagePlatforms {
 		logger.Info(fmt.Sprintf("running checks for %s for platform %s", containerImage, platform))
 		artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(filepath.Join(cfg.Artifacts, platform)))
@@ -168,6 +253,11 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 		pc := lib.NewPyxisClient(ctx, cfg.CertificationComponentID, cfg.PyxisAPIToken, cfg.PyxisHost)
 		resultSubmitter := lib.ResolveSubmitter(pc, cfg.CertificationComponentID, cfg.DockerConfig, cfg.LogFile)
 
+		// a dry run only sends read-only requests to pyxis, and writes the rest to the artifacts dir.
+		if cfg.DryRun && pc != nil {
+			resultSubmitter = lib.NewDryRunSubmitter(ctx, cfg.CertificationComponentID, cfg.PyxisAPIToken, cfg.PyxisHost, cfg.DockerConfig, cfg.LogFile)
+		}
+
 		// use a noop submitter, since the konflux system has no need to submit results to pyxis.
 		if cfg.Konflux {
 			resultSubmitter = lib.NewNoopSubmitter(true, nil)
@@ -190,6 +280,10 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 			return err
 		}
 
+		if err := writeArtifactsManifest(ctx, artifactsWriter, signer); err != nil {
+			return err
+		}
+
 		// checking for offline flag, if present tar up the contents of the artifacts directory
 		if cfg.Offline {
 			src := artifactsWriter.Path()
@@ -212,6 +306,23 @@ func checkContainerRunE(cmd *cobra.Command, args []string, runpreflight runPrefl
 				}
 			}
 
+			// the logfile is written to the artifacts directory in the offline flow, rather than
+			// the directory of the platform, so it is copied there to be submitted with the results,
+			// unless it is already written there.
+			logname := filepath.Base(cfg.LogFile)
+			if sameFile(cfg.LogFile, filepath.Join(src, logname)) {
+				logger.V(log.DBG).Info("logfile is already written to the artifacts directory", "logfile", cfg.LogFile)
+			} else if logfile, err := os.Open(filepath.Join(cfg.Artifacts, logname)); err == nil {
+				_, err = artifactsWriter.WriteFile(logname, logfile)
+				logfile.Close()
+				if err != nil {
+					//coverage:ignore
+					return fmt.Errorf("could not copy logfile to artifacts dir: %w", err)
+				}
+			} else {
+				logger.Info("logfile was not found in the artifacts directory, and will not be included in the tar", "logfile", logname)
+			}
+
 			// tar the directory
 			err = artifactsTar(ctx, src, &buf)
 			if err != nil {
@@ -263,6 +374,10 @@ func checkContainerPositionalArgs(cmd *cobra.Command, args []string) error {
 
 	viper := viper.Instance()
 
+	if viper.GetBool("dry_run") && !submit {
+		return fmt.Errorf("--dry-run can only be used when --submit is present")
+	}
+
 	// --submit was specified
 	if submit {
 		// If the flag is not marked as changed AND viper hasn't gotten it from environment, it's an error
@@ -321,6 +436,7 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		container.WithPlatform(cfg.Platform),
 		container.WithManifestListDigest(cfg.ManifestListDigest),
 		container.WithTempDir(cfg.TempDir),
+		container.WithRegistriesConfig(cfg.RegistriesConf, cfg.CertsDir, cfg.CredentialHelpers),
 	}
 
 	// set auth information if both are present in config.
@@ -339,9 +455,46 @@ func generateContainerCheckOptions(cfg *runtime.Config) []container.Option {
 		o = append(o, container.WithKonflux())
 	}
 
+	if len(cfg.Plugins) > 0 {
+		o = append(o, container.WithPlugins(cfg.Plugins...))
+	}
+
+	if len(cfg.Rules) > 0 {
+		o = append(o, container.WithRules(cfg.Rules...))
+	}
+
+	if cfg.ScanSecrets {
+		o = append(o, container.WithSecretScan(cfg.SecretRules))
+	}
+
+	if cfg.AuditFilePermissions {
+		o = append(o, container.WithFilePermissionsAudit(cfg.AllowedFileCapabilities...))
+	}
+
+	if cfg.CheckArbitraryUID {
+		o = append(o, container.WithArbitraryUIDCheck())
+	}
+
+	if cfg.AnalyzeLayers {
+		o = append(o, container.WithLayerAnalysis(cfg.LayerThresholds))
+	}
+
 	return o
 }
 
+// sameFile returns true if the paths a and b are of the same existing file.
+func sameFile(a, b string) bool {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	fb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fa, fb)
+}
+
 // artifactsTar takes a source path and a writer; a tar writer loops over the files in the source
 // directory, writes the appropriate header information and copies the file into the tar writer
 //
@@ -429,17 +582,36 @@ func platformsToBeProcessed(cmd *cobra.Command, cfg *runtime.Config) ([]string,
 
 	containerImagePlatforms := []string{cfg.Platform}
 
-	options := crane.GetOptions(option.GenerateCraneOptions(ctx, cfg)...)
-	ref, err := name.ParseReference(cfg.Image, options.Name...)
+	sources, err := registries.FromContext(ctx).Sources(cfg.Image)
 	if err != nil {
-		//coverage:ignore
-		return nil, fmt.Errorf("invalid image reference: %w", err)
+		return nil, err
 	}
 
-	desc, err := remote.Get(ref, options.Remote...)
-	if err != nil {
+	// use the first of the image's mirrors, or its own registry, that it can be retrieved from.
+	var desc *remote.Descriptor
+	var errs []error
+	for _, source := range sources {
+		craneOptions := option.GenerateCraneOptions(ctx, cfg)
+		if source.Insecure {
+			craneOptions = append(craneOptions, crane.Insecure)
+		}
+		options := crane.GetOptions(craneOptions...)
+
+		ref, err := name.ParseReference(source.Reference, options.Name...)
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("invalid image reference: %w", err)
+		}
+
+		desc, err = remote.Get(ref, options.Remote...)
+		if err == nil {
+			break
+		}
+		errs = append(errs, err)
+	}
+	if desc == nil {
 		//coverage:ignore
-		return nil, fmt.Errorf("invalid manifest?: %w", err)
+		return nil, fmt.Errorf("invalid manifest?: %w", errors.Join(errs...))
 	}
 
 	if !desc.MediaType.IsIndex() {
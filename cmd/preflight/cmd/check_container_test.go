@@ -31,7 +31,9 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
 	preruntime "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
@@ -177,6 +179,7 @@ var _ = Describe("Check Container Command", func() {
 			Entry("submit is passed after empty api token with certification-component-id", "pyxis API token and certification component ID are required when --submit is present", []string{"foo", "--certification-component-id=fooid", "--pyxis-api-token", "--submit"}),
 			Entry("certification-component-id and submit is passed with explicit value after empty api token", "pyxis API token and certification component ID are required when --submit is present", []string{"foo", "--certification-component-id=fooid", "--pyxis-api-token", "--submit=true"}),
 			Entry("certification-component-id and submit is passed and insecure is specified", "if any flags in the group [submit insecure] are set", []string{"foo", "--submit", "--insecure", "--certification-component-id=fooid", "--pyxis-api-token=footoken"}),
+			Entry("dry-run is passed without submit", "--dry-run can only be used when --submit is present", []string{"foo", "--dry-run"}),
 		)
 
 		When("the user enables the submit flag", func() {
@@ -309,6 +312,18 @@ var _ = Describe("Check Container Command", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(out).ToNot(BeNil())
 			})
+			It("should include the logfile written to the artifacts directory in the tar", func() {
+				artifactsDir := viper.Instance().GetString("artifacts")
+				logname := filepath.Base(viper.Instance().GetString("logfile"))
+				Expect(os.WriteFile(filepath.Join(artifactsDir, logname), []byte("offline log"), 0o600)).To(Succeed())
+
+				_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src)
+				Expect(err).ToNot(HaveOccurred())
+
+				extracted := GinkgoT().TempDir()
+				Expect(untarArtifacts(context.TODO(), filepath.Join(artifactsDir, goruntime.GOARCH, check.DefaultArtifactsTarFileName), extracted)).To(Succeed())
+				Expect(filepath.Join(extracted, logname)).To(BeARegularFile())
+			})
 		})
 		Context("when an existing artifacts.tar already on disk", func() {
 			BeforeEach(func() {
@@ -393,6 +408,160 @@ var _ = Describe("Check Container Command", func() {
 			})
 		})
 	})
+	DescribeTable("when an opt-in check is enabled with --offline",
+		func(expected string, configure func(), args ...string) {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+			configure()
+
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), append([]string{src, "--offline"}, args...)...)
+			Expect(err).To(MatchError(ContainSubstring(expected + " cannot be used with --offline")))
+		},
+		Entry("plugins", "plugins", func() {
+			viper.Instance().Set("plugins", []map[string]any{{"path": "/usr/local/bin/has-internal-ca-bundle"}})
+		}),
+		Entry("rules", "rules", func() {
+			viper.Instance().Set("rules", []string{"/etc/preflight/rules.yaml"})
+		}),
+		Entry("the secret scan", "the secret scan", func() {}, "--scan-secrets"),
+		Entry("the file permissions audit", "the file permissions audit", func() {}, "--audit-file-permissions"),
+		Entry("the arbitrary UID check", "the arbitrary UID check", func() {}, "--check-arbitrary-uid"),
+		Entry("the layer analysis", "the layer analysis", func() {}, "--analyze-layers"),
+	)
+
+	Context("when plugins are configured", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			viper.Instance().Set("plugins", []map[string]any{{"path": "/usr/local/bin/has-internal-ca-bundle"}})
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src)
+			Expect(err).To(MatchError(ContainSubstring("plugins cannot be used with --submit")))
+		})
+		It("should include the plugins option", func() {
+			cfg := &preruntime.Config{
+				Plugins: []plugin.Config{{Path: "/usr/local/bin/has-internal-ca-bundle"}},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
+	Context("when rules are configured", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			viper.Instance().Set("rules", []string{"/etc/preflight/rules.yaml"})
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src)
+			Expect(err).To(MatchError(ContainSubstring("rules cannot be used with --submit")))
+		})
+		It("should include the rules option", func() {
+			cfg := &preruntime.Config{
+				Rules: []string{"/etc/preflight/rules.yaml"},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
+	Context("when the secret scan is enabled", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--scan-secrets")
+			Expect(err).To(MatchError(ContainSubstring("the secret scan cannot be used with --submit")))
+		})
+		It("should require it for a secret ruleset", func() {
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--secret-rules", "/etc/preflight/secrets.yaml")
+			Expect(err).To(MatchError(ContainSubstring("a secret ruleset requires --scan-secrets")))
+		})
+		It("should include the secret scan option", func() {
+			cfg := &preruntime.Config{
+				ScanSecrets: true,
+				SecretRules: "/etc/preflight/secrets.yaml",
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
+	Context("when the file permissions audit is enabled", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--audit-file-permissions")
+			Expect(err).To(MatchError(ContainSubstring("the file permissions audit cannot be used with --submit")))
+		})
+		It("should require it for allowed file capabilities", func() {
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--allowed-file-capabilities", "opt/app/bin/*")
+			Expect(err).To(MatchError(ContainSubstring("allowed file capabilities require --audit-file-permissions")))
+		})
+		It("should include the file permissions audit option", func() {
+			cfg := &preruntime.Config{
+				AuditFilePermissions:    true,
+				AllowedFileCapabilities: []string{"opt/app/bin/*"},
+			}
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
+	Context("when the arbitrary UID check is enabled", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--check-arbitrary-uid")
+			Expect(err).To(MatchError(ContainSubstring("the arbitrary UID check cannot be used with --submit")))
+		})
+		It("should include the arbitrary UID check option", func() {
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(&preruntime.Config{CheckArbitraryUID: true})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
+	Context("when the layer analysis is enabled", func() {
+		BeforeEach(func() {
+			viper.Reset()
+			initConfig(viper.Instance())
+			DeferCleanup(viper.Reset)
+		})
+		It("should refuse to submit the results", func() {
+			viper.Instance().Set("submit", true)
+			_, err := executeCommandWithLogger(checkContainerCmd(mockRunPreflightReturnNil), logr.Discard(), src, "--analyze-layers")
+			Expect(err).To(MatchError(ContainSubstring("the layer analysis cannot be used with --submit")))
+		})
+		It("should include the layer analysis option", func() {
+			baseOpts := generateContainerCheckOptions(&preruntime.Config{})
+			opts := generateContainerCheckOptions(&preruntime.Config{AnalyzeLayers: true, LayerThresholds: layers.DefaultThresholds()})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+	})
+
 	Context("when PFLT_KONFLUX env is set to true", func() {
 		BeforeEach(func() {
 			viper.Reset()
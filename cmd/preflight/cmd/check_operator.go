@@ -2,17 +2,21 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/cli"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/operator"
@@ -61,6 +65,50 @@ func checkOperatorCmd(runpreflight runPreflight) *cobra.Command {
 		"If empty the default of 180s will be used. (env: PFLT_SUBSCRIPTION_TIMEOUT)")
 	_ = viper.BindPFlag("subscription_timeout", checkOperatorCmd.Flags().Lookup("subscription-timeout"))
 
+	checkOperatorCmd.Flags().String("catalog-base-image", "", fmt.Sprintf("The opm image that serves the catalog built for the bundle when PFLT_INDEXIMAGE is not set.\n"+
+		"If empty, %s is used. (env: PFLT_CATALOG_BASE_IMAGE)", catalog.DefaultBaseImage))
+	_ = viper.BindPFlag("catalog_base_image", checkOperatorCmd.Flags().Lookup("catalog-base-image"))
+
+	checkOperatorCmd.Flags().String("catalog-dir", "", "Write the catalog built for the bundle to this directory, rather than serving it from the cluster.\n"+
+		"Requires --catalog-address. (env: PFLT_CATALOG_DIR)")
+	_ = viper.BindPFlag("catalog_dir", checkOperatorCmd.Flags().Lookup("catalog-dir"))
+
+	checkOperatorCmd.Flags().String("catalog-address", "", "The address, reachable from the cluster, at which the catalog in --catalog-dir is served,\n"+
+		"e.g. by opm serve. Requires --catalog-dir. (env: PFLT_CATALOG_ADDRESS)")
+	_ = viper.BindPFlag("catalog_address", checkOperatorCmd.Flags().Lookup("catalog-address"))
+
+	checkOperatorCmd.Flags().Bool("all-install-modes", false, "Deploy the operator in every install mode its CSV supports, each in its own namespaces,\n"+
+		"rather than only the first of OwnNamespace, SingleNamespace, MultiNamespace and AllNamespaces. (env: PFLT_ALL_INSTALL_MODES)")
+	_ = viper.BindPFlag("all_install_modes", checkOperatorCmd.Flags().Lookup("all-install-modes"))
+
+	checkOperatorCmd.Flags().Bool("reconcile-alm-examples", false, "Add a check that creates the examples in the CSV's alm-examples once the operator is installed,\n"+
+		"and waits for the operator to reconcile them. (env: PFLT_RECONCILE_ALM_EXAMPLES)")
+	_ = viper.BindPFlag("reconcile_alm_examples", checkOperatorCmd.Flags().Lookup("reconcile-alm-examples"))
+
+	checkOperatorCmd.Flags().StringSlice("example-conditions", nil, "The status conditions, any of which being True means an example was reconciled.\n"+
+		"If empty, Ready and Available are used. (env: PFLT_EXAMPLE_CONDITIONS)")
+	_ = viper.BindPFlag("example_conditions", checkOperatorCmd.Flags().Lookup("example-conditions"))
+
+	checkOperatorCmd.Flags().Duration("example-timeout", 0, "The Duration of time to wait for the operator to reconcile each example.\n"+
+		"If empty the default of 180s will be used. (env: PFLT_EXAMPLE_TIMEOUT)")
+	_ = viper.BindPFlag("example_timeout", checkOperatorCmd.Flags().Lookup("example-timeout"))
+
+	checkOperatorCmd.Flags().StringSlice("approved-registries", nil, "The registries the images pulled by the operator's pods are approved from.\n"+
+		"If empty, the Red Hat registries are used. (env: PFLT_APPROVED_REGISTRIES)")
+	_ = viper.BindPFlag("approved_registries", checkOperatorCmd.Flags().Lookup("approved-registries"))
+
+	checkOperatorCmd.Flags().Bool("enforce-restricted-network", false, "Fail certification if a bundle claiming to support restricted networks does not pin,\n"+
+		"and list in relatedImages, every image it references, rather than warning. (env: PFLT_ENFORCE_RESTRICTED_NETWORK)")
+	_ = viper.BindPFlag("enforce_restricted_network", checkOperatorCmd.Flags().Lookup("enforce-restricted-network"))
+
+	checkOperatorCmd.Flags().Bool("version-matrix", false, "Validate the bundle against each OpenShift version in its com.redhat.openshift.versions range,\n"+
+		"rather than only the one it targets (env: PFLT_VERSION_MATRIX)")
+	_ = viper.BindPFlag("version_matrix", checkOperatorCmd.Flags().Lookup("version-matrix"))
+
+	checkOperatorCmd.Flags().String("openshift-versions", "", "A file of OpenShift versions, and the Kubernetes version of each, added to those known to preflight\n"+
+		"(env: PFLT_OPENSHIFT_VERSIONS)")
+	_ = viper.BindPFlag("openshift_versions", checkOperatorCmd.Flags().Lookup("openshift-versions"))
+
 	_ = checkOperatorCmd.Flags().MarkHidden("csv-timeout")
 	_ = checkOperatorCmd.Flags().MarkHidden("subscription-timeout")
 
@@ -76,18 +124,8 @@ func ensureKubeconfigIsSet() error {
 	return nil
 }
 
-// ensureIndexImageConfigIsSet ensures that the PFLT_INDEXIMAGE environment variable has
-// a value.
-func ensureIndexImageConfigIsSet() error {
-	if catalogImage := viper.Instance().GetString("indexImage"); len(catalogImage) == 0 {
-		return fmt.Errorf("environment variable PFLT_INDEXIMAGE could not be found")
-	}
-
-	return nil
-}
-
 // checkOperatorRunE executes checkOperator using the user args to inform the execution.
-func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPreflight) error {
+func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPreflight) (err error) {
 	ctx := cmd.Context()
 	logger, err := logr.FromContext(ctx)
 	if err != nil {
@@ -105,7 +143,28 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	ctx, _, err = configureArtifactsWriter(ctx, cfg.Artifacts)
+	// a catalog is only built for the bundle if there is no index image.
+	if cfg.IndexImage != "" && (cfg.CatalogBaseImage != "" || cfg.CatalogDir != "") {
+		return errors.New("the catalog options cannot be used with PFLT_INDEXIMAGE, as no catalog is built for the bundle")
+	}
+	if (cfg.CatalogDir == "") != (cfg.CatalogAddress == "") {
+		return errors.New("--catalog-dir and --catalog-address must be used together")
+	}
+
+	ctx = network.ContextWithPolicy(ctx, cfg.Network)
+
+	ctx, finishTelemetry, err := setupTelemetry(ctx, cfg, "check operator", attribute.String("image", operatorImage))
+	if err != nil {
+		return err
+	}
+	defer func() { finishTelemetry(err) }()
+
+	signer, err := loadSigner(cfg.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	ctx, artifactsWriter, err := configureArtifactsWriter(ctx, cfg.Artifacts)
 	if err != nil {
 		//coverage:ignore
 		return err
@@ -134,7 +193,7 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 	checkoperator := operator.NewCheck(operatorImage, cfg.IndexImage, kubeconfig, opts...)
 
 	cmd.SilenceUsage = true
-	return runpreflight(
+	if err := runpreflight(
 		ctx,
 		checkoperator.Run,
 		cli.CheckConfig{
@@ -144,7 +203,11 @@ func checkOperatorRunE(cmd *cobra.Command, args []string, runpreflight runPrefli
 		formatter,
 		&runtime.ResultWriterFile{},
 		&lib.NoopSubmitter{},
-	)
+	); err != nil {
+		return err
+	}
+
+	return writeArtifactsManifest(ctx, artifactsWriter, signer)
 }
 
 func checkOperatorPositionalArgs(cmd *cobra.Command, args []string) error {
@@ -156,10 +219,6 @@ func checkOperatorPositionalArgs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if err := ensureIndexImageConfigIsSet(); err != nil {
-		return err
-	}
-
 	return nil
 }
 
@@ -167,6 +226,7 @@ func checkOperatorPositionalArgs(cmd *cobra.Command, args []string) error {
 func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 	opts := []operator.Option{
 		operator.WithDockerConfigJSONFromFile(cfg.DockerConfig),
+		operator.WithRegistriesConfig(cfg.RegistriesConf, cfg.CertsDir, cfg.CredentialHelpers),
 	}
 
 	if cfg.Channel != "" {
@@ -185,6 +245,46 @@ func generateOperatorCheckOptions(cfg *runtime.Config) []operator.Option {
 		opts = append(opts, operator.WithSubscriptionTimeout(cfg.SubscriptionTimeout))
 	}
 
+	if len(cfg.Rules) > 0 {
+		opts = append(opts, operator.WithRules(cfg.Rules...))
+	}
+
+	if cfg.CatalogBaseImage != "" {
+		opts = append(opts, operator.WithCatalogBaseImage(cfg.CatalogBaseImage))
+	}
+
+	if cfg.CatalogDir != "" {
+		opts = append(opts, operator.WithCatalogDir(cfg.CatalogDir, cfg.CatalogAddress))
+	}
+
+	if cfg.AllInstallModes {
+		opts = append(opts, operator.WithAllInstallModes())
+	}
+
+	if cfg.ReconcileAlmExamples {
+		opts = append(opts, operator.WithAlmExamples(cfg.ExampleConditions, cfg.ExampleTimeout))
+	}
+
+	if len(cfg.ApprovedRegistries) != 0 {
+		opts = append(opts, operator.WithApprovedRegistries(cfg.ApprovedRegistries))
+	}
+
+	if len(cfg.ReadinessActions) != 0 {
+		opts = append(opts, operator.WithReadinessActions(cfg.ReadinessActions))
+	}
+
+	if cfg.EnforceRestrictedNetwork {
+		opts = append(opts, operator.WithRestrictedNetworkEnforced())
+	}
+
+	if cfg.VersionMatrix {
+		opts = append(opts, operator.WithVersionMatrix())
+	}
+
+	if cfg.OpenShiftVersions != "" {
+		opts = append(opts, operator.WithOpenShiftVersions(cfg.OpenShiftVersions))
+	}
+
 	return opts
 }
 
@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
@@ -37,7 +38,7 @@ var _ = Describe("Check Operator", func() {
 			})
 		})
 
-		Context("without having set the PFLT_INDEXIMAGE environment variable", func() {
+		Context("without having set the PFLT_INDEXIMAGE environment variable, as a catalog is built for the bundle", func() {
 			BeforeEach(func() {
 				if val, isSet := os.LookupEnv("PFLT_INDEXIMAGE"); isSet {
 					DeferCleanup(os.Setenv, "PFLT_INDEXIMAGE", val)
@@ -50,10 +51,33 @@ var _ = Describe("Check Operator", func() {
 				}
 				os.Setenv("KUBECONFIG", "foo")
 			})
-			It("should return an error", func() {
-				out, err := executeCommand(checkOperatorCmd(mockRunPreflightReturnNil), "quay.io/example/image:mytag")
+			It("should not require it", func() {
+				out, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag")
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring(": open foo: no such file or directory"))
+			})
+			It("should require the catalog directory and address to be used together", func() {
+				out, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag", "--catalog-dir", "/tmp/catalog")
+				Expect(err).To(HaveOccurred())
+				Expect(out).To(ContainSubstring("--catalog-dir and --catalog-address must be used together"))
+			})
+		})
+
+		Context("With an index image and catalog options", func() {
+			BeforeEach(func() {
+				DeferCleanup(viper.Instance().Set, "indexImage", viper.Instance().GetString("indexImage"))
+				viper.Instance().Set("indexImage", "foo")
+				if val, isSet := os.LookupEnv("KUBECONFIG"); isSet {
+					DeferCleanup(os.Setenv, "KUBECONFIG", val)
+				} else {
+					DeferCleanup(os.Unsetenv, "KUBECONFIG")
+				}
+				os.Setenv("KUBECONFIG", "foo")
+			})
+			It("should return an error, as no catalog is built", func() {
+				out, err := executeCommandWithLogger(checkOperatorCmd(mockRunPreflightReturnNil), logr.Discard(), "quay.io/example/image:mytag", "--catalog-base-image", "quay.io/operator-framework/opm:v1.50.0")
 				Expect(err).To(HaveOccurred())
-				Expect(out).To(ContainSubstring("PFLT_INDEXIMAGE could not"))
+				Expect(out).To(ContainSubstring("the catalog options cannot be used with PFLT_INDEXIMAGE"))
 			})
 		})
 
@@ -150,23 +174,6 @@ var _ = Describe("Check Operator", func() {
 				Expect(err).To(HaveOccurred())
 			})
 		})
-
-		Context("specifically, PFLT_INDEXIMAGE", func() {
-			BeforeEach(func() {
-				DeferCleanup(viper.Instance().Set, "indexImage", viper.Instance().GetString("indexImage"))
-				viper.Instance().Set("indexImage", "foo")
-			})
-			It("should not encounter an error if the value is set", func() {
-				err := ensureIndexImageConfigIsSet()
-				Expect(err).ToNot(HaveOccurred())
-			})
-
-			It("should encounter an error if the value is not set", func() {
-				viper.Instance().Set("indexImage", "")
-				err := ensureIndexImageConfigIsSet()
-				Expect(err).To(HaveOccurred())
-			})
-		})
 	})
 
 	Context("When testing positional arg parsing", func() {
@@ -219,5 +226,61 @@ var _ = Describe("Check Operator", func() {
 			opts := generateOperatorCheckOptions(cfg)
 			Expect(opts).To(HaveLen(len(baseOpts) + 2))
 		})
+
+		It("should include the rules option when rules are set", func() {
+			cfg := &runtime.Config{
+				Rules: []string{"/etc/preflight/bundle-rules.yaml"},
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the catalog options when they are set", func() {
+			cfg := &runtime.Config{
+				CatalogBaseImage: "quay.io/operator-framework/opm:v1.50.0",
+				CatalogDir:       "/tmp/catalog",
+				CatalogAddress:   "localhost:50051",
+			}
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(cfg)
+			Expect(opts).To(HaveLen(len(baseOpts) + 2))
+		})
+
+		It("should include the all install modes option when it is set", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{AllInstallModes: true})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the alm-examples option when it is set", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{ReconcileAlmExamples: true})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the approved registries option when they are set", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{ApprovedRegistries: []string{"quay.io"}})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the readiness actions option when they are set", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{ReadinessActions: readiness.DefaultActions()})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the restricted network option when it is enforced", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{EnforceRestrictedNetwork: true})
+			Expect(opts).To(HaveLen(len(baseOpts) + 1))
+		})
+
+		It("should include the version matrix options when they are set", func() {
+			baseOpts := generateOperatorCheckOptions(&runtime.Config{})
+			opts := generateOperatorCheckOptions(&runtime.Config{VersionMatrix: true, OpenShiftVersions: "/tmp/versions.yaml"})
+			Expect(opts).To(HaveLen(len(baseOpts) + 2))
+		})
 	})
 })
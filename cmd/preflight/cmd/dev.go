@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func devCmd() *cobra.Command {
+	devCmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Utilities for developing and testing preflight",
+		Long:  "Utilities intended for preflight development and for testing integrations with preflight. These are not used for certification.",
+	}
+
+	devCmd.AddCommand(devPyxisServerCmd())
+
+	return devCmd
+}
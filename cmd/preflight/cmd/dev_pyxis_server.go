@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis/pyxistest"
+)
+
+const defaultPyxisServerListenAddress = "127.0.0.1:8080"
+
+func devPyxisServerCmd() *cobra.Command {
+	devPyxisServerCmd := &cobra.Command{
+		Use:   "pyxis-server",
+		Short: "Run an in-memory stand-in for the Pyxis API",
+		Long: "This command runs a local, in-memory stand-in for the Pyxis API that can be used to exercise submissions\n" +
+			"without network access or credentials. State is lost when the server exits.",
+		Example: fmt.Sprintf("  %s\n  %s",
+			"preflight dev pyxis-server --fixtures fixtures.yaml",
+			"preflight check container quay.io/repo-name/container-name:version --submit --pyxis-host http://127.0.0.1:8080/api --pyxis-api-token anything"),
+		Args: cobra.NoArgs,
+		RunE: devPyxisServerRunE,
+	}
+
+	flags := devPyxisServerCmd.Flags()
+	flags.String("listen", defaultPyxisServerListenAddress, "Address on which the server listens.")
+	flags.String("fixtures", "", "A JSON or YAML file containing projects and images to seed the server with.")
+	flags.String("api-token", "", "Require this API token on authenticated requests. By default, any non-empty token is accepted.")
+
+	return devPyxisServerCmd
+}
+
+// devPyxisServerRunE runs the stand-in Pyxis server until the command's context is cancelled.
+func devPyxisServerRunE(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listen, _ := cmd.Flags().GetString("listen")
+	fixturesPath, _ := cmd.Flags().GetString("fixtures")
+	apiToken, _ := cmd.Flags().GetString("api-token")
+
+	opts := []pyxistest.Option{}
+	if fixturesPath != "" {
+		fixtures, err := pyxistest.LoadFixtures(fixturesPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, pyxistest.WithFixtures(fixtures))
+	}
+	if apiToken != "" {
+		opts = append(opts, pyxistest.WithAPIToken(apiToken))
+	}
+
+	ln, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", listen, err)
+	}
+
+	return servePyxisStandIn(ctx, ln, pyxistest.NewServer(opts...), cmd.OutOrStdout())
+}
+
+// servePyxisStandIn serves handler on ln until ctx is done, and reports the
+// pyxis host to use with the server to out.
+func servePyxisStandIn(ctx context.Context, ln net.Listener, handler http.Handler, out io.Writer) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	host := pyxistest.HostFor("http://" + ln.Addr().String())
+	fmt.Fprintf(out, "Pyxis stand-in server listening on %s\n", ln.Addr())
+	fmt.Fprintf(out, "Use it with: --pyxis-host %s\n", host)
+	logger.Info("pyxis stand-in server started", "host", host)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		//coverage:ignore
+		return fmt.Errorf("pyxis stand-in server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not shut down pyxis stand-in server: %w", err)
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		//coverage:ignore
+		return fmt.Errorf("pyxis stand-in server failed: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis/pyxistest"
+)
+
+var _ = Describe("dev pyxis-server subcommand", func() {
+	BeforeEach(createAndCleanupDirForArtifactsAndLogs)
+
+	Context("when the fixtures file does not exist", func() {
+		It("should fail", func() {
+			_, err := executeCommand(rootCmd(), "dev", "pyxis-server", "--listen", "127.0.0.1:0", "--fixtures", filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not read fixtures file"))
+		})
+	})
+
+	Context("when the listen address is invalid", func() {
+		It("should fail", func() {
+			_, err := executeCommand(rootCmd(), "dev", "pyxis-server", "--listen", "not-an-address")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not listen"))
+		})
+	})
+
+	Context("when serving", func() {
+		It("should serve until the context is cancelled", func() {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			out := &bytes.Buffer{}
+			done := make(chan error, 1)
+			go func() {
+				done <- servePyxisStandIn(ctx, ln, pyxistest.NewServer(), out)
+			}()
+
+			Eventually(func() error {
+				resp, err := http.Post(pyxistest.HostFor("http://"+ln.Addr().String())+"/graphql/", "application/json", bytes.NewBufferString("{}"))
+				if err != nil {
+					return err
+				}
+				return resp.Body.Close()
+			}).Should(Succeed())
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(out.String()).To(ContainSubstring("--pyxis-host http://" + ln.Addr().String() + "/api"))
+		})
+	})
+})
@@ -38,6 +38,9 @@ func printChecks(w io.Writer) {
 		"automatically applied for container checks if preflight determines a scratch exception flag has been added to your Red Hat Connect project"))
 	fmt.Fprintln(w, formattedPolicyBlock("Container Scratch (Root) Exception", engine.ScratchRootContainerPolicy(context.TODO()),
 		"automatically applied for container checks if preflight determines scratch and root exception flags have both been added to your Red Hat Connect project"))
+	fmt.Fprintln(w, formattedPolicyBlock("Catalog", engine.CatalogPolicy(context.TODO()), "invoked on file-based catalogs"))
+	fmt.Fprintln(w, formattedPolicyBlock("Webhook", engine.WebhookContainerPolicy(context.TODO()),
+		"the checks that may be enforced on workload images by preflight webhook"))
 }
 
 // formattedPolicyBlock accepts information about the checklist
@@ -71,6 +71,22 @@ var _ = Describe("list checks subcommand", func() {
 
 			Expect(buf.String()).To(ContainSubstring(expected))
 		})
+
+		It("should always contain the catalog policy", func() {
+			expected := formatList(engine.CatalogPolicy(context.TODO()))
+			buf := strings.Builder{}
+			printChecks(&buf)
+
+			Expect(buf.String()).To(ContainSubstring(expected))
+		})
+
+		It("should always contain the webhook policy", func() {
+			expected := formatList(engine.WebhookContainerPolicy(context.TODO()))
+			buf := strings.Builder{}
+			printChecks(&buf)
+
+			Expect(buf.String()).To(ContainSubstring(expected))
+		})
 	})
 
 	Context("When executing the cobra command", func() {
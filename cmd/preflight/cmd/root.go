@@ -64,6 +64,10 @@ func rootCmd() *cobra.Command {
 	rootCmd.AddCommand(checkCmd())
 	rootCmd.AddCommand(listChecksCmd())
 	rootCmd.AddCommand(supportCmd())
+	rootCmd.AddCommand(submitCmd())
+	rootCmd.AddCommand(verifyArtifactsCmd())
+	rootCmd.AddCommand(webhookCmd())
+	rootCmd.AddCommand(devCmd())
 
 	return rootCmd
 }
@@ -105,6 +109,9 @@ func initConfig(viper *spfviper.Viper) {
 
 	// Set up subscription timeout default
 	viper.SetDefault("subscription_timeout", runtime.DefaultSubscriptionTimeout)
+
+	// Set up alm-examples timeout default
+	viper.SetDefault("example_timeout", runtime.DefaultExampleTimeout)
 }
 
 // preRunConfig is used by cobra.PreRun in all non-root commands to load all necessary configurations
@@ -152,6 +159,36 @@ func preRunConfig(cmd *cobra.Command, args []string) {
 		l.Debug("config file not found, proceeding without it")
 	}
 
+	setContextLogger(cmd, l)
+}
+
+// preRunSubmit is used by cobra.PreRun in commands that submit, or verify, the results of
+// a previous execution. Logs are only written to stderr, because the logfile written during
+// that execution is submitted along with its results, and must not be truncated.
+func preRunSubmit(cmd *cobra.Command, args []string) {
+	l := logrus.New()
+	l.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	l.SetOutput(stderr)
+	if ll, err := logrus.ParseLevel(viper.Instance().GetString("loglevel")); err == nil {
+		l.SetLevel(ll)
+	}
+
+	setContextLogger(cmd, l)
+}
+
+// bindFlags binds the flags of cmd to their configuration keys in keys, by flag name. Flags such
+// as docker-config and insecure are shared by several commands, and viper only keeps the last flag
+// bound to a key, so commands other than check container bind them when they run, rather than
+// when they are created.
+func bindFlags(cmd *cobra.Command, keys map[string]string) {
+	viper := viper.Instance()
+	for name, key := range keys {
+		_ = viper.BindPFlag(key, cmd.Flags().Lookup(name))
+	}
+}
+
+// setContextLogger adds l to the command's context.
+func setContextLogger(cmd *cobra.Command, l *logrus.Logger) {
 	logger := logrusr.New(l)
 	ctx := logr.NewContext(cmd.Context(), logger)
 
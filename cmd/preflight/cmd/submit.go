@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	rt "runtime"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+func submitCmd() *cobra.Command {
+	submitCmd := &cobra.Command{
+		Use:   "submit [artifacts.tar|artifacts directory]",
+		Short: "Submit the results of a previous container check",
+		Long: "This command will submit the results of a previous execution of check container to Red Hat, using the artifacts\n" +
+			"written by that execution. The artifacts directory defaults to the platform-specific directory within the configured\n" +
+			"artifacts directory, e.g. artifacts/amd64. The artifacts tar written by check container --offline may be passed\n" +
+			"instead, so that results produced on a disconnected host can be submitted from a connected one. The artifacts are\n" +
+			"validated before anything is submitted.",
+		Args: cobra.MaximumNArgs(1),
+		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
+		Example:          fmt.Sprintf("  %s", "preflight submit artifacts/amd64 --resume --certification-component-id=<id> --pyxis-api-token=<token>"),
+		PersistentPreRun: preRunSubmit,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags(cmd, submitFlagKeys)
+			return validateCertificationComponentID(cmd, args)
+		},
+		RunE: submitRunE,
+	}
+
+	flags := submitCmd.Flags()
+	flags.Bool("resume", false, "Continue a previous submission from the last successful step recorded in\n"+
+		fmt.Sprintf("the %s file in the artifacts directory.", check.DefaultSubmissionJournal))
+	flags.StringP("docker-config", "d", "", "Path to docker config.json file used to pull the image. (env: PFLT_DOCKERCONFIG)")
+	flags.String("pyxis-api-token", "", "API token for Pyxis authentication (env: PFLT_PYXIS_API_TOKEN)")
+	flags.String("pyxis-host", "", "Host to use for Pyxis submissions. This will override Pyxis Env. Only set this if you know what you are doing.\n"+
+		"If you do set it, it should include just the host, and the URI path. (env: PFLT_PYXIS_HOST)")
+	flags.String("pyxis-env", check.DefaultPyxisEnv, "Env to use for Pyxis submissions.")
+	flags.String("certification-component-id", "", "Certification component ID from connect.redhat.com/component/view/{certification-component-id}/images\n"+
+		"URL paramater. This value may differ from the component PID on the overview page. (env: PFLT_CERTIFICATION_COMPONENT_ID)")
+
+	return submitCmd
+}
+
+// submitFlagKeys are the configuration keys of the submit command's flags.
+var submitFlagKeys = map[string]string{
+	"docker-config":              "dockerConfig",
+	"pyxis-api-token":            "pyxis_api_token",
+	"pyxis-host":                 "pyxis_host",
+	"pyxis-env":                  "pyxis_env",
+	"certification-component-id": "certification_component_id",
+}
+
+// submitRunE submits the results found in the artifacts directory to Pyxis.
+func submitRunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	logger := logr.FromContextOrDiscard(ctx)
+
+	cfg, err := runtime.NewConfigFrom(*viper.Instance())
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	ctx = network.ContextWithPolicy(ctx, cfg.Network)
+
+	if cfg.CertificationComponentID == "" || cfg.PyxisAPIToken == "" {
+		return fmt.Errorf("pyxis API token and certification component ID are required to submit results")
+	}
+
+	dir := filepath.Join(cfg.Artifacts, rt.GOARCH)
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	if strings.HasSuffix(dir, ".tar") {
+		// extract next to the tar rather than to a temporary directory, so the submission
+		// journal written there is available to a later --resume.
+		dst := strings.TrimSuffix(dir, ".tar") + "-submission"
+		if err := untarArtifacts(ctx, dir, dst); err != nil {
+			return fmt.Errorf("could not extract artifacts tar %s: %w", dir, err)
+		}
+		dir = dst
+	}
+
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf("artifacts directory %s does not exist or is not a directory", dir)
+	}
+
+	// prefer a logfile that was carried along with the artifacts, as it belongs to the
+	// execution that produced them.
+	logfile := cfg.LogFile
+	if fi, err := os.Stat(filepath.Join(dir, filepath.Base(cfg.LogFile))); err == nil && fi.Mode().IsRegular() {
+		logfile = filepath.Join(dir, filepath.Base(cfg.LogFile))
+	}
+
+	if err := lib.ValidateSubmissionArtifacts(ctx, dir, logfile); err != nil {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("artifacts in %s cannot be submitted:\n%w", dir, err)
+	}
+
+	// the manifest written by check confirms the artifacts have not changed since.
+	if _, err := os.Stat(filepath.Join(dir, artifacts.ManifestFilename)); err == nil {
+		if _, err := artifacts.VerifyManifest(dir, nil); err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("artifacts in %s have changed since they were written:\n%w", dir, err)
+		}
+	}
+
+	artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(dir))
+	if err != nil {
+		//coverage:ignore
+		return err
+	}
+	ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+	resume, _ := cmd.Flags().GetBool("resume")
+
+	submitter := &lib.ContainerCertificationSubmitter{
+		CertificationProjectID: cfg.CertificationComponentID,
+		Pyxis:                  lib.NewPyxisClient(ctx, cfg.CertificationComponentID, cfg.PyxisAPIToken, cfg.PyxisHost),
+		DockerConfig:           cfg.DockerConfig,
+		PreflightLogFile:       logfile,
+		Resume:                 resume,
+	}
+
+	logger.Info("submitting results", "artifacts", artifactsWriter.Path(), "resume", resume)
+
+	cmd.SilenceUsage = true
+	return submitter.Submit(ctx)
+}
+
+// untarArtifacts extracts the artifacts tar at src, as written by artifactsTar, into dst.
+// Only regular files at the top level of the tar are extracted, and any existing files
+// of the same name in dst are replaced.
+func untarArtifacts(ctx context.Context, src string, dst string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			logger.V(log.DBG).Info("skipping non-regular file in artifacts tar", "name", header.Name)
+			continue
+		}
+
+		if header.Name != filepath.Base(header.Name) || header.Name == ".." {
+			return fmt.Errorf("unexpected path %s in artifacts tar", header.Name)
+		}
+
+		err = func() error {
+			out, err := os.OpenFile(filepath.Join(dst, header.Name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			if _, err := io.Copy(out, tr); err != nil {
+				return err
+			}
+			return nil
+		}()
+		if err != nil {
+			return err
+		}
+	}
+}
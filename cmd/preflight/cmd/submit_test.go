@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis/pyxistest"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+var _ = Describe("submit subcommand", func() {
+	const (
+		projectID = "000000000000000000000abc"
+		digest    = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	)
+
+	var (
+		server *pyxistest.Server
+		host   string
+		dir    string
+	)
+
+	BeforeEach(func() {
+		createAndCleanupDirForArtifactsAndLogs()
+		viper.Reset()
+		DeferCleanup(viper.Reset)
+
+		server = pyxistest.NewServer(pyxistest.WithProjects(pyxis.CertProject{
+			ID:        projectID,
+			Name:      "my project",
+			Container: pyxis.Container{ISVPID: "my-isv-pid"},
+		}))
+		ts := httptest.NewServer(server)
+		DeferCleanup(ts.Close)
+		host = pyxistest.HostFor(ts.URL)
+
+		dir = GinkgoT().TempDir()
+		aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(dir))
+		Expect(err).ToNot(HaveOccurred())
+
+		certImage, err := json.Marshal(pyxis.CertImage{
+			DockerImageDigest: digest,
+			Repositories:      []pyxis.Repository{{Registry: "quay.io", Repository: "my/repo"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		results, err := json.Marshal(formatters.UserResponse{
+			Image:       "quay.io/my/repo@" + digest,
+			Passed:      true,
+			LibraryInfo: version.VersionContext{Name: "github.com/redhat-openshift-ecosystem/openshift-preflight", Version: "1.0.0"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(aw.WriteFile(check.DefaultCertImageFilename, bytes.NewReader(certImage)))
+		Expect(aw.WriteFile(check.DefaultTestResultsFilename, bytes.NewReader(results)))
+		Expect(aw.WriteFile(check.DefaultRPMManifestFilename, strings.NewReader("{}")))
+		Expect(os.WriteFile(os.Getenv("PFLT_LOGFILE"), []byte("preflight log"), 0o600)).To(Succeed())
+	})
+
+	Context("when the pyxis api token or certification component id are missing", func() {
+		It("should fail", func() {
+			_, err := executeCommand(rootCmd(), "submit", dir, "--pyxis-host", host)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("are required to submit results"))
+		})
+	})
+
+	Context("when the configuration is invalid", func() {
+		It("should fail", func() {
+			viper.Instance().Set("network.attempts", 0)
+			_, err := executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(MatchError(ContainSubstring("invalid configuration")))
+		})
+	})
+
+	Context("when the artifacts directory does not exist", func() {
+		It("should fail", func() {
+			_, err := executeCommand(rootCmd(), "submit", filepath.Join(dir, "missing"),
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("does not exist"))
+		})
+	})
+
+	Context("when submitting the results in an artifacts directory", func() {
+		It("should submit them", func() {
+			_, err := executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.Images()).To(HaveLen(1))
+			Expect(server.Artifacts()).To(HaveLen(1))
+			Expect(filepath.Join(dir, check.DefaultSubmissionJournal)).To(BeARegularFile())
+		})
+
+		It("should not truncate the logfile that is submitted", func() {
+			_, err := executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).ToNot(HaveOccurred())
+
+			b, err := os.ReadFile(os.Getenv("PFLT_LOGFILE"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(b)).To(Equal("preflight log"))
+		})
+
+		It("should resume a submission that failed midway", func() {
+			server.InjectError(pyxistest.RouteUpdateTestResults, http.StatusInternalServerError, 1)
+			_, err := executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(HaveOccurred())
+
+			_, err = executeCommand(rootCmd(), "submit", dir, "--resume",
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.Images()).To(HaveLen(1))
+			Expect(server.Artifacts()).To(HaveLen(1))
+		})
+	})
+
+	Context("when the artifacts are not valid", func() {
+		It("should fail without submitting anything", func() {
+			Expect(os.WriteFile(filepath.Join(dir, check.DefaultRPMManifestFilename),
+				[]byte(`{"image_id": "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"}`), 0o644)).To(Succeed())
+			_, err := executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot be submitted"))
+			Expect(server.Requests()).To(BeEmpty())
+		})
+	})
+
+	Context("when the artifacts have changed since the manifest was written", func() {
+		It("should fail without submitting anything", func() {
+			aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(dir))
+			Expect(err).ToNot(HaveOccurred())
+			_, err = aw.WriteFile(check.DefaultRPMManifestFilename, strings.NewReader("{}"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(aw.WriteManifest(nil)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, check.DefaultRPMManifestFilename), []byte(`{"rpms": []}`), 0o644)).To(Succeed())
+
+			_, err = executeCommand(rootCmd(), "submit", dir,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("have changed since they were written"))
+			Expect(server.Requests()).To(BeEmpty())
+		})
+	})
+
+	Context("when submitting the results in an artifacts tar", func() {
+		var tarPath string
+
+		BeforeEach(func() {
+			Expect(os.WriteFile(filepath.Join(dir, "preflight.log"), []byte("offline preflight log"), 0o600)).To(Succeed())
+
+			var buf bytes.Buffer
+			Expect(artifactsTar(context.TODO(), dir, &buf)).To(Succeed())
+			tarPath = filepath.Join(GinkgoT().TempDir(), check.DefaultArtifactsTarFileName)
+			Expect(os.WriteFile(tarPath, buf.Bytes(), 0o600)).To(Succeed())
+		})
+
+		It("should extract and submit them, along with the logfile in the tar", func() {
+			_, err := executeCommand(rootCmd(), "submit", tarPath,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.Images()).To(HaveLen(1))
+
+			submitted := server.Artifacts()
+			Expect(submitted).To(HaveLen(1))
+			Expect(submitted[0].Content).To(ContainSubstring(base64.StdEncoding.EncodeToString([]byte("offline preflight log"))))
+
+			extracted := strings.TrimSuffix(tarPath, ".tar") + "-submission"
+			Expect(filepath.Join(extracted, check.DefaultSubmissionJournal)).To(BeARegularFile())
+		})
+
+		It("should fail when the tar contains nested paths", func() {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			Expect(tw.WriteHeader(&tar.Header{Name: "../escape", Typeflag: tar.TypeReg, Size: 1, Mode: 0o644})).To(Succeed())
+			_, err := tw.Write([]byte("x"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+			Expect(os.WriteFile(tarPath, buf.Bytes(), 0o600)).To(Succeed())
+
+			_, err = executeCommand(rootCmd(), "submit", tarPath,
+				"--pyxis-host", host, "--pyxis-api-token", "token", "--certification-component-id", projectID)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unexpected path"))
+		})
+	})
+})
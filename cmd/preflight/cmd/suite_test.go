@@ -8,6 +8,8 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
 
 func TestCMD(t *testing.T) {
@@ -15,14 +17,22 @@ func TestCMD(t *testing.T) {
 	RunSpecs(t, "CMD Suite")
 }
 
+// createAndCleanupDirForArtifactsAndLogs points the artifacts, including the artifacts manifest,
+// and the log file at a temporary directory. The configuration is set as well as the environment,
+// as a value set in the configuration by an earlier test takes precedence over the environment.
+// The previous configuration is restored afterwards.
 var createAndCleanupDirForArtifactsAndLogs = func() {
-	tmpDir, err := os.MkdirTemp("", "cmd-execute-*")
-	Expect(err).ToNot(HaveOccurred())
+	tmpDir := GinkgoT().TempDir()
 	os.Setenv("PFLT_ARTIFACTS", filepath.Join(tmpDir, "artifacts"))
 	os.Setenv("PFLT_LOGFILE", filepath.Join(tmpDir, "preflight.log"))
-	DeferCleanup(os.RemoveAll, tmpDir)
 	DeferCleanup(os.Unsetenv, "PFLT_ARTIFACTS")
 	DeferCleanup(os.Unsetenv, "PFLT_LOGFILE")
+	for _, key := range []string{"artifacts", "logfile"} {
+		previous := viper.Instance().Get(key)
+		DeferCleanup(func() { viper.Instance().Set(key, previous) })
+	}
+	viper.Instance().Set("artifacts", filepath.Join(tmpDir, "artifacts"))
+	viper.Instance().Set("logfile", filepath.Join(tmpDir, "preflight.log"))
 }
 
 // In order to test some negative paths, this io.Writer will just throw an error
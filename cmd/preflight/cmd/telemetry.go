@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
+)
+
+// telemetryShutdownTimeout bounds how long exporting the remaining telemetry may take.
+const telemetryShutdownTimeout = 10 * time.Second
+
+// setupTelemetry configures telemetry from cfg, and starts the span named name that
+// the rest of the execution is traced under. The returned function ends that span with
+// the execution's error, and flushes the telemetry, writing any trace file to the root
+// of the artifacts directory.
+func setupTelemetry(ctx context.Context, cfg *runtime.Config, name string, attrs ...attribute.KeyValue) (context.Context, func(error), error) {
+	shutdown, err := telemetry.Setup(ctx, telemetry.Config{
+		Endpoint:  cfg.OTelEndpoint,
+		TraceFile: cfg.TraceFile,
+	})
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	ctx, span := telemetry.Start(ctx, name, attrs...)
+
+	return ctx, func(runErr error) {
+		telemetry.End(span, runErr)
+
+		logger := logr.FromContextOrDiscard(ctx)
+		// telemetry is exported even if the execution was cancelled.
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryShutdownTimeout)
+		defer cancel()
+
+		if cfg.TraceFile {
+			aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(cfg.Artifacts))
+			if err != nil {
+				//coverage:ignore
+				logger.Error(err, "could not write trace file")
+			} else {
+				shutdownCtx = artifacts.ContextWithWriter(shutdownCtx, aw)
+			}
+		}
+
+		if err := shutdown(shutdownCtx); err != nil {
+			logger.Error(err, "could not export telemetry")
+		}
+	}, nil
+}
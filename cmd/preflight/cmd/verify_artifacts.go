@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	rt "runtime"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+func verifyArtifactsCmd() *cobra.Command {
+	verifyArtifactsCmd := &cobra.Command{
+		Use:   "verify-artifacts [artifacts.tar|artifacts directory]",
+		Short: "Verify that artifacts have not changed since they were written",
+		Long: fmt.Sprintf("This command will verify that the files listed in the %s written by a previous execution of check\n", artifacts.ManifestFilename) +
+			"have not been modified since. If a public key is provided, the manifest's signature is verified as well. The artifacts\n" +
+			"directory defaults to the platform-specific directory within the configured artifacts directory, e.g. artifacts/amd64.",
+		Args: cobra.MaximumNArgs(1),
+		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
+		Example:          fmt.Sprintf("  %s", "preflight verify-artifacts artifacts/amd64 --public-key=signing.pub"),
+		PersistentPreRun: preRunSubmit,
+		RunE:             verifyArtifactsRunE,
+	}
+
+	verifyArtifactsCmd.Flags().String("public-key", "", "Path to a PEM encoded public key, or certificate, used to verify the signature of the artifacts manifest.\n"+
+		"If this is not set, only the digests of the artifacts are verified.")
+
+	return verifyArtifactsCmd
+}
+
+// verifyArtifactsRunE verifies the artifacts manifest in the artifacts directory, or tar.
+func verifyArtifactsRunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	logger := logr.FromContextOrDiscard(ctx)
+
+	dir := filepath.Join(viper.Instance().GetString("artifacts"), rt.GOARCH)
+	if len(args) == 1 {
+		dir = args[0]
+	}
+
+	if strings.HasSuffix(dir, ".tar") {
+		tmp, err := os.MkdirTemp("", "preflight-verify-artifacts-*")
+		if err != nil {
+			//coverage:ignore
+			return err
+		}
+		defer os.RemoveAll(tmp)
+
+		if err := untarArtifacts(ctx, dir, tmp); err != nil {
+			return fmt.Errorf("could not extract artifacts tar %s: %w", dir, err)
+		}
+		dir = tmp
+	}
+
+	var verifier artifacts.Verifier
+	if publicKey, _ := cmd.Flags().GetString("public-key"); publicKey != "" {
+		b, err := os.ReadFile(publicKey)
+		if err != nil {
+			return fmt.Errorf("could not read public key: %w", err)
+		}
+		verifier, err = artifacts.NewVerifierFromPEM(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	cmd.SilenceUsage = true
+	manifest, err := artifacts.VerifyManifest(dir, verifier)
+	if err != nil {
+		return fmt.Errorf("artifacts could not be verified:\n%w", err)
+	}
+
+	if verifier == nil {
+		logger.Info("the artifacts manifest signature was not verified, because no public key was provided")
+	}
+
+	for _, f := range manifest.Files {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s  %s\n", f.Digest, f.Filename)
+	}
+	logger.Info("artifacts verified", "files", len(manifest.Files), "signed", verifier != nil)
+
+	return nil
+}
+
+// loadSigner returns the Signer for the PEM encoded private key at path, or nil
+// if path is empty.
+func loadSigner(path string) (artifacts.Signer, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signing key: %w", err)
+	}
+
+	return artifacts.NewSignerFromPEM(b)
+}
+
+// writeArtifactsManifest writes the manifest of the files written by artifactsWriter,
+// signed by signer when it is not nil.
+func writeArtifactsManifest(ctx context.Context, artifactsWriter *artifacts.FilesystemWriter, signer artifacts.Signer) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if err := artifactsWriter.WriteManifest(signer); err != nil {
+		return fmt.Errorf("could not write artifacts manifest: %w", err)
+	}
+
+	logger.Info("artifacts manifest written to disk", "filename", artifacts.ManifestFilename, "signed", signer != nil)
+	return nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+var _ = Describe("verify-artifacts subcommand", func() {
+	var (
+		dir        string
+		signingKey string
+		publicKey  string
+	)
+
+	BeforeEach(func() {
+		createAndCleanupDirForArtifactsAndLogs()
+		viper.Reset()
+		DeferCleanup(viper.Reset)
+
+		keyDir := GinkgoT().TempDir()
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).ToNot(HaveOccurred())
+		privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+		Expect(err).ToNot(HaveOccurred())
+		publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+		Expect(err).ToNot(HaveOccurred())
+
+		signingKey = filepath.Join(keyDir, "signing.key")
+		publicKey = filepath.Join(keyDir, "signing.pub")
+		Expect(os.WriteFile(signingKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600)).To(Succeed())
+		Expect(os.WriteFile(publicKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600)).To(Succeed())
+
+		dir = GinkgoT().TempDir()
+		aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(dir))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = aw.WriteFile(check.DefaultTestResultsFilename, strings.NewReader(`{"passed": true}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		signer, err := loadSigner(signingKey)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writeArtifactsManifest(context.TODO(), aw, signer)).To(Succeed())
+	})
+
+	Context("when the artifacts have not been modified", func() {
+		It("should verify the digests and the signature", func() {
+			out, err := executeCommand(rootCmd(), "verify-artifacts", dir, "--public-key", publicKey)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(out).To(ContainSubstring("sha256:"))
+			Expect(out).To(ContainSubstring(check.DefaultTestResultsFilename))
+		})
+
+		It("should verify the digests without a public key", func() {
+			_, err := executeCommand(rootCmd(), "verify-artifacts", dir)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should verify the artifacts in a tar", func() {
+			var buf bytes.Buffer
+			Expect(artifactsTar(context.TODO(), dir, &buf)).To(Succeed())
+			tarPath := filepath.Join(GinkgoT().TempDir(), check.DefaultArtifactsTarFileName)
+			Expect(os.WriteFile(tarPath, buf.Bytes(), 0o600)).To(Succeed())
+
+			_, err := executeCommand(rootCmd(), "verify-artifacts", tarPath, "--public-key", publicKey)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("when an artifact has been modified", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(filepath.Join(dir, check.DefaultTestResultsFilename), []byte(`{"passed": false}`), 0o600)).To(Succeed())
+		})
+
+		It("should fail", func() {
+			_, err := executeCommand(rootCmd(), "verify-artifacts", dir, "--public-key", publicKey)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("artifacts could not be verified"))
+		})
+	})
+
+	Context("when the signing key cannot be read", func() {
+		It("should fail", func() {
+			_, err := loadSigner(filepath.Join(dir, "missing.key"))
+			Expect(err).To(MatchError(ContainSubstring("could not read signing key")))
+		})
+	})
+})
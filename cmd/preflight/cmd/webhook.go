@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	rt "runtime"
+	"syscall"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/webhook"
+)
+
+func webhookCmd() *cobra.Command {
+	webhookCmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Run a validating admission webhook that enforces preflight checks on workload images",
+		Long: "This command will run a validating admission webhook server. The images of the Pods and Deployments it is\n" +
+			"sent are checked using the webhook policy, a subset of the container policy that does not require Pyxis.\n" +
+			"Admission is denied, or a warning is returned, when an image does not comply. Results are cached by image digest.",
+		Args: cobra.NoArgs,
+		// this fmt.Sprintf is in place to keep spacing consistent with cobras two spaces that's used in: Usage, Flags, etc
+		Example: fmt.Sprintf("  %s", "preflight webhook --cert-dir /etc/preflight/tls --enforcement warn --checks HasLicense,RunAsNonRoot"),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			bindFlags(cmd, webhookFlagKeys)
+			return validateWebhookFlags(cmd)
+		},
+		RunE: webhookRunE,
+	}
+
+	flags := webhookCmd.Flags()
+	flags.Int("port", 9443, "The port the webhook is served on.")
+	flags.String("cert-dir", "", "Directory containing the serving certificate and key. It is watched for changes.\n"+
+		"Defaults to <temp-dir>/k8s-webhook-server/serving-certs.")
+	flags.String("cert-name", "tls.crt", "Name of the serving certificate in the cert-dir.")
+	flags.String("key-name", "tls.key", "Name of the serving key in the cert-dir.")
+	flags.String("enforcement", webhook.EnforcementDeny, fmt.Sprintf("What to do when an image does not comply. One of %s or %s.",
+		webhook.EnforcementDeny, webhook.EnforcementWarn))
+	flags.StringSlice("checks", nil, "The checks of the webhook policy to run. All of them are run if not set.\n"+
+		"See preflight list-checks.")
+	flags.Duration("cache-ttl", webhook.DefaultCacheTTL, "How long the result for an image digest is reused.")
+	flags.StringP("docker-config", "d", "", "Path to docker config.json file used to pull images. (env: PFLT_DOCKERCONFIG)")
+	flags.String("platform", rt.GOARCH, "Architecture of the images to check. (env: PFLT_PLATFORM)")
+	flags.Bool("insecure", false, "Use insecure protocol for the registries images are pulled from. Default is False.")
+	flags.String("registries-conf", "", "Path to a containers registries.conf file. (env: PFLT_REGISTRIES_CONF)")
+	flags.String("certs-dir", "", "Path to a directory of per-registry certificates, laid out like /etc/containers/certs.d.\n"+
+		"(env: PFLT_CERTS_DIR)")
+
+	return webhookCmd
+}
+
+// webhookFlagKeys are the configuration keys of the webhook command's flags.
+var webhookFlagKeys = map[string]string{
+	"docker-config":   "dockerConfig",
+	"platform":        "platform",
+	"insecure":        "insecure",
+	"registries-conf": "registries_conf",
+	"certs-dir":       "certs_dir",
+}
+
+// validateWebhookFlags ensures the webhook-specific flags have acceptable values.
+func validateWebhookFlags(cmd *cobra.Command) error {
+	enforcement, _ := cmd.Flags().GetString("enforcement")
+	if enforcement != webhook.EnforcementDeny && enforcement != webhook.EnforcementWarn {
+		return fmt.Errorf("invalid enforcement %q: must be %s or %s", enforcement, webhook.EnforcementDeny, webhook.EnforcementWarn)
+	}
+
+	return nil
+}
+
+// webhookRunE serves the admission webhook until the process is interrupted.
+func webhookRunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	logger := logr.FromContextOrDiscard(ctx)
+
+	cfg, err := runtime.NewConfigFrom(*viper.Instance())
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	registriesConfig, err := registries.New(cfg.RegistriesConf, cfg.CertsDir, cfg.CredentialHelpers)
+	if err != nil {
+		return err
+	}
+	ctx = registries.ContextWithConfig(ctx, registriesConfig)
+	ctx = network.ContextWithPolicy(ctx, cfg.Network)
+
+	flags := cmd.Flags()
+	checks, _ := flags.GetStringSlice("checks")
+	evaluate, err := webhook.NewEvaluator(ctx, *cfg, checks)
+	if err != nil {
+		return err
+	}
+
+	enforcement, _ := flags.GetString("enforcement")
+	cacheTTL, _ := flags.GetDuration("cache-ttl")
+	handler := webhook.NewHandler(webhook.NewResolver(*cfg), evaluate,
+		webhook.WithEnforcement(enforcement),
+		webhook.WithCacheTTL(cacheTTL),
+	)
+
+	port, _ := flags.GetInt("port")
+	certDir, _ := flags.GetString("cert-dir")
+	certName, _ := flags.GetString("cert-name")
+	keyName, _ := flags.GetString("key-name")
+	srv := webhook.NewServer(handler, crwebhook.Options{
+		Port:     port,
+		CertDir:  certDir,
+		CertName: certName,
+		KeyName:  keyName,
+	})
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd.SilenceUsage = true
+	logger.Info("serving preflight webhook", "port", port, "path", webhook.Path, "enforcement", enforcement, "platform", cfg.Platform)
+	return srv.Start(ctx)
+}
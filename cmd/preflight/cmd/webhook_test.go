@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
+)
+
+var _ = Describe("webhook subcommand", func() {
+	BeforeEach(func() {
+		createAndCleanupDirForArtifactsAndLogs()
+		viper.Reset()
+		DeferCleanup(viper.Reset)
+	})
+
+	It("should reject an unknown enforcement", func() {
+		_, err := executeCommand(webhookCmd(), "--enforcement", "audit")
+		Expect(err).To(MatchError(ContainSubstring(`invalid enforcement "audit"`)))
+	})
+
+	It("should reject a check that is not in the webhook policy", func() {
+		_, err := executeCommand(webhookCmd(), "--checks", "HasLicense,BasedOnUbi")
+		Expect(err).To(MatchError(ContainSubstring("check BasedOnUbi is not in the webhook policy")))
+	})
+
+	It("should fail to serve without a serving certificate", func() {
+		_, err := executeCommand(webhookCmd(), "--cert-dir", GinkgoT().TempDir(), "--port", "0")
+		Expect(err).To(HaveOccurred())
+	})
+})
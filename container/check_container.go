@@ -4,9 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/http"
 	goruntime "runtime"
-	"time"
 
 	"github.com/go-logr/logr"
 
@@ -14,10 +12,14 @@ import (
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/lib"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 )
 
@@ -47,6 +49,14 @@ func (c *containerCheck) Run(ctx context.Context) (certification.Results, error)
 		return certification.Results{}, err
 	}
 
+	registriesConfig, err := registries.New(c.registriesConf, c.certsDir, c.credentialHelpers)
+	if err != nil {
+		return certification.Results{}, err
+	}
+	if registriesConfig != nil {
+		ctx = registries.ContextWithConfig(ctx, registriesConfig)
+	}
+
 	cfg := runtime.Config{
 		Image:              c.image,
 		DockerConfig:       c.dockerconfigjson,
@@ -93,7 +103,7 @@ func (c *containerCheck) resolve(ctx context.Context) error {
 				c.pyxisHost,
 				c.pyxisToken,
 				c.certificationProjectID,
-				&http.Client{Timeout: 60 * time.Second},
+				network.FromContext(ctx).Client(),
 			)
 		}
 
@@ -121,10 +131,19 @@ func (c *containerCheck) resolve(ctx context.Context) error {
 	}
 
 	newChecks, err := engine.InitializeContainerChecks(ctx, c.policy, engine.ContainerCheckConfig{
-		DockerConfig:           c.dockerconfigjson,
-		PyxisAPIToken:          c.pyxisToken,
-		CertificationProjectID: c.certificationProjectID,
-		PyxisHost:              c.pyxisHost,
+		DockerConfig:            c.dockerconfigjson,
+		PyxisAPIToken:           c.pyxisToken,
+		CertificationProjectID:  c.certificationProjectID,
+		PyxisHost:               c.pyxisHost,
+		Plugins:                 c.plugins,
+		Rules:                   c.rules,
+		ScanSecrets:             c.scanSecrets,
+		SecretRules:             c.secretRules,
+		AuditFilePermissions:    c.auditFilePermissions,
+		AllowedFileCapabilities: c.allowedFileCapabilities,
+		CheckArbitraryUID:       c.checkArbitraryUID,
+		AnalyzeLayers:           c.analyzeLayers,
+		LayerThresholds:         c.layerThresholds,
 	})
 	if err != nil {
 		//coverage:ignore
@@ -213,6 +232,19 @@ func WithInsecureConnection() Option {
 	}
 }
 
+// WithRegistriesConfig configures how images are pulled from their registries. registriesConf
+// is a containers registries.conf file (version 2), whose mirrors, blocked registries, and
+// insecure settings are used. certsDir contains the certificates for each registry, laid out
+// like /etc/containers/certs.d. credentialHelpers maps a registry to the docker credential
+// helper holding its credentials, e.g. quay.io to secretservice. Each may be empty.
+func WithRegistriesConfig(registriesConf string, certsDir string, credentialHelpers map[string]string) Option {
+	return func(cc *containerCheck) {
+		cc.registriesConf = registriesConf
+		cc.certsDir = certsDir
+		cc.credentialHelpers = credentialHelpers
+	}
+}
+
 // WithManifestListDigest signifies that we have a manifest list and should add
 // this digest to any Pyxis calls.
 // This is only valid when submitting to Pyxis. Otherwise, it will be ignored.
@@ -229,6 +261,61 @@ func WithKonflux() Option {
 	}
 }
 
+// WithPlugins adds the checks implemented by plugins, i.e. executables or WebAssembly
+// modules, to those of the resolved policy. See the plugin package for the protocol they
+// implement.
+func WithPlugins(plugins ...plugin.Config) Option {
+	return func(cc *containerCheck) {
+		cc.plugins = append(cc.plugins, plugins...)
+	}
+}
+
+// WithRules adds the checks of the rules in the rules files at paths to those of the
+// resolved policy. See the rules package for the documents rules are evaluated against.
+func WithRules(paths ...string) Option {
+	return func(cc *containerCheck) {
+		cc.rules = append(cc.rules, paths...)
+	}
+}
+
+// WithSecretScan adds a scan of every layer of the image for secrets, such as private keys
+// and credentials, using the ruleset at rulesetPath, or the default ruleset if it is empty.
+func WithSecretScan(rulesetPath string) Option {
+	return func(cc *containerCheck) {
+		cc.scanSecrets = true
+		cc.secretRules = rulesetPath
+	}
+}
+
+// WithFilePermissionsAudit adds an audit of the image's files for setuid and setgid files not
+// installed by a package, world-writable directories without the sticky bit, and files with
+// capabilities. Files matching the globs in allowedCapabilities may carry capabilities.
+func WithFilePermissionsAudit(allowedCapabilities ...string) Option {
+	return func(cc *containerCheck) {
+		cc.auditFilePermissions = true
+		cc.allowedFileCapabilities = append(cc.allowedFileCapabilities, allowedCapabilities...)
+	}
+}
+
+// WithArbitraryUIDCheck adds a check that the directories the image writes to can be
+// written to by the arbitrary UID, in the root group, that OpenShift assigns to containers.
+func WithArbitraryUIDCheck() Option {
+	return func(cc *containerCheck) {
+		cc.checkArbitraryUID = true
+	}
+}
+
+// WithLayerAnalysis adds an analysis of the bytes each layer of the image adds, removes and
+// overwrites, its package manager caches, and the files duplicated across its layers. The
+// analysis is evaluated against thresholds, or the default thresholds if they are nil, and
+// written to the artifacts.
+func WithLayerAnalysis(thresholds layers.Thresholds) Option {
+	return func(cc *containerCheck) {
+		cc.analyzeLayers = true
+		cc.layerThresholds = thresholds
+	}
+}
+
 // WithTempDir sets the temporary directory for cache and filesystem
 func WithTempDir(tempDir string) Option {
 	//coverage:ignore
@@ -239,18 +326,30 @@ func WithTempDir(tempDir string) Option {
 }
 
 type containerCheck struct {
-	image                  string
-	dockerconfigjson       string
-	certificationProjectID string
-	pyxisToken             string
-	pyxisHost              string
-	platform               string
-	insecure               bool
-	manifestListDigest     string
-	checks                 []check.Check
-	resolved               bool
-	policy                 policy.Policy
-	konflux                bool
-	pyxisClient            lib.PyxisClient // for testing purposes
-	tempDir                string
+	image                   string
+	dockerconfigjson        string
+	certificationProjectID  string
+	pyxisToken              string
+	pyxisHost               string
+	platform                string
+	insecure                bool
+	registriesConf          string
+	certsDir                string
+	credentialHelpers       map[string]string
+	manifestListDigest      string
+	checks                  []check.Check
+	resolved                bool
+	policy                  policy.Policy
+	konflux                 bool
+	pyxisClient             lib.PyxisClient // for testing purposes
+	tempDir                 string
+	plugins                 []plugin.Config
+	rules                   []string
+	scanSecrets             bool
+	secretRules             string
+	auditFilePermissions    bool
+	allowedFileCapabilities []string
+	checkArbitraryUID       bool
+	analyzeLayers           bool
+	layerThresholds         layers.Thresholds
 }
@@ -5,8 +5,12 @@ import "errors"
 // Library-wide error messages are here.
 var (
 	ErrKubeconfigEmpty              = errors.New("kubeconfig value is empty")
-	ErrIndexImageEmpty              = errors.New("index image value is empty")
 	ErrImageEmpty                   = errors.New("image is empty")
 	ErrCannotResolvePolicyException = errors.New("cannot resolve policy exception")
 	ErrCannotInitializeChecks       = errors.New("unable to initialize checks")
 )
+
+// ErrIndexImageEmpty is no longer returned.
+//
+// Deprecated: an empty index image is not an error, as a catalog is built for the bundle.
+var ErrIndexImageEmpty = errors.New("index image value is empty")
@@ -0,0 +1,48 @@
+// Package action defines the actions a configurable check takes for its findings, and how they
+// decide the result and level of the check.
+package action
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+// Action is taken for a finding of a configurable check.
+type Action string
+
+// The actions taken for a finding.
+const (
+	Warn Action = "warn"
+	Fail Action = "fail"
+)
+
+// Validate returns an error if a is not Warn or Fail.
+func (a Action) Validate() error {
+	if a != Warn && a != Fail {
+		return fmt.Errorf("must be %s or %s, got %q", Warn, Fail, string(a))
+	}
+	return nil
+}
+
+// Fails is true if any of actions is Fail.
+func Fails(actions ...Action) bool {
+	return slices.Contains(actions, Fail)
+}
+
+// Failed is true if a finding taken with action a fails its check. A check configured to fail for
+// no finding is a warning, and so fails for each. Otherwise, it fails only for the findings whose
+// action is Fail, as its warnings would fail it too.
+func Failed(a Action, fails bool) bool {
+	return a == Fail || !fails
+}
+
+// Level is the level of a check, which is a best practice if it is configured to fail for any
+// finding, and a warning otherwise.
+func Level(fails bool) string {
+	if fails {
+		return check.LevelBest
+	}
+	return check.LevelWarn
+}
@@ -0,0 +1,38 @@
+package action_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+var _ = Describe("Action", func() {
+	It("should only accept warn and fail", func() {
+		Expect(action.Warn.Validate()).To(Succeed())
+		Expect(action.Fail.Validate()).To(Succeed())
+		Expect(action.Action("ignore").Validate()).To(MatchError(`must be warn or fail, got "ignore"`))
+	})
+
+	It("should fail if any action is fail", func() {
+		Expect(action.Fails()).To(BeFalse())
+		Expect(action.Fails(action.Warn, action.Warn)).To(BeFalse())
+		Expect(action.Fails(action.Warn, action.Fail)).To(BeTrue())
+	})
+
+	When("no action fails", func() {
+		It("should be a warning failed by every finding", func() {
+			Expect(action.Level(false)).To(Equal(check.LevelWarn))
+			Expect(action.Failed(action.Warn, false)).To(BeTrue())
+		})
+	})
+
+	When("an action fails", func() {
+		It("should be a best practice failed only by the findings whose action is fail", func() {
+			Expect(action.Level(true)).To(Equal(check.LevelBest))
+			Expect(action.Failed(action.Warn, true)).To(BeFalse())
+			Expect(action.Failed(action.Fail, true)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package authn
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	craneauthn "github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// DockerConfigJSON returns the docker config.json content, with auths added for the
+// registries of images that it has no credentials for, and whose credentials are held by
+// the credential helper configured in the registries.Config of ctx. This allows a pull
+// secret to be created for credentials that are not in a file. content is returned
+// unchanged when no credentials are added.
+func DockerConfigJSON(ctx context.Context, content []byte, images ...string) ([]byte, error) {
+	cfg := map[string]any{}
+	if len(content) != 0 {
+		if err := json.Unmarshal(content, &cfg); err != nil {
+			return nil, fmt.Errorf("could not parse docker config: %w", err)
+		}
+	}
+
+	auths, _ := cfg["auths"].(map[string]any)
+	if auths == nil {
+		auths = map[string]any{}
+	}
+
+	helperKeychain := &preflightKeychain{ctx: ctx}
+	added := false
+	for _, image := range images {
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse image reference %s: %w", image, err)
+		}
+
+		key := ref.Context().RegistryStr()
+		if key == name.DefaultRegistry {
+			key = craneauthn.DefaultAuthKey
+		}
+		if _, ok := auths[key]; ok {
+			continue
+		}
+
+		creds, err := helperKeychain.resolveFromCredentialHelper(ref.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case creds.IdentityToken != "":
+			auths[key] = map[string]any{"identitytoken": creds.IdentityToken}
+		case creds.Username != "" || creds.Password != "":
+			auths[key] = map[string]any{"auth": base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))}
+		default:
+			continue
+		}
+		added = true
+	}
+
+	if !added {
+		return content, nil
+	}
+
+	cfg["auths"] = auths
+	return json.Marshal(cfg)
+}
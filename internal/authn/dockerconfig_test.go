@@ -0,0 +1,58 @@
+package authn
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
+)
+
+var _ = Describe("DockerConfigJSON", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		setupCredentialHelper("test", "test.io", "foo", "bar")
+		ctx = registries.ContextWithConfig(context.TODO(), &registries.Config{
+			CredentialHelpers: map[string]string{"test.io": "test", "docker.io": "test"},
+		})
+	})
+
+	It("should add the credentials held by a credential helper", func() {
+		content, err := DockerConfigJSON(ctx, []byte(`{"auths": {"other.io": {"auth": "b3RoZXI6b3RoZXI="}}}`), "test.io/my-repo:latest")
+		Expect(err).ToNot(HaveOccurred())
+
+		var cfg struct {
+			Auths map[string]map[string]string `json:"auths"`
+		}
+		Expect(json.Unmarshal(content, &cfg)).To(Succeed())
+		Expect(cfg.Auths).To(HaveKey("other.io"))
+		Expect(cfg.Auths).To(HaveKeyWithValue("test.io", map[string]string{"auth": encode("foo", "bar")}))
+	})
+
+	It("should create the config when there is no content", func() {
+		content, err := DockerConfigJSON(ctx, nil, "test.io/my-repo:latest")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring(`"test.io"`))
+	})
+
+	It("should not replace credentials that are already in the config", func() {
+		original := []byte(`{"auths": {"test.io": {"auth": "b3RoZXI6b3RoZXI="}}}`)
+		content, err := DockerConfigJSON(ctx, original, "test.io/my-repo:latest")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(Equal(original))
+	})
+
+	It("should return the content unchanged when the helper has no credentials", func() {
+		content, err := DockerConfigJSON(ctx, nil, "busybox:latest")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(content).To(BeEmpty())
+	})
+
+	It("should fail when the content is not a docker config", func() {
+		_, err := DockerConfigJSON(ctx, []byte("not json"), "test.io/my-repo:latest")
+		Expect(err).To(MatchError(ContainSubstring("could not parse docker config")))
+	})
+})
@@ -8,11 +8,14 @@ import (
 
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/types"
+	"github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
 	"github.com/go-logr/logr"
 	craneauthn "github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 )
 
 type preflightKeychain struct {
@@ -58,31 +61,65 @@ func PreflightKeychain(ctx context.Context, opts ...PreflightKeychainOption) cra
 // are found for the target. This implements the Keychain interface from go-containerregistry,
 // and will be passed to crane,.
 //
-// If the dockerConfig value is empty, assume Anonymous.
-// If the file cannot be found or read, that constitutes an error.
+// Credentials in the dockerConfig, including those from its credHelpers and credsStore, are
+// used first. Then, those from the credential helper configured for the target's registry
+// in the registries.Config of the keychain's context. If neither has credentials, assume
+// Anonymous.
+// If the dockerConfig file cannot be found or read, that constitutes an error.
 // Can return os.IsNotExist.
 func (k *preflightKeychain) Resolve(target craneauthn.Resource) (craneauthn.Authenticator, error) {
 	logger := logr.FromContextOrDiscard(k.ctx)
 
 	logger.V(log.TRC).Info("entering preflight keychain Resolve")
 
+	cfg, err := k.resolveFromDockerConfig(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var empty types.AuthConfig
+	if cfg == empty {
+		cfg, err = k.resolveFromCredentialHelper(target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg == empty {
+		return craneauthn.Anonymous, nil
+	}
+
+	return craneauthn.FromConfig(craneauthn.AuthConfig{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Auth:          cfg.Auth,
+		IdentityToken: cfg.IdentityToken,
+		RegistryToken: cfg.RegistryToken,
+	}), nil
+}
+
+// resolveFromDockerConfig returns the credentials for target in the dockerConfig, or an
+// empty AuthConfig if there are none, or no dockerConfig is set.
+func (k *preflightKeychain) resolveFromDockerConfig(target craneauthn.Resource) (types.AuthConfig, error) {
+	var cfg, empty types.AuthConfig
+
 	if k.dockercfg == "" {
 		// No file specified. No auth expected
-		return craneauthn.Anonymous, nil
+		return empty, nil
 	}
 
 	r, err := os.Open(k.dockercfg)
 	if os.IsNotExist(err) {
-		return nil, fmt.Errorf("could not find authfile: %s: %w", k.dockercfg, err)
+		return empty, fmt.Errorf("could not find authfile: %s: %w", k.dockercfg, err)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("could not open authfile: %s: %v", k.dockercfg, err)
+		return empty, fmt.Errorf("could not open authfile: %s: %v", k.dockercfg, err)
 	}
 
 	defer r.Close()
 	cf, err := config.LoadFromReader(r)
 	if err != nil {
-		return nil, fmt.Errorf("could not load authfile from reader: %v", err)
+		return empty, fmt.Errorf("could not load authfile from reader: %v", err)
 	}
 
 	// We'll check the authconfig for creds associated with these endpoints.
@@ -102,7 +139,6 @@ func (k *preflightKeychain) Resolve(target craneauthn.Resource) (craneauthn.Auth
 		)
 	}
 
-	var cfg, empty types.AuthConfig
 	for _, key := range authFileTargets {
 		if key == name.DefaultRegistry {
 			key = craneauthn.DefaultAuthKey
@@ -114,28 +150,50 @@ func (k *preflightKeychain) Resolve(target craneauthn.Resource) (craneauthn.Auth
 		// (as podman does) but looked up as "index.docker.io".
 		// See: https://github.com/docker/cli/blob/v29.5.1/cli/config/configfile/file.go#L152
 		if authCfg, ok := cf.GetAuthConfigs()[key]; ok && authCfg != empty {
-			cfg = authCfg
-			break
+			return authCfg, nil
 		}
 
 		// Fall back to GetAuthConfig for credential helpers and other auth stores
 		cfg, err = cf.GetAuthConfig(key)
 		if err != nil {
-			return nil, fmt.Errorf("could not get auth config: %v", err)
+			return empty, fmt.Errorf("could not get auth config: %v", err)
 		}
 		if cfg != empty {
-			break
+			return cfg, nil
 		}
 	}
-	if cfg == empty {
-		return craneauthn.Anonymous, nil
+
+	return empty, nil
+}
+
+// resolveFromCredentialHelper returns the credentials for target from the credential
+// helper configured for its registry, or an empty AuthConfig if there are none, or no
+// helper is configured.
+func (k *preflightKeychain) resolveFromCredentialHelper(target craneauthn.Resource) (types.AuthConfig, error) {
+	var empty types.AuthConfig
+
+	helper := registries.FromContext(k.ctx).CredentialHelper(target.RegistryStr())
+	if helper == "" {
+		return empty, nil
 	}
 
-	return craneauthn.FromConfig(craneauthn.AuthConfig{
-		Username:      cfg.Username,
-		Password:      cfg.Password,
-		Auth:          cfg.Auth,
-		IdentityToken: cfg.IdentityToken,
-		RegistryToken: cfg.RegistryToken,
-	}), nil
+	serverURL := target.RegistryStr()
+	if serverURL == name.DefaultRegistry {
+		serverURL = craneauthn.DefaultAuthKey
+	}
+
+	creds, err := client.Get(client.NewShellProgramFunc("docker-credential-"+helper), serverURL)
+	if credentials.IsErrCredentialsNotFound(err) {
+		return empty, nil
+	}
+	if err != nil {
+		return empty, fmt.Errorf("could not get credentials for %s from credential helper %s: %v", serverURL, helper, err)
+	}
+
+	// credential helpers return identity tokens with a placeholder username.
+	if creds.Username == "<token>" {
+		return types.AuthConfig{IdentityToken: creds.Secret}, nil
+	}
+
+	return types.AuthConfig{Username: creds.Username, Password: creds.Secret}, nil
 }
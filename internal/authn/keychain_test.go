@@ -25,6 +25,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 )
 
 var (
@@ -65,6 +67,23 @@ func setupConfigFile(content string) string {
 	return cd
 }
 
+// setupCredentialHelper puts a docker-credential-<helper> executable on the PATH that
+// returns secret as the credentials for registry, and reports no credentials otherwise.
+func setupCredentialHelper(helper, registry, username, secret string) {
+	dir := GinkgoT().TempDir()
+	script := fmt.Sprintf(`#!/bin/sh
+read server
+if [ "$1" = get ] && [ "$server" = %q ]; then
+  echo '{"ServerURL": %q, "Username": %q, "Secret": %q}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`, registry, registry, username, secret)
+	Expect(os.WriteFile(filepath.Join(dir, "docker-credential-"+helper), []byte(script), 0o700)).To(Succeed())
+	GinkgoT().Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
 func encode(user, pass string) string {
 	delimited := fmt.Sprintf("%s:%s", user, pass)
 	return base64.StdEncoding.EncodeToString([]byte(delimited))
@@ -127,6 +146,57 @@ var _ = Describe("PreflightKeychain", func() {
 		})
 	})
 
+	When("a credential helper is configured for the registry", func() {
+		BeforeEach(func() {
+			setupCredentialHelper("test", testRegistry.RegistryStr(), "foo", "bar")
+			keychain.dockercfg = ""
+			keychain.ctx = registries.ContextWithConfig(context.TODO(), &registries.Config{
+				CredentialHelpers: map[string]string{testRegistry.RegistryStr(): "test"},
+			})
+		})
+
+		It("should use the credentials from the helper", func() {
+			auth, err := keychain.Resolve(testRegistry)
+			Expect(err).ToNot(HaveOccurred())
+			cfg, err := auth.Authorization()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg).To(Equal(&craneauthn.AuthConfig{Username: "foo", Password: "bar"}))
+		})
+
+		It("should prefer the credentials in the config file", func() {
+			cd := setupConfigFile(fmt.Sprintf(`{"auths": {"test.io": {"auth": %q}}}`, encode("baz", "quux")))
+			DeferCleanup(os.RemoveAll, cd)
+			keychain.ctx = registries.ContextWithConfig(context.TODO(), &registries.Config{
+				CredentialHelpers: map[string]string{testRegistry.RegistryStr(): "test"},
+			})
+
+			auth, err := keychain.Resolve(testRegistry)
+			Expect(err).ToNot(HaveOccurred())
+			cfg, err := auth.Authorization()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cfg).To(Equal(&craneauthn.AuthConfig{Username: "baz", Password: "quux"}))
+		})
+
+		It("should return Anonymous when the helper has no credentials", func() {
+			keychain.ctx = registries.ContextWithConfig(context.TODO(), &registries.Config{
+				CredentialHelpers: map[string]string{defaultRegistry.RegistryStr(): "test"},
+			})
+
+			auth, err := keychain.Resolve(defaultRegistry)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(auth).To(Equal(craneauthn.Anonymous))
+		})
+
+		It("should fail when the helper does not exist", func() {
+			keychain.ctx = registries.ContextWithConfig(context.TODO(), &registries.Config{
+				CredentialHelpers: map[string]string{testRegistry.RegistryStr(): "missing"},
+			})
+
+			_, err := keychain.Resolve(testRegistry)
+			Expect(err).To(MatchError(ContainSubstring("from credential helper missing")))
+		})
+	})
+
 	When("no config file is set", func() {
 		It("should return Anonymous", func() {
 			cd := setupConfigDir()
@@ -6,11 +6,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/blang/semver"
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/api/pkg/validation"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"sigs.k8s.io/yaml"
@@ -18,142 +19,145 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 )
 
-// This table signifies what the NEXT release of OpenShift will
-// deprecate, not what it matches up to.
-var ocpToKubeVersion = map[string]string{
-	"4.9":  "1.22",
-	"4.10": "1.23",
-	"4.11": "1.24",
-	"4.12": "1.25",
-	"4.13": "1.26",
-	"4.14": "1.27",
-	"4.15": "1.28",
-	"4.16": "1.29",
-	"4.17": "1.30",
-	"4.18": "1.31",
-	"4.19": "1.32",
-	"4.20": "1.33",
-	"4.21": "1.34",
-	"4.22": "1.35",
-	"4.23": "1.36",
-}
-
-const latestReleasedVersion = "4.22"
-
 var BundleFiles = []string{
 	"/manifests/*",
 	"/metadata/annotations.yaml",
 }
 
-func Validate(ctx context.Context, imagePath string) (*Report, error) {
-	logger := logr.FromContextOrDiscard(ctx)
-	logger.V(log.TRC).Info("reading annotations file from the bundle")
-	logger.V(log.DBG).Info("image extraction directory", "directory", imagePath)
+// Option configures the validation of a bundle.
+type Option func(*options)
 
-	bundle, err := manifests.GetBundleFromDir(imagePath)
-	if err != nil {
-		return nil, fmt.Errorf("could not load bundle from path: %s: %v", imagePath, err)
-	}
-	validators := validation.DefaultBundleValidators.WithValidators(
-		validation.AlphaDeprecatedAPIsValidator,
-		validation.OperatorHubV2Validator,
-		validation.StandardCapabilitiesValidator,
-		validation.StandardCategoriesValidator,
-	)
+type options struct {
+	versions *VersionTable
+}
 
-	objs := bundle.ObjectsToValidate()
+// WithVersionTable validates the bundle against the Kubernetes versions of versions, rather
+// than of the DefaultVersionTable.
+func WithVersionTable(versions *VersionTable) Option {
+	return func(o *options) {
+		o.versions = versions
+	}
+}
 
-	// retrieve the operator metadata from bundle image
-	annotationsFileName := filepath.Join(imagePath, "metadata", "annotations.yaml")
-	annotationsFile, err := os.Open(annotationsFileName)
-	if err != nil {
-		return nil, fmt.Errorf("could not open annotations.yaml: %v", err)
+func newOptions(opts []Option) *options {
+	o := &options{versions: defaultVersionTable}
+	for _, opt := range opts {
+		opt(o)
 	}
-	annotations, err := LoadAnnotations(ctx, annotationsFile)
+	return o
+}
+
+// Validate validates the bundle at imagePath against the Kubernetes version of the OpenShift
+// version its com.redhat.openshift.versions annotation targets, if it has one.
+func Validate(ctx context.Context, imagePath string, opts ...Option) (*Report, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	o := newOptions(opts)
+
+	objs, annotations, err := loadBundle(ctx, imagePath)
 	if err != nil {
-		//coverage:ignore
-		return nil, fmt.Errorf("unable to get annotations.yaml from the bundle: %v", err)
+		return nil, err
 	}
 
 	optionalValues := make(map[string]string)
 	if annotations.OpenshiftVersions != "" {
 		// Check that the label range contains >= 4.9
-		targetVersion, err := targetVersion(annotations.OpenshiftVersions)
+		targetVersion, err := o.versions.targetVersion(annotations.OpenshiftVersions)
 		if err != nil {
 			//coverage:ignore
 			// Could not parse the version, which probably means the annotation is invalid
 			return nil, fmt.Errorf("%v", err)
 		}
-		if k8sVer, found := ocpToKubeVersion[targetVersion]; found {
+		if k8sVer, found := o.versions.KubeVersion(targetVersion); found {
 			logger.V(log.DBG).Info("running with additional checks enabled because of the OpenShift version detected", "version", targetVersion)
 			optionalValues = make(map[string]string)
 			optionalValues["k8s-version"] = k8sVer
 		}
 	}
-	objs = append(objs, optionalValues)
 
-	results := validators.Validate(objs...)
-	passed := true
-	for _, v := range results {
-		if v.HasError() {
-			passed = false
-			break
-		}
+	return validate(objs, optionalValues), nil
+}
+
+// ValidateVersions validates the bundle at imagePath against the Kubernetes version of each
+// OpenShift version in the range of its com.redhat.openshift.versions annotation, in order.
+// It returns no reports if the bundle has no such annotation.
+func ValidateVersions(ctx context.Context, imagePath string, opts ...Option) ([]VersionReport, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	o := newOptions(opts)
+
+	objs, annotations, err := loadBundle(ctx, imagePath)
+	if err != nil {
+		return nil, err
+	}
+	if annotations.OpenshiftVersions == "" {
+		return nil, nil
+	}
+
+	ocpVersions, err := o.versions.Range(annotations.OpenshiftVersions)
+	if err != nil {
+		return nil, fmt.Errorf("%v", err)
 	}
 
-	return &Report{Results: results, Passed: passed}, nil
+	reports := make([]VersionReport, 0, len(ocpVersions))
+	for _, ocp := range ocpVersions {
+		kube, _ := o.versions.KubeVersion(ocp)
+		logger.V(log.DBG).Info("validating the bundle against an OpenShift version", "version", ocp, "kubernetes", kube)
+		reports = append(reports, VersionReport{
+			OpenShiftVersion:  ocp,
+			KubernetesVersion: kube,
+			Report:            *validate(objs, map[string]string{"k8s-version": kube}),
+		})
+	}
+	return reports, nil
 }
 
-func targetVersion(ocpLabelIndex string) (string, error) {
-	beginsEqual := strings.HasPrefix(ocpLabelIndex, "=")
-	// It means that the OCP label is =OCP version
-	if beginsEqual {
-		version := cleanStringToGetTheVersionToParse(strings.Split(ocpLabelIndex, "=")[1])
-		verParsed, err := semver.ParseTolerant(version)
-		if err != nil {
-			return "", fmt.Errorf("unable to parse the value (%s) on (%s): %v", version, ocpLabelIndex, err)
-		}
+// loadBundle returns the objects of the bundle at imagePath to validate, and its annotations.
+func loadBundle(ctx context.Context, imagePath string) ([]any, *Annotations, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	logger.V(log.TRC).Info("reading annotations file from the bundle")
+	logger.V(log.DBG).Info("image extraction directory", "directory", imagePath)
 
-		return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
+	bundle, err := manifests.GetBundleFromDir(imagePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load bundle from path: %s: %v", imagePath, err)
 	}
 
-	indexRange := cleanStringToGetTheVersionToParse(ocpLabelIndex)
-	if len(indexRange) > 1 {
-		// Bare version, so send back latest released
-		if !strings.Contains(indexRange, "-") {
-			verParsed, err := semver.ParseTolerant(indexRange)
-			if err != nil {
-				// The passed version is not valid. We don't care what it is,
-				// just that it's valid.
-				return "", fmt.Errorf("unable to parse the version: %v", err)
-			}
+	objs := bundle.ObjectsToValidate()
 
-			// If the specified version is greater than latestReleased, we will accept that
-			latestReleasedParsed, _ := semver.ParseTolerant(latestReleasedVersion)
-			if verParsed.GT(latestReleasedParsed) {
-				return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
-			}
+	// retrieve the operator metadata from bundle image
+	annotationsFileName := filepath.Join(imagePath, "metadata", "annotations.yaml")
+	annotationsFile, err := os.Open(annotationsFileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open annotations.yaml: %v", err)
+	}
+	defer annotationsFile.Close()
+	annotations, err := LoadAnnotations(ctx, annotationsFile)
+	if err != nil {
+		//coverage:ignore
+		return nil, nil, fmt.Errorf("unable to get annotations.yaml from the bundle: %v", err)
+	}
 
-			return latestReleasedVersion, nil
-		}
+	return objs, annotations, nil
+}
 
-		versions := strings.Split(indexRange, "-")
-		// This is a normal range of 1.0-2.0
-		if len(versions) > 1 && versions[1] != "" {
-			version := versions[1]
-			verParsed, err := semver.ParseTolerant(version)
-			if err != nil {
-				return "", fmt.Errorf("unable to parse the version: %v", err)
-			}
-			return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
-		}
+// validate runs the bundle validators on objs, with optionalValues.
+func validate(objs []any, optionalValues map[string]string) *Report {
+	validators := validation.DefaultBundleValidators.WithValidators(
+		validation.AlphaDeprecatedAPIsValidator,
+		validation.OperatorHubV2Validator,
+		validation.StandardCapabilitiesValidator,
+		validation.StandardCategoriesValidator,
+	)
 
-		// This is an open-ended range: v1-. This is not valid.
-		// So, we just fall through to the default return.
-		return "", fmt.Errorf("unable to parse the version: malformed range: %s", indexRange)
+	results := validators.Validate(append(slices.Clone(objs), optionalValues)...)
+	passed := true
+	for _, v := range results {
+		if v.HasError() {
+			passed = false
+			break
+		}
 	}
-	//coverage:ignore
-	return "", fmt.Errorf("unable to parse the version: unknown error")
+
+	return &Report{Results: results, Passed: passed}
 }
 
 // cleanStringToGetTheVersionToParse will remove the expected characters for
@@ -203,6 +207,26 @@ func GetSecurityContextConstraints(ctx context.Context, bundlePath string) ([]st
 	return nil, nil
 }
 
+// SecurityContextConstraintsByServiceAccount returns the names of the SCCs the operator in csv is granted the use of,
+// by the service account they are granted to. A rule without resourceNames grants the use of every SCC, which is
+// returned as rbacv1.ResourceAll.
+func SecurityContextConstraintsByServiceAccount(csv *operatorsv1alpha1.ClusterServiceVersion) map[string][]string {
+	sccs := map[string][]string{}
+	for _, cp := range csv.Spec.InstallStrategy.StrategySpec.ClusterPermissions {
+		for _, rule := range cp.Rules {
+			if !hasSCCApiGroup(rule) || !hasSCCResource(rule) || (!slices.Contains(rule.Verbs, "use") && !slices.Contains(rule.Verbs, rbacv1.VerbAll)) {
+				continue
+			}
+			if len(rule.ResourceNames) == 0 {
+				sccs[cp.ServiceAccountName] = append(sccs[cp.ServiceAccountName], rbacv1.ResourceAll)
+				continue
+			}
+			sccs[cp.ServiceAccountName] = append(sccs[cp.ServiceAccountName], rule.ResourceNames...)
+		}
+	}
+	return sccs
+}
+
 // hasSCCApiGroup returns a bool indicating if security.openshift.io is in the list of apigroups referenced in a policy
 // rule
 func hasSCCApiGroup(rule rbacv1.PolicyRule) bool {
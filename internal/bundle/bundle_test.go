@@ -3,10 +3,14 @@ package bundle
 import (
 	"bytes"
 	"context"
+	"os"
+	"path/filepath"
+	"slices"
 
 	. "github.com/onsi/ginkgo/v2/dsl/core"
 	. "github.com/onsi/ginkgo/v2/dsl/table"
 	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/manifests"
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
@@ -174,9 +178,51 @@ var _ = Describe("BundleValidateCheck", func() {
 		})
 	})
 
+	Describe("SecurityContextConstraintsByServiceAccount", func() {
+		It("should return the SCC resource names requested for each service account", func() {
+			b, err := manifests.GetBundleFromDir("./testdata/scc_bundle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(SecurityContextConstraintsByServiceAccount(b.CSV)).To(Equal(map[string][]string{
+				"memcached-operator-controller-manager": {"my-scc", "another-scc"},
+			}))
+		})
+
+		It("should return no SCCs if none are requested", func() {
+			b, err := manifests.GetBundleFromDir("./testdata/valid_bundle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(SecurityContextConstraintsByServiceAccount(b.CSV)).To(BeEmpty())
+		})
+
+		It("should only return the SCCs the use of is granted", func() {
+			b, err := manifests.GetBundleFromDir("./testdata/scc_bundle")
+			Expect(err).ToNot(HaveOccurred())
+			rules := b.CSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules
+			for i := range rules {
+				if slices.Contains(rules[i].Resources, "securitycontextconstraints") {
+					rules[i].Verbs = []string{"get", "list"}
+				}
+			}
+			Expect(SecurityContextConstraintsByServiceAccount(b.CSV)).To(BeEmpty())
+		})
+
+		It("should return every SCC if the use of any is granted", func() {
+			b, err := manifests.GetBundleFromDir("./testdata/scc_bundle")
+			Expect(err).ToNot(HaveOccurred())
+			rules := b.CSV.Spec.InstallStrategy.StrategySpec.ClusterPermissions[0].Rules
+			for i := range rules {
+				if slices.Contains(rules[i].Resources, "securitycontextconstraints") {
+					rules[i].ResourceNames = nil
+				}
+			}
+			Expect(SecurityContextConstraintsByServiceAccount(b.CSV)).To(Equal(map[string][]string{
+				"memcached-operator-controller-manager": {rbacv1.ResourceAll},
+			}))
+		})
+	})
+
 	DescribeTable("Image Registry validation",
 		func(versions string, expected string, success bool) {
-			version, err := targetVersion(versions)
+			version, err := defaultVersionTable.targetVersion(versions)
 			if success {
 				Expect(err).ToNot(HaveOccurred())
 			} else {
@@ -189,17 +235,17 @@ var _ = Describe("BundleValidateCheck", func() {
 		Entry("exactly 4.8", "=v4.8", "4.8", true),
 		Entry("exactly 4.9", "=v4.9", "4.9", true),
 		Entry("range 4.6 to 4.9", "v4.6-v4.9", "4.9", true),
-		Entry(">= 4.8", "v4.8", latestReleasedVersion, true),
-		Entry(">= 4.9", "v4.9", latestReleasedVersion, true),
-		Entry(">= 4.11", "v4.11", latestReleasedVersion, true),
-		Entry(">= 4.15", "v4.15", latestReleasedVersion, true),
-		Entry(">= 4.16, which is more than released", "v4.16", latestReleasedVersion, true),
-		Entry(">= 4.17, which is more than released", "v4.17", latestReleasedVersion, true),
-		Entry(">= 4.18, which is more than released", "v4.18", latestReleasedVersion, true),
-		Entry(">= 4.19, which is more than released", "v4.19", latestReleasedVersion, true),
-		Entry(">= 4.20, which is more than released", "v4.20", latestReleasedVersion, true),
-		Entry(">= 4.21, which is more than released", "v4.21", latestReleasedVersion, true),
-		Entry(">= 4.22, which is more than released", "v4.22", latestReleasedVersion, true),
+		Entry(">= 4.8", "v4.8", defaultVersionTable.Latest, true),
+		Entry(">= 4.9", "v4.9", defaultVersionTable.Latest, true),
+		Entry(">= 4.11", "v4.11", defaultVersionTable.Latest, true),
+		Entry(">= 4.15", "v4.15", defaultVersionTable.Latest, true),
+		Entry(">= 4.16, which is more than released", "v4.16", defaultVersionTable.Latest, true),
+		Entry(">= 4.17, which is more than released", "v4.17", defaultVersionTable.Latest, true),
+		Entry(">= 4.18, which is more than released", "v4.18", defaultVersionTable.Latest, true),
+		Entry(">= 4.19, which is more than released", "v4.19", defaultVersionTable.Latest, true),
+		Entry(">= 4.20, which is more than released", "v4.20", defaultVersionTable.Latest, true),
+		Entry(">= 4.21, which is more than released", "v4.21", defaultVersionTable.Latest, true),
+		Entry(">= 4.22, which is more than released", "v4.22", defaultVersionTable.Latest, true),
 		Entry(">= 4.23, which is more than released", "v4.23", "4.23", true),
 		Entry("begins = with error", "=foo", "", false),
 		Entry("bare version with error", "vfoo", "", false),
@@ -207,3 +253,99 @@ var _ = Describe("BundleValidateCheck", func() {
 		Entry("open-ended range is error", "v4.11-", "", false),
 	)
 })
+
+var _ = Describe("Bundle version matrix validation", func() {
+	It("should validate the bundle against each OpenShift version in its range", func() {
+		reports, err := ValidateVersions(context.Background(), "./testdata/removed_api_bundle")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(HaveLen(3))
+
+		Expect(reports[0].OpenShiftVersion).To(Equal("4.11"))
+		Expect(reports[0].KubernetesVersion).To(Equal("1.24"))
+		Expect(reports[0].Passed).To(BeTrue())
+
+		for _, r := range reports[1:] {
+			Expect(r.Passed).To(BeFalse(), "the PodDisruptionBudget API is removed in OpenShift %s", r.OpenShiftVersion)
+			Expect(r.Results).To(ContainElement(HaveField("Errors", ContainElement(
+				MatchError(ContainSubstring("APIs which were deprecated and removed in v1.25")),
+			))))
+		}
+		Expect(reports[1].OpenShiftVersion).To(Equal("4.12"))
+		Expect(reports[2].OpenShiftVersion).To(Equal("4.13"))
+	})
+
+	It("should return no reports if the bundle does not declare its OpenShift versions", func() {
+		reports, err := ValidateVersions(context.Background(), "./testdata/invalid_bundle")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reports).To(BeEmpty())
+	})
+
+	It("should error if the bundle cannot be loaded", func() {
+		_, err := ValidateVersions(context.Background(), "./testdata/no_annotations_file")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("OpenShift version table", func() {
+	DescribeTable("the OpenShift versions of an annotation",
+		func(versions string, expected []string) {
+			ocps, err := defaultVersionTable.Range(versions)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ocps).To(Equal(expected))
+		},
+		Entry("exactly 4.12", "=v4.12", []string{"4.12"}),
+		Entry("range 4.9 to 4.11", "v4.9-v4.11", []string{"4.9", "4.10", "4.11"}),
+		Entry("range 4.6 to 4.9, before the table", "v4.6-v4.9", []string{"4.9"}),
+		Entry("range with patch versions", "v4.12.3-v4.13.1", []string{"4.12", "4.13"}),
+		Entry(">= 4.20, up to the latest released", "v4.20", []string{"4.20", "4.21", "4.22"}),
+		Entry(">= 4.23, which is more than released", "v4.23", []string{"4.23"}),
+	)
+
+	It("should error if the annotation is invalid", func() {
+		_, err := defaultVersionTable.Range("v4.11-")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should return a copy of the default table", func() {
+		table := DefaultVersionTable()
+		table.Versions["4.9"] = "1.0"
+		Expect(defaultVersionTable.Versions["4.9"]).To(Equal("1.22"))
+	})
+
+	Context("loading a table", func() {
+		It("should return the default table without a path", func() {
+			table, err := LoadVersionTable("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(table).To(Equal(DefaultVersionTable()))
+		})
+
+		It("should add and replace the versions of the file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "versions.yaml")
+			Expect(os.WriteFile(path, []byte("latest: \"4.23\"\nversions:\n  \"4.24\": \"1.37\"\n  \"4.9\": \"1.21\"\n"), 0o644)).To(Succeed())
+			table, err := LoadVersionTable(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(table.Latest).To(Equal("4.23"))
+			Expect(table.Versions).To(HaveKeyWithValue("4.24", "1.37"))
+			Expect(table.Versions).To(HaveKeyWithValue("4.9", "1.21"))
+			Expect(table.Versions).To(HaveKeyWithValue("4.16", "1.29"))
+		})
+
+		It("should error if the file does not exist", func() {
+			_, err := LoadVersionTable(filepath.Join(GinkgoT().TempDir(), "versions.yaml"))
+			Expect(err).To(MatchError(ContainSubstring("could not read OpenShift versions")))
+		})
+
+		DescribeTable("should error if the file is invalid",
+			func(contents string) {
+				path := filepath.Join(GinkgoT().TempDir(), "versions.yaml")
+				Expect(os.WriteFile(path, []byte(contents), 0o644)).To(Succeed())
+				_, err := LoadVersionTable(path)
+				Expect(err).To(MatchError(ContainSubstring("could not decode OpenShift versions")))
+			},
+			Entry("unknown field", "releases: []\n"),
+			Entry("invalid latest version", "latest: foo\n"),
+			Entry("invalid OpenShift version", "versions:\n  foo: \"1.37\"\n"),
+			Entry("invalid Kubernetes version", "versions:\n  \"4.24\": foo\n"),
+		)
+	})
+})
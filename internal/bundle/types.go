@@ -19,3 +19,11 @@ type Report struct {
 	Results []validationerrors.ManifestResult
 	Passed  bool
 }
+
+// VersionReport is the Report of validating a bundle against the Kubernetes version of an
+// OpenShift version.
+type VersionReport struct {
+	Report
+	OpenShiftVersion  string
+	KubernetesVersion string
+}
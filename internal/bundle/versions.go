@@ -0,0 +1,201 @@
+package bundle
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver"
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed openshift_versions.yaml
+var defaultVersionTableYAML []byte
+
+// defaultVersionTable is the VersionTable in openshift_versions.yaml.
+var defaultVersionTable = mustParseVersionTable(defaultVersionTableYAML)
+
+// VersionTable maps OpenShift versions to the Kubernetes version a bundle is validated against
+// for each, and names the latest released OpenShift version.
+type VersionTable struct {
+	// Latest is the latest released OpenShift version. A bundle supporting an earlier version,
+	// and those after it, is validated against it.
+	Latest string `json:"latest,omitempty"`
+	// Versions maps OpenShift versions, e.g. 4.16, to Kubernetes versions, e.g. 1.29.
+	Versions map[string]string `json:"versions,omitempty"`
+}
+
+// DefaultVersionTable returns the versions known to this release of preflight.
+func DefaultVersionTable() *VersionTable {
+	return &VersionTable{
+		Latest:   defaultVersionTable.Latest,
+		Versions: copyVersions(defaultVersionTable.Versions),
+	}
+}
+
+// LoadVersionTable returns the DefaultVersionTable, with the versions in the file at path
+// added to, or replacing, its own, and its latest version replaced if the file sets one. If
+// path is empty, the DefaultVersionTable is returned.
+func LoadVersionTable(path string) (*VersionTable, error) {
+	table := DefaultVersionTable()
+	if path == "" {
+		return table, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read OpenShift versions: %w", err)
+	}
+	overrides, err := parseVersionTable(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode OpenShift versions %s: %w", path, err)
+	}
+
+	if overrides.Latest != "" {
+		table.Latest = overrides.Latest
+	}
+	for ocp, kube := range overrides.Versions {
+		table.Versions[ocp] = kube
+	}
+	return table, nil
+}
+
+func parseVersionTable(raw []byte) (*VersionTable, error) {
+	table := &VersionTable{}
+	if err := yaml.UnmarshalStrict(raw, table); err != nil {
+		return nil, err
+	}
+	if table.Latest != "" {
+		if _, err := semver.ParseTolerant(table.Latest); err != nil {
+			return nil, fmt.Errorf("invalid latest version %s: %w", table.Latest, err)
+		}
+	}
+	for ocp, kube := range table.Versions {
+		if _, err := semver.ParseTolerant(ocp); err != nil {
+			return nil, fmt.Errorf("invalid OpenShift version %s: %w", ocp, err)
+		}
+		if _, err := semver.ParseTolerant(kube); err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes version %s of OpenShift %s: %w", kube, ocp, err)
+		}
+	}
+	return table, nil
+}
+
+func mustParseVersionTable(raw []byte) *VersionTable {
+	table, err := parseVersionTable(raw)
+	if err != nil {
+		panic(fmt.Sprintf("invalid openshift_versions.yaml: %v", err))
+	}
+	return table
+}
+
+func copyVersions(versions map[string]string) map[string]string {
+	c := make(map[string]string, len(versions))
+	for k, v := range versions {
+		c[k] = v
+	}
+	return c
+}
+
+// KubeVersion returns the Kubernetes version of the OpenShift version ocp.
+func (t *VersionTable) KubeVersion(ocp string) (string, bool) {
+	kube, ok := t.Versions[ocp]
+	return kube, ok
+}
+
+// targetVersion returns the OpenShift version the com.redhat.openshift.versions annotation
+// ocpLabelIndex is validated against: the version it is equal to, the end of its range, or
+// the latest released version for a bare version, unless the bare version is later.
+func (t *VersionTable) targetVersion(ocpLabelIndex string) (string, error) {
+	beginsEqual := strings.HasPrefix(ocpLabelIndex, "=")
+	// It means that the OCP label is =OCP version
+	if beginsEqual {
+		version := cleanStringToGetTheVersionToParse(strings.Split(ocpLabelIndex, "=")[1])
+		verParsed, err := semver.ParseTolerant(version)
+		if err != nil {
+			return "", fmt.Errorf("unable to parse the value (%s) on (%s): %v", version, ocpLabelIndex, err)
+		}
+
+		return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
+	}
+
+	indexRange := cleanStringToGetTheVersionToParse(ocpLabelIndex)
+	if len(indexRange) > 1 {
+		// Bare version, so send back latest released
+		if !strings.Contains(indexRange, "-") {
+			verParsed, err := semver.ParseTolerant(indexRange)
+			if err != nil {
+				// The passed version is not valid. We don't care what it is,
+				// just that it's valid.
+				return "", fmt.Errorf("unable to parse the version: %v", err)
+			}
+
+			// If the specified version is greater than latestReleased, we will accept that
+			latestReleasedParsed, _ := semver.ParseTolerant(t.Latest)
+			if verParsed.GT(latestReleasedParsed) {
+				return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
+			}
+
+			return t.Latest, nil
+		}
+
+		versions := strings.Split(indexRange, "-")
+		// This is a normal range of 1.0-2.0
+		if len(versions) > 1 && versions[1] != "" {
+			version := versions[1]
+			verParsed, err := semver.ParseTolerant(version)
+			if err != nil {
+				return "", fmt.Errorf("unable to parse the version: %v", err)
+			}
+			return fmt.Sprintf("%d.%d", verParsed.Major, verParsed.Minor), nil
+		}
+
+		// This is an open-ended range: v1-. This is not valid.
+		// So, we just fall through to the default return.
+		return "", fmt.Errorf("unable to parse the version: malformed range: %s", indexRange)
+	}
+	//coverage:ignore
+	return "", fmt.Errorf("unable to parse the version: unknown error")
+}
+
+// Range returns the OpenShift versions in the table that the com.redhat.openshift.versions
+// annotation ocpLabelIndex includes, in order: the version it is equal to, those in its range,
+// or, for a bare version, it and those after it up to the latest released version.
+func (t *VersionTable) Range(ocpLabelIndex string) ([]string, error) {
+	end, err := t.targetVersion(ocpLabelIndex)
+	if err != nil {
+		return nil, err
+	}
+	last, _ := semver.ParseTolerant(end)
+
+	first := last
+	if !strings.HasPrefix(ocpLabelIndex, "=") {
+		start := strings.Split(cleanStringToGetTheVersionToParse(ocpLabelIndex), "-")[0]
+		parsed, err := semver.ParseTolerant(start)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the version: %v", err)
+		}
+		first = semver.Version{Major: parsed.Major, Minor: parsed.Minor}
+	}
+
+	type version struct {
+		ocp    string
+		parsed semver.Version
+	}
+	var inRange []version
+	for ocp := range t.Versions {
+		parsed, _ := semver.ParseTolerant(ocp)
+		if parsed.GTE(first) && parsed.LTE(last) {
+			inRange = append(inRange, version{ocp: ocp, parsed: parsed})
+		}
+	}
+	slices.SortFunc(inRange, func(a, b version) int { return a.parsed.Compare(b.parsed) })
+
+	ocps := make([]string, 0, len(inRange))
+	for _, v := range inRange {
+		ocps = append(ocps, v.ocp)
+	}
+	return ocps, nil
+}
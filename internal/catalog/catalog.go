@@ -0,0 +1,258 @@
+// Package catalog builds a file-based catalog (FBC) containing only the bundle under test,
+// so that OLM can install the bundle without an index image, and serves it to OLM. It also
+// loads the blobs of existing file-based catalogs, so that they can be validated.
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	"sigs.k8s.io/yaml"
+)
+
+// The schemas of the blobs in a file-based catalog.
+const (
+	SchemaPackage = "olm.package"
+	SchemaChannel = "olm.channel"
+	SchemaBundle  = "olm.bundle"
+)
+
+// The types of the bundle properties OLM resolves dependencies with.
+const (
+	PropertyPackage         = "olm.package"
+	PropertyGVK             = "olm.gvk"
+	PropertyPackageRequired = "olm.package.required"
+	PropertyGVKRequired     = "olm.gvk.required"
+)
+
+// Filename is the name of the file the catalog of a package is written to, in a directory
+// named after the package.
+const Filename = "catalog.json"
+
+// Package is the olm.package blob of a catalog.
+type Package struct {
+	Schema         string `json:"schema"`
+	Name           string `json:"name"`
+	DefaultChannel string `json:"defaultChannel"`
+}
+
+// Channel is an olm.channel blob of a catalog.
+type Channel struct {
+	Schema  string         `json:"schema"`
+	Name    string         `json:"name"`
+	Package string         `json:"package"`
+	Entries []ChannelEntry `json:"entries"`
+}
+
+// ChannelEntry is a bundle in a channel, and the bundles it upgrades from.
+type ChannelEntry struct {
+	Name      string   `json:"name"`
+	Replaces  string   `json:"replaces,omitempty"`
+	Skips     []string `json:"skips,omitempty"`
+	SkipRange string   `json:"skipRange,omitempty"`
+}
+
+// Bundle is an olm.bundle blob of a catalog.
+type Bundle struct {
+	Schema        string         `json:"schema"`
+	Name          string         `json:"name"`
+	Package       string         `json:"package"`
+	Image         string         `json:"image"`
+	Properties    []Property     `json:"properties"`
+	RelatedImages []RelatedImage `json:"relatedImages,omitempty"`
+}
+
+// Property is a property of a bundle.
+type Property struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// RelatedImage is an image referenced by a bundle.
+type RelatedImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// Catalog is a file-based catalog with a single package, channel and bundle.
+type Catalog struct {
+	Package Package
+	Channel Channel
+	Bundle  Bundle
+}
+
+// Files are the files of a file-based catalog, by their path relative to the catalog's root.
+type Files map[string][]byte
+
+// Build returns a catalog of the bundle in dir, whose image is image, as the only bundle of
+// the channel of packageName.
+func Build(dir, image, packageName, channel string) (*Catalog, error) {
+	if packageName == "" || channel == "" {
+		return nil, errors.New("a package and channel are required to build a catalog")
+	}
+
+	bundle, err := manifests.GetBundleFromDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the bundle: %w", err)
+	}
+
+	properties, err := bundleProperties(dir, packageName, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	relatedImages := []RelatedImage{{Image: image}}
+	for _, ri := range bundle.CSV.Spec.RelatedImages {
+		relatedImages = append(relatedImages, RelatedImage{Name: ri.Name, Image: ri.Image})
+	}
+
+	return &Catalog{
+		Package: Package{Schema: SchemaPackage, Name: packageName, DefaultChannel: channel},
+		Channel: Channel{Schema: SchemaChannel, Name: channel, Package: packageName, Entries: []ChannelEntry{{Name: bundle.CSV.Name}}},
+		Bundle: Bundle{
+			Schema:        SchemaBundle,
+			Name:          bundle.CSV.Name,
+			Package:       packageName,
+			Image:         image,
+			Properties:    properties,
+			RelatedImages: relatedImages,
+		},
+	}, nil
+}
+
+// bundleProperties returns the properties of bundle: its package and version, the APIs it
+// provides and requires, the dependencies in metadata/dependencies.yaml, and the properties
+// in metadata/properties.yaml.
+func bundleProperties(dir, packageName string, bundle *manifests.Bundle) ([]Property, error) {
+	var properties []Property
+	add := func(propertyType string, value any) error {
+		// version ranges, e.g. >=0.9.0, are kept readable.
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(value); err != nil {
+			//coverage:ignore
+			return fmt.Errorf("could not marshal property %s: %w", propertyType, err)
+		}
+		properties = append(properties, Property{Type: propertyType, Value: bytes.TrimSpace(buf.Bytes())})
+		return nil
+	}
+
+	if err := add(PropertyPackage, map[string]string{"packageName": packageName, "version": bundle.CSV.Spec.Version.String()}); err != nil {
+		return nil, err
+	}
+	for _, crd := range bundle.CSV.Spec.CustomResourceDefinitions.Owned {
+		if err := add(PropertyGVK, gvk(crd.Name, crd.Version, crd.Kind)); err != nil {
+			return nil, err
+		}
+	}
+	for _, api := range bundle.CSV.Spec.APIServiceDefinitions.Owned {
+		if err := add(PropertyGVK, map[string]string{"group": api.Group, "version": api.Version, "kind": api.Kind}); err != nil {
+			return nil, err
+		}
+	}
+	for _, crd := range bundle.CSV.Spec.CustomResourceDefinitions.Required {
+		if err := add(PropertyGVKRequired, gvk(crd.Name, crd.Version, crd.Kind)); err != nil {
+			return nil, err
+		}
+	}
+	for _, api := range bundle.CSV.Spec.APIServiceDefinitions.Required {
+		if err := add(PropertyGVKRequired, map[string]string{"group": api.Group, "version": api.Version, "kind": api.Kind}); err != nil {
+			return nil, err
+		}
+	}
+
+	var dependencies struct {
+		Dependencies []Property `json:"dependencies"`
+	}
+	if err := readMetadata(dir, "dependencies.yaml", &dependencies); err != nil {
+		return nil, err
+	}
+	for _, d := range dependencies.Dependencies {
+		switch d.Type {
+		case PropertyPackage:
+			var value struct {
+				PackageName string `json:"packageName"`
+				Version     string `json:"version"`
+			}
+			if err := json.Unmarshal(d.Value, &value); err != nil {
+				return nil, fmt.Errorf("invalid %s dependency: %w", d.Type, err)
+			}
+			if err := add(PropertyPackageRequired, map[string]string{"packageName": value.PackageName, "versionRange": value.Version}); err != nil {
+				return nil, err
+			}
+		case PropertyGVK:
+			properties = append(properties, Property{Type: PropertyGVKRequired, Value: d.Value})
+		}
+	}
+
+	var metadataProperties struct {
+		Properties []Property `json:"properties"`
+	}
+	if err := readMetadata(dir, "properties.yaml", &metadataProperties); err != nil {
+		return nil, err
+	}
+	properties = append(properties, metadataProperties.Properties...)
+
+	return properties, nil
+}
+
+// gvk returns the group, version and kind of the CRD with name, e.g. memcacheds.cache.example.com.
+func gvk(name, version, kind string) map[string]string {
+	_, group, _ := strings.Cut(name, ".")
+	return map[string]string{"group": group, "version": version, "kind": kind}
+}
+
+// readMetadata unmarshals the file with name in the bundle's metadata directory into v, if
+// the file exists.
+func readMetadata(dir, name string, v any) error {
+	contents, err := os.ReadFile(filepath.Join(dir, "metadata", name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read metadata/%s: %w", name, err)
+	}
+	if err := yaml.Unmarshal(contents, v); err != nil {
+		return fmt.Errorf("could not parse metadata/%s: %w", name, err)
+	}
+	return nil
+}
+
+// Files returns the files of the catalog: its blobs, in the file named Filename, in a
+// directory named after its package.
+func (c *Catalog) Files() (Files, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "    ")
+	enc.SetEscapeHTML(false)
+	for _, blob := range []any{c.Package, c.Channel, c.Bundle} {
+		if err := enc.Encode(blob); err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not marshal catalog: %w", err)
+		}
+	}
+	return Files{path.Join(c.Package.Name, Filename): buf.Bytes()}, nil
+}
+
+// WriteDir writes the files to dir.
+func (f Files) WriteDir(dir string) error {
+	for name, contents := range f {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			return fmt.Errorf("could not write catalog file %s: %w", name, err)
+		}
+		if err := os.WriteFile(filename, contents, 0o644); err != nil {
+			return fmt.Errorf("could not write catalog file %s: %w", name, err)
+		}
+	}
+	return nil
+}
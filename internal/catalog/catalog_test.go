@@ -0,0 +1,180 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	fakecg "k8s.io/client-go/kubernetes/fake"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
+)
+
+const bundleImage = "quay.io/example/memcached-operator-bundle:v0.0.2"
+
+var _ = Describe("Catalog", func() {
+	Context("When building a catalog for a bundle", func() {
+		It("should contain the package, the channel, and the bundle", func() {
+			cat, err := Build("./testdata/bundle", bundleImage, "memcached-operator", "fast")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cat.Package).To(Equal(Package{Schema: SchemaPackage, Name: "memcached-operator", DefaultChannel: "fast"}))
+			Expect(cat.Channel).To(Equal(Channel{
+				Schema:  SchemaChannel,
+				Name:    "fast",
+				Package: "memcached-operator",
+				Entries: []ChannelEntry{{Name: "memcached-operator.v0.0.2"}},
+			}))
+			Expect(cat.Bundle.Name).To(Equal("memcached-operator.v0.0.2"))
+			Expect(cat.Bundle.Image).To(Equal(bundleImage))
+			Expect(cat.Bundle.RelatedImages).To(ConsistOf(
+				RelatedImage{Image: bundleImage},
+				RelatedImage{Name: "manager", Image: "quay.io/example/memcached-operator@sha256:0000000000000000000000000000000000000000000000000000000000000000"},
+			))
+		})
+
+		It("should have the properties OLM resolves dependencies with", func() {
+			cat, err := Build("./testdata/bundle", bundleImage, "memcached-operator", "stable")
+			Expect(err).ToNot(HaveOccurred())
+
+			properties := map[string][]string{}
+			for _, p := range cat.Bundle.Properties {
+				properties[p.Type] = append(properties[p.Type], string(p.Value))
+			}
+			Expect(properties).To(Equal(map[string][]string{
+				PropertyPackage:           {`{"packageName":"memcached-operator","version":"0.0.2"}`},
+				PropertyGVK:               {`{"group":"cache.example.com","kind":"Memcached","version":"v1alpha1"}`},
+				PropertyGVKRequired:       {`{"group":"backup.example.com","kind":"Backup","version":"v1"}`, `{"group":"monitoring.coreos.com","kind":"ServiceMonitor","version":"v1"}`},
+				PropertyPackageRequired:   {`{"packageName":"etcd","versionRange":">=0.9.0"}`},
+				"olm.maxOpenShiftVersion": {`"4.20"`},
+			}))
+		})
+
+		It("should require a package and a channel", func() {
+			_, err := Build("./testdata/bundle", bundleImage, "memcached-operator", "")
+			Expect(err).To(MatchError(ContainSubstring("a package and channel are required")))
+		})
+
+		It("should return an error if the bundle cannot be read", func() {
+			_, err := Build("./testdata/missing", bundleImage, "memcached-operator", "stable")
+			Expect(err).To(MatchError(ContainSubstring("could not read the bundle")))
+		})
+
+		It("should write its blobs to a file named after the package", func() {
+			cat, err := Build("./testdata/bundle", bundleImage, "memcached-operator", "stable")
+			Expect(err).ToNot(HaveOccurred())
+			files, err := cat.Files()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveKey("memcached-operator/catalog.json"))
+
+			var schemas []string
+			dec := json.NewDecoder(bytes.NewReader(files["memcached-operator/catalog.json"]))
+			for dec.More() {
+				var blob struct {
+					Schema string `json:"schema"`
+				}
+				Expect(dec.Decode(&blob)).To(Succeed())
+				schemas = append(schemas, blob.Schema)
+			}
+			Expect(schemas).To(Equal([]string{SchemaPackage, SchemaChannel, SchemaBundle}))
+
+			dir := GinkgoT().TempDir()
+			Expect(files.WriteDir(dir)).To(Succeed())
+			Expect(filepath.Join(dir, "memcached-operator", "catalog.json")).To(BeAnExistingFile())
+		})
+	})
+})
+
+var _ = Describe("Server", func() {
+	var (
+		ctx   context.Context
+		files Files
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		files = Files{"memcached-operator/catalog.json": []byte(`{"schema":"olm.package"}`)}
+	})
+
+	Context("When serving a catalog from the cluster", func() {
+		var (
+			client crclient.Client
+			server *ClusterServer
+		)
+
+		BeforeEach(func() {
+			scheme := apiruntime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(openshift.AddSchemes(scheme)).To(Succeed())
+			client = fake.NewClientBuilder().WithScheme(scheme).Build()
+			server = NewClusterServer(openshift.NewClient(client, fakecg.NewClientset()), "", "registry-auth-keys")
+		})
+
+		It("should serve it with opm, from a ConfigMap", func() {
+			address, err := server.Serve(ctx, "memcached", "preflight", files)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(address).To(Equal("memcached-catalog.preflight.svc:50051"))
+
+			var configMap corev1.ConfigMap
+			Expect(client.Get(ctx, crclient.ObjectKey{Name: "memcached-catalog", Namespace: "preflight"}, &configMap)).To(Succeed())
+			Expect(configMap.Data).To(Equal(map[string]string{"catalog-0": `{"schema":"olm.package"}`}))
+
+			var pod corev1.Pod
+			Expect(client.Get(ctx, crclient.ObjectKey{Name: "memcached-catalog", Namespace: "preflight"}, &pod)).To(Succeed())
+			Expect(pod.Spec.Containers[0].Image).To(Equal(DefaultBaseImage))
+			Expect(pod.Spec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "registry-auth-keys"}))
+			Expect(pod.Spec.Volumes[0].ConfigMap.Items).To(ConsistOf(corev1.KeyToPath{Key: "catalog-0", Path: "memcached-operator/catalog.json"}))
+
+			var service corev1.Service
+			Expect(client.Get(ctx, crclient.ObjectKey{Name: "memcached-catalog", Namespace: "preflight"}, &service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(pod.Labels))
+		})
+
+		It("should use the base image it is given", func() {
+			server = NewClusterServer(openshift.NewClient(client, fakecg.NewClientset()), "registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.18")
+			_, err := server.Serve(ctx, "memcached", "preflight", files)
+			Expect(err).ToNot(HaveOccurred())
+
+			var pod corev1.Pod
+			Expect(client.Get(ctx, crclient.ObjectKey{Name: "memcached-catalog", Namespace: "preflight"}, &pod)).To(Succeed())
+			Expect(pod.Spec.Containers[0].Image).To(Equal("registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.18"))
+		})
+
+		It("should remove the catalog server when stopped", func() {
+			_, err := server.Serve(ctx, "memcached", "preflight", files)
+			Expect(err).ToNot(HaveOccurred())
+			server.Stop(ctx, "memcached", "preflight")
+
+			for _, obj := range []crclient.Object{&corev1.ConfigMap{}, &corev1.Pod{}, &corev1.Service{}} {
+				err := client.Get(ctx, crclient.ObjectKey{Name: "memcached-catalog", Namespace: "preflight"}, obj)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		})
+	})
+
+	Context("When serving a catalog from a directory", func() {
+		It("should write the catalog to the directory, and return its address", func() {
+			dir := GinkgoT().TempDir()
+			server := NewDirServer(dir, "localhost:50051")
+			address, err := server.Serve(ctx, "memcached", "preflight", files)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(address).To(Equal("localhost:50051"))
+
+			contents, err := os.ReadFile(filepath.Join(dir, "memcached-operator", "catalog.json"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`{"schema":"olm.package"}`))
+
+			server.Stop(ctx, "memcached", "preflight")
+			Expect(filepath.Join(dir, "memcached-operator", "catalog.json")).To(BeAnExistingFile())
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package catalog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// Blobs are the blobs of a file-based catalog, by schema.
+type Blobs struct {
+	Packages []Package
+	Channels []Channel
+	Bundles  []Bundle
+	// Others are the blobs of any other schema, e.g. olm.deprecations, or of none.
+	Others []Meta
+}
+
+// Meta identifies a blob that is not a package, channel or bundle.
+type Meta struct {
+	Schema  string `json:"schema"`
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name,omitempty"`
+	// File is the path of the file the blob is in, relative to the catalog's root.
+	File string `json:"-"`
+}
+
+// Load returns the blobs of the file-based catalog in dir, read from each of its JSON and YAML
+// files. Hidden files and directories are skipped.
+func Load(dir string) (*Blobs, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read catalog: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("could not read catalog: %s is not a directory", dir)
+	}
+
+	blobs := &Blobs{}
+	err = filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filename != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(filename) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filename)
+		if err != nil {
+			//coverage:ignore
+			return err
+		}
+		return blobs.loadFile(filename, filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read catalog: %w", err)
+	}
+
+	return blobs, nil
+}
+
+// loadFile adds the blobs in filename, whose path relative to the catalog's root is rel. A file
+// may hold a stream of JSON blobs, or YAML documents.
+func (b *Blobs) loadFile(filename, rel string) error {
+	contents, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(contents), 4096)
+	for {
+		var blob json.RawMessage
+		if err := decoder.Decode(&blob); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("could not parse %s: %w", rel, err)
+		}
+		// empty YAML documents are skipped.
+		if len(bytes.TrimSpace(blob)) == 0 || string(blob) == "null" {
+			continue
+		}
+		if err := b.add(blob, rel); err != nil {
+			return fmt.Errorf("could not parse %s: %w", rel, err)
+		}
+	}
+}
+
+// add adds blob, from the file rel, to the blobs of its schema.
+func (b *Blobs) add(blob json.RawMessage, rel string) error {
+	var meta Meta
+	if err := json.Unmarshal(blob, &meta); err != nil {
+		return err
+	}
+
+	switch meta.Schema {
+	case SchemaPackage:
+		var pkg Package
+		if err := json.Unmarshal(blob, &pkg); err != nil {
+			return fmt.Errorf("invalid %s %s: %w", meta.Schema, meta.Name, err)
+		}
+		b.Packages = append(b.Packages, pkg)
+	case SchemaChannel:
+		var channel Channel
+		if err := json.Unmarshal(blob, &channel); err != nil {
+			return fmt.Errorf("invalid %s %s: %w", meta.Schema, meta.Name, err)
+		}
+		b.Channels = append(b.Channels, channel)
+	case SchemaBundle:
+		var bundle Bundle
+		if err := json.Unmarshal(blob, &bundle); err != nil {
+			return fmt.Errorf("invalid %s %s: %w", meta.Schema, meta.Name, err)
+		}
+		b.Bundles = append(b.Bundles, bundle)
+	default:
+		meta.File = rel
+		b.Others = append(b.Others, meta)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Loading a catalog", func() {
+	It("should load the blobs of its JSON and YAML files", func() {
+		blobs, err := Load("./testdata/fbc")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(blobs.Packages).To(ConsistOf(
+			Package{Schema: SchemaPackage, Name: "memcached-operator", DefaultChannel: "stable"},
+			Package{Schema: SchemaPackage, Name: "etcd", DefaultChannel: "alpha"},
+		))
+		Expect(blobs.Channels).To(HaveLen(2))
+		Expect(blobs.Channels).To(ContainElement(Channel{
+			Schema:  SchemaChannel,
+			Name:    "stable",
+			Package: "memcached-operator",
+			Entries: []ChannelEntry{
+				{Name: "memcached-operator.v0.0.1"},
+				{
+					Name:      "memcached-operator.v0.0.2",
+					Replaces:  "memcached-operator.v0.0.1",
+					Skips:     []string{"memcached-operator.v0.0.1-rc.1"},
+					SkipRange: ">=0.0.1 <0.0.2",
+				},
+			},
+		}))
+		Expect(blobs.Bundles).To(HaveLen(3))
+		Expect(blobs.Others).To(Equal([]Meta{{Schema: "olm.deprecations", Package: "etcd", File: "etcd/catalog.json"}}))
+	})
+
+	It("should fail if the catalog does not exist", func() {
+		_, err := Load("./testdata/missing")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail if the catalog is not a directory", func() {
+		_, err := Load("./testdata/fbc/etcd/catalog.json")
+		Expect(err).To(MatchError(ContainSubstring("is not a directory")))
+	})
+
+	It("should fail if a file cannot be parsed", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "catalog.json"), []byte(`{"schema": "olm.package"`), 0o644)).To(Succeed())
+
+		_, err := Load(dir)
+		Expect(err).To(MatchError(ContainSubstring("could not parse catalog.json")))
+	})
+
+	It("should fail if a blob does not match its schema", func() {
+		dir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte("schema: olm.channel\nname: stable\nentries: stable\n"), 0o644)).To(Succeed())
+
+		_, err := Load(dir)
+		Expect(err).To(MatchError(ContainSubstring("invalid olm.channel stable")))
+	})
+})
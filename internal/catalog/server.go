@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
+)
+
+// DefaultBaseImage is the opm image the catalog is served with in the cluster. It is pinned to a
+// release, so that the image serving the catalog does not change between executions.
+const DefaultBaseImage = "quay.io/operator-framework/opm:v1.50.0"
+
+// Server serves a catalog to OLM.
+type Server interface {
+	// Serve serves files, the catalog of the CatalogSource with name in namespace, and
+	// returns the address of the registry serving it.
+	Serve(ctx context.Context, name, namespace string, files Files) (string, error)
+	// Stop stops serving the catalog of the CatalogSource with name in namespace.
+	Stop(ctx context.Context, name, namespace string)
+}
+
+var (
+	_ Server = &ClusterServer{}
+	_ Server = &DirServer{}
+)
+
+// ClusterServer serves a catalog from the cluster, with the opm serve command of its base
+// image, which reads the catalog from a ConfigMap. ConfigMaps are limited to 1MiB, which is
+// ample for a catalog of a single bundle.
+type ClusterServer struct {
+	client    openshift.Client
+	baseImage string
+	secrets   []string
+}
+
+// NewClusterServer returns a Server that runs the opm serve command of baseImage in the
+// cluster, pulling it with secrets. DefaultBaseImage is used if baseImage is empty.
+func NewClusterServer(client openshift.Client, baseImage string, secrets ...string) *ClusterServer {
+	if baseImage == "" {
+		baseImage = DefaultBaseImage
+	}
+	return &ClusterServer{client: client, baseImage: baseImage, secrets: secrets}
+}
+
+func (s *ClusterServer) Serve(ctx context.Context, name, namespace string, files Files) (string, error) {
+	serverName := serverName(name)
+
+	data := make(map[string]string, len(files))
+	paths := make(map[string]string, len(files))
+	// ConfigMap keys cannot contain the directories of the catalog's files, so they are
+	// mounted at their paths instead.
+	for i, path := range slices.Sorted(maps.Keys(files)) {
+		key := fmt.Sprintf("catalog-%d", i)
+		data[key] = string(files[path])
+		paths[key] = path
+	}
+
+	if _, err := s.client.CreateConfigMap(ctx, openshift.ConfigMapData{Name: serverName, Data: data}, namespace); err != nil && !errors.Is(err, openshift.ErrAlreadyExists) {
+		return "", err
+	}
+
+	service, err := s.client.CreateCatalogServer(ctx, openshift.CatalogServerData{
+		Name:      serverName,
+		Image:     s.baseImage,
+		ConfigMap: serverName,
+		Files:     paths,
+		Secrets:   s.secrets,
+	}, namespace)
+	if err != nil && !errors.Is(err, openshift.ErrAlreadyExists) {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s.svc:%d", service.Name, namespace, openshift.CatalogServerPort), nil
+}
+
+func (s *ClusterServer) Stop(ctx context.Context, name, namespace string) {
+	serverName := serverName(name)
+	_ = s.client.DeleteCatalogServer(ctx, serverName, namespace)
+	_ = s.client.DeleteConfigMap(ctx, serverName, namespace)
+}
+
+// serverName returns the name of the objects serving the catalog of the CatalogSource with name.
+func serverName(name string) string {
+	return name + "-catalog"
+}
+
+// DirServer writes a catalog to a local directory, for it to be served at an address OLM
+// can reach, e.g. by opm serve, in tests and development clusters.
+type DirServer struct {
+	dir     string
+	address string
+}
+
+// NewDirServer returns a Server that writes the catalog to dir, and returns address.
+func NewDirServer(dir, address string) *DirServer {
+	return &DirServer{dir: dir, address: address}
+}
+
+func (s *DirServer) Serve(ctx context.Context, name, namespace string, files Files) (string, error) {
+	if err := files.WriteDir(s.dir); err != nil {
+		return "", err
+	}
+	logr.FromContextOrDiscard(ctx).V(log.DBG).Info("wrote the catalog to its directory", "dir", s.dir, "address", s.address)
+	return s.address, nil
+}
+
+func (s *DirServer) Stop(ctx context.Context, name, namespace string) {
+	// the catalog is left in the directory, to be inspected.
+}
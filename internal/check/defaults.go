@@ -5,6 +5,8 @@ var (
 	DefaultRPMManifestFilename  = "rpm-manifest.json"
 	DefaultTestResultsFilename  = "results.json"
 	DefaultArtifactsTarFileName = "artifacts.tar"
+	DefaultPyxisDryRunFilename  = "pyxis-dry-run.json"
+	DefaultSubmissionJournal    = "submission-journal.json"
 	DefaultPyxisHost            = "catalog.redhat.com/api/containers"
 	DefaultPyxisEnv             = "prod"
 	SystemdDir                  = "/etc/systemd/system"
@@ -0,0 +1,49 @@
+package check
+
+import (
+	"context"
+	"sync"
+)
+
+// Findings collects what a check found in the image it validated, e.g. the paths of
+// files that caused it to fail, so they can be included in its result.
+type Findings struct {
+	mu       sync.Mutex
+	findings []string
+}
+
+// List returns the findings reported so far.
+func (f *Findings) List() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.findings) == 0 {
+		return nil
+	}
+	return append([]string(nil), f.findings...)
+}
+
+// contextKey is a key used to store/retrieve Findings in/from context.Context.
+type contextKey string
+
+const findingsContextKey contextKey = "Findings"
+
+// ContextWithFindings adds a new Findings to ctx, to collect the findings reported by
+// a check's validation.
+func ContextWithFindings(ctx context.Context) (context.Context, *Findings) {
+	f := &Findings{}
+	return context.WithValue(ctx, findingsContextKey, f), f
+}
+
+// ReportFindings records findings for the check being validated with ctx. Nothing is
+// recorded if ctx has no Findings.
+func ReportFindings(ctx context.Context, findings ...string) {
+	f, ok := ctx.Value(findingsContextKey).(*Findings)
+	if !ok || f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.findings = append(f.findings, findings...)
+}
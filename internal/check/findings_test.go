@@ -0,0 +1,23 @@
+package check
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Findings", func() {
+	It("should collect the findings reported with the context", func() {
+		ctx, findings := ContextWithFindings(context.Background())
+		Expect(findings.List()).To(BeNil())
+
+		ReportFindings(ctx, "/etc/pki/ca.crt")
+		ReportFindings(ctx, "/usr/bin/nc", "/usr/bin/telnet")
+		Expect(findings.List()).To(Equal([]string{"/etc/pki/ca.crt", "/usr/bin/nc", "/usr/bin/telnet"}))
+	})
+
+	It("should ignore findings reported without a collector", func() {
+		Expect(func() { ReportFindings(context.Background(), "/usr/bin/nc") }).ToNot(Panic())
+	})
+})
@@ -2,12 +2,11 @@ package csv
 
 import (
 	"encoding/json"
+	"slices"
 	"strings"
 
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"github.com/operator-framework/operator-manifest-tools/pkg/imagename"
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -79,49 +78,32 @@ func HasRelatedImages(csv *operatorsv1alpha1.ClusterServiceVersion) bool {
 	return len(csv.Spec.RelatedImages) > 0
 }
 
-// RelatedImagesArePinned returns true if all related images are digest-references.
-// If a reference cannot be parsed, this returns false. If relatedImage is empty,
-// this returns false.
-func RelatedImagesArePinned(relatedImages []operatorsv1alpha1.RelatedImage) bool {
-	if len(relatedImages) == 0 {
-		return false
-	}
-
-	for _, ri := range relatedImages {
-		img := imagename.Parse(ri.Image)
-		if !img.HasDigest() {
-			return false
-		}
-	}
-
-	return true
+// IsPinned returns true if image is a digest reference.
+func IsPinned(image string) bool {
+	return imagename.Parse(image).HasDigest()
 }
 
-// RelatedImageReferencesInEnvironment returns a slice of environment variables that are
-// prefixed with RELATED_IMAGE_, which is the typical way to push these values into
-// the controller's environment.
-func RelatedImageReferencesInEnvironment(deployments ...appsv1.DeploymentSpec) []string {
-	values := []string{}
-
-	for _, depl := range deployments {
-		values = append(values, relatedImageReferencesInContainerEnvironment(depl.Template.Spec.Containers)...)
-		values = append(values, relatedImageReferencesInContainerEnvironment(depl.Template.Spec.InitContainers)...)
-	}
-
-	return values
+// RelatedImageEnvVar is an environment variable prefixed with RELATED_IMAGE_ in a container
+// of a deployment of a CSV.
+type RelatedImageEnvVar struct {
+	Deployment string
+	Container  string
+	Name       string
+	Value      string
 }
 
-func relatedImageReferencesInContainerEnvironment(containers []corev1.Container) []string {
-	values := []string{}
-
-	for _, container := range containers {
-		envs := container.Env
-		for _, env := range envs {
-			if strings.HasPrefix(env.Name, "RELATED_IMAGE_") {
-				values = append(values, env.Name)
+// RelatedImageEnvironment returns the environment variables prefixed with RELATED_IMAGE_ in the
+// containers and init containers of deployments, in order.
+func RelatedImageEnvironment(deployments ...operatorsv1alpha1.StrategyDeploymentSpec) []RelatedImageEnvVar {
+	var envVars []RelatedImageEnvVar
+	for _, depl := range deployments {
+		for _, container := range slices.Concat(depl.Spec.Template.Spec.InitContainers, depl.Spec.Template.Spec.Containers) {
+			for _, env := range container.Env {
+				if strings.HasPrefix(env.Name, "RELATED_IMAGE_") {
+					envVars = append(envVars, RelatedImageEnvVar{Deployment: depl.Name, Container: container.Name, Name: env.Name, Value: env.Value})
+				}
 			}
 		}
 	}
-
-	return values
+	return envVars
 }
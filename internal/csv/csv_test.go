@@ -1,8 +1,6 @@
 package csv
 
 import (
-	"sort"
-
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
@@ -95,138 +93,39 @@ var _ = Describe("CSV Functions", func() {
 		})
 	})
 
-	DescribeTable(
-		"Determining the image values in related images are pinned",
-		func(imgs []operatorsv1alpha1.RelatedImage, expected bool) {
-			Expect(RelatedImagesArePinned(imgs)).To(Equal(expected))
+	DescribeTable("Checking whether an image is pinned",
+		func(image string, expected bool) {
+			Expect(IsPinned(image)).To(Equal(expected))
 		},
-		Entry("a pinned image", []operatorsv1alpha1.RelatedImage{
-			{
-				Name:  "memcached",
-				Image: "docker.io/library/memcached@sha256:00b68b00139155817a8b1d69d74865563def06b3af1e6fc79ac541a1b2f6b961",
-			},
-		}, true),
-		Entry("a tagged image", []operatorsv1alpha1.RelatedImage{
-			{
-				Name:  "redis",
-				Image: "mirror.gcr.io/library/redis:1.0.0",
-			},
-		}, false),
-		Entry("no related image", []operatorsv1alpha1.RelatedImage{}, false),
-		Entry("an image with no tag or digest", []operatorsv1alpha1.RelatedImage{
-			{
-				Name:  "redis",
-				Image: "mirror.gcr.io/library/redis",
-			},
-		}, false),
+		Entry("digest reference", "quay.io/fedora/fedora@sha256:ce08a91085403ecbc637eb2a96bd3554d75537871a12a14030b89243501050f2", true),
+		Entry("tag reference", "quay.io/fedora/fedora:latest", false),
+		Entry("no tag", "quay.io/fedora/fedora", false),
 	)
 
-	DescribeTable("Extracting related images from the container spec environment",
-		func(deps []appsv1.DeploymentSpec, expected []string) {
-			actual := RelatedImageReferencesInEnvironment(deps...)
-			sort.Strings(actual)
-			sort.Strings(expected)
-			Expect(actual).To(Equal(expected))
-		},
-		Entry(
-			"deployment with 1 container with two refs", []appsv1.DeploymentSpec{
-				{
-					Template: corev1.PodTemplateSpec{
-						Spec: corev1.PodSpec{
-							Containers: []corev1.Container{
-								{
-									Env: []corev1.EnvVar{
-										{
-											Name:  "RELATED_IMAGE_FOO",
-											Value: "foovalue",
-										},
-										{
-											Name:  "RELATED_IMAGE_BAR",
-											Value: "barvalue",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			[]string{"RELATED_IMAGE_FOO", "RELATED_IMAGE_BAR"},
-		),
-		Entry(
-			"deployment with 1 container with two refs", []appsv1.DeploymentSpec{
-				{
-					Template: corev1.PodTemplateSpec{
-						Spec: corev1.PodSpec{
-							Containers: []corev1.Container{
-								{
-									Env: []corev1.EnvVar{
-										{
-											Name:  "RELATED_IMAGE_FOO",
-											Value: "foovalue",
-										},
-										{
-											Name:  "RELATED_IMAGE_BAR",
-											Value: "barvalue",
-										},
-									},
-								},
-							},
-						},
-					},
-				},
-			},
-			[]string{"RELATED_IMAGE_FOO", "RELATED_IMAGE_BAR"},
-		),
-		Entry(
-			"deployment with 1 initContainer with two refs", []appsv1.DeploymentSpec{
-				{
+	It("Should return the RELATED_IMAGE_ environment variables of each container of each deployment", func() {
+		deployments := []operatorsv1alpha1.StrategyDeploymentSpec{
+			{
+				Name: "manager",
+				Spec: appsv1.DeploymentSpec{
 					Template: corev1.PodTemplateSpec{
 						Spec: corev1.PodSpec{
 							InitContainers: []corev1.Container{
-								{
-									Env: []corev1.EnvVar{
-										{
-											Name:  "RELATED_IMAGE_FOO",
-											Value: "foovalue",
-										},
-										{
-											Name:  "RELATED_IMAGE_BAR",
-											Value: "barvalue",
-										},
-									},
-								},
+								{Name: "init", Env: []corev1.EnvVar{{Name: "RELATED_IMAGE_INIT", Value: "initvalue"}}},
 							},
-						},
-					},
-				},
-			},
-			[]string{"RELATED_IMAGE_FOO", "RELATED_IMAGE_BAR"},
-		),
-		Entry(
-			"deployment with 1 initContainer with two refs", []appsv1.DeploymentSpec{
-				{
-					Template: corev1.PodTemplateSpec{
-						Spec: corev1.PodSpec{
-							InitContainers: []corev1.Container{
-								{
-									Env: []corev1.EnvVar{
-										{
-											Name:  "RELATED_IMAGE_FOO",
-											Value: "foovalue",
-										},
-										{
-											Name:  "RELATED_IMAGE_BAR",
-											Value: "barvalue",
-										},
-									},
-								},
+							Containers: []corev1.Container{
+								{Name: "manager", Env: []corev1.EnvVar{
+									{Name: "RELATED_IMAGE_FOO", Value: "foovalue"},
+									{Name: "WATCH_NAMESPACE", Value: "ns"},
+								}},
 							},
 						},
 					},
 				},
 			},
-			[]string{"RELATED_IMAGE_FOO", "RELATED_IMAGE_BAR"},
-		),
-	)
+		}
+		Expect(RelatedImageEnvironment(deployments...)).To(Equal([]RelatedImageEnvVar{
+			{Deployment: "manager", Container: "init", Name: "RELATED_IMAGE_INIT", Value: "initvalue"},
+			{Deployment: "manager", Container: "manager", Name: "RELATED_IMAGE_FOO", Value: "foovalue"},
+		}))
+	})
 })
@@ -10,7 +10,6 @@ import (
 	"io"
 	"io/fs"
 	"maps"
-	"net/http"
 	"os"
 	"path"
 	"regexp"
@@ -21,22 +20,33 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
 	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	catalogpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/catalog"
 	containerpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/container"
 	operatorpol "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy/operator"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rules"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
 )
 
 // New creates a new CraneEngine from the passed params
@@ -59,6 +69,17 @@ func New(ctx context.Context,
 	}, nil
 }
 
+// NewForDirectory creates a new CraneEngine that executes checks against the files in dir,
+// e.g. a file-based catalog, rather than the filesystem of a pulled image. The checks are
+// given no ImageInfo.
+func NewForDirectory(checks []check.Check, dir string) (craneEngine, error) {
+	return craneEngine{
+		image:  dir,
+		dir:    dir,
+		checks: checks,
+	}, nil
+}
+
 // CraneEngine implements a certification.CheckEngine, and leverage crane to interact with
 // the container registry and target image.
 type craneEngine struct {
@@ -92,6 +113,9 @@ type craneEngine struct {
 	// tempDir is optional. If empty, will use an OS tmp dir.
 	tempDir string
 
+	// dir is the directory the checks are executed against, when nothing is pulled.
+	dir string
+
 	imageRef image.ImageReference
 	results  certification.Results
 }
@@ -110,8 +134,50 @@ func (c *craneEngine) CraneInsecure() bool {
 
 var _ option.CraneConfig = &craneEngine{}
 
+// pull returns the image from the first of its sources, i.e. its mirrors or its own registry,
+// that it can be pulled from.
+func (c *craneEngine) pull(ctx context.Context) (cranev1.Image, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	sources, err := registries.FromContext(ctx).Sources(c.image)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, source := range sources {
+		options := option.GenerateCraneOptions(ctx, c)
+		if source.Insecure {
+			options = append(options, crane.Insecure)
+		}
+
+		logger.V(log.DBG).Info("pulling image", "source", source.Reference)
+		img, err := crane.Pull(source.Reference, options...)
+		if err == nil {
+			return img, nil
+		}
+
+		logger.V(log.DBG).Info("unable to pull image", "source", source.Reference, "reason", err.Error())
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
 func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	logger := logr.FromContextOrDiscard(ctx)
+
+	if c.dir != "" {
+		logger.Info("target directory", "dir", c.dir)
+		c.imageRef = image.ImageReference{
+			ImageURI:    c.dir,
+			ImageFSPath: c.dir,
+		}
+		c.results.TestedOn = runtime.UnknownOpenshiftClusterVersion()
+		c.runChecks(ctx)
+		return nil
+	}
+
 	logger.Info("target image", "image", c.image)
 
 	logger.V(log.TRC).Info("temp directory", "dir", c.tempDir)
@@ -142,8 +208,9 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 
 	// pull the image manifest
 	logger.V(log.DBG).Info("pulling image from target registry")
-	options := option.GenerateCraneOptions(ctx, c)
-	img, err := crane.Pull(c.image, options...)
+	pullCtx, span := telemetry.Start(ctx, "pull image", attribute.String("image", c.image))
+	img, err := c.pull(pullCtx)
+	telemetry.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to pull remote container: %v", err)
 	}
@@ -174,7 +241,7 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 	// layer content from the registry during untar. This isolates registry/network
 	// flakiness to a single, retried step instead of surfacing as a hard failure
 	// partway through extracting files to disk.
-	if err := pullLayers(ctx, img, layerCache); err != nil {
+	if err := pullLayers(ctx, img, layerCache, network.FromContext(ctx)); err != nil {
 		return fmt.Errorf("failed to pull image layers: %w", err)
 	}
 
@@ -226,6 +293,40 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		c.results.TestedOn = runtime.UnknownOpenshiftClusterVersion()
 	}
 
+	c.runChecks(ctx)
+
+	if c.isBundle { // for operators:
+		// hash the contents of the bundle.
+		md5sum, err := generateBundleHash(ctx, c.imageRef.ImageFSPath)
+		if err != nil {
+			//coverage:ignore
+			logger.Error(err, "could not generate bundle hash")
+		}
+		c.results.CertificationHash = md5sum
+	} else { // for containers:
+		// Inform the user about the sha/tag binding.
+
+		// By this point, we should have already resolved the digest so
+		// we don't handle this error, but fail safe and don't log a potentially
+		// incorrect line message to the user.
+		if resolvedDigest, err := c.imageRef.ImageInfo.Digest(); err == nil {
+			msg, warn := tagDigestBindingInfo(c.imageRef.ImageTagOrSha, resolvedDigest.String())
+			if warn {
+				//coverage:ignore
+				logger.Info(fmt.Sprintf("Warning: %s", msg))
+			} else {
+				logger.Info(msg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// runChecks executes the checks against the engine's image reference, and records their results.
+func (c *craneEngine) runChecks(ctx context.Context) {
+	logger := logr.FromContextOrDiscard(ctx)
+
 	// execute checks
 	logger.V(log.DBG).Info("executing checks")
 	for _, executedCheck := range c.checks {
@@ -239,13 +340,21 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		}
 
 		// run the validation
+		ctx, span := telemetry.Start(ctx, "check "+executedCheck.Name(), attribute.String("check.name", executedCheck.Name()))
+		ctx, findings := check.ContextWithFindings(ctx)
 		checkStartTime := time.Now()
 		checkPassed, err := executedCheck.Validate(ctx, c.imageRef)
 		checkElapsedTime := time.Since(checkStartTime)
+		telemetry.End(span, err)
+		recordCheck(ctx, executedCheck, checkPassed, err, checkElapsedTime)
+
+		result := certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime, Findings: findings.List()}
+		if len(result.Findings) > 0 {
+			logger.V(log.DBG).Info("check reported findings", "findings", result.Findings)
+		}
 
 		if err != nil {
 			logger.WithValues("result", "ERROR", "err", err.Error()).Info("check completed")
-			result := certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime}
 			c.results.Errors = appendUnlessOptional(c.results.Errors, *result.WithError(err))
 			continue
 		}
@@ -254,16 +363,16 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 			// if a test doesn't pass but is of level warn include it in warning results, instead of failed results
 			if executedCheck.Metadata().Level == check.LevelWarn {
 				logger.WithValues("result", "WARNING").Info("check completed")
-				c.results.Warned = appendUnlessOptional(c.results.Warned, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
+				c.results.Warned = appendUnlessOptional(c.results.Warned, result)
 				continue
 			}
 			logger.WithValues("result", "FAILED").Info("check completed")
-			c.results.Failed = appendUnlessOptional(c.results.Failed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
+			c.results.Failed = appendUnlessOptional(c.results.Failed, result)
 			continue
 		}
 
 		logger.WithValues("result", "PASSED").Info("check completed")
-		c.results.Passed = appendUnlessOptional(c.results.Passed, certification.Result{Check: executedCheck, ElapsedTime: checkElapsedTime})
+		c.results.Passed = appendUnlessOptional(c.results.Passed, result)
 	}
 
 	if len(c.results.Errors) > 0 || len(c.results.Failed) > 0 {
@@ -272,33 +381,20 @@ func (c *craneEngine) ExecuteChecks(ctx context.Context) error {
 		//coverage:ignore
 		c.results.PassedOverall = true
 	}
+}
 
-	if c.isBundle { // for operators:
-		// hash the contents of the bundle.
-		md5sum, err := generateBundleHash(ctx, c.imageRef.ImageFSPath)
-		if err != nil {
-			//coverage:ignore
-			logger.Error(err, "could not generate bundle hash")
-		}
-		c.results.CertificationHash = md5sum
-	} else { // for containers:
-		// Inform the user about the sha/tag binding.
-
-		// By this point, we should have already resolved the digest so
-		// we don't handle this error, but fail safe and don't log a potentially
-		// incorrect line message to the user.
-		if resolvedDigest, err := c.imageRef.ImageInfo.Digest(); err == nil {
-			msg, warn := tagDigestBindingInfo(c.imageRef.ImageTagOrSha, resolvedDigest.String())
-			if warn {
-				//coverage:ignore
-				logger.Info(fmt.Sprintf("Warning: %s", msg))
-			} else {
-				logger.Info(msg)
-			}
-		}
+// recordCheck records the duration and outcome of executedCheck's validation.
+func recordCheck(ctx context.Context, executedCheck check.Check, passed bool, err error, elapsed time.Duration) {
+	outcome := telemetry.OutcomePassed
+	switch {
+	case err != nil:
+		outcome = telemetry.OutcomeErrored
+	case !passed && executedCheck.Metadata().Level == check.LevelWarn:
+		outcome = telemetry.OutcomeWarned
+	case !passed:
+		outcome = telemetry.OutcomeFailed
 	}
-
-	return nil
+	telemetry.RecordCheck(ctx, executedCheck.Name(), outcome, elapsed)
 }
 
 func appendUnlessOptional(results []certification.Result, result certification.Result) []certification.Result {
@@ -650,26 +746,106 @@ type OperatorCheckConfig struct {
 	Kubeconfig                        []byte
 	CSVTimeout                        time.Duration
 	SubscriptionTimeout               time.Duration
+	// Rules are the rules files whose checks are run after the checks of the policy.
+	Rules []string
+	// CatalogBaseImage is the opm image that serves the catalog built for the bundle when
+	// IndexImage is empty. If CatalogDir is set, the catalog is instead written to it, to be
+	// served at CatalogAddress.
+	CatalogBaseImage, CatalogDir, CatalogAddress string
+	// AllInstallModes deploys the operator in every install mode its CSV supports.
+	AllInstallModes bool
+	// ReconcileAlmExamples adds the ReconcilesAlmExamples check, which waits up to ExampleTimeout
+	// for any of ExampleConditions of each example in the CSV's alm-examples to be True.
+	ReconcileAlmExamples bool
+	ExampleConditions    []string
+	ExampleTimeout       time.Duration
+	// ApprovedRegistries are the registries the ImagesFromApprovedRegistries check approves
+	// the images of the operator from. If empty, operatorpol.DefaultApprovedRegistries are used.
+	ApprovedRegistries []string
+	// ReadinessActions are the actions the DeploymentsAreProductionReady check takes for each
+	// category of findings. If empty, readiness.DefaultActions are used.
+	ReadinessActions readiness.Actions
+	// EnforceRestrictedNetwork fails certification if a bundle claiming to support restricted
+	// networks does not follow the restricted network guidelines, rather than warning.
+	EnforceRestrictedNetwork bool
+	// VersionMatrix validates the bundle against each OpenShift version in its declared range,
+	// rather than only the one it targets.
+	VersionMatrix bool
+	// OpenShiftVersions is a file of OpenShift versions, and the Kubernetes version of each, added
+	// to those known to preflight.
+	OpenShiftVersions string
 }
 
-// InitializeOperatorChecks returns opeartor checks for policy p give cfg.
+// InitializeOperatorChecks returns opeartor checks for policy p give cfg, followed by the
+// checks of any rules in cfg.
 func InitializeOperatorChecks(ctx context.Context, p policy.Policy, cfg OperatorCheckConfig) ([]check.Check, error) {
+	checks, err := operatorPolicyChecks(ctx, p, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Rules) == 0 {
+		return checks, nil
+	}
+
+	ruleChecks, err := rules.Load(ctx, cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendChecks(p, checks, "rule", ruleChecks)
+}
+
+// operatorPolicyChecks returns the checks of policy p given cfg.
+func operatorPolicyChecks(ctx context.Context, p policy.Policy, cfg OperatorCheckConfig) ([]check.Check, error) {
 	switch p {
 	case policy.PolicyOperator:
-		return []check.Check{
-			operatorpol.NewDeployableByOlmCheck(cfg.IndexImage, cfg.DockerConfig, cfg.Channel, operatorpol.WithCSVTimeout(cfg.CSVTimeout), operatorpol.WithSubscriptionTimeout(cfg.SubscriptionTimeout)),
-			operatorpol.NewValidateOperatorBundleCheck(),
+		deployableByOlmOptions := []operatorpol.Option{
+			operatorpol.WithCSVTimeout(cfg.CSVTimeout),
+			operatorpol.WithSubscriptionTimeout(cfg.SubscriptionTimeout),
+			operatorpol.WithCatalogBaseImage(cfg.CatalogBaseImage),
+			operatorpol.WithCatalogDir(cfg.CatalogDir, cfg.CatalogAddress),
+		}
+		if cfg.AllInstallModes {
+			deployableByOlmOptions = append(deployableByOlmOptions, operatorpol.WithAllInstallModes())
+		}
+		versions, err := bundle.LoadVersionTable(cfg.OpenShiftVersions)
+		if err != nil {
+			return nil, err
+		}
+		validateOperatorBundleOptions := []operatorpol.ValidateOperatorBundleOption{
+			operatorpol.WithVersionTable(versions),
+		}
+		if cfg.VersionMatrix {
+			validateOperatorBundleOptions = append(validateOperatorBundleOptions, operatorpol.WithVersionMatrix())
+		}
+		// the images are audited, and the examples created, while DeployableByOLM has the operator installed.
+		imagesFromApprovedRegistries := operatorpol.NewImagesFromApprovedRegistriesCheck(cfg.ApprovedRegistries)
+		deployableByOlmOptions = append(deployableByOlmOptions, operatorpol.WithImageSources(imagesFromApprovedRegistries))
+		var almExamples []check.Check
+		if cfg.ReconcileAlmExamples {
+			reconcilesAlmExamples := operatorpol.NewReconcilesAlmExamplesCheck(cfg.ExampleConditions, cfg.ExampleTimeout)
+			deployableByOlmOptions = append(deployableByOlmOptions, operatorpol.WithAlmExamples(reconcilesAlmExamples))
+			almExamples = append(almExamples, reconcilesAlmExamples)
+		}
+		return append([]check.Check{
+			operatorpol.NewDeployableByOlmCheck(cfg.IndexImage, cfg.DockerConfig, cfg.Channel, deployableByOlmOptions...),
+			operatorpol.NewValidateOperatorBundleCheck(validateOperatorBundleOptions...),
 			operatorpol.NewCertifiedImagesCheck(pyxis.NewPyxisClient(
 				check.DefaultPyxisHost,
 				"",
 				"",
-				&http.Client{Timeout: 60 * time.Second}),
+				network.FromContext(ctx).Client()),
 			),
 			operatorpol.NewSecurityContextConstraintsCheck(),
 			&operatorpol.RelatedImagesCheck{},
-			operatorpol.FollowsRestrictedNetworkEnablementGuidelines{},
+			operatorpol.NewFollowsRestrictedNetworkEnablementGuidelinesCheck(cfg.EnforceRestrictedNetwork),
 			operatorpol.RequiredAnnotations{},
-		}, nil
+			imagesFromApprovedRegistries,
+			&operatorpol.RBACFollowsLeastPrivilegeCheck{},
+			&operatorpol.DeploymentsAreRestrictedCheck{},
+			operatorpol.NewDeploymentsAreProductionReadyCheck(cfg.ReadinessActions),
+		}, almExamples...), nil
 	}
 
 	return nil, fmt.Errorf("provided operator policy %s is unknown", p)
@@ -678,10 +854,100 @@ func InitializeOperatorChecks(ctx context.Context, p policy.Policy, cfg Operator
 // ContainerCheckConfig contains configuration relevant to an individual check's execution.
 type ContainerCheckConfig struct {
 	DockerConfig, PyxisAPIToken, CertificationProjectID, PyxisHost string
+	// Plugins are run after the checks of the policy.
+	Plugins []plugin.Config
+	// Rules are the rules files whose checks are run after the checks of the policy.
+	Rules []string
+	// ScanSecrets adds the HasNoSecrets check, applying the secret ruleset at SecretRules,
+	// or the default ruleset if it is not set.
+	ScanSecrets bool
+	SecretRules string
+	// AuditFilePermissions adds the HasNoRiskyFilePermissions check, allowing files matching
+	// AllowedFileCapabilities to carry capabilities.
+	AuditFilePermissions    bool
+	AllowedFileCapabilities []string
+	// CheckArbitraryUID adds the SupportsArbitraryUID check.
+	CheckArbitraryUID bool
+	// AnalyzeLayers adds the HasEfficientLayers check, evaluating the layer analysis against
+	// LayerThresholds, or the default thresholds if they are not set.
+	AnalyzeLayers   bool
+	LayerThresholds layers.Thresholds
 }
 
-// InitializeContainerChecks returns the appropriate checks for policy p given cfg.
+// InitializeContainerChecks returns the appropriate checks for policy p given cfg, followed
+// by the checks of any plugins and rules in cfg, and the secret scan, file permission audit,
+// arbitrary UID check and layer analysis if they are enabled.
 func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg ContainerCheckConfig) ([]check.Check, error) {
+	checks, err := containerPolicyChecks(ctx, p, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Plugins) > 0 {
+		plugins, err := plugin.Load(ctx, cfg.Plugins)
+		if err != nil {
+			return nil, err
+		}
+		if checks, err = appendChecks(p, checks, "plugin", plugins); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.Rules) > 0 {
+		ruleChecks, err := rules.Load(ctx, cfg.Rules)
+		if err != nil {
+			return nil, err
+		}
+		if checks, err = appendChecks(p, checks, "rule", ruleChecks); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ScanSecrets {
+		ruleset, err := containerpol.LoadSecretRuleset(cfg.SecretRules)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, containerpol.NewHasNoSecretsCheck(ruleset))
+	}
+
+	if cfg.AuditFilePermissions {
+		audit, err := containerpol.NewHasNoRiskyFilePermissionsCheck(cfg.AllowedFileCapabilities...)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, audit)
+	}
+
+	if cfg.CheckArbitraryUID {
+		checks = append(checks, &containerpol.SupportsArbitraryUIDCheck{})
+	}
+
+	if cfg.AnalyzeLayers {
+		thresholds := cfg.LayerThresholds
+		if thresholds == nil {
+			thresholds = layers.DefaultThresholds()
+		}
+		checks = append(checks, containerpol.NewHasEfficientLayersCheck(thresholds))
+	}
+
+	return checks, nil
+}
+
+// appendChecks appends the extra checks, of the given kind, to the checks of policy p, so
+// long as their names are unique.
+func appendChecks(p policy.Policy, checks []check.Check, kind string, extra []check.Check) ([]check.Check, error) {
+	for _, c := range extra {
+		if slices.Contains(makeCheckList(checks), c.Name()) {
+			return nil, fmt.Errorf("%s check %s has the same name as a check in the %s policy", kind, c.Name(), p)
+		}
+	}
+
+	return append(checks, extra...), nil
+}
+
+// containerPolicyChecks returns the checks of policy p given cfg.
+func containerPolicyChecks(ctx context.Context, p policy.Policy, cfg ContainerCheckConfig) ([]check.Check, error) {
 	switch p {
 	case policy.PolicyContainer:
 		return []check.Check{
@@ -697,7 +963,7 @@ func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg Contain
 				cfg.PyxisHost,
 				cfg.PyxisAPIToken,
 				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
+				network.FromContext(ctx).Client())),
 			&containerpol.HasProhibitedContainerName{},
 		}, nil
 	case policy.PolicyRoot:
@@ -713,7 +979,7 @@ func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg Contain
 				cfg.PyxisHost,
 				cfg.PyxisAPIToken,
 				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
+				network.FromContext(ctx).Client())),
 			&containerpol.HasProhibitedContainerName{},
 		}, nil
 	case policy.PolicyScratchNonRoot:
@@ -748,13 +1014,47 @@ func InitializeContainerChecks(ctx context.Context, p policy.Policy, cfg Contain
 				cfg.PyxisHost,
 				cfg.PyxisAPIToken,
 				cfg.CertificationProjectID,
-				&http.Client{Timeout: 60 * time.Second})),
+				network.FromContext(ctx).Client())),
+		}, nil
+	case policy.PolicyWebhook:
+		// checks that need neither Pyxis nor the image's full RPM history, so that
+		// admission requests can be answered quickly.
+		return []check.Check{
+			&containerpol.HasLicenseCheck{},
+			&containerpol.MaxLayersCheck{},
+			containerpol.NewHasNoProhibitedPackagesCheck(),
+			&containerpol.HasRequiredLabelsCheck{},
+			&containerpol.HasNoProhibitedLabelsCheck{},
+			&containerpol.RunAsNonRootCheck{},
+			&containerpol.HasProhibitedContainerName{},
 		}, nil
 	}
 
 	return nil, fmt.Errorf("provided container policy %s is unknown", p)
 }
 
+// CatalogCheckConfig contains configuration relevant to an individual check's execution.
+type CatalogCheckConfig struct {
+	// DockerConfig is the credential required to look up the bundle images of the catalog.
+	DockerConfig string
+	// Insecure allows an insecure connection to the registries of the bundle images.
+	Insecure bool
+}
+
+// InitializeCatalogChecks returns the checks of catalog policy p given cfg.
+func InitializeCatalogChecks(ctx context.Context, p policy.Policy, cfg CatalogCheckConfig) ([]check.Check, error) {
+	switch p {
+	case policy.PolicyCatalog:
+		return []check.Check{
+			&catalogpol.ValidateCatalogSchemaCheck{},
+			&catalogpol.ValidateUpgradeGraphCheck{},
+			catalogpol.NewBundleImagesArePinnedCheck(cfg.DockerConfig, cfg.Insecure),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("provided catalog policy %s is unknown", p)
+}
+
 // makeCheckList returns a list of check names.
 func makeCheckList(checks []check.Check) []string {
 	checkNames := make([]string, len(checks))
@@ -770,10 +1070,12 @@ func makeCheckList(checks []check.Check) []string {
 func checkNamesFor(ctx context.Context, p policy.Policy) []string {
 	var c []check.Check
 	switch p {
-	case policy.PolicyContainer, policy.PolicyRoot, policy.PolicyScratchNonRoot, policy.PolicyScratchRoot, policy.PolicyKonflux:
+	case policy.PolicyContainer, policy.PolicyRoot, policy.PolicyScratchNonRoot, policy.PolicyScratchRoot, policy.PolicyKonflux, policy.PolicyWebhook:
 		c, _ = InitializeContainerChecks(ctx, p, ContainerCheckConfig{})
 	case policy.PolicyOperator:
 		c, _ = InitializeOperatorChecks(ctx, p, OperatorCheckConfig{})
+	case policy.PolicyCatalog:
+		c, _ = InitializeCatalogChecks(ctx, p, CatalogCheckConfig{})
 	default:
 		return []string{}
 	}
@@ -814,3 +1116,14 @@ func RootExceptionContainerPolicy(ctx context.Context) []string {
 func KonfluxContainerPolicy(ctx context.Context) []string {
 	return checkNamesFor(ctx, policy.PolicyKonflux)
 }
+
+// CatalogPolicy returns the names of checks in the catalog policy.
+func CatalogPolicy(ctx context.Context) []string {
+	return checkNamesFor(ctx, policy.PolicyCatalog)
+}
+
+// WebhookContainerPolicy returns the names of checks that can be
+// enforced by the admission webhook.
+func WebhookContainerPolicy(ctx context.Context) []string {
+	return checkNamesFor(ctx, policy.PolicyWebhook)
+}
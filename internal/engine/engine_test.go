@@ -13,6 +13,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -28,8 +29,11 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 )
@@ -165,6 +169,51 @@ var _ = Describe("Execute Checks tests", func() {
 			Expect(engine.results.Warned).To(HaveLen(1))
 			Expect(engine.results.CertificationHash).To(BeEmpty())
 		})
+		It("should record the findings reported by each check with its result", func() {
+			engine.checks = append(engine.checks, check.NewGenericCheck(
+				"findingsCheck",
+				func(ctx context.Context, _ image.ImageReference) (bool, error) {
+					check.ReportFindings(ctx, "/usr/bin/nc")
+					return false, nil
+				},
+				check.Metadata{},
+				check.HelpText{},
+				nil,
+			))
+			err := engine.ExecuteChecks(testcontext)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(engine.results.Failed).To(ContainElement(SatisfyAll(
+				WithTransform(func(r certification.Result) string { return r.Name() }, Equal("findingsCheck")),
+				HaveField("Findings", ConsistOf("/usr/bin/nc")),
+			)))
+			for _, r := range engine.results.Passed {
+				Expect(r.Findings).To(BeEmpty())
+			}
+		})
+		Context("it is a directory", func() {
+			It("should run the checks against the directory, without pulling an image", func() {
+				dir := GinkgoT().TempDir()
+				var ref image.ImageReference
+				engine, err := NewForDirectory([]check.Check{check.NewGenericCheck(
+					"dirCheck",
+					func(_ context.Context, imgRef image.ImageReference) (bool, error) {
+						ref = imgRef
+						return true, nil
+					},
+					check.Metadata{},
+					check.HelpText{},
+					nil,
+				)}, dir)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(engine.ExecuteChecks(testcontext)).To(Succeed())
+				Expect(engine.results.Passed).To(HaveLen(1))
+				Expect(engine.results.PassedOverall).To(BeTrue())
+				Expect(engine.results.TestedImage).To(Equal(dir))
+				Expect(ref.ImageFSPath).To(Equal(dir))
+				Expect(ref.ImageInfo).To(BeNil())
+			})
+		})
 		Context("it is a bundle", func() {
 			It("should succeed and generate a bundle hash", func() {
 				engine.isBundle = true
@@ -181,14 +230,12 @@ var _ = Describe("Execute Checks tests", func() {
 			})
 		})
 		Context("a layer fails to download", func() {
-			var origDelay time.Duration
-
 			BeforeEach(func() {
 				// Speed up the retry backoff so this test doesn't have to wait
 				// through the real, production-sized delays.
-				origDelay = pullLayerRetryBaseDelay
-				pullLayerRetryBaseDelay = time.Millisecond
-				DeferCleanup(func() { pullLayerRetryBaseDelay = origDelay })
+				policy := network.DefaultPolicy()
+				policy.Backoff = time.Millisecond
+				testcontext = network.ContextWithPolicy(testcontext, policy)
 
 				// Front the already-populated registry with a proxy that always
 				// fails blob (layer) GET requests, while still serving manifests
@@ -368,10 +415,108 @@ var _ = Describe("Check Initialization", func() {
 			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyKonflux, ContainerCheckConfig{})
 			Expect(err).ToNot(HaveOccurred())
 		})
+		It("should properly return checks for the webhook policy, without checks that need Pyxis", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyWebhook, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElements("BasedOnUbi", "HasModifiedFiles"))
+		})
 		It("should throw an error if the policy is unknown", func() {
 			_, err := InitializeContainerChecks(context.TODO(), policy.Policy("foo"), ContainerCheckConfig{})
 			Expect(err).To(HaveOccurred())
 		})
+		It("should add the checks of the configured plugins to those of the policy", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				Plugins: []plugin.Config{{Path: writePlugin("HasInternalCABundle")}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("HasLicense", "HasInternalCABundle"))
+		})
+		It("should throw an error if a plugin has the name of a check in the policy", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				Plugins: []plugin.Config{{Path: writePlugin("HasLicense")}},
+			})
+			Expect(err).To(MatchError(ContainSubstring("plugin check HasLicense has the same name as a check in the container policy")))
+		})
+		It("should add the checks of the configured rules to those of the policy", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				Rules: []string{writeRules("HasVendorLabel")},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("HasLicense", "HasVendorLabel"))
+		})
+		It("should throw an error if a rule has the name of a check in the policy, or a plugin", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				Plugins: []plugin.Config{{Path: writePlugin("HasInternalCABundle")}},
+				Rules:   []string{writeRules("HasInternalCABundle")},
+			})
+			Expect(err).To(MatchError(ContainSubstring("rule check HasInternalCABundle has the same name as a check in the container policy")))
+		})
+		It("should add the secret scan only if it is enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("HasNoSecrets"))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{ScanSecrets: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("HasLicense", "HasNoSecrets"))
+		})
+		It("should throw an error if the secret ruleset is invalid", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				ScanSecrets: true,
+				SecretRules: filepath.Join(GinkgoT().TempDir(), "missing.yaml"),
+			})
+			Expect(err).To(MatchError(ContainSubstring("could not read secret ruleset")))
+		})
+		It("should add the file permissions audit only if it is enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("HasNoRiskyFilePermissions"))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				AuditFilePermissions:    true,
+				AllowedFileCapabilities: []string{"opt/app/bin/*"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("HasLicense", "HasNoRiskyFilePermissions"))
+		})
+		It("should throw an error if an allowed file capabilities pattern is invalid", func() {
+			_, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{
+				AuditFilePermissions:    true,
+				AllowedFileCapabilities: []string{"[a"},
+			})
+			Expect(err).To(MatchError(ContainSubstring("invalid allowed file capabilities pattern")))
+		})
+		It("should add the arbitrary UID check only if it is enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("SupportsArbitraryUID"))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{CheckArbitraryUID: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("RunAsNonRoot", "SupportsArbitraryUID"))
+		})
+		It("should add the layer analysis only if it is enabled", func() {
+			checks, err := InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("HasEfficientLayers"))
+
+			checks, err = InitializeContainerChecks(context.TODO(), policy.PolicyContainer, ContainerCheckConfig{AnalyzeLayers: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("LayerCountAcceptable", "HasEfficientLayers"))
+			Expect(checks[len(checks)-1].Metadata().Level).To(Equal(check.LevelWarn))
+		})
+	})
+
+	When("initializing catalog checks", func() {
+		It("should return the checks of the catalog policy", func() {
+			checks, err := InitializeCatalogChecks(context.TODO(), policy.PolicyCatalog, CatalogCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(3))
+		})
+		It("should throw an error if the policy is unknown", func() {
+			_, err := InitializeCatalogChecks(context.TODO(), policy.Policy("bar"), CatalogCheckConfig{})
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	When("initializing operator checks", func() {
@@ -383,9 +528,47 @@ var _ = Describe("Check Initialization", func() {
 			_, err := InitializeOperatorChecks(context.TODO(), policy.Policy("bar"), OperatorCheckConfig{})
 			Expect(err).To(HaveOccurred())
 		})
+		It("should add the checks of the configured rules to those of the policy", func() {
+			checks, err := InitializeOperatorChecks(context.TODO(), policy.PolicyOperator, OperatorCheckConfig{
+				Rules: []string{writeRules("HasMaintainers")},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("DeployableByOLM", "HasMaintainers"))
+		})
+		It("should throw an error if a rule has the name of a check in the policy", func() {
+			_, err := InitializeOperatorChecks(context.TODO(), policy.PolicyOperator, OperatorCheckConfig{
+				Rules: []string{writeRules("RequiredAnnotations")},
+			})
+			Expect(err).To(MatchError(ContainSubstring("rule check RequiredAnnotations has the same name as a check in the operator policy")))
+		})
+		It("should add the alm-examples check only if it is enabled", func() {
+			checks, err := InitializeOperatorChecks(context.TODO(), policy.PolicyOperator, OperatorCheckConfig{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).ToNot(ContainElement("ReconcilesAlmExamples"))
+
+			checks, err = InitializeOperatorChecks(context.TODO(), policy.PolicyOperator, OperatorCheckConfig{ReconcileAlmExamples: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(makeCheckList(checks)).To(ContainElements("DeployableByOLM", "ReconcilesAlmExamples"))
+		})
 	})
 })
 
+// writePlugin writes a plugin executable declaring a check named name.
+func writePlugin(name string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "plugin")
+	script := fmt.Sprintf("#!/bin/sh\necho '{\"name\": \"%s\"}'\n", name)
+	Expect(os.WriteFile(path, []byte(script), 0o755)).To(Succeed())
+	return path
+}
+
+// writeRules writes a rules file declaring a rule named name.
+func writeRules(name string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+	rules := fmt.Sprintf("rules:\n- name: %s\n  expression: '\"vendor\" in labels'\n", name)
+	Expect(os.WriteFile(path, []byte(rules), 0o644)).To(Succeed())
+	return path
+}
+
 var _ = Describe("Check Name Queries", func() {
 	DescribeTable("The checks associated with valid policy should return the expected check names",
 		func(queryFunc func(context.Context) []string, expected []string) {
@@ -413,6 +596,15 @@ var _ = Describe("Check Name Queries", func() {
 			"AllImageRefsInRelatedImages",
 			"FollowsRestrictedNetworkEnablementGuidelines",
 			"RequiredAnnotations",
+			"ImagesFromApprovedRegistries",
+			"RBACFollowsLeastPrivilege",
+			"DeploymentsAreRestricted",
+			"DeploymentsAreProductionReady",
+		}),
+		Entry("catalog policy", CatalogPolicy, []string{
+			"ValidateCatalogSchema",
+			"ValidateUpgradeGraph",
+			"BundleImagesArePinned",
 		}),
 		Entry("scratch nonroot container policy", ScratchNonRootContainerPolicy, []string{
 			"HasLicense",
@@ -9,9 +9,12 @@ import (
 	"github.com/go-logr/logr"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
 )
 
 // errLayerContentMismatch indicates that a layer's content, once fully read,
@@ -20,23 +23,6 @@ import (
 // truncated by an interrupted download (see pullLayerWithRetry).
 var errLayerContentMismatch = errors.New("layer content does not match expected digest")
 
-const (
-	// pullLayerMaxAttempts is the number of times to attempt to fully download a
-	// single layer before giving up. go-containerregistry's own retry support
-	// (remote.WithRetryBackoff, etc.) only retries the initial HTTP round trip; it
-	// does not retry a read that fails partway through streaming a layer's body.
-	// This is the layer of retry that covers that gap.
-	pullLayerMaxAttempts = 3
-	// pullLayerConcurrency bounds how many layers are pulled at the same time,
-	// mirroring crane's own default job concurrency.
-	pullLayerConcurrency = 4
-)
-
-// pullLayerRetryBaseDelay is the initial backoff delay between failed
-// attempts to pull a single layer. Subsequent attempts double this delay.
-// It's a var (rather than a const) so tests can shrink it.
-var pullLayerRetryBaseDelay = 2 * time.Second
-
 // pullLayers eagerly downloads the full, uncompressed content of every layer in
 // img, verifying it against the layer's own digest. Reading through
 // cache.Image's lazy layer wrapper (see the caller in ExecuteChecks) is what
@@ -48,7 +34,11 @@ var pullLayerRetryBaseDelay = 2 * time.Second
 // error or a corrupted/truncated cache entry -- has already been retried here.
 // layerCache must be the same Cache instance img's layers were wrapped with
 // (via cache.Image), so a bad on-disk entry can be cleared before retrying.
-func pullLayers(ctx context.Context, img v1.Image, layerCache cache.Cache) error {
+// At most policy.MaxConcurrentLayerPulls layers are pulled at the same time.
+func pullLayers(ctx context.Context, img v1.Image, layerCache cache.Cache, policy network.Policy) (err error) {
+	ctx, span := telemetry.Start(ctx, "pull layers")
+	defer func() { telemetry.End(span, err) }()
+
 	logger := logr.FromContextOrDiscard(ctx)
 
 	layers, err := img.Layers()
@@ -57,11 +47,11 @@ func pullLayers(ctx context.Context, img v1.Image, layerCache cache.Cache) error
 	}
 
 	g, gctx := errgroup.WithContext(ctx)
-	g.SetLimit(pullLayerConcurrency)
+	g.SetLimit(policy.MaxConcurrentLayerPulls)
 
 	for _, layer := range layers {
 		g.Go(func() error {
-			return pullLayerWithRetry(gctx, logger, layer, layerCache)
+			return pullLayerWithRetry(gctx, logger, layer, layerCache, policy)
 		})
 	}
 
@@ -78,20 +68,28 @@ func pullLayers(ctx context.Context, img v1.Image, layerCache cache.Cache) error
 // truncated file cached. Left alone, a later read of that file can complete
 // without error (a short read isn't necessarily a read *error*), so a retry
 // could otherwise silently "succeed" against corrupt, incomplete data.
-func pullLayerWithRetry(ctx context.Context, logger logr.Logger, layer v1.Layer, layerCache cache.Cache) error {
+//
+// go-containerregistry's own retry support (remote.WithRetryBackoff, etc.) only
+// retries the initial HTTP round trip; it does not retry a read that fails
+// partway through streaming a layer's body. This is the layer of retry that
+// covers that gap, making up to policy.Attempts attempts.
+func pullLayerWithRetry(ctx context.Context, logger logr.Logger, layer v1.Layer, layerCache cache.Cache, policy network.Policy) (err error) {
 	diffID, err := layer.DiffID()
 	if err != nil {
 		return fmt.Errorf("failed to determine layer diff id: %w", err)
 	}
 
-	delay := pullLayerRetryBaseDelay
+	ctx, span := telemetry.Start(ctx, "pull layer", attribute.String("layer.diff_id", diffID.String()))
+	defer func() { telemetry.End(span, err) }()
+
 	var lastErr error
-	for attempt := 1; attempt <= pullLayerMaxAttempts; attempt++ {
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
 		if err := ctx.Err(); err != nil {
 			return err
 		}
 
-		if err := pullLayerOnce(layer, diffID); err != nil {
+		size, err := pullLayerOnce(layer, diffID)
+		if err != nil {
 			lastErr = err
 
 			if delErr := layerCache.Delete(diffID); delErr != nil && !errors.Is(delErr, cache.ErrNotFound) {
@@ -104,47 +102,50 @@ func pullLayerWithRetry(ctx context.Context, logger logr.Logger, layer v1.Layer,
 				reason = "cached layer content did not match expected digest; cleared cache entry"
 			}
 
-			if attempt == pullLayerMaxAttempts {
+			if attempt == policy.Attempts {
 				logger.V(log.DBG).Info(reason+", exhausted all attempts",
-					"diffID", diffID.String(), "attempt", attempt, "maxAttempts", pullLayerMaxAttempts, "reason", err.Error())
+					"diffID", diffID.String(), "attempt", attempt, "maxAttempts", policy.Attempts, "reason", err.Error())
 				break
 			}
 
 			logger.V(log.DBG).Info(reason+", will retry",
-				"diffID", diffID.String(), "attempt", attempt, "maxAttempts", pullLayerMaxAttempts, "reason", err.Error())
+				"diffID", diffID.String(), "attempt", attempt, "maxAttempts", policy.Attempts, "reason", err.Error())
 
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
-			case <-time.After(delay):
+			case <-time.After(policy.Delay(attempt)):
 			}
-			delay *= 2
+			telemetry.RecordRetry(ctx, "layer")
 			continue
 		}
 
+		span.SetAttributes(attribute.Int64("layer.size", size), attribute.Int("layer.attempts", attempt))
+		telemetry.RecordBytesPulled(ctx, size)
 		return nil
 	}
 
-	return fmt.Errorf("failed to pull layer %s after %d attempts: %w", diffID, pullLayerMaxAttempts, lastErr)
+	return fmt.Errorf("failed to pull layer %s after %d attempts: %w", diffID, policy.Attempts, lastErr)
 }
 
 // pullLayerOnce makes a single attempt to fully read a layer's uncompressed
-// content, verifying that it hashes to the layer's own reported diffID.
-func pullLayerOnce(layer v1.Layer, diffID v1.Hash) error {
+// content, verifying that it hashes to the layer's own reported diffID. The size
+// of the uncompressed content is returned.
+func pullLayerOnce(layer v1.Layer, diffID v1.Hash) (int64, error) {
 	rc, err := layer.Uncompressed()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer rc.Close()
 
-	got, _, err := v1.SHA256(rc)
+	got, size, err := v1.SHA256(rc)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if got != diffID {
-		return fmt.Errorf("%w: computed %s, expected %s", errLayerContentMismatch, got, diffID)
+		return 0, fmt.Errorf("%w: computed %s, expected %s", errLayerContentMismatch, got, diffID)
 	}
 
-	return nil
+	return size, nil
 }
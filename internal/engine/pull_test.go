@@ -21,6 +21,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 )
 
 // fakeLayer wraps a real, static v1.Layer so that Digest/DiffID/Size/MediaType
@@ -224,12 +226,11 @@ func (c *fakeCache) Deleted() []v1.Hash {
 }
 
 var _ = Describe("pullLayers", func() {
-	var origDelay time.Duration
+	var policy network.Policy
 
 	BeforeEach(func() {
-		origDelay = pullLayerRetryBaseDelay
-		pullLayerRetryBaseDelay = time.Millisecond
-		DeferCleanup(func() { pullLayerRetryBaseDelay = origDelay })
+		policy = network.DefaultPolicy()
+		policy.Backoff = time.Millisecond
 	})
 
 	It("succeeds on the first attempt when the layer downloads cleanly", func() {
@@ -237,32 +238,32 @@ var _ = Describe("pullLayers", func() {
 		img, err := mutate.AppendLayers(empty.Image, layer)
 		Expect(err).ToNot(HaveOccurred())
 
-		Expect(pullLayers(context.Background(), img, &fakeCache{})).To(Succeed())
+		Expect(pullLayers(context.Background(), img, &fakeCache{}, policy)).To(Succeed())
 		Expect(layer.Calls()).To(Equal(1))
 	})
 
 	It("retries a layer that fails transiently and eventually succeeds", func() {
-		layer := newFakeLayer([]byte("hello"), pullLayerMaxAttempts-1)
+		layer := newFakeLayer([]byte("hello"), network.DefaultAttempts-1)
 		img, err := mutate.AppendLayers(empty.Image, layer)
 		Expect(err).ToNot(HaveOccurred())
 
-		Expect(pullLayers(context.Background(), img, &fakeCache{})).To(Succeed())
-		Expect(layer.Calls()).To(Equal(pullLayerMaxAttempts))
+		Expect(pullLayers(context.Background(), img, &fakeCache{}, policy)).To(Succeed())
+		Expect(layer.Calls()).To(Equal(network.DefaultAttempts))
 	})
 
 	It("returns a wrapped error once all attempts are exhausted", func() {
-		layer := newFakeLayer([]byte("hello"), pullLayerMaxAttempts+5)
+		layer := newFakeLayer([]byte("hello"), network.DefaultAttempts+5)
 		img, err := mutate.AppendLayers(empty.Image, layer)
 		Expect(err).ToNot(HaveOccurred())
 
-		err = pullLayers(context.Background(), img, &fakeCache{})
+		err = pullLayers(context.Background(), img, &fakeCache{}, policy)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("failed to pull layer"))
-		Expect(layer.Calls()).To(Equal(pullLayerMaxAttempts))
+		Expect(layer.Calls()).To(Equal(network.DefaultAttempts))
 	})
 
 	It("bounds concurrency across many layers", func() {
-		const numLayers = pullLayerConcurrency * 3
+		const numLayers = network.DefaultMaxConcurrentLayerPulls * 3
 
 		var (
 			mu         sync.Mutex
@@ -296,7 +297,7 @@ var _ = Describe("pullLayers", func() {
 
 		done := make(chan error, 1)
 		go func() {
-			done <- pullLayers(context.Background(), img, &fakeCache{})
+			done <- pullLayers(context.Background(), img, &fakeCache{}, policy)
 		}()
 
 		// Wait until the pool has filled up to its configured limit.
@@ -304,29 +305,29 @@ var _ = Describe("pullLayers", func() {
 			mu.Lock()
 			defer mu.Unlock()
 			return active
-		}).Should(Equal(pullLayerConcurrency))
+		}).Should(Equal(network.DefaultMaxConcurrentLayerPulls))
 
 		// It should never exceed that limit while more layers remain queued.
 		Consistently(func() int {
 			mu.Lock()
 			defer mu.Unlock()
 			return maxActive
-		}).Should(BeNumerically("<=", pullLayerConcurrency))
+		}).Should(BeNumerically("<=", network.DefaultMaxConcurrentLayerPulls))
 
 		close(releaseAll)
 		Eventually(done).Should(Receive(BeNil()))
-		Expect(maxActive).To(Equal(pullLayerConcurrency))
+		Expect(maxActive).To(Equal(network.DefaultMaxConcurrentLayerPulls))
 	})
 
 	It("stops promptly without retrying when the context is already cancelled", func() {
-		layer := newFakeLayer([]byte("hello"), pullLayerMaxAttempts+5)
+		layer := newFakeLayer([]byte("hello"), network.DefaultAttempts+5)
 		img, err := mutate.AppendLayers(empty.Image, layer)
 		Expect(err).ToNot(HaveOccurred())
 
 		ctx, cancel := context.WithCancel(context.Background())
 		cancel()
 
-		err = pullLayers(ctx, img, &fakeCache{})
+		err = pullLayers(ctx, img, &fakeCache{}, policy)
 		Expect(err).To(MatchError(context.Canceled))
 		// The context is checked before the first attempt, so the layer
 		// should never actually be read -- distinguishing prompt
@@ -335,7 +336,7 @@ var _ = Describe("pullLayers", func() {
 	})
 
 	It("returns a wrapped error when listing an image's layers fails", func() {
-		err := pullLayers(context.Background(), erroringLayersImage{Image: empty.Image}, &fakeCache{})
+		err := pullLayers(context.Background(), erroringLayersImage{Image: empty.Image}, &fakeCache{}, policy)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("failed to list image layers"))
 	})
@@ -345,21 +346,21 @@ var _ = Describe("pullLayers", func() {
 		diffID, err := layer.DiffID()
 		Expect(err).ToNot(HaveOccurred())
 
-		err = pullLayerOnce(layer, diffID)
+		_, err = pullLayerOnce(layer, diffID)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("simulated connection reset mid-stream"))
 	})
 
 	It("returns a wrapped error when a layer's DiffID cannot be determined", func() {
 		layer := diffIDErrLayer{Layer: static.NewLayer([]byte("hello"), types.DockerLayer)}
-		err := pullLayerWithRetry(context.Background(), logr.Discard(), layer, &fakeCache{})
+		err := pullLayerWithRetry(context.Background(), logr.Discard(), layer, &fakeCache{}, policy)
 		Expect(err).To(HaveOccurred())
 		Expect(err.Error()).To(ContainSubstring("failed to determine layer diff id"))
 	})
 
 	It("stops waiting and returns promptly when the context is cancelled during backoff", func() {
-		pullLayerRetryBaseDelay = 200 * time.Millisecond
-		layer := newFakeLayer([]byte("hello"), pullLayerMaxAttempts+5)
+		policy.Backoff = 200 * time.Millisecond
+		layer := newFakeLayer([]byte("hello"), network.DefaultAttempts+5)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		go func() {
@@ -367,7 +368,7 @@ var _ = Describe("pullLayers", func() {
 			cancel()
 		}()
 
-		err := pullLayerWithRetry(ctx, logr.Discard(), layer, &fakeCache{})
+		err := pullLayerWithRetry(ctx, logr.Discard(), layer, &fakeCache{}, policy)
 		Expect(err).To(HaveOccurred())
 		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
 		Expect(layer.Calls()).To(Equal(1))
@@ -382,7 +383,7 @@ var _ = Describe("pullLayers", func() {
 		Expect(err).ToNot(HaveOccurred())
 
 		fc := &fakeCache{}
-		Expect(pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc)).To(Succeed())
+		Expect(pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc, policy)).To(Succeed())
 		Expect(layer.Calls()).To(Equal(2))
 		Expect(fc.Deleted()).To(ConsistOf(diffID))
 	})
@@ -390,18 +391,18 @@ var _ = Describe("pullLayers", func() {
 	It("returns a wrapped mismatch error and clears the cache on every attempt when corruption persists", func() {
 		good := []byte("the-real-uncorrupted-layer-content")
 		bad := []byte("always-corrupted")
-		layer := newCorruptThenCleanLayer(good, bad, pullLayerMaxAttempts+5)
+		layer := newCorruptThenCleanLayer(good, bad, network.DefaultAttempts+5)
 
 		diffID, err := layer.DiffID()
 		Expect(err).ToNot(HaveOccurred())
 
 		fc := &fakeCache{}
-		err = pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc)
+		err = pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc, policy)
 		Expect(err).To(HaveOccurred())
 		Expect(errors.Is(err, errLayerContentMismatch)).To(BeTrue())
 
 		deleted := fc.Deleted()
-		Expect(deleted).To(HaveLen(pullLayerMaxAttempts))
+		Expect(deleted).To(HaveLen(network.DefaultAttempts))
 		for _, h := range deleted {
 			Expect(h).To(Equal(diffID))
 		}
@@ -411,7 +412,7 @@ var _ = Describe("pullLayers", func() {
 		layer := newFakeLayer([]byte("hello"), 1)
 		fc := &fakeCache{deleteErr: errors.New("simulated disk error clearing cache entry")}
 
-		Expect(pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc)).To(Succeed())
+		Expect(pullLayerWithRetry(context.Background(), logr.Discard(), layer, fc, policy)).To(Succeed())
 		Expect(fc.Deleted()).To(HaveLen(1))
 	})
 
@@ -439,7 +440,7 @@ var _ = Describe("pullLayers", func() {
 		// cache would happily hand back that truncated file on the next
 		// read (a short plain-file read isn't an io.ErrUnexpectedEOF), so
 		// this only succeeds if the corrupted entry was actually cleared.
-		Expect(pullLayers(context.Background(), cachedImg, realCache)).To(Succeed())
+		Expect(pullLayers(context.Background(), cachedImg, realCache, policy)).To(Succeed())
 		Expect(remoteLayer.Calls()).To(Equal(2))
 
 		cachedLayer, err := realCache.Get(diffID)
@@ -16,8 +16,10 @@ import (
 	"github.com/go-logr/logr"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
 )
 
 // expandLiteralPatternsWithDescendantGlob adds a "path/**" pattern for each pattern that has no
@@ -76,13 +78,15 @@ func appendSymlinkTargetPatterns(filterPatterns []string, logger logr.Logger, re
 
 // untar takes a destination path, a container image, and a list of files or match patterns
 // which should be extracted out of the image.
-func untar(ctx context.Context, dst string, img v1.Image, requiredFilePatterns []string) error {
+func untar(ctx context.Context, dst string, img v1.Image, requiredFilePatterns []string) (err error) {
+	ctx, span := telemetry.Start(ctx, "extract image")
+	defer func() { telemetry.End(span, err) }()
+
 	logger := logr.FromContextOrDiscard(ctx)
 	logger.V(log.DBG).Info("exporting and flattening image")
 
 	// Extract all files matching the required file patterns.
 	state := make(map[string]struct{})
-	var err error
 
 	logger.V(log.DBG).Info("extracting container filesystem", "path", dst)
 
@@ -91,14 +95,18 @@ func untar(ctx context.Context, dst string, img v1.Image, requiredFilePatterns [
 	// In the case of symlinks, the targets may not be included in the original required file
 	// patterns, so make additional passes through the layers as needed to find them.
 	// Make at least one pass to validate the tar format, even if there are no required patterns.
-	for {
-		if remaining, err = untarOnce(ctx, dst, img, remaining, state); err != nil {
+	for pass := 1; ; pass++ {
+		passCtx, passSpan := telemetry.Start(ctx, "extract pass", attribute.Int("extract.pass", pass))
+		remaining, err = untarOnce(passCtx, dst, img, remaining, state)
+		telemetry.End(passSpan, err)
+		if err != nil {
 			return fmt.Errorf("failed to extract tarball: %w", err)
 		}
 		if len(remaining) == 0 {
 			break
 		}
 	}
+	span.SetAttributes(attribute.Int("extract.files", len(state)))
 
 	return nil
 }
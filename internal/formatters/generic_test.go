@@ -29,6 +29,7 @@ var _ = Describe("Generic formatters", func() {
 				{
 					Check:       check.NewGenericCheck("failed1", nil, check.Metadata{}, check.HelpText{}, nil),
 					ElapsedTime: 1001 * time.Millisecond,
+					Findings:    []string{"/usr/bin/nc"},
 				},
 			},
 		}
@@ -72,6 +73,7 @@ var _ = Describe("Generic formatters", func() {
 				for index, i := range results.Failed {
 					Expect(testResponseObj.Results.Failed[index].Name).To(Equal(i.Name()))
 					Expect(testResponseObj.Results.Failed[index].ElapsedTime).To(Equal(float64(i.ElapsedTime / time.Millisecond)))
+					Expect(testResponseObj.Results.Failed[index].Findings).To(Equal(i.Findings))
 				}
 			},
 			Entry("with passing results", "image1", true, false, ""),
@@ -118,6 +120,7 @@ var _ = Describe("Generic formatters", func() {
 				for index, i := range results.Failed {
 					Expect(testResponseObj.Results.Failed[index].Name).To(Equal(i.Name()))
 					Expect(testResponseObj.Results.Failed[index].ElapsedTime).To(Equal(float64(i.ElapsedTime / time.Millisecond)))
+					Expect(testResponseObj.Results.Failed[index].Findings).To(Equal(i.Findings))
 				}
 			},
 			Entry("with passing results", "image1", true, false, ""),
@@ -86,7 +86,7 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 			Failure: &JUnitMessage{
 				Message:  "Failed",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: junitContents(result),
 			},
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
@@ -101,7 +101,7 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 			Warning: &JUnitMessage{
 				Message:  "Warn",
 				Type:     "",
-				Contents: fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion),
+				Contents: junitContents(result),
 			},
 		}
 		testsuite.TestCases = append(testsuite.TestCases, testCase)
@@ -124,3 +124,12 @@ func junitXMLFormatter(_ context.Context, r certification.Results) ([]byte, erro
 
 	return bytes, nil
 }
+
+// junitContents describes how to fix the check in result, followed by its findings.
+func junitContents(result certification.Result) string {
+	contents := fmt.Sprintf("%s: Suggested Fix: %s", result.Help().Message, result.Help().Suggestion)
+	for _, finding := range result.Findings {
+		contents += "\n- " + finding
+	}
+	return contents
+}
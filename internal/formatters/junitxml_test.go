@@ -2,6 +2,7 @@ package formatters
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -59,6 +60,7 @@ var _ = Describe("JUnitXML Formatter", func() {
 							},
 							nil),
 						ElapsedTime: 0,
+						Findings:    []string{"/usr/bin/nc", "/usr/bin/telnet"},
 					},
 				},
 				Errors: []certification.Result{
@@ -124,5 +126,16 @@ var _ = Describe("JUnitXML Formatter", func() {
 			Expect(string(out)).To(ContainSubstring("FailedCheck"))
 			Expect(string(out)).To(ContainSubstring("ErroredCheck"))
 		})
+		It("should include the findings of a failed check", func() {
+			out, err := junitXMLFormatter(context.TODO(), response)
+			Expect(err).ToNot(HaveOccurred())
+
+			var suites JUnitTestSuites
+			Expect(xml.Unmarshal(out, &suites)).To(Succeed())
+			Expect(suites.Suites[0].TestCases).To(ContainElement(SatisfyAll(
+				HaveField("Name", "FailedCheck"),
+				HaveField("Failure.Contents", "helptext: Suggested Fix: suggestion\n- /usr/bin/nc\n- /usr/bin/telnet"),
+			)))
+		})
 	})
 })
@@ -19,6 +19,7 @@ func getResponse(r certification.Results) UserResponse {
 				Name:        check.Name(),
 				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
 				Description: check.Metadata().Description,
+				Findings:    check.Findings,
 			})
 		}
 	}
@@ -33,6 +34,7 @@ func getResponse(r certification.Results) UserResponse {
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
 			})
 		}
 	}
@@ -44,6 +46,7 @@ func getResponse(r certification.Results) UserResponse {
 				ElapsedTime: float64(check.ElapsedTime.Milliseconds()),
 				Description: check.Metadata().Description,
 				Help:        check.Help().Message,
+				Findings:    check.Findings,
 			})
 		}
 	}
@@ -58,6 +61,7 @@ func getResponse(r certification.Results) UserResponse {
 				Suggestion:       check.Help().Suggestion,
 				KnowledgeBaseURL: check.Metadata().KnowledgeBaseURL,
 				CheckURL:         check.Metadata().CheckURL,
+				Findings:         check.Findings,
 			})
 		}
 	}
@@ -98,11 +102,12 @@ type resultsText struct {
 // checkExecutionInfo contains all possible output fields that a user might see in their result.
 // Empty fields will be omitted.
 type checkExecutionInfo struct {
-	Name             string  `json:"name,omitempty" xml:"name,omitempty"`
-	ElapsedTime      float64 `json:"elapsed_time" xml:"elapsed_time"`
-	Description      string  `json:"description,omitempty" xml:"description,omitempty"`
-	Help             string  `json:"help,omitempty" xml:"help,omitempty"`
-	Suggestion       string  `json:"suggestion,omitempty" xml:"suggestion,omitempty"`
-	KnowledgeBaseURL string  `json:"knowledgebase_url,omitempty" xml:"knowledgebase_url,omitempty"`
-	CheckURL         string  `json:"check_url,omitempty" xml:"check_url,omitempty"`
+	Name             string   `json:"name,omitempty" xml:"name,omitempty"`
+	ElapsedTime      float64  `json:"elapsed_time" xml:"elapsed_time"`
+	Description      string   `json:"description,omitempty" xml:"description,omitempty"`
+	Help             string   `json:"help,omitempty" xml:"help,omitempty"`
+	Suggestion       string   `json:"suggestion,omitempty" xml:"suggestion,omitempty"`
+	KnowledgeBaseURL string   `json:"knowledgebase_url,omitempty" xml:"knowledgebase_url,omitempty"`
+	CheckURL         string   `json:"check_url,omitempty" xml:"check_url,omitempty"`
+	Findings         []string `json:"findings,omitempty" xml:"findings,omitempty"`
 }
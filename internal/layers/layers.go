@@ -0,0 +1,279 @@
+// Package layers analyzes how efficiently an image's layers are built: the bytes each layer
+// adds, and the bytes it removes or overwrites from earlier layers, which are still pulled;
+// the package manager caches left in the image; and the files duplicated across layers.
+package layers
+
+import (
+	"archive/tar"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = whiteoutPrefix + whiteoutPrefix + ".opq"
+	// cacheDir holds the caches of dnf, yum and microdnf, among other package managers.
+	cacheDir = "var/cache"
+)
+
+// Analysis is the analysis of an image's layers.
+type Analysis struct {
+	// Size is the bytes of the files added by all layers, including those removed or
+	// overwritten by later layers.
+	Size int64 `json:"size"`
+	// WastedBytes is the bytes of the files removed or overwritten by later layers.
+	WastedBytes int64 `json:"wasted_bytes"`
+	// CacheBytes is the bytes of the package manager caches in the image.
+	CacheBytes int64 `json:"cache_bytes"`
+	// DuplicateBytes is the bytes of the copies of files whose contents are added by more
+	// than one layer.
+	DuplicateBytes   int64             `json:"duplicate_bytes"`
+	Layers           []Layer           `json:"layers"`
+	OverwrittenFiles []OverwrittenFile `json:"overwritten_files"`
+	Caches           []Cache           `json:"caches"`
+	Duplicates       []Duplicate       `json:"duplicates"`
+}
+
+// Layer is the analysis of a single layer.
+type Layer struct {
+	Digest string `json:"digest"`
+	Files  int    `json:"files"`
+	// AddedBytes is the bytes of the files the layer adds.
+	AddedBytes int64 `json:"added_bytes"`
+	// RemovedBytes is the bytes of the files from earlier layers the layer removes.
+	RemovedBytes int64 `json:"removed_bytes"`
+	// OverwrittenBytes is the bytes of the files from earlier layers the layer replaces.
+	OverwrittenBytes int64 `json:"overwritten_bytes"`
+}
+
+// OverwrittenFile is a file added by a layer, and replaced by a later one.
+type OverwrittenFile struct {
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	Layer         int    `json:"layer"`
+	OverwrittenBy int    `json:"overwritten_by"`
+}
+
+// Cache is a package manager cache in the image.
+type Cache struct {
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Duplicate is contents added more than once, by more than one layer.
+type Duplicate struct {
+	Digest string    `json:"digest"`
+	Size   int64     `json:"size"`
+	Copies []FileRef `json:"copies"`
+}
+
+// FileRef is a file in a layer.
+type FileRef struct {
+	Layer int    `json:"layer"`
+	Path  string `json:"path"`
+}
+
+// file is a regular file added by a layer.
+type file struct {
+	layer  int
+	size   int64
+	digest string
+}
+
+// Analyze returns the analysis of the layers of img.
+func Analyze(ctx context.Context, img cranev1.Image) (*Analysis, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("could not get image layers: %w", err)
+	}
+
+	analysis := &Analysis{Layers: make([]Layer, 0, len(layers))}
+	// files are the regular files in the filesystem as of the last layer analyzed.
+	files := map[string]file{}
+	copies := map[string]*Duplicate{}
+	for i, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("could not get layer digest: %w", err)
+		}
+
+		lr, err := analyzeLayer(ctx, i, layer, files, copies, analysis)
+		if err != nil {
+			return nil, fmt.Errorf("could not analyze layer %s: %w", digest, err)
+		}
+		lr.Digest = digest.String()
+		analysis.Layers = append(analysis.Layers, lr)
+		analysis.Size += lr.AddedBytes
+		analysis.WastedBytes += lr.RemovedBytes + lr.OverwrittenBytes
+	}
+
+	analysis.Caches = caches(files)
+	for _, c := range analysis.Caches {
+		analysis.CacheBytes += c.Bytes
+	}
+
+	analysis.Duplicates = duplicates(copies)
+	for _, d := range analysis.Duplicates {
+		analysis.DuplicateBytes += d.Size * int64(len(d.Copies)-1)
+	}
+
+	slices.SortFunc(analysis.OverwrittenFiles, func(a, b OverwrittenFile) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), cmp.Compare(a.Layer, b.Layer), strings.Compare(a.Path, b.Path))
+	})
+
+	return analysis, nil
+}
+
+// analyzeLayer applies the layer at index to files, recording the copies of the contents it
+// adds, and the files it overwrites in analysis.
+func analyzeLayer(ctx context.Context, index int, layer cranev1.Layer, files map[string]file, copies map[string]*Duplicate, analysis *Analysis) (Layer, error) {
+	lr := Layer{}
+
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return lr, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer rc.Close()
+
+	// the whiteouts of a layer only apply to earlier layers, but may come after the files
+	// it adds, so they are applied first.
+	var removed, opaque []string
+	added := map[string]file{}
+	// others are the entries, e.g. directories and links, that replace a file.
+	others := map[string]struct{}{}
+
+	tr := tar.NewReader(rc)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return lr, fmt.Errorf("reading layer contents: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return lr, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case base == opaqueWhiteout:
+			opaque = append(opaque, dir)
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			removed = append(removed, path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)))
+			continue
+		case header.Typeflag != tar.TypeReg:
+			others[name] = struct{}{}
+			continue
+		}
+
+		h := sha256.New()
+		if _, err := io.Copy(h, tr); err != nil {
+			return lr, fmt.Errorf("reading %s: %w", name, err)
+		}
+		added[name] = file{layer: index, size: header.Size, digest: hex.EncodeToString(h.Sum(nil))}
+	}
+
+	for name, f := range files {
+		_, replaced := others[name]
+		if replaced || slices.ContainsFunc(opaque, func(dir string) bool { return within(name, dir) }) ||
+			slices.ContainsFunc(removed, func(r string) bool { return name == r || within(name, r) }) {
+			lr.RemovedBytes += f.size
+			delete(files, name)
+		}
+	}
+
+	for name, f := range added {
+		if prev, found := files[name]; found {
+			lr.OverwrittenBytes += prev.size
+			analysis.OverwrittenFiles = append(analysis.OverwrittenFiles, OverwrittenFile{
+				Path: "/" + name, Size: prev.size, Layer: prev.layer, OverwrittenBy: index,
+			})
+		}
+		files[name] = f
+		lr.Files++
+		lr.AddedBytes += f.size
+		if f.size > 0 {
+			d, ok := copies[f.digest]
+			if !ok {
+				d = &Duplicate{Digest: "sha256:" + f.digest, Size: f.size}
+				copies[f.digest] = d
+			}
+			d.Copies = append(d.Copies, FileRef{Layer: index, Path: "/" + name})
+		}
+	}
+
+	return lr, nil
+}
+
+// within is true if name is in the directory dir, which is empty for the root directory.
+func within(name, dir string) bool {
+	return dir == "" || strings.HasPrefix(name, dir+"/")
+}
+
+// caches returns the package manager caches in files, largest first.
+func caches(files map[string]file) []Cache {
+	byPath := map[string]*Cache{}
+	for name, f := range files {
+		if !within(name, cacheDir) {
+			continue
+		}
+		cachePath := cacheDir
+		if sub, _, found := strings.Cut(strings.TrimPrefix(name, cacheDir+"/"), "/"); found {
+			cachePath = path.Join(cacheDir, sub)
+		}
+		c, ok := byPath[cachePath]
+		if !ok {
+			c = &Cache{Path: "/" + cachePath}
+			byPath[cachePath] = c
+		}
+		c.Files++
+		c.Bytes += f.size
+	}
+
+	result := make([]Cache, 0, len(byPath))
+	for _, c := range byPath {
+		result = append(result, *c)
+	}
+	slices.SortFunc(result, func(a, b Cache) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), strings.Compare(a.Path, b.Path))
+	})
+	return result
+}
+
+// duplicates returns the contents added by more than one layer, those wasting the most bytes
+// first.
+func duplicates(copies map[string]*Duplicate) []Duplicate {
+	var result []Duplicate
+	for _, d := range copies {
+		if !slices.ContainsFunc(d.Copies, func(r FileRef) bool { return r.Layer != d.Copies[0].Layer }) {
+			continue
+		}
+		slices.SortFunc(d.Copies, func(a, b FileRef) int {
+			return cmp.Or(cmp.Compare(a.Layer, b.Layer), strings.Compare(a.Path, b.Path))
+		})
+		result = append(result, *d)
+	}
+
+	slices.SortFunc(result, func(a, b Duplicate) int {
+		return cmp.Or(
+			cmp.Compare(b.Size*int64(len(b.Copies)-1), a.Size*int64(len(a.Copies)-1)),
+			strings.Compare(a.Digest, b.Digest),
+		)
+	})
+	return result
+}
@@ -0,0 +1,196 @@
+package layers
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"strings"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+)
+
+// layer returns a layer containing files, a map of paths to contents. Paths ending in / are
+// directories.
+func layer(files map[string]string) cranev1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, contents := range files {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Size: int64(len(contents)), Mode: 0o644}
+		if strings.HasSuffix(name, "/") {
+			header = &tar.Header{Typeflag: tar.TypeDir, Name: name, Mode: 0o755}
+		}
+		Expect(tw.WriteHeader(header)).To(Succeed())
+		_, err := tw.Write([]byte(contents))
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return static.NewLayer(buf.Bytes(), types.DockerLayer)
+}
+
+var _ = Describe("Layer analysis", func() {
+	analyze := func(layers ...cranev1.Layer) *Analysis {
+		img, err := mutate.AppendLayers(empty.Image, layers...)
+		Expect(err).ToNot(HaveOccurred())
+		analysis, err := Analyze(context.Background(), img)
+		Expect(err).ToNot(HaveOccurred())
+		return analysis
+	}
+
+	It("should analyze the bytes each layer adds, removes and overwrites", func() {
+		analysis := analyze(
+			layer(map[string]string{
+				"usr/":              "",
+				"usr/bin/app":       strings.Repeat("a", 100),
+				"opt/app/build.tgz": strings.Repeat("b", 50),
+				"etc/app.conf":      strings.Repeat("c", 10),
+				"opt/app/tmp/x":     strings.Repeat("d", 5),
+			}),
+			layer(map[string]string{
+				"opt/app/.wh.build.tgz": "",
+				"etc/app.conf":          strings.Repeat("e", 20),
+			}),
+			layer(map[string]string{
+				"opt/app/tmp/.wh..wh..opq": "",
+			}),
+		)
+
+		Expect(analysis.Layers).To(HaveLen(3))
+		Expect(analysis.Layers[0].Digest).To(HavePrefix("sha256:"))
+		Expect(analysis.Layers[0].Files).To(Equal(4))
+		Expect(analysis.Layers[0].AddedBytes).To(BeEquivalentTo(165))
+		Expect(analysis.Layers[1].AddedBytes).To(BeEquivalentTo(20))
+		Expect(analysis.Layers[1].RemovedBytes).To(BeEquivalentTo(50))
+		Expect(analysis.Layers[1].OverwrittenBytes).To(BeEquivalentTo(10))
+		Expect(analysis.Layers[2].AddedBytes).To(BeEquivalentTo(0))
+		Expect(analysis.Layers[2].RemovedBytes).To(BeEquivalentTo(5))
+		Expect(analysis.Size).To(BeEquivalentTo(185))
+		Expect(analysis.WastedBytes).To(BeEquivalentTo(65))
+		Expect(analysis.OverwrittenFiles).To(ConsistOf(OverwrittenFile{Path: "/etc/app.conf", Size: 10, Layer: 0, OverwrittenBy: 1}))
+	})
+
+	It("should analyze the package manager caches left in the image", func() {
+		analysis := analyze(
+			layer(map[string]string{
+				"var/cache/dnf/repo.solv":        strings.Repeat("a", 30),
+				"var/cache/dnf/packages/app.rpm": strings.Repeat("b", 70),
+				"var/cache/yum/metadata":         strings.Repeat("c", 10),
+				"var/cache/ldconfig":             strings.Repeat("d", 1),
+			}),
+			layer(map[string]string{"var/cache/.wh.yum": ""}),
+		)
+
+		Expect(analysis.Caches).To(Equal([]Cache{
+			{Path: "/var/cache/dnf", Files: 2, Bytes: 100},
+			{Path: "/var/cache", Files: 1, Bytes: 1},
+		}))
+		Expect(analysis.CacheBytes).To(BeEquivalentTo(101))
+	})
+
+	It("should analyze files duplicated across layers", func() {
+		analysis := analyze(
+			layer(map[string]string{"opt/app/lib.so": strings.Repeat("a", 40), "opt/app/a": "x", "opt/app/b": "x"}),
+			layer(map[string]string{"opt/app/v2/lib.so": strings.Repeat("a", 40), "usr/lib/lib.so": strings.Repeat("a", 40)}),
+		)
+
+		Expect(analysis.Duplicates).To(HaveLen(1))
+		Expect(analysis.Duplicates[0].Size).To(BeEquivalentTo(40))
+		Expect(analysis.Duplicates[0].Copies).To(Equal([]FileRef{
+			{Layer: 0, Path: "/opt/app/lib.so"},
+			{Layer: 1, Path: "/opt/app/v2/lib.so"},
+			{Layer: 1, Path: "/usr/lib/lib.so"},
+		}))
+		Expect(analysis.DuplicateBytes).To(BeEquivalentTo(80))
+	})
+
+	It("should return an error if the context is done", func() {
+		img, err := mutate.AppendLayers(empty.Image, layer(map[string]string{"a": "a"}))
+		Expect(err).ToNot(HaveOccurred())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = Analyze(ctx, img)
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
+
+var _ = Describe("Thresholds", func() {
+	analysis := &Analysis{
+		Size:           300 << 20,
+		WastedBytes:    120 << 20,
+		CacheBytes:     1 << 20,
+		DuplicateBytes: 60 << 20,
+		Layers:         []Layer{{Digest: "sha256:aaaa", AddedBytes: 200 << 20}, {Digest: "sha256:bbbb", AddedBytes: 100 << 20}},
+	}
+
+	It("should only warn of waste by default", func() {
+		thresholds := DefaultThresholds()
+		Expect(thresholds.Validate()).To(Succeed())
+		Expect(thresholds.Fails()).To(BeFalse())
+
+		var messages []string
+		for _, e := range thresholds.Evaluate(analysis) {
+			messages = append(messages, e.String())
+		}
+		Expect(messages).To(ConsistOf(
+			"120.0 MiB of files are removed or overwritten by later layers, over the warn limit of 100.0 MiB",
+			"60.0 MiB of files are duplicated across layers, over the warn limit of 50.0 MiB",
+		))
+	})
+
+	It("should evaluate the size of the image and of each layer", func() {
+		thresholds := DefaultThresholds()
+		thresholds[ThresholdImageSize] = Threshold{Limit: 250 << 20, Action: action.Fail}
+		thresholds[ThresholdLayerSize] = Threshold{Limit: 150 << 20, Action: action.Warn}
+		Expect(thresholds.Fails()).To(BeTrue())
+
+		exceeded := thresholds.Evaluate(analysis)
+		Expect(exceeded).To(ContainElements(
+			Exceeded{Threshold: thresholds[ThresholdImageSize], Name: ThresholdImageSize, Message: "the layers add 300.0 MiB"},
+			Exceeded{Threshold: thresholds[ThresholdLayerSize], Name: ThresholdLayerSize, Message: "layer 0 (sha256:aaaa) adds 200.0 MiB"},
+		))
+		Expect(exceeded).To(HaveLen(4))
+	})
+
+	It("should reject invalid thresholds", func() {
+		thresholds := Thresholds{
+			ThresholdCaches: {Limit: -1, Action: action.Warn},
+			ThresholdWasted: {Limit: 1, Action: "error"},
+			"layer_count":   {Limit: 1, Action: action.Warn},
+		}
+		err := thresholds.Validate()
+		Expect(err).To(MatchError(ContainSubstring("limit of threshold caches must not be negative")))
+		Expect(err).To(MatchError(ContainSubstring(`action of threshold wasted must be warn or fail, got "error"`)))
+		Expect(err).To(MatchError(ContainSubstring("unknown threshold layer_count")))
+	})
+
+	DescribeTable("should parse limits",
+		func(limit string, expected int64) {
+			Expect(ParseLimit(limit)).To(Equal(expected))
+		},
+		Entry("binary", "500Mi", int64(500<<20)),
+		Entry("decimal", "1G", int64(1000000000)),
+		Entry("bytes", "1024", int64(1024)),
+	)
+
+	It("should reject an invalid limit", func() {
+		_, err := ParseLimit("lots")
+		Expect(err).To(MatchError(ContainSubstring(`invalid limit "lots"`)))
+	})
+
+	DescribeTable("should format bytes",
+		func(n int64, expected string) {
+			Expect(FormatBytes(n)).To(Equal(expected))
+		},
+		Entry("bytes", int64(512), "512 B"),
+		Entry("kibibytes", int64(1536), "1.5 KiB"),
+		Entry("gibibytes", int64(3<<30), "3.0 GiB"),
+	)
+})
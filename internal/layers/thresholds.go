@@ -0,0 +1,129 @@
+package layers
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+)
+
+// The names of the thresholds, as set in the layer_analysis section of the config.
+const (
+	ThresholdImageSize  = "image_size"
+	ThresholdLayerSize  = "layer_size"
+	ThresholdWasted     = "wasted"
+	ThresholdCaches     = "caches"
+	ThresholdDuplicates = "duplicates"
+)
+
+// Threshold is a limit, in bytes, and the action taken when it is exceeded.
+type Threshold struct {
+	// Limit is the most bytes allowed. The threshold is not evaluated if it is 0.
+	Limit int64
+	// Action is taken when the threshold is exceeded.
+	Action action.Action
+}
+
+// Thresholds are the thresholds an Analysis is evaluated against, by name.
+type Thresholds map[string]Threshold
+
+// DefaultThresholds warns of more than 100Mi wasted by later layers, 10Mi of package manager
+// caches, and 50Mi of duplicated files. The size of the image and its layers is not limited.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		ThresholdImageSize:  {Action: action.Warn},
+		ThresholdLayerSize:  {Action: action.Warn},
+		ThresholdWasted:     {Limit: 100 << 20, Action: action.Warn},
+		ThresholdCaches:     {Limit: 10 << 20, Action: action.Warn},
+		ThresholdDuplicates: {Limit: 50 << 20, Action: action.Warn},
+	}
+}
+
+// ParseLimit returns the bytes in limit, a quantity such as 500Mi or 1G.
+func ParseLimit(limit string) (int64, error) {
+	q, err := resource.ParseQuantity(limit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid limit %q: %w", limit, err)
+	}
+	return q.Value(), nil
+}
+
+// Validate returns an error describing every invalid threshold in t.
+func (t Thresholds) Validate() error {
+	var errs []error
+	for name, threshold := range t {
+		if _, ok := defaultThresholds[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown threshold %s", name))
+			continue
+		}
+		if threshold.Limit < 0 {
+			errs = append(errs, fmt.Errorf("limit of threshold %s must not be negative, got %d", name, threshold.Limit))
+		}
+		if err := threshold.Action.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("action of threshold %s %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fails is true if exceeding any threshold in t fails.
+func (t Thresholds) Fails() bool {
+	var actions []action.Action
+	for _, threshold := range t {
+		if threshold.Limit > 0 {
+			actions = append(actions, threshold.Action)
+		}
+	}
+	return action.Fails(actions...)
+}
+
+var defaultThresholds = DefaultThresholds()
+
+// Exceeded is a threshold exceeded by an Analysis.
+type Exceeded struct {
+	Threshold
+	Name string
+	// Message describes what exceeded the threshold.
+	Message string
+}
+
+func (e Exceeded) String() string {
+	return fmt.Sprintf("%s, over the %s limit of %s", e.Message, e.Action, FormatBytes(e.Limit))
+}
+
+// Evaluate returns the thresholds in t that r exceeds.
+func (t Thresholds) Evaluate(r *Analysis) []Exceeded {
+	var exceeded []Exceeded
+	exceeds := func(name string, value int64, message string) {
+		threshold := t[name]
+		if threshold.Limit > 0 && value > threshold.Limit {
+			exceeded = append(exceeded, Exceeded{Threshold: threshold, Name: name, Message: message})
+		}
+	}
+
+	exceeds(ThresholdImageSize, r.Size, fmt.Sprintf("the layers add %s", FormatBytes(r.Size)))
+	for i, l := range r.Layers {
+		exceeds(ThresholdLayerSize, l.AddedBytes, fmt.Sprintf("layer %d (%s) adds %s", i, l.Digest, FormatBytes(l.AddedBytes)))
+	}
+	exceeds(ThresholdWasted, r.WastedBytes, fmt.Sprintf("%s of files are removed or overwritten by later layers", FormatBytes(r.WastedBytes)))
+	exceeds(ThresholdCaches, r.CacheBytes, fmt.Sprintf("package manager caches take %s", FormatBytes(r.CacheBytes)))
+	exceeds(ThresholdDuplicates, r.DuplicateBytes, fmt.Sprintf("%s of files are duplicated across layers", FormatBytes(r.DuplicateBytes)))
+
+	return exceeded
+}
+
+// FormatBytes returns n in binary units, e.g. 1.5 MiB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -1,21 +1,22 @@
 package lib
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path"
 	"path/filepath"
-	"time"
 
 	"github.com/go-logr/logr"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
@@ -51,7 +52,7 @@ func NewPyxisClient(ctx context.Context, projectID, token, host string) PyxisCli
 		host,
 		token,
 		projectID,
-		&http.Client{Timeout: 60 * time.Second},
+		network.FromContext(ctx).Client(),
 	)
 }
 
@@ -62,16 +63,49 @@ type ContainerCertificationSubmitter struct {
 	Pyxis                  PyxisClient
 	DockerConfig           string
 	PreflightLogFile       string
+	// Resume continues a previous submission from the last successful step recorded
+	// in the submission journal in the artifacts directory, instead of starting a new one.
+	Resume bool
 }
 
 func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 	logger := logr.FromContextOrDiscard(ctx)
+
+	submission, err := s.prepareSubmission(ctx)
+	if err != nil {
+		return err
+	}
+
+	journal, err := s.submissionJournal(ctx, submission)
+	if err != nil {
+		return err
+	}
+
+	certResults, err := s.Pyxis.SubmitResults(pyxis.ContextWithJournal(ctx, journal), submission)
+	if err != nil {
+		return fmt.Errorf("could not submit to pyxis: %w", err)
+	}
+
+	logger.Info("Test results have been submitted to Red Hat.")
+	logger.Info("These results will be reviewed by Red Hat for final certification.")
+	logger.Info(fmt.Sprintf("The container's image id is: %s.", certResults.CertImage.ID))
+	logger.Info(fmt.Sprintf("Please check %s to view test results.", BuildTestResultsURL(s.CertificationProjectID, certResults.TestResults.ID)))
+	logger.Info(fmt.Sprintf("Please check %s to view security vulnerabilities.", BuildVulnerabilitiesURL(s.CertificationProjectID, certResults.CertImage.ID)))
+	logger.Info(fmt.Sprintf("Please check %s to monitor the progress.", BuildImagesURL(s.CertificationProjectID)))
+
+	return nil
+}
+
+// prepareSubmission retrieves the project from pyxis, and reads the artifacts written
+// during the check execution into the CertificationInput that will be submitted.
+func (s *ContainerCertificationSubmitter) prepareSubmission(ctx context.Context) (*pyxis.CertificationInput, error) {
+	logger := logr.FromContextOrDiscard(ctx)
 	logger.Info("preparing results that will be submitted to Red Hat")
 
 	// get the project info from pyxis
 	certProject, err := s.Pyxis.GetProject(ctx)
 	if err != nil {
-		return fmt.Errorf("could not retrieve project: %w", err)
+		return nil, fmt.Errorf("could not retrieve project: %w", err)
 	}
 
 	// Ensure that a certProject was returned. In theory we would expect pyxis
@@ -79,7 +113,7 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 	// we need to confirm before we proceed in order to prevent a runtime panic
 	// setting the DockerConfigJSON below.
 	if certProject == nil {
-		return fmt.Errorf("no certification project was returned from pyxis")
+		return nil, fmt.Errorf("no certification project was returned from pyxis")
 	}
 
 	logger.V(log.TRC).Info("certification project id", "project", certProject)
@@ -89,7 +123,7 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 	if s.DockerConfig != "" {
 		dockerConfigJSONBytes, err := os.ReadFile(s.DockerConfig)
 		if err != nil {
-			return fmt.Errorf("could not open file for submission: %s: %w",
+			return nil, fmt.Errorf("could not open file for submission: %s: %w",
 				s.DockerConfig,
 				err,
 			)
@@ -120,12 +154,12 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 	// not work here.
 	artifactWriter, ok := artifacts.WriterFromContext(ctx).(*artifacts.FilesystemWriter)
 	if artifactWriter == nil || !ok {
-		return errors.New("the artifact writer was either missing or was not supported, so results cannot be submitted")
+		return nil, errors.New("the artifact writer was either missing or was not supported, so results cannot be submitted")
 	}
 
 	certImage, err := os.Open(path.Join(artifactWriter.Path(), check.DefaultCertImageFilename))
 	if err != nil {
-		return fmt.Errorf("could not open file for submission: %s: %w",
+		return nil, fmt.Errorf("could not open file for submission: %s: %w",
 			check.DefaultCertImageFilename,
 			err,
 		)
@@ -134,7 +168,7 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 
 	preflightResults, err := os.Open(path.Join(artifactWriter.Path(), check.DefaultTestResultsFilename))
 	if err != nil {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"could not open file for submission: %s: %w",
 			check.DefaultTestResultsFilename,
 			err,
@@ -144,7 +178,7 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 
 	logfile, err := os.Open(s.PreflightLogFile)
 	if err != nil {
-		return fmt.Errorf(
+		return nil, fmt.Errorf(
 			"could not open file for submission: %s: %w",
 			s.PreflightLogFile,
 			err,
@@ -169,7 +203,7 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 	if pol != policy.PolicyScratchNonRoot {
 		rpmManifest, err := os.Open(path.Join(artifactWriter.Path(), check.DefaultRPMManifestFilename))
 		if err != nil {
-			return fmt.Errorf(
+			return nil, fmt.Errorf(
 				"could not open file for submission: %s: %w",
 				check.DefaultRPMManifestFilename,
 				err,
@@ -182,20 +216,123 @@ func (s *ContainerCertificationSubmitter) Submit(ctx context.Context) error {
 
 	submission, err := pyxis.NewCertificationInput(ctx, certProject, options...)
 	if err != nil {
-		return fmt.Errorf("unable to finalize data that would be sent to pyxis: %w", err)
+		return nil, fmt.Errorf("unable to finalize data that would be sent to pyxis: %w", err)
+	}
+
+	return submission, nil
+}
+
+// submissionJournal returns the journal in which the progress of the submission is recorded.
+// A new journal is written to the artifacts directory unless s.Resume is set, in which case the
+// existing journal is read, and must have been written for the same project and image.
+func (s *ContainerCertificationSubmitter) submissionJournal(ctx context.Context, submission *pyxis.CertificationInput) (*pyxis.SubmissionJournal, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	// prepareSubmission has already confirmed this is a FilesystemWriter.
+	artifactWriter, _ := artifacts.WriterFromContext(ctx).(*artifacts.FilesystemWriter)
+
+	persist := func(j *pyxis.SubmissionJournal) error {
+		// calling MarshalIndent so the json file written to disk is human-readable when opened
+		journalJSON, err := json.MarshalIndent(j, "", "    ")
+		if err != nil {
+			//coverage:ignore
+			return fmt.Errorf("could not marshal submission journal: %w", err)
+		}
+
+		if _, err := artifactWriter.WriteFile(check.DefaultSubmissionJournal, bytes.NewReader(journalJSON)); err != nil {
+			return fmt.Errorf("could not write submission journal: %w", err)
+		}
+
+		return nil
 	}
 
-	certResults, err := s.Pyxis.SubmitResults(ctx, submission)
+	imageDigest := submission.CertImage.DockerImageDigest
+
+	if !s.Resume {
+		journal := pyxis.NewSubmissionJournal(s.CertificationProjectID, imageDigest, persist)
+		if err := persist(journal); err != nil {
+			return nil, err
+		}
+		return journal, nil
+	}
+
+	journalFile, err := os.Open(path.Join(artifactWriter.Path(), check.DefaultSubmissionJournal))
 	if err != nil {
-		return fmt.Errorf("could not submit to pyxis: %w", err)
+		return nil, fmt.Errorf("could not open submission journal to resume from: %w", err)
 	}
+	defer journalFile.Close()
 
-	logger.Info("Test results have been submitted to Red Hat.")
-	logger.Info("These results will be reviewed by Red Hat for final certification.")
-	logger.Info(fmt.Sprintf("The container's image id is: %s.", certResults.CertImage.ID))
-	logger.Info(fmt.Sprintf("Please check %s to view test results.", BuildTestResultsURL(s.CertificationProjectID, certResults.TestResults.ID)))
-	logger.Info(fmt.Sprintf("Please check %s to view security vulnerabilities.", BuildVulnerabilitiesURL(s.CertificationProjectID, certResults.CertImage.ID)))
-	logger.Info(fmt.Sprintf("Please check %s to monitor the progress.", BuildImagesURL(s.CertificationProjectID)))
+	journal, err := pyxis.ReadSubmissionJournal(journalFile, persist)
+	if err != nil {
+		return nil, err
+	}
+
+	if journal.CertProject != s.CertificationProjectID || journal.DockerImageDigest != imageDigest {
+		return nil, fmt.Errorf("the submission journal was written for image %s in project %s, and cannot be used to resume a submission of image %s in project %s",
+			journal.DockerImageDigest, journal.CertProject, imageDigest, s.CertificationProjectID)
+	}
+
+	logger.Info("resuming submission", "completedSteps", len(journal.Steps))
+
+	return journal, nil
+}
+
+// DryRunSubmitter prepares container results for submission in the same way as
+// ContainerCertificationSubmitter, but writes the requests that would be sent to
+// Pyxis to the artifacts directory instead of sending them. Only read-only requests,
+// such as retrieving the project, are sent to Pyxis.
+type DryRunSubmitter struct {
+	ContainerCertificationSubmitter
+	// Recorder records the requests made by the embedded Pyxis client, and must be
+	// the HTTPClient used by it.
+	Recorder *pyxis.DryRunClient
+}
+
+// NewDryRunSubmitter returns a DryRunSubmitter whose Pyxis client sends read-only
+// requests to host.
+func NewDryRunSubmitter(ctx context.Context, projectID, token, host, dockerconfig, logfile string) *DryRunSubmitter {
+	recorder := pyxis.NewDryRunClient(network.FromContext(ctx).Client())
+	return &DryRunSubmitter{
+		ContainerCertificationSubmitter: ContainerCertificationSubmitter{
+			CertificationProjectID: projectID,
+			Pyxis:                  pyxis.NewPyxisClient(host, token, projectID, recorder),
+			DockerConfig:           dockerconfig,
+			PreflightLogFile:       logfile,
+		},
+		Recorder: recorder,
+	}
+}
+
+var _ ResultSubmitter = &DryRunSubmitter{}
+
+func (s *DryRunSubmitter) Submit(ctx context.Context) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	submission, err := s.prepareSubmission(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Pyxis.SubmitResults(ctx, submission); err != nil {
+		return fmt.Errorf("could not render the requests that would be sent to pyxis: %w", err)
+	}
+
+	// prepareSubmission has already confirmed this is a FilesystemWriter.
+	artifactWriter, _ := artifacts.WriterFromContext(ctx).(*artifacts.FilesystemWriter)
+
+	// calling MarshalIndent so the json file written to disk is human-readable when opened
+	requestsJSON, err := json.MarshalIndent(s.Recorder.Requests(), "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not marshal pyxis requests: %w", err)
+	}
+
+	if _, err := artifactWriter.WriteFile(check.DefaultPyxisDryRunFilename, bytes.NewReader(requestsJSON)); err != nil {
+		return fmt.Errorf("could not write pyxis requests to artifacts dir: %w", err)
+	}
+
+	logger.Info("Dry run: test results have not been submitted to Red Hat.")
+	logger.Info("requests that would be sent to pyxis written to disk", "filename", check.DefaultPyxisDryRunFilename)
 
 	return nil
 }
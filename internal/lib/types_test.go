@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strings"
@@ -19,6 +21,7 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis/pyxistest"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/viper"
 )
 
@@ -453,3 +456,223 @@ var _ = Describe("Container Certification Submitter", func() {
 		})
 	})
 })
+
+var _ = Describe("Dry Run Submitter", func() {
+	Context("When using the DryRunSubmitter", func() {
+		var sbmt *DryRunSubmitter
+		var server *pyxistest.Server
+		var aw *artifacts.FilesystemWriter
+		var testcontext context.Context
+
+		const projectID = "000000000000000000000abc"
+
+		BeforeEach(func() {
+			var err error
+			aw, err = artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+			Expect(err).ToNot(HaveOccurred())
+			testcontext = artifacts.ContextWithWriter(context.Background(), aw)
+
+			server = pyxistest.NewServer(pyxistest.WithProjects(pyxis.CertProject{
+				ID:                  projectID,
+				Name:                "my project",
+				CertificationStatus: "Started",
+				Container:           pyxis.Container{ISVPID: "my-isv-pid"},
+			}))
+			ts := httptest.NewServer(server)
+			DeferCleanup(ts.Close)
+
+			recorder := pyxis.NewDryRunClient(ts.Client())
+			sbmt = &DryRunSubmitter{
+				ContainerCertificationSubmitter: ContainerCertificationSubmitter{
+					CertificationProjectID: projectID,
+					Pyxis:                  pyxis.NewPyxisClient(pyxistest.HostFor(ts.URL), "token", projectID, recorder),
+					PreflightLogFile:       path.Join(aw.Path(), "preflight.log"),
+				},
+				Recorder: recorder,
+			}
+
+			writeSubmissionArtifacts(aw, "sha256:deadb33f")
+		})
+
+		It("should only send read-only requests to pyxis", func() {
+			Expect(sbmt.Submit(testcontext)).To(Succeed())
+
+			requests := server.Requests()
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Route).To(Equal(pyxistest.RouteGetProject))
+			Expect(server.Images()).To(BeEmpty())
+		})
+
+		It("should write the requests that would be sent, in order, to the artifacts dir", func() {
+			Expect(sbmt.Submit(testcontext)).To(Succeed())
+
+			b, err := os.ReadFile(path.Join(aw.Path(), check.DefaultPyxisDryRunFilename))
+			Expect(err).ToNot(HaveOccurred())
+
+			var requests []pyxis.DryRunRequest
+			Expect(json.Unmarshal(b, &requests)).To(Succeed())
+
+			summary := make([]string, 0, len(requests))
+			for _, r := range requests {
+				summary = append(summary, r.Method+" "+strings.TrimPrefix(r.URL, pyxistest.HostFor("")))
+			}
+			Expect(summary).To(HaveLen(6))
+			Expect(summary[0]).To(HaveSuffix("/test-results"))
+			Expect(summary[1]).To(HavePrefix(http.MethodPatch))
+			Expect(summary[1]).To(ContainSubstring("/projects/certification/id/" + projectID))
+			Expect(summary[2]).To(HaveSuffix("/v1/images"))
+			Expect(summary[3]).To(HaveSuffix("/rpm-manifest"))
+			Expect(summary[4]).To(HaveSuffix("/artifacts"))
+			Expect(summary[5]).To(HavePrefix(http.MethodPatch))
+			Expect(summary[5]).To(ContainSubstring("/test-results/id/"))
+
+			var image pyxis.CertImage
+			Expect(json.Unmarshal(requests[2].Body, &image)).To(Succeed())
+			Expect(image.DockerImageDigest).To(Equal("sha256:deadb33f"))
+			Expect(image.ISVPID).To(Equal("my-isv-pid"))
+		})
+
+		Context("and the project cannot be retrieved", func() {
+			It("should throw an error and write nothing", func() {
+				server.InjectError(pyxistest.RouteGetProject, http.StatusNotFound, 0)
+				Expect(sbmt.Submit(testcontext)).ToNot(Succeed())
+
+				exists, err := aw.Exists(check.DefaultPyxisDryRunFilename)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exists).To(BeFalse())
+			})
+		})
+	})
+})
+
+var _ = Describe("Resumable submission", func() {
+	var sbmt *ContainerCertificationSubmitter
+	var server *pyxistest.Server
+	var aw *artifacts.FilesystemWriter
+	var testcontext context.Context
+
+	const projectID = "000000000000000000000abc"
+
+	BeforeEach(func() {
+		var err error
+		aw, err = artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+		Expect(err).ToNot(HaveOccurred())
+		testcontext = artifacts.ContextWithWriter(context.Background(), aw)
+
+		server = pyxistest.NewServer(pyxistest.WithProjects(pyxis.CertProject{
+			ID:        projectID,
+			Name:      "my project",
+			Container: pyxis.Container{ISVPID: "my-isv-pid"},
+		}))
+		ts := httptest.NewServer(server)
+		DeferCleanup(ts.Close)
+
+		sbmt = &ContainerCertificationSubmitter{
+			CertificationProjectID: projectID,
+			Pyxis:                  pyxis.NewPyxisClient(pyxistest.HostFor(ts.URL), "token", projectID, ts.Client()),
+			PreflightLogFile:       path.Join(aw.Path(), "preflight.log"),
+		}
+
+		writeSubmissionArtifacts(aw, "sha256:deadb33f")
+	})
+
+	readJournal := func() *pyxis.SubmissionJournal {
+		f, err := os.Open(path.Join(aw.Path(), check.DefaultSubmissionJournal))
+		Expect(err).ToNot(HaveOccurred())
+		defer f.Close()
+		journal, err := pyxis.ReadSubmissionJournal(f, nil)
+		Expect(err).ToNot(HaveOccurred())
+		return journal
+	}
+
+	Context("when a submission completes", func() {
+		It("should write every step to the journal", func() {
+			Expect(sbmt.Submit(testcontext)).To(Succeed())
+
+			journal := readJournal()
+			Expect(journal.CertProject).To(Equal(projectID))
+			Expect(journal.DockerImageDigest).To(Equal("sha256:deadb33f"))
+			Expect(journal.Steps).To(HaveLen(6))
+		})
+	})
+
+	Context("when a submission fails midway", func() {
+		BeforeEach(func() {
+			server.InjectError(pyxistest.RouteCreateArtifact, http.StatusBadGateway, 1)
+			Expect(sbmt.Submit(testcontext)).ToNot(Succeed())
+		})
+
+		It("should record the steps that completed", func() {
+			journal := readJournal()
+			Expect(journal.Steps).To(HaveLen(4))
+			step, ok := journal.Completed(pyxis.StepCreateImage, "")
+			Expect(ok).To(BeTrue())
+			Expect(server.Images()[0].ID).To(Equal(step.ID))
+		})
+
+		It("should continue from the last successful step when resuming", func() {
+			sbmt.Resume = true
+			Expect(sbmt.Submit(testcontext)).To(Succeed())
+
+			routes := []pyxistest.Route{}
+			for _, r := range server.Requests() {
+				routes = append(routes, r.Route)
+			}
+			Expect(routes).To(Equal([]pyxistest.Route{
+				pyxistest.RouteGetProject,
+				pyxistest.RouteCreateTestResults,
+				pyxistest.RouteUpdateProject,
+				pyxistest.RouteCreateImage,
+				pyxistest.RouteCreateRPMManifest,
+				pyxistest.RouteCreateArtifact,
+				pyxistest.RouteGetProject,
+				pyxistest.RouteCreateArtifact,
+				pyxistest.RouteUpdateTestResults,
+			}))
+			Expect(server.Images()).To(HaveLen(1))
+			Expect(readJournal().Steps).To(HaveLen(6))
+		})
+
+		It("should refuse to resume a submission of a different image", func() {
+			writeSubmissionArtifacts(aw, "sha256:c0ffee")
+			sbmt.Resume = true
+
+			err := sbmt.Submit(testcontext)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot be used to resume"))
+		})
+	})
+
+	Context("when resuming without a journal", func() {
+		It("should throw an error", func() {
+			sbmt.Resume = true
+			err := sbmt.Submit(testcontext)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not open submission journal"))
+		})
+	})
+})
+
+// writeSubmissionArtifacts writes the files read by the submitters into aw, for an
+// image with digest.
+func writeSubmissionArtifacts(aw *artifacts.FilesystemWriter, digest string) {
+	certImageJSONBytes, err := json.Marshal(pyxis.CertImage{
+		DockerImageDigest: digest,
+		Repositories:      []pyxis.Repository{{Registry: "quay.io", Repository: "my/repo"}},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	preflightTestResultsJSONBytes, err := json.Marshal(certification.Results{
+		TestedImage:   "foo",
+		PassedOverall: true,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	rpmManifestJSONBytes, err := json.Marshal(pyxis.RPMManifest{})
+	Expect(err).ToNot(HaveOccurred())
+
+	Expect(aw.WriteFile("preflight.log", strings.NewReader("preflight log")))
+	Expect(aw.WriteFile(check.DefaultCertImageFilename, bytes.NewReader(certImageJSONBytes)))
+	Expect(aw.WriteFile(check.DefaultTestResultsFilename, bytes.NewReader(preflightTestResultsJSONBytes)))
+	Expect(aw.WriteFile(check.DefaultRPMManifestFilename, bytes.NewReader(rpmManifestJSONBytes)))
+}
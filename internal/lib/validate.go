@@ -0,0 +1,169 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+)
+
+// ValidateSubmissionArtifacts confirms that the files in dir that are read by
+// ContainerCertificationSubmitter match the structure written by check container,
+// and that they all describe the same image. The rpm manifest is optional, as it is
+// not written for scratch images. logfile is the preflight logfile that will be
+// submitted alongside the results. The results may only include checks of the container
+// policy, so that the results of plugins, rules and opt-in checks are not submitted.
+//
+// All problems found are returned together.
+func ValidateSubmissionArtifacts(ctx context.Context, dir string, logfile string) error {
+	var errs []error
+
+	var certImage pyxis.CertImage
+	if err := decodeStrict(filepath.Join(dir, check.DefaultCertImageFilename), &certImage); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, validateCertImage(certImage)...)
+	}
+
+	var results formatters.UserResponse
+	if err := decodeStrict(filepath.Join(dir, check.DefaultTestResultsFilename), &results); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, validateTestResults(results, certImage, engine.ContainerPolicy(ctx))...)
+	}
+
+	rpmManifestPath := filepath.Join(dir, check.DefaultRPMManifestFilename)
+	if _, err := os.Stat(rpmManifestPath); err == nil {
+		var rpmManifest pyxis.RPMManifest
+		if err := decodeStrict(rpmManifestPath, &rpmManifest); err != nil {
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, validateRPMManifest(rpmManifest, certImage.DockerImageDigest)...)
+		}
+	}
+
+	if fi, err := os.Stat(logfile); err != nil {
+		errs = append(errs, fmt.Errorf("could not read logfile: %w", err))
+	} else if fi.Size() == 0 {
+		errs = append(errs, fmt.Errorf("logfile %s is empty", logfile))
+	}
+
+	return errors.Join(errs...)
+}
+
+// decodeStrict unmarshals the JSON file at path into v, failing on fields that v does not define.
+func decodeStrict(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", filepath.Base(path), err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%s is not valid: %w", filepath.Base(path), err)
+	}
+
+	return nil
+}
+
+func validateCertImage(certImage pyxis.CertImage) []error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s is not valid: %s", check.DefaultCertImageFilename, fmt.Sprintf(format, args...)))
+	}
+
+	digest := certImage.DockerImageDigest
+	if _, err := cranev1.NewHash(digest); err != nil {
+		invalid("docker_image_digest %q is not a valid digest", digest)
+		return errs
+	}
+
+	if certImage.ImageID != "" && certImage.ImageID != digest {
+		invalid("image_id %s does not match docker_image_digest %s", certImage.ImageID, digest)
+	}
+
+	if certImage.ParsedData != nil && certImage.ParsedData.ImageID != "" && certImage.ParsedData.ImageID != digest {
+		invalid("parsed_data.image_id %s does not match docker_image_digest %s", certImage.ParsedData.ImageID, digest)
+	}
+
+	if len(certImage.Repositories) == 0 {
+		invalid("no repositories are defined")
+	}
+	for i, repo := range certImage.Repositories {
+		if repo.Registry == "" || repo.Repository == "" {
+			invalid("repositories[%d] must include a registry and a repository", i)
+		}
+	}
+
+	return errs
+}
+
+// validateTestResults confirms that results are of the image described by certImage, and only
+// include the checks named in policy. Every other container policy is a subset of the container policy.
+func validateTestResults(results formatters.UserResponse, certImage pyxis.CertImage, policy []string) []error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s is not valid: %s", check.DefaultTestResultsFilename, fmt.Sprintf(format, args...)))
+	}
+
+	if results.Image == "" {
+		invalid("image is not defined")
+	}
+
+	if results.LibraryInfo.Name == "" || results.LibraryInfo.Version == "" {
+		invalid("test_library must include a name and a version")
+	}
+
+	// the tested image is only comparable when it was referenced by digest, which
+	// is either the digest of the image, or of the manifest list containing it.
+	if _, pinned, ok := strings.Cut(results.Image, "@"); ok && certImage.DockerImageDigest != "" {
+		digests := []string{certImage.DockerImageDigest}
+		for _, repo := range certImage.Repositories {
+			digests = append(digests, repo.ManifestListDigest)
+		}
+		if !slices.Contains(digests, pinned) {
+			invalid("image %s was not tested at docker_image_digest %s", results.Image, certImage.DockerImageDigest)
+		}
+	}
+
+	checks := slices.Concat(results.Results.Passed, results.Results.Failed, results.Results.Errors, results.Results.Warnings)
+	for _, c := range checks {
+		if !slices.Contains(policy, c.Name) {
+			invalid("check %s is not part of the container policy", c.Name)
+		}
+	}
+
+	return errs
+}
+
+func validateRPMManifest(rpmManifest pyxis.RPMManifest, digest string) []error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s is not valid: %s", check.DefaultRPMManifestFilename, fmt.Sprintf(format, args...)))
+	}
+
+	if rpmManifest.ImageID != "" && digest != "" && rpmManifest.ImageID != digest {
+		invalid("image_id %s does not match docker_image_digest %s", rpmManifest.ImageID, digest)
+	}
+
+	for i, rpm := range rpmManifest.RPMS {
+		if rpm.Name == "" {
+			invalid("rpms[%d] does not have a name", i)
+		}
+	}
+
+	return errs
+}
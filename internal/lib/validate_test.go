@@ -0,0 +1,161 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+var _ = Describe("Submission Artifact Validation", func() {
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	var (
+		ctx         context.Context
+		dir         string
+		logfile     string
+		certImage   pyxis.CertImage
+		results     formatters.UserResponse
+		rpmManifest pyxis.RPMManifest
+	)
+
+	writeJSON := func(filename string, v any) {
+		b, err := json.Marshal(v)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, filename), b, 0o644)).To(Succeed())
+	}
+
+	writeArtifacts := func() {
+		writeJSON(check.DefaultCertImageFilename, certImage)
+		writeJSON(check.DefaultTestResultsFilename, results)
+		writeJSON(check.DefaultRPMManifestFilename, rpmManifest)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+		logfile = filepath.Join(dir, "preflight.log")
+		Expect(os.WriteFile(logfile, []byte("preflight log"), 0o644)).To(Succeed())
+
+		certImage = pyxis.CertImage{
+			DockerImageDigest: digest,
+			ImageID:           digest,
+			ParsedData:        &pyxis.ParsedData{ImageID: digest},
+			Repositories:      []pyxis.Repository{{Registry: "quay.io", Repository: "my/repo"}},
+		}
+		results = formatters.UserResponse{
+			Image:       "quay.io/my/repo@" + digest,
+			Passed:      true,
+			LibraryInfo: version.VersionContext{Name: "github.com/redhat-openshift-ecosystem/openshift-preflight", Version: "1.0.0"},
+		}
+		rpmManifest = pyxis.RPMManifest{ImageID: digest, RPMS: []pyxis.RPM{{Name: "bash"}}}
+		Expect(json.Unmarshal([]byte(`{"passed": [{"name": "HasLicense"}], "failed": [{"name": "RunAsNonRoot"}]}`), &results.Results)).To(Succeed())
+	})
+
+	Context("when the artifacts are valid", func() {
+		It("should succeed", func() {
+			writeArtifacts()
+			Expect(ValidateSubmissionArtifacts(ctx, dir, logfile)).To(Succeed())
+		})
+
+		It("should succeed without an rpm manifest", func() {
+			writeArtifacts()
+			Expect(os.Remove(filepath.Join(dir, check.DefaultRPMManifestFilename))).To(Succeed())
+			Expect(ValidateSubmissionArtifacts(ctx, dir, logfile)).To(Succeed())
+		})
+
+		It("should accept an image tested by its manifest list digest", func() {
+			listDigest := "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+			certImage.Repositories[0].ManifestListDigest = listDigest
+			results.Image = "quay.io/my/repo@" + listDigest
+			writeArtifacts()
+			Expect(ValidateSubmissionArtifacts(ctx, dir, logfile)).To(Succeed())
+		})
+	})
+
+	Context("when the artifacts are missing", func() {
+		It("should report each missing file", func() {
+			err := ValidateSubmissionArtifacts(ctx, dir, filepath.Join(dir, "missing.log"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("could not read " + check.DefaultCertImageFilename))
+			Expect(err.Error()).To(ContainSubstring("could not read " + check.DefaultTestResultsFilename))
+			Expect(err.Error()).To(ContainSubstring("could not read logfile"))
+		})
+	})
+
+	Context("when a file contains fields that are not part of its schema", func() {
+		It("should fail", func() {
+			writeArtifacts()
+			Expect(os.WriteFile(filepath.Join(dir, check.DefaultCertImageFilename), []byte(`{"unknown": true}`), 0o644)).To(Succeed())
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`unknown field "unknown"`))
+		})
+	})
+
+	Context("when the docker image digest is not valid", func() {
+		It("should fail", func() {
+			certImage.DockerImageDigest = "sha256:deadb33f"
+			writeArtifacts()
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not a valid digest"))
+		})
+	})
+
+	Context("when the files describe different images", func() {
+		It("should fail when the cert image ids disagree", func() {
+			certImage.ParsedData.ImageID = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+			writeArtifacts()
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("parsed_data.image_id"))
+		})
+
+		It("should fail when the results were for another digest", func() {
+			results.Image = "quay.io/my/repo@sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+			writeArtifacts()
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("was not tested at docker_image_digest"))
+		})
+
+		It("should fail when the rpm manifest is for another image", func() {
+			rpmManifest.ImageID = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+			writeArtifacts()
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(check.DefaultRPMManifestFilename + " is not valid"))
+		})
+	})
+
+	Context("when the results include checks that are not part of the container policy", func() {
+		It("should report each of them", func() {
+			Expect(json.Unmarshal([]byte(`{"passed": [{"name": "HasLicense"}, {"name": "MyPlugin"}], "warning": [{"name": "HasNoSecrets"}]}`), &results.Results)).To(Succeed())
+			writeArtifacts()
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("check MyPlugin is not part of the container policy"))
+			Expect(err.Error()).To(ContainSubstring("check HasNoSecrets is not part of the container policy"))
+			Expect(err.Error()).ToNot(ContainSubstring("check HasLicense"))
+		})
+	})
+
+	Context("when the logfile is empty", func() {
+		It("should fail", func() {
+			writeArtifacts()
+			Expect(os.WriteFile(logfile, nil, 0o644)).To(Succeed())
+			err := ValidateSubmissionArtifacts(ctx, dir, logfile)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is empty"))
+		})
+	})
+})
@@ -0,0 +1,113 @@
+// Package network defines the policy applied to the HTTP traffic preflight sends to container
+// registries and Pyxis: how many attempts are made, the backoff between them, per-request
+// timeouts, how many layers are pulled at once, and how many requests are made per second to
+// each host. Retry-After is honored when a host responds with 429 Too Many Requests or 503
+// Service Unavailable.
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultAttempts is the number of times a request, or a layer pull, is attempted.
+	DefaultAttempts = 3
+	// DefaultBackoff is the delay before the first retry. It doubles on each subsequent retry.
+	DefaultBackoff = 2 * time.Second
+	// DefaultTimeout bounds each API request, and how long to wait for the response headers
+	// of each registry request.
+	DefaultTimeout = 60 * time.Second
+	// DefaultMaxConcurrentLayerPulls mirrors crane's own default job concurrency.
+	DefaultMaxConcurrentLayerPulls = 4
+	// DefaultMaxRetryAfter is the longest Retry-After that is waited for.
+	DefaultMaxRetryAfter = 2 * time.Minute
+)
+
+// Policy is the network policy. The zero value is not valid; start from DefaultPolicy.
+type Policy struct {
+	// Attempts is the number of times a request, or a layer pull, is attempted.
+	Attempts int
+	// Backoff is the delay before the first retry. It doubles on each subsequent retry.
+	Backoff time.Duration
+	// Timeout bounds each API request, and how long to wait for the response headers of each
+	// registry request. Registry response bodies, e.g. layers, are not bounded.
+	Timeout time.Duration
+	// MaxConcurrentLayerPulls is the number of image layers pulled at the same time.
+	MaxConcurrentLayerPulls int
+	// RequestsPerSecond limits the requests made to each host. Zero is unlimited.
+	RequestsPerSecond float64
+	// MaxRetryAfter is the longest Retry-After that is waited for. Responses asking to wait
+	// longer are returned without retrying.
+	MaxRetryAfter time.Duration
+
+	// limiters are shared by the copies of the policy, and so by every Transport made from
+	// them, so that RequestsPerSecond limits the requests to each host rather than of each client.
+	limiters *limiters
+}
+
+// DefaultPolicy returns the Policy used when none is configured.
+func DefaultPolicy() Policy {
+	return Policy{
+		Attempts:                DefaultAttempts,
+		Backoff:                 DefaultBackoff,
+		Timeout:                 DefaultTimeout,
+		MaxConcurrentLayerPulls: DefaultMaxConcurrentLayerPulls,
+		MaxRetryAfter:           DefaultMaxRetryAfter,
+		limiters:                &limiters{},
+	}
+}
+
+// Validate returns an error describing every invalid value in p.
+func (p Policy) Validate() error {
+	var errs []error
+	if p.Attempts < 1 {
+		errs = append(errs, fmt.Errorf("attempts must be at least 1, got %d", p.Attempts))
+	}
+	if p.Backoff < 0 {
+		errs = append(errs, fmt.Errorf("backoff must not be negative, got %s", p.Backoff))
+	}
+	if p.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("timeout must be positive, got %s", p.Timeout))
+	}
+	if p.MaxConcurrentLayerPulls < 1 {
+		errs = append(errs, fmt.Errorf("max concurrent layer pulls must be at least 1, got %d", p.MaxConcurrentLayerPulls))
+	}
+	if p.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("requests per second must not be negative, got %g", p.RequestsPerSecond))
+	}
+	if p.MaxRetryAfter < 0 {
+		errs = append(errs, fmt.Errorf("max retry after must not be negative, got %s", p.MaxRetryAfter))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Delay returns the backoff before the given retry, counting from 1.
+func (p Policy) Delay(retry int) time.Duration {
+	return p.Backoff << (retry - 1)
+}
+
+// contextKey is a key used to store/retrieve a Policy in/from context.Context.
+type contextKey string
+
+const policyContextKey contextKey = "NetworkPolicy"
+
+// ContextWithPolicy adds the Policy p to the context ctx. The transports of every policy
+// retrieved from ctx share the same rate limits.
+func ContextWithPolicy(ctx context.Context, p Policy) context.Context {
+	if p.limiters == nil {
+		p.limiters = &limiters{}
+	}
+	return context.WithValue(ctx, policyContextKey, p)
+}
+
+// FromContext returns the Policy from the context, or DefaultPolicy if there is none.
+func FromContext(ctx context.Context) Policy {
+	if p, ok := ctx.Value(policyContextKey).(Policy); ok {
+		return p
+	}
+	return DefaultPolicy()
+}
@@ -0,0 +1,40 @@
+package network
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Policy", func() {
+	It("should be valid by default", func() {
+		Expect(DefaultPolicy().Validate()).To(Succeed())
+	})
+
+	It("should report every invalid value", func() {
+		p := Policy{Backoff: -time.Second, RequestsPerSecond: -1, MaxRetryAfter: -time.Second}
+		err := p.Validate()
+		Expect(err).To(HaveOccurred())
+		for _, field := range []string{"attempts", "backoff", "timeout", "max concurrent layer pulls", "requests per second", "max retry after"} {
+			Expect(err.Error()).To(ContainSubstring(field))
+		}
+	})
+
+	It("should double the backoff on each retry", func() {
+		p := DefaultPolicy()
+		p.Backoff = time.Second
+		Expect(p.Delay(1)).To(Equal(time.Second))
+		Expect(p.Delay(2)).To(Equal(2 * time.Second))
+		Expect(p.Delay(3)).To(Equal(4 * time.Second))
+	})
+
+	It("should be stored in, and retrieved from, a context", func() {
+		Expect(FromContext(context.TODO())).To(Equal(DefaultPolicy()))
+
+		p := DefaultPolicy()
+		p.Attempts = 7
+		Expect(FromContext(ContextWithPolicy(context.TODO(), p))).To(Equal(p))
+	})
+})
@@ -0,0 +1,158 @@
+package network
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/time/rate"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
+)
+
+// maxDrain is the most of a response body read so that its connection can be reused.
+const maxDrain = 4096
+
+// HTTPTransport returns a clone of base that waits at most Timeout for each response's headers.
+func (p Policy) HTTPTransport(base *http.Transport) *http.Transport {
+	t := base.Clone()
+	t.ResponseHeaderTimeout = p.Timeout
+	return t
+}
+
+// Transport returns an http.RoundTripper that limits the requests made to each host to
+// RequestsPerSecond, across every Transport of p, and retries requests that receive a 429 or
+// 503 response, waiting for their Retry-After, or the backoff if there is none. Other failures
+// are left to the caller to retry, e.g. crane's own retries, so that requests are not retried
+// twice.
+func (p Policy) Transport(base http.RoundTripper) http.RoundTripper {
+	if p.limiters == nil {
+		p.limiters = &limiters{}
+	}
+	return &transport{policy: p, base: base}
+}
+
+// Client returns an http.Client for API requests, e.g. to Pyxis, bounded by Timeout, that
+// uses Transport. Each request is traced.
+func (p Policy) Client() *http.Client {
+	return &http.Client{
+		Timeout:   p.Timeout,
+		Transport: otelhttp.NewTransport(p.Transport(p.HTTPTransport(http.DefaultTransport.(*http.Transport)))),
+	}
+}
+
+type transport struct {
+	policy Policy
+	base   http.RoundTripper
+}
+
+// limiters are the rate limiters of each host requests are made to.
+type limiters struct {
+	mu     sync.Mutex
+	byHost map[string]*rate.Limiter
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := t.policy.limiters.get(req.URL.Host, t.policy.RequestsPerSecond)
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.policy.Attempts || !throttled(resp.StatusCode) {
+			return resp, err
+		}
+
+		// the request cannot be sent again if its body cannot be replayed.
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		delay, ok := t.policy.retryAfter(resp, attempt)
+		if !ok {
+			return resp, nil
+		}
+
+		_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+		resp.Body.Close()
+		telemetry.RecordRetry(req.Context(), "http")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				//coverage:ignore
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// get returns the rate limiter for host, limited to perSecond, or nil when requests are unlimited.
+func (l *limiters) get(host string, perSecond float64) *rate.Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limiter, ok := l.byHost[host]; ok {
+		return limiter
+	}
+
+	if l.byHost == nil {
+		l.byHost = map[string]*rate.Limiter{}
+	}
+	burst := int(math.Max(1, math.Ceil(perSecond)))
+	limiter := rate.NewLimiter(rate.Limit(perSecond), burst)
+	l.byHost[host] = limiter
+
+	return limiter
+}
+
+// retryAfter returns how long to wait before retrying after resp, and false if the
+// response's Retry-After is longer than MaxRetryAfter.
+func (p Policy) retryAfter(resp *http.Response, attempt int) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return p.Delay(attempt), true
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(header); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		delay = time.Until(date)
+	} else {
+		return p.Delay(attempt), true
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay, delay <= p.MaxRetryAfter
+}
+
+// throttled reports whether the status code asks the client to slow down.
+func throttled(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable
+}
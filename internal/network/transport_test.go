@@ -0,0 +1,158 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		policy   Policy
+		requests atomic.Int32
+		handler  http.HandlerFunc
+		server   *httptest.Server
+	)
+
+	BeforeEach(func() {
+		policy = DefaultPolicy()
+		policy.Backoff = time.Millisecond
+		requests.Store(0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			handler(w, r)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	throttleOnce := func(retryAfter string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if requests.Load() == 1 {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			_, _ = w.Write(body)
+		}
+	}
+
+	It("should retry a throttled request after its Retry-After", func() {
+		handler = throttleOnce("0")
+		resp, err := policy.Client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(requests.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should retry a throttled request after the backoff when there is no Retry-After", func() {
+		handler = throttleOnce("")
+		resp, err := policy.Client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("should send the body again when retrying", func() {
+		handler = throttleOnce("0")
+		resp, err := policy.Client().Post(server.URL, "text/plain", strings.NewReader("payload"))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(body)).To(Equal("payload"))
+	})
+
+	It("should return the response when Retry-After exceeds the maximum", func() {
+		handler = throttleOnce("3600")
+		resp, err := policy.Client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusTooManyRequests))
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should stop after the configured attempts", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		resp, err := policy.Client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(requests.Load()).To(BeEquivalentTo(policy.Attempts))
+	})
+
+	It("should not retry other failures", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		resp, err := policy.Client().Get(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+		Expect(requests.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should limit the requests per second to each host", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {}
+		policy.RequestsPerSecond = 10
+
+		client := policy.Client()
+		start := time.Now()
+		for range 12 {
+			resp, err := client.Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		// the first 10 requests are the burst, the remaining 2 wait for a token each.
+		Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+	})
+
+	It("should share the limit between the clients of a policy", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {}
+		policy.RequestsPerSecond = 10
+
+		clients := []*http.Client{policy.Client(), policy.Client()}
+		start := time.Now()
+		for i := range 12 {
+			resp, err := clients[i%2].Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+	})
+
+	It("should share the limit between the policies retrieved from a context", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {}
+		policy.RequestsPerSecond = 10
+		ctx := ContextWithPolicy(context.Background(), policy)
+
+		start := time.Now()
+		for range 12 {
+			resp, err := FromContext(ctx).Client().Get(server.URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+		}
+		Expect(time.Since(start)).To(BeNumerically(">=", 150*time.Millisecond))
+	})
+
+	It("should bound API requests by the timeout", func() {
+		handler = func(w http.ResponseWriter, _ *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}
+		policy.Timeout = 20 * time.Millisecond
+		_, err := policy.Client().Get(server.URL)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"slices"
 
 	"github.com/go-logr/logr"
 	imagestreamv1 "github.com/openshift/api/image/v1"
@@ -16,13 +17,26 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 )
 
+// CatalogServerPort is the port a catalog server serves its catalog on.
+const CatalogServerPort = 50051
+
+const (
+	catalogServerLabel      = "preflight.openshift.io/catalog-server"
+	catalogServerConfigsDir = "/configs"
+	catalogServerCacheDir   = "/tmp/cache"
+)
+
 type openshiftClient struct {
 	Client       crclient.Client
 	K8sInterface kubernetes.Interface
@@ -275,6 +289,12 @@ func (oe openshiftClient) CreateCatalogSource(ctx context.Context, data CatalogS
 			},
 		},
 	}
+	if data.Address != "" {
+		// OLM connects to the registry at the address, rather than running one.
+		catalogSource.Spec.Image = ""
+		catalogSource.Spec.Address = data.Address
+		catalogSource.Spec.GrpcPodConfig = nil
+	}
 	err := oe.Client.Create(ctx, catalogSource)
 	if apierrors.IsAlreadyExists(err) {
 		return catalogSource, fmt.Errorf("could not create catalogsource: %s/%s: %w: %v", namespace, data.Name, ErrAlreadyExists, err)
@@ -324,6 +344,147 @@ func (oe *openshiftClient) GetCatalogSource(ctx context.Context, name string, na
 	return catalogSource, nil
 }
 
+// CreateConfigMap can return an ErrAlreadyExists
+func (oe *openshiftClient) CreateConfigMap(ctx context.Context, data ConfigMapData, namespace string) (*corev1.ConfigMap, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("creating ConfigMap", "namespace", namespace, "name", data.Name)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: namespace,
+		},
+		Data: data.Data,
+	}
+	err := oe.Client.Create(ctx, configMap, &crclient.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return configMap, fmt.Errorf("could not create configmap: %s/%s: %w: %v", namespace, data.Name, ErrAlreadyExists, err)
+	}
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not create configmap: %s/%s: %v", namespace, data.Name, err)
+	}
+	return configMap, nil
+}
+
+func (oe *openshiftClient) DeleteConfigMap(ctx context.Context, name string, namespace string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("deleting ConfigMap", "namespace", namespace, "name", name)
+	configMap := corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	if err := oe.Client.Delete(ctx, &configMap, &crclient.DeleteOptions{}); err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not delete configmap: %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// CreateCatalogServer creates a pod serving the catalog, and a service in front of it, and
+// returns the service. It can return an ErrAlreadyExists.
+func (oe *openshiftClient) CreateCatalogServer(ctx context.Context, data CatalogServerData, namespace string) (*corev1.Service, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("creating catalog server", "namespace", namespace, "name", data.Name, "image", data.Image)
+	labels := map[string]string{catalogServerLabel: data.Name}
+
+	items := make([]corev1.KeyToPath, 0, len(data.Files))
+	for _, key := range slices.Sorted(maps.Keys(data.Files)) {
+		items = append(items, corev1.KeyToPath{Key: key, Path: data.Files[key]})
+	}
+	pullSecrets := make([]corev1.LocalObjectReference, 0, len(data.Secrets))
+	for _, secret := range data.Secrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: pullSecrets,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+			Containers: []corev1.Container{{
+				Name:    "registry-server",
+				Image:   data.Image,
+				Command: []string{"/bin/opm"},
+				Args:    []string{"serve", catalogServerConfigsDir, "--cache-dir=" + catalogServerCacheDir},
+				Ports:   []corev1.ContainerPort{{Name: "grpc", ContainerPort: CatalogServerPort}},
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{GRPC: &corev1.GRPCAction{Port: CatalogServerPort}},
+				},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: ptr.To(false),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{Name: "configs", MountPath: catalogServerConfigsDir, ReadOnly: true},
+					{Name: "cache", MountPath: catalogServerCacheDir},
+				},
+			}},
+			Volumes: []corev1.Volume{
+				{Name: "configs", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: data.ConfigMap},
+					Items:                items,
+				}}},
+				{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+		},
+	}
+	err := oe.Client.Create(ctx, pod, &crclient.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not create catalog server pod: %s/%s: %v", namespace, data.Name, err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      data.Name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Name: "grpc", Port: CatalogServerPort, TargetPort: intstr.FromInt32(CatalogServerPort)}},
+		},
+	}
+	err = oe.Client.Create(ctx, service, &crclient.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return service, fmt.Errorf("could not create catalog server service: %s/%s: %w: %v", namespace, data.Name, ErrAlreadyExists, err)
+	}
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not create catalog server service: %s/%s: %v", namespace, data.Name, err)
+	}
+	return service, nil
+}
+
+// DeleteCatalogServer deletes the pod and service created by CreateCatalogServer.
+func (oe *openshiftClient) DeleteCatalogServer(ctx context.Context, name string, namespace string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("deleting catalog server", "namespace", namespace, "name", name)
+	meta := metav1.ObjectMeta{Name: name, Namespace: namespace}
+	if err := oe.Client.Delete(ctx, &corev1.Service{ObjectMeta: meta}, &crclient.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		//coverage:ignore
+		return fmt.Errorf("could not delete catalog server service: %s/%s: %v", namespace, name, err)
+	}
+	if err := oe.Client.Delete(ctx, &corev1.Pod{ObjectMeta: meta}, &crclient.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		//coverage:ignore
+		return fmt.Errorf("could not delete catalog server pod: %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
 // CreateSubscription can return an ErrAlreadyExists
 func (oe openshiftClient) CreateSubscription(ctx context.Context, data SubscriptionData, namespace string) (*operatorsv1alpha1.Subscription, error) {
 	logger := logr.FromContextOrDiscard(ctx)
@@ -421,7 +582,10 @@ func (oe *openshiftClient) GetImages(ctx context.Context) (map[string]struct{},
 
 	imageList := make(map[string]struct{})
 	for _, pod := range pods.Items {
-		for _, container := range pod.Spec.Containers {
+		for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+			imageList[container.Image] = struct{}{}
+		}
+		for _, container := range pod.Spec.EphemeralContainers {
 			imageList[container.Image] = struct{}{}
 		}
 	}
@@ -664,3 +828,62 @@ func (oe *openshiftClient) GetPodLogs(ctx context.Context, name string, namespac
 
 	return results, nil
 }
+
+// CreateCustomResource creates obj, a custom resource, in namespace, which is empty for a
+// cluster-scoped resource. It can return an ErrAlreadyExists
+func (oe *openshiftClient) CreateCustomResource(ctx context.Context, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("creating custom resource", "kind", obj.GetKind(), "namespace", namespace, "name", obj.GetName())
+	obj = obj.DeepCopy()
+	obj.SetNamespace(namespace)
+	err := oe.Client.Create(ctx, obj, &crclient.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		return obj, fmt.Errorf("could not create %s: %s/%s: %w: %v", obj.GetKind(), namespace, obj.GetName(), ErrAlreadyExists, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create %s: %s/%s: %v", obj.GetKind(), namespace, obj.GetName(), err)
+	}
+	return obj, nil
+}
+
+// GetCustomResource can return an ErrNotFound
+func (oe *openshiftClient) GetCustomResource(ctx context.Context, gvk schema.GroupVersionKind, name string, namespace string) (*unstructured.Unstructured, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("fetching custom resource", "kind", gvk.Kind, "namespace", namespace, "name", name)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := oe.Client.Get(ctx, crclient.ObjectKey{
+		Name:      name,
+		Namespace: namespace,
+	}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("could not retrieve %s: %s/%s: %w: %v", gvk.Kind, namespace, name, ErrNotFound, err)
+	}
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not retrieve %s: %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+	return obj, nil
+}
+
+// DeleteCustomResource can return an ErrNotFound
+func (oe *openshiftClient) DeleteCustomResource(ctx context.Context, gvk schema.GroupVersionKind, name string, namespace string) error {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.TRC).Info("deleting custom resource", "kind", gvk.Kind, "namespace", namespace, "name", name)
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	err := oe.Client.Delete(ctx, obj, &crclient.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return fmt.Errorf("could not delete %s: %s/%s: %w: %v", gvk.Kind, namespace, name, ErrNotFound, err)
+	}
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not delete %s: %s/%s: %v", gvk.Kind, namespace, name, err)
+	}
+	return nil
+}
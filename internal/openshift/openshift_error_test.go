@@ -562,6 +562,39 @@ var _ = Describe("OpenShift Client Error Paths", func() {
 		})
 	})
 
+	Context("GetImages with init and ephemeral containers", func() {
+		It("should include the images of every container of a pod", func() {
+			podList := &corev1.PodList{
+				Items: []corev1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "pod1",
+							Namespace: "testns",
+						},
+						Spec: corev1.PodSpec{
+							InitContainers: []corev1.Container{{Name: "init", Image: "init-image:latest"}},
+							Containers:     []corev1.Container{{Name: "main", Image: "main-image:latest"}},
+							EphemeralContainers: []corev1.EphemeralContainer{
+								{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Image: "debug-image:latest"}},
+							},
+						},
+					},
+				},
+			}
+			cl := fakecr.NewClientBuilder().
+				WithScheme(buildScheme()).
+				WithLists(podList).
+				Build()
+			oc := NewClient(cl, fakecg.NewClientset())
+
+			images, err := oc.GetImages(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(HaveKey("init-image:latest"))
+			Expect(images).To(HaveKey("main-image:latest"))
+			Expect(images).To(HaveKey("debug-image:latest"))
+		})
+	})
+
 	// Verify that errFake is not accidentally matching sentinel errors
 	Context("error sentinel verification", func() {
 		It("errFake should not match ErrNotFound or ErrAlreadyExists", func() {
@@ -10,7 +10,9 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	fakecg "k8s.io/client-go/kubernetes/fake"
 	fakecr "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -494,4 +496,40 @@ var _ = Describe("OpenShift Engine", func() {
 			}
 		})
 	})
+	Context("CustomResources", func() {
+		It("should exercise CustomResources", func() {
+			gvk := schema.GroupVersionKind{Group: "cache.example.com", Version: "v1alpha1", Kind: "Memcached"}
+			memcached := &unstructured.Unstructured{}
+			memcached.SetGroupVersionKind(gvk)
+			memcached.SetName("memcached-sample")
+			Expect(unstructured.SetNestedField(memcached.Object, int64(3), "spec", "size")).To(Succeed())
+
+			By("creating a CustomResource", func() {
+				obj, err := oc.CreateCustomResource(context.TODO(), memcached, "testns")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(obj.GetNamespace()).To(Equal("testns"))
+				Expect(memcached.GetNamespace()).To(BeEmpty())
+			})
+			By("creating it again should error", func() {
+				obj, err := oc.CreateCustomResource(context.TODO(), memcached, "testns")
+				Expect(err).To(MatchError(ErrAlreadyExists))
+				Expect(obj).ToNot(BeNil())
+			})
+			By("getting that CustomResource", func() {
+				obj, err := oc.GetCustomResource(context.TODO(), gvk, "memcached-sample", "testns")
+				Expect(err).ToNot(HaveOccurred())
+				size, _, _ := unstructured.NestedInt64(obj.Object, "spec", "size")
+				Expect(size).To(BeEquivalentTo(3))
+			})
+			By("deleting that CustomResource", func() {
+				Expect(oc.DeleteCustomResource(context.TODO(), gvk, "memcached-sample", "testns")).To(Succeed())
+			})
+			By("trying to get and delete it again, but failing", func() {
+				obj, err := oc.GetCustomResource(context.TODO(), gvk, "memcached-sample", "testns")
+				Expect(err).To(MatchError(ErrNotFound))
+				Expect(obj).To(BeNil())
+				Expect(oc.DeleteCustomResource(context.TODO(), gvk, "memcached-sample", "testns")).To(MatchError(ErrNotFound))
+			})
+		})
+	})
 })
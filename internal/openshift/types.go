@@ -9,6 +9,8 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 type SubscriptionData struct {
@@ -20,11 +22,29 @@ type SubscriptionData struct {
 }
 
 type CatalogSourceData struct {
-	Name    string
-	Image   string
+	Name  string
+	Image string
+	// Address is the address of a registry serving the catalog, used instead of Image.
+	Address string
 	Secrets []string
 }
 
+type ConfigMapData struct {
+	Name string
+	Data map[string]string
+}
+
+// CatalogServerData describes a registry, running the opm serve command of Image, that serves
+// the file-based catalog in ConfigMap. Files maps each key of ConfigMap to the path of its file
+// in the catalog.
+type CatalogServerData struct {
+	Name      string
+	Image     string
+	ConfigMap string
+	Files     map[string]string
+	Secrets   []string
+}
+
 type OperatorGroupData struct {
 	Name             string
 	TargetNamespaces []string
@@ -50,6 +70,10 @@ type Client interface {
 	CreateCatalogSource(ctx context.Context, data CatalogSourceData, namespace string) (*operatorsv1alpha1.CatalogSource, error)
 	DeleteCatalogSource(ctx context.Context, name string, namespace string) error
 	GetCatalogSource(ctx context.Context, name string, namespace string) (*operatorsv1alpha1.CatalogSource, error)
+	CreateConfigMap(ctx context.Context, data ConfigMapData, namespace string) (*corev1.ConfigMap, error)
+	DeleteConfigMap(ctx context.Context, name string, namespace string) error
+	CreateCatalogServer(ctx context.Context, data CatalogServerData, namespace string) (*corev1.Service, error)
+	DeleteCatalogServer(ctx context.Context, name string, namespace string) error
 	CreateSubscription(ctx context.Context, data SubscriptionData, namespace string) (*operatorsv1alpha1.Subscription, error)
 	DeleteSubscription(ctx context.Context, name string, namespace string) error
 	GetSubscription(ctx context.Context, name string, namespace string) (*operatorsv1alpha1.Subscription, error)
@@ -62,4 +86,7 @@ type Client interface {
 	GetDeploymentPods(ctx context.Context, name string, namespace string) ([]corev1.Pod, error)
 	GetPod(ctx context.Context, name string, namespace string) (*corev1.Pod, error)
 	GetPodLogs(ctx context.Context, name string, namespace string) (map[string]*bytes.Buffer, error)
+	CreateCustomResource(ctx context.Context, obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error)
+	GetCustomResource(ctx context.Context, gvk schema.GroupVersionKind, name string, namespace string) (*unstructured.Unstructured, error)
+	DeleteCustomResource(ctx context.Context, gvk schema.GroupVersionKind, name string, namespace string) error
 }
@@ -4,13 +4,14 @@ import (
 	"context"
 	"crypto/tls"
 	"net/http"
-	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	cranev1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/authn"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 )
 
 type CraneConfig interface {
@@ -39,9 +40,11 @@ func GenerateCraneOptions(ctx context.Context, craneConfig CraneConfig) []crane.
 			OS:           "linux",
 			Architecture: craneConfig.CranePlatform(),
 		}),
-		RetryOnceAfter(5 * time.Second),
 	}
 
+	policy := network.FromContext(ctx)
+	rt := policy.HTTPTransport(remote.DefaultTransport.(*http.Transport))
+
 	if craneConfig.CraneInsecure() {
 		// Adding WithTransport opt is a workaround to allow for access to HTTPS
 		// container registries with self-signed or non-trusted certificates.
@@ -49,25 +52,46 @@ func GenerateCraneOptions(ctx context.Context, craneConfig CraneConfig) []crane.
 		// See https://github.com/google/go-containerregistry/issues/1553 for more context. If this issue
 		// is resolved, then this workaround can likely be removed or adjusted to use new features in the
 		// go-containerregistry project.
-		rt := remote.DefaultTransport.(*http.Transport).Clone()
 		rt.TLSClientConfig = &tls.Config{
 			InsecureSkipVerify: true, //nolint: gosec
 		}
 
-		options = append(options, crane.Insecure, crane.WithTransport(rt))
+		options = append(options, crane.Insecure, WithNetworkPolicy(policy, rt))
+	} else if registriesConfig := registries.FromContext(ctx); registriesConfig != nil {
+		// per-registry certificates and insecure settings, e.g. for a mirror with a private CA.
+		options = append(options, WithNetworkPolicy(policy, registriesConfig.Transport(rt)))
+	} else {
+		options = append(options, WithNetworkPolicy(policy, rt))
 	}
 
 	return options
 }
 
-// RetryOnceAfter is a crane option that retries once after t duration.
-func RetryOnceAfter(t time.Duration) crane.Option {
+// WithNetworkPolicy is a crane option that sends requests through base, applying the
+// policy's rate limit and handling of throttled responses, and retries other failed
+// requests using the policy's attempts and backoff.
+func WithNetworkPolicy(policy network.Policy, base http.RoundTripper) crane.Option {
 	return func(o *crane.Options) {
-		o.Remote = append(o.Remote, remote.WithRetryBackoff(remote.Backoff{
-			Duration: t,
-			Factor:   1.0,
+		o.Remote = append(o.Remote, RemoteOptions(policy, base)...)
+	}
+}
+
+// RemoteOptions returns the remote options that apply policy to requests sent through base.
+// 503 Service Unavailable is left to the policy's transport, which honors Retry-After.
+func RemoteOptions(policy network.Policy, base http.RoundTripper) []remote.Option {
+	return []remote.Option{
+		remote.WithTransport(policy.Transport(base)),
+		remote.WithRetryBackoff(remote.Backoff{
+			Duration: policy.Backoff,
+			Factor:   2.0,
 			Jitter:   0.1,
-			Steps:    2,
-		}))
+			Steps:    policy.Attempts,
+		}),
+		remote.WithRetryStatusCodes(
+			http.StatusRequestTimeout,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusGatewayTimeout,
+		),
 	}
 }
@@ -2,11 +2,16 @@ package option
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 )
 
 type fakeCraneConfig struct {
@@ -52,17 +57,40 @@ var _ = Describe("Option", func() {
 		})
 	})
 
-	Describe("RetryOnceAfter", func() {
+	Describe("WithNetworkPolicy", func() {
 		It("should return a non-nil crane.Option", func() {
-			opt := RetryOnceAfter(5 * time.Second)
+			opt := WithNetworkPolicy(network.DefaultPolicy(), http.DefaultTransport)
 			Expect(opt).ToNot(BeNil())
 		})
 
 		It("should apply remote options to crane.Options when called", func() {
-			opt := RetryOnceAfter(5 * time.Second)
+			opt := WithNetworkPolicy(network.DefaultPolicy(), http.DefaultTransport)
 			o := &crane.Options{}
 			opt(o)
-			Expect(o.Remote).ToNot(BeEmpty())
+			Expect(o.Remote).To(HaveLen(3))
+		})
+	})
+
+	Describe("GenerateCraneOptions with a network policy", func() {
+		It("should retry throttled requests using the policy from the context", func() {
+			requests := 0
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			DeferCleanup(s.Close)
+
+			policy := network.DefaultPolicy()
+			policy.Attempts = 2
+			policy.Backoff = time.Millisecond
+			ctx := network.ContextWithPolicy(context.Background(), policy)
+
+			u, err := url.Parse(s.URL)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = crane.Digest(u.Host+"/test/image:latest", GenerateCraneOptions(ctx, &fakeCraneConfig{platform: "amd64"})...)
+			Expect(err).To(HaveOccurred())
+			Expect(requests).To(Equal(2))
 		})
 	})
 })
@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// maxStderr is how much of a plugin's stderr is included in the error when it fails.
+const maxStderr = 1024
+
+// execRunner runs a plugin that is an executable.
+type execRunner struct {
+	path string
+}
+
+func (r *execRunner) run(ctx context.Context, args []string, stdin []byte, _ string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, r.path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, withStderr(err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (r *execRunner) fsPath(imageFSPath string) string {
+	return imageFSPath
+}
+
+// withStderr adds the end of stderr, if any, to err.
+func withStderr(err error, stderr string) error {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return err
+	}
+	if len(stderr) > maxStderr {
+		stderr = "..." + stderr[len(stderr)-maxStderr:]
+	}
+	return fmt.Errorf("%w: %s", err, stderr)
+}
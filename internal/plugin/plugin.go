@@ -0,0 +1,265 @@
+// Package plugin runs checks supplied outside of preflight, as executables or WebAssembly
+// modules, alongside the checks of a policy.
+//
+// A plugin is invoked with its configured arguments followed by a command. For the describe
+// command, it writes a Description of its check to stdout as JSON. For the validate command,
+// it reads an Input describing the image under test from stdin as JSON, and writes an Output
+// to stdout as JSON.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// DefaultTimeout bounds each invocation of a plugin that has no timeout configured.
+const DefaultTimeout = 5 * time.Minute
+
+// The commands a plugin is invoked with.
+const (
+	CommandDescribe = "describe"
+	CommandValidate = "validate"
+)
+
+// The results a plugin can return.
+const (
+	ResultPass  = "pass"
+	ResultFail  = "fail"
+	ResultError = "error"
+)
+
+// Config registers a plugin.
+type Config struct {
+	// Path is the executable, or WebAssembly module ending in .wasm, implementing the check.
+	Path string `mapstructure:"path" json:"path"`
+	// Args are passed to the plugin before the command.
+	Args []string `mapstructure:"args" json:"args,omitempty"`
+	// Timeout bounds each invocation of the plugin. DefaultTimeout is used if it is not set.
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout,omitempty"`
+}
+
+// Description is what a plugin declares about its check.
+type Description struct {
+	Name                 string         `json:"name"`
+	Level                string         `json:"level,omitempty"`
+	Description          string         `json:"description"`
+	KnowledgeBaseURL     string         `json:"knowledge_base_url,omitempty"`
+	CheckURL             string         `json:"check_url,omitempty"`
+	Help                 check.HelpText `json:"help"`
+	RequiredFilePatterns []string       `json:"required_file_patterns,omitempty"`
+}
+
+// Input describes the image under test to a plugin.
+type Input struct {
+	// ImageFSPath is the directory the files matching the plugin's required file patterns
+	// were extracted to. It is /image for WebAssembly modules.
+	ImageFSPath string `json:"image_fs_path"`
+	Image       Image  `json:"image"`
+}
+
+// Image describes the image under test.
+type Image struct {
+	URI                string            `json:"uri"`
+	Registry           string            `json:"registry"`
+	Repository         string            `json:"repository"`
+	TagOrSha           string            `json:"tag_or_sha"`
+	ManifestListDigest string            `json:"manifest_list_digest,omitempty"`
+	Digest             string            `json:"digest,omitempty"`
+	Architecture       string            `json:"architecture,omitempty"`
+	User               string            `json:"user,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+}
+
+// Output is a plugin's result for the image under test.
+type Output struct {
+	// Result is one of ResultPass, ResultFail, or ResultError.
+	Result string `json:"result"`
+	// Findings are reported with the check's result, e.g. the files that caused it to fail.
+	Findings []string `json:"findings,omitempty"`
+	// Message explains an error.
+	Message string `json:"message,omitempty"`
+}
+
+// runner invokes a plugin with args, writing stdin to it, and returns what it wrote to stdout.
+// imageFSPath, if not empty, is made available to the plugin.
+type runner interface {
+	run(ctx context.Context, args []string, stdin []byte, imageFSPath string) ([]byte, error)
+	// fsPath is the path imageFSPath is made available to the plugin at.
+	fsPath(imageFSPath string) string
+}
+
+// Load describes each of the configured plugins, and returns a check for each.
+func Load(ctx context.Context, configs []Config) ([]check.Check, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	checks := make([]check.Check, 0, len(configs))
+	var names []string
+	for _, cfg := range configs {
+		p, err := load(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("could not load plugin %s: %w", cfg.Path, err)
+		}
+		if slices.Contains(names, p.Name()) {
+			return nil, fmt.Errorf("could not load plugin %s: a plugin named %s is already loaded", cfg.Path, p.Name())
+		}
+		names = append(names, p.Name())
+
+		logger.V(log.DBG).Info("loaded plugin", "path", cfg.Path, "name", p.Name(), "level", p.Metadata().Level)
+		checks = append(checks, p)
+	}
+
+	return checks, nil
+}
+
+func load(ctx context.Context, cfg Config) (*pluginCheck, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("a path is required")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	var r runner
+	if strings.EqualFold(filepath.Ext(cfg.Path), ".wasm") {
+		w, err := newWasmRunner(ctx, cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		r = w
+	} else {
+		r = &execRunner{path: cfg.Path}
+	}
+
+	p := &pluginCheck{cfg: cfg, runner: r}
+	out, err := p.invoke(ctx, CommandDescribe, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(out, &p.description); err != nil {
+		return nil, fmt.Errorf("could not decode description: %w", err)
+	}
+
+	d := &p.description
+	if d.Name == "" {
+		return nil, errors.New("the description has no name")
+	}
+	if d.Level == "" {
+		d.Level = check.LevelBest
+	}
+	if d.Level != check.LevelBest && d.Level != check.LevelWarn && d.Level != check.LevelOptional {
+		return nil, fmt.Errorf("level %q is not one of %s, %s or %s", d.Level, check.LevelBest, check.LevelWarn, check.LevelOptional)
+	}
+
+	return p, nil
+}
+
+var _ check.Check = &pluginCheck{}
+
+// pluginCheck is a check implemented by a plugin.
+type pluginCheck struct {
+	cfg         Config
+	runner      runner
+	description Description
+}
+
+func (p *pluginCheck) invoke(ctx context.Context, command string, stdin []byte, imageFSPath string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+	defer cancel()
+
+	args := append(slices.Clone(p.cfg.Args), command)
+	out, err := p.runner.run(ctx, args, stdin, imageFSPath)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s timed out after %s", command, p.cfg.Timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", command, err)
+	}
+
+	return out, nil
+}
+
+func (p *pluginCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	input := Input{
+		ImageFSPath: p.runner.fsPath(imgRef.ImageFSPath),
+		Image: Image{
+			URI:                imgRef.ImageURI,
+			Registry:           imgRef.ImageRegistry,
+			Repository:         imgRef.ImageRepository,
+			TagOrSha:           imgRef.ImageTagOrSha,
+			ManifestListDigest: imgRef.ManifestListDigest,
+		},
+	}
+	if imgRef.ImageInfo != nil {
+		if digest, err := imgRef.ImageInfo.Digest(); err == nil {
+			input.Image.Digest = digest.String()
+		}
+		if config, err := imgRef.ImageInfo.ConfigFile(); err == nil && config != nil {
+			input.Image.Architecture = config.Architecture
+			input.Image.User = config.Config.User
+			input.Image.Labels = config.Config.Labels
+		}
+	}
+
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		//coverage:ignore
+		return false, fmt.Errorf("could not encode plugin input: %w", err)
+	}
+
+	out, err := p.invoke(ctx, CommandValidate, stdin, imgRef.ImageFSPath)
+	if err != nil {
+		return false, err
+	}
+
+	var output Output
+	if err := json.Unmarshal(out, &output); err != nil {
+		return false, fmt.Errorf("could not decode plugin output: %w", err)
+	}
+
+	check.ReportFindings(ctx, output.Findings...)
+
+	switch output.Result {
+	case ResultPass:
+		return true, nil
+	case ResultFail:
+		return false, nil
+	case ResultError:
+		return false, fmt.Errorf("plugin %s reported an error: %s", p.Name(), output.Message)
+	}
+
+	return false, fmt.Errorf("plugin %s returned unknown result %q", p.Name(), output.Result)
+}
+
+func (p *pluginCheck) Name() string {
+	return p.description.Name
+}
+
+func (p *pluginCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      p.description.Description,
+		Level:            p.description.Level,
+		KnowledgeBaseURL: p.description.KnowledgeBaseURL,
+		CheckURL:         p.description.CheckURL,
+	}
+}
+
+func (p *pluginCheck) Help() check.HelpText {
+	return p.description.Help
+}
+
+func (p *pluginCheck) RequiredFilePatterns() []string {
+	return p.description.RequiredFilePatterns
+}
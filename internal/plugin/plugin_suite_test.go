@@ -0,0 +1,34 @@
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPlugin(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Plugin Suite")
+}
+
+// wasmPlugin is the WebAssembly module built from testdata/wasmplugin.
+var wasmPlugin string
+
+var _ = SynchronizedBeforeSuite(func() []byte {
+	out := filepath.Join(os.TempDir(), "preflight-test-wasmplugin.wasm")
+	build := exec.Command("go", "build", "-o", out, "./testdata/wasmplugin")
+	build.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	output, err := build.CombinedOutput()
+	Expect(err).ToNot(HaveOccurred(), string(output))
+	return []byte(out)
+}, func(path []byte) {
+	wasmPlugin = string(path)
+})
+
+var _ = SynchronizedAfterSuite(func() {}, func() {
+	_ = os.Remove(wasmPlugin)
+})
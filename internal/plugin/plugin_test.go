@@ -0,0 +1,250 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// writeExecPlugin writes a plugin script that describes itself with description, and
+// answers validate with output, saving its input and arguments next to it.
+func writeExecPlugin(description string, output string) string {
+	dir := GinkgoT().TempDir()
+	path := filepath.Join(dir, "plugin")
+	script := fmt.Sprintf(`#!/bin/sh
+for command; do :; done
+echo "$@" > %[1]s/args
+case "$command" in
+describe)
+	cat <<'JSON'
+%[2]s
+JSON
+	;;
+validate)
+	cat > %[1]s/input.json
+	cat <<'JSON'
+%[3]s
+JSON
+	;;
+esac
+`, dir, description, output)
+	Expect(os.WriteFile(path, []byte(script), 0o755)).To(Succeed())
+	return path
+}
+
+const caBundleDescription = `{
+	"name": "HasInternalCABundle",
+	"level": "warn",
+	"description": "Checking for the internal CA bundle.",
+	"help": {"message": "The internal CA bundle is missing.", "suggestion": "Add it."},
+	"required_file_patterns": ["etc/pki/ca-trust/source/anchors/**"]
+}`
+
+var _ = Describe("Plugins", func() {
+	var (
+		ctx    context.Context
+		imgRef image.ImageReference
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		img, err := random.Image(512, 1)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{
+			ImageURI:        "quay.io/example/app:v1",
+			ImageFSPath:     GinkgoT().TempDir(),
+			ImageInfo:       img,
+			ImageRegistry:   "quay.io",
+			ImageRepository: "example/app",
+			ImageTagOrSha:   "v1",
+		}
+	})
+
+	Context("that are executables", func() {
+		It("should declare the check's name, metadata, level and required file patterns", func() {
+			checks, err := Load(ctx, []Config{{Path: writeExecPlugin(caBundleDescription, "")}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(1))
+
+			c := checks[0]
+			Expect(c.Name()).To(Equal("HasInternalCABundle"))
+			Expect(c.Metadata().Level).To(Equal(check.LevelWarn))
+			Expect(c.Metadata().Description).To(Equal("Checking for the internal CA bundle."))
+			Expect(c.Help().Suggestion).To(Equal("Add it."))
+			Expect(c.RequiredFilePatterns()).To(ConsistOf("etc/pki/ca-trust/source/anchors/**"))
+		})
+
+		It("should pass the configured arguments before the command", func() {
+			path := writeExecPlugin(caBundleDescription, `{"result": "pass"}`)
+			checks, err := Load(ctx, []Config{{Path: path, Args: []string{"--bundle", "corp"}}})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = checks[0].Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+
+			args, err := os.ReadFile(filepath.Join(filepath.Dir(path), "args"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(args)).To(Equal("--bundle corp validate\n"))
+		})
+
+		It("should describe the image under test to the plugin", func() {
+			path := writeExecPlugin(caBundleDescription, `{"result": "pass"}`)
+			checks, err := Load(ctx, []Config{{Path: path}})
+			Expect(err).ToNot(HaveOccurred())
+
+			passed, err := checks[0].Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeTrue())
+
+			raw, err := os.ReadFile(filepath.Join(filepath.Dir(path), "input.json"))
+			Expect(err).ToNot(HaveOccurred())
+			var input Input
+			Expect(json.Unmarshal(raw, &input)).To(Succeed())
+			Expect(input.ImageFSPath).To(Equal(imgRef.ImageFSPath))
+			Expect(input.Image.URI).To(Equal("quay.io/example/app:v1"))
+			Expect(input.Image.Repository).To(Equal("example/app"))
+			Expect(input.Image.Digest).To(HavePrefix("sha256:"))
+		})
+
+		It("should report the findings of a failed check", func() {
+			path := writeExecPlugin(caBundleDescription, `{"result": "fail", "findings": ["/etc/pki/ca-trust/source/anchors is empty"]}`)
+			checks, err := Load(ctx, []Config{{Path: path}})
+			Expect(err).ToNot(HaveOccurred())
+
+			ctx, findings := check.ContextWithFindings(ctx)
+			passed, err := checks[0].Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf("/etc/pki/ca-trust/source/anchors is empty"))
+		})
+
+		DescribeTable("should return an error for an invalid result",
+			func(output string, expected string) {
+				checks, err := Load(ctx, []Config{{Path: writeExecPlugin(caBundleDescription, output)}})
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = checks[0].Validate(ctx, imgRef)
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("reported error", `{"result": "error", "message": "bundle unreadable"}`, "reported an error: bundle unreadable"),
+			Entry("unknown result", `{"result": "maybe"}`, `unknown result "maybe"`),
+			Entry("malformed output", `not json`, "could not decode plugin output"),
+		)
+
+		It("should include the plugin's stderr when it exits unsuccessfully", func() {
+			dir := GinkgoT().TempDir()
+			path := filepath.Join(dir, "plugin")
+			Expect(os.WriteFile(path, []byte("#!/bin/sh\necho 'cannot describe' >&2\nexit 3\n"), 0o755)).To(Succeed())
+
+			_, err := Load(ctx, []Config{{Path: path}})
+			Expect(err).To(MatchError(ContainSubstring("describe failed: exit status 3: cannot describe")))
+		})
+
+		It("should stop a plugin that takes longer than its timeout", func() {
+			dir := GinkgoT().TempDir()
+			path := filepath.Join(dir, "plugin")
+			Expect(os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755)).To(Succeed())
+
+			_, err := Load(ctx, []Config{{Path: path, Timeout: 100 * time.Millisecond}})
+			Expect(err).To(MatchError(ContainSubstring("describe timed out after 100ms")))
+		})
+	})
+
+	Context("when loading", func() {
+		DescribeTable("should reject an invalid description",
+			func(description string, expected string) {
+				_, err := Load(ctx, []Config{{Path: writeExecPlugin(description, "")}})
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("no name", `{"description": "unnamed"}`, "the description has no name"),
+			Entry("unknown level", `{"name": "Named", "level": "critical"}`, `level "critical" is not one of`),
+			Entry("malformed", `not json`, "could not decode description"),
+		)
+
+		It("should default the level to best", func() {
+			checks, err := Load(ctx, []Config{{Path: writeExecPlugin(`{"name": "Named"}`, "")}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[0].Metadata().Level).To(Equal(check.LevelBest))
+		})
+
+		It("should reject plugins with the same name", func() {
+			path := writeExecPlugin(caBundleDescription, "")
+			_, err := Load(ctx, []Config{{Path: path}, {Path: path}})
+			Expect(err).To(MatchError(ContainSubstring("a plugin named HasInternalCABundle is already loaded")))
+		})
+
+		It("should reject a plugin without a path", func() {
+			_, err := Load(ctx, []Config{{}})
+			Expect(err).To(MatchError(ContainSubstring("a path is required")))
+		})
+
+		It("should reject a plugin that does not exist", func() {
+			_, err := Load(ctx, []Config{{Path: filepath.Join(GinkgoT().TempDir(), "missing")}})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("that are WebAssembly modules", Ordered, func() {
+		// the module is loaded once, as compiling it is slow.
+		var wasm *pluginCheck
+
+		BeforeAll(func() {
+			checks, err := Load(context.Background(), []Config{{Path: wasmPlugin}})
+			Expect(err).ToNot(HaveOccurred())
+			wasm = checks[0].(*pluginCheck)
+		})
+
+		It("should declare the check", func() {
+			Expect(wasm.Name()).To(Equal("HasNoBannedBinaries"))
+			Expect(wasm.Metadata().Level).To(Equal(check.LevelBest))
+			Expect(wasm.RequiredFilePatterns()).To(ConsistOf("usr/bin/nc", "usr/bin/telnet"))
+		})
+
+		It("should pass an image without banned binaries", func() {
+			passed, err := wasm.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeTrue())
+		})
+
+		It("should read the image's filesystem, and report its findings", func() {
+			Expect(os.MkdirAll(filepath.Join(imgRef.ImageFSPath, "usr", "bin"), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(imgRef.ImageFSPath, "usr", "bin", "nc"), []byte("nc"), 0o755)).To(Succeed())
+
+			ctx, findings := check.ContextWithFindings(ctx)
+			passed, err := wasm.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf("/usr/bin/nc"))
+		})
+
+		It("should stop a module that takes longer than its timeout", func() {
+			hanging := &pluginCheck{
+				cfg:         Config{Path: wasmPlugin, Args: []string{"--hang"}, Timeout: 500 * time.Millisecond},
+				runner:      wasm.runner,
+				description: wasm.description,
+			}
+
+			_, err := hanging.Validate(ctx, imgRef)
+			Expect(err).To(MatchError(ContainSubstring("validate timed out after 500ms")))
+		})
+
+		It("should reject a file that is not a WebAssembly module", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "invalid.wasm")
+			Expect(os.WriteFile(path, []byte("not wasm"), 0o644)).To(Succeed())
+
+			_, err := Load(ctx, []Config{{Path: path}})
+			Expect(err).To(MatchError(ContainSubstring("could not compile WebAssembly module")))
+		})
+	})
+})
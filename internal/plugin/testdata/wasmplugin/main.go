@@ -0,0 +1,60 @@
+// This is a plugin, built for GOOS=wasip1 GOARCH=wasm by the tests, that fails images
+// containing banned binaries.
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var banned = []string{"usr/bin/nc", "usr/bin/telnet"}
+
+func main() {
+	args := os.Args[1:]
+	command := args[len(args)-1]
+
+	switch command {
+	case "describe":
+		write(map[string]any{
+			"name":                   "HasNoBannedBinaries",
+			"description":            "Checking that the image has no banned binaries.",
+			"help":                   map[string]string{"message": "The image contains banned binaries.", "suggestion": "Remove them."},
+			"required_file_patterns": banned,
+		})
+	case "validate":
+		if slices.Contains(args, "--hang") {
+			for {
+			}
+		}
+
+		var input struct {
+			ImageFSPath string `json:"image_fs_path"`
+		}
+		if err := json.NewDecoder(os.Stdin).Decode(&input); err != nil {
+			write(map[string]any{"result": "error", "message": err.Error()})
+			return
+		}
+
+		var findings []string
+		for _, b := range banned {
+			if _, err := os.Stat(filepath.Join(input.ImageFSPath, b)); err == nil {
+				findings = append(findings, "/"+b)
+			}
+		}
+
+		result := "pass"
+		if len(findings) > 0 {
+			result = "fail"
+		}
+		write(map[string]any{"result": result, "findings": findings})
+	default:
+		os.Stderr.WriteString("unknown command " + command)
+		os.Exit(2)
+	}
+}
+
+func write(v any) {
+	_ = json.NewEncoder(os.Stdout).Encode(v)
+}
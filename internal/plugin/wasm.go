@@ -0,0 +1,84 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// wasmImageFSPath is where the image's extracted filesystem is mounted, read only, for
+// WebAssembly modules.
+const wasmImageFSPath = "/image"
+
+// wasmRunner runs a plugin that is a WebAssembly module targeting WASI preview 1, e.g. built
+// with GOOS=wasip1 GOARCH=wasm. The module has no access to the host beyond its stdin,
+// stdout and stderr, and the image's filesystem.
+type wasmRunner struct {
+	name   string
+	module []byte
+	// cache holds the module's compiled code, so that it is compiled once rather than on each
+	// run.
+	cache wazero.CompilationCache
+}
+
+func newWasmRunner(ctx context.Context, path string) (*wasmRunner, error) {
+	module, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// compile the module when it is loaded, to report a malformed module early.
+	cache := wazero.NewCompilationCache()
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(cache))
+	defer runtime.Close(ctx)
+	if _, err := runtime.CompileModule(ctx, module); err != nil {
+		return nil, fmt.Errorf("could not compile WebAssembly module: %w", err)
+	}
+
+	return &wasmRunner{name: filepath.Base(path), module: module, cache: cache}, nil
+}
+
+func (r *wasmRunner) run(ctx context.Context, args []string, stdin []byte, imageFSPath string) ([]byte, error) {
+	// the module is closed when ctx is done, e.g. because the plugin timed out.
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithCompilationCache(r.cache).
+		WithCloseOnContextDone(true))
+	defer runtime.Close(ctx)
+	wasi_snapshot_preview1.MustInstantiate(ctx, runtime)
+
+	var stdout, stderr bytes.Buffer
+	config := wazero.NewModuleConfig().
+		WithName(r.name).
+		WithArgs(append([]string{r.name}, args...)...).
+		WithStdin(bytes.NewReader(stdin)).
+		WithStdout(&stdout).
+		WithStderr(&stderr)
+	if imageFSPath != "" {
+		config = config.WithFSConfig(wazero.NewFSConfig().WithReadOnlyDirMount(imageFSPath, wasmImageFSPath))
+	}
+
+	_, err := runtime.InstantiateWithConfig(ctx, r.module, config)
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, withStderr(err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func (r *wasmRunner) fsPath(imageFSPath string) string {
+	if imageFSPath == "" {
+		return ""
+	}
+	return wasmImageFSPath
+}
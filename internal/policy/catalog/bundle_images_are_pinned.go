@@ -0,0 +1,143 @@
+package catalog
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/crane"
+
+	fbc "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/csv"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
+)
+
+var _ check.Check = &BundleImagesArePinnedCheck{}
+
+// BundleImagesArePinnedCheck checks that the image of each bundle of the catalog is pinned to
+// a digest, and exists in its registry, or one of its mirrors.
+type BundleImagesArePinnedCheck struct {
+	// dockerConfig is the credential required to pull the bundle images.
+	dockerConfig string
+	// insecure allows an insecure connection to the registries of the bundle images.
+	insecure bool
+}
+
+var _ option.CraneConfig = &BundleImagesArePinnedCheck{}
+
+// NewBundleImagesArePinnedCheck returns a check that the bundle images of the catalog are
+// pinned and exist, looking them up with the credentials in dockerConfig.
+func NewBundleImagesArePinnedCheck(dockerConfig string, insecure bool) *BundleImagesArePinnedCheck {
+	return &BundleImagesArePinnedCheck{
+		dockerConfig: dockerConfig,
+		insecure:     insecure,
+	}
+}
+
+func (p *BundleImagesArePinnedCheck) Validate(ctx context.Context, ref image.ImageReference) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	blobs, err := load(ref)
+	if err != nil {
+		return false, err
+	}
+
+	bundles := slices.Clone(blobs.Bundles)
+	slices.SortFunc(bundles, func(a, b fbc.Bundle) int {
+		return cmp.Or(cmp.Compare(a.Package, b.Package), cmp.Compare(a.Name, b.Name))
+	})
+
+	var problems []string
+	// the error looking up each image, which bundles may share.
+	lookups := map[string]error{}
+	for _, bundle := range bundles {
+		// bundles without an image are reported by ValidateCatalogSchema.
+		if bundle.Image == "" {
+			continue
+		}
+
+		if !csv.IsPinned(bundle.Image) {
+			problems = append(problems, fmt.Sprintf("the image %s of bundle %s of package %s is not pinned to a digest", bundle.Image, bundle.Name, bundle.Package))
+		}
+
+		err, ok := lookups[bundle.Image]
+		if !ok {
+			logger.V(log.DBG).Info("looking up bundle image", "bundle", bundle.Name, "image", bundle.Image)
+			err = p.lookup(ctx, bundle.Image)
+			lookups[bundle.Image] = err
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("the image %s of bundle %s of package %s could not be found: %v", bundle.Image, bundle.Name, bundle.Package, err))
+		}
+	}
+	check.ReportFindings(ctx, problems...)
+	return len(problems) == 0, nil
+}
+
+// lookup returns nil if img exists in the first of its sources, i.e. its mirrors or its own
+// registry, that responds.
+func (p *BundleImagesArePinnedCheck) lookup(ctx context.Context, img string) error {
+	sources, err := registries.FromContext(ctx).Sources(img)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, source := range sources {
+		options := option.GenerateCraneOptions(ctx, p)
+		if source.Insecure {
+			options = append(options, crane.Insecure)
+		}
+		if _, err := crane.Head(source.Reference, options...); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (p *BundleImagesArePinnedCheck) CraneDockerConfig() string {
+	return p.dockerConfig
+}
+
+func (p *BundleImagesArePinnedCheck) CranePlatform() string {
+	return goruntime.GOARCH
+}
+
+func (p *BundleImagesArePinnedCheck) CraneInsecure() bool {
+	return p.insecure
+}
+
+func (p *BundleImagesArePinnedCheck) Name() string {
+	return "BundleImagesArePinned"
+}
+
+func (p *BundleImagesArePinnedCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image of each bundle of the file-based catalog is pinned to a digest, and exists",
+		Level:            check.LevelBest,
+		KnowledgeBaseURL: "https://olm.operatorframework.io/docs/reference/file-based-catalogs/",
+		CheckURL:         "https://olm.operatorframework.io/docs/reference/file-based-catalogs/#olmbundle",
+	}
+}
+
+func (p *BundleImagesArePinnedCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check BundleImagesArePinned encountered an error. Please review the findings for the bundle images that are not pinned, or could not be found.",
+		Suggestion: "Reference each bundle image by its digest, e.g. quay.io/example/operator-bundle@sha256:<digest>, and make sure it has been pushed to its registry.",
+	}
+}
+
+func (p *BundleImagesArePinnedCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return catalogFiles
+}
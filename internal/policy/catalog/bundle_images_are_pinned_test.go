@@ -0,0 +1,89 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+var _ = Describe("BundleImagesArePinned", func() {
+	var (
+		bundleImagesArePinned *BundleImagesArePinnedCheck
+		ctx                   context.Context
+		findings              *check.Findings
+		// catalog is memcachedCatalog, with its bundle images pushed to a local registry.
+		catalog string
+		repo    string
+	)
+
+	BeforeEach(func() {
+		bundleImagesArePinned = NewBundleImagesArePinnedCheck("", false)
+		ctx, findings = check.ContextWithFindings(context.Background())
+
+		s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", log.Ldate))))
+		DeferCleanup(s.Close)
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+		repo = fmt.Sprintf("%s/example/memcached-operator-bundle", u.Host)
+
+		catalog = memcachedCatalog
+		for i, version := range []string{"v0.0.1", "v0.0.2", "v0.0.3"} {
+			img, err := random.Image(1024, 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crane.Push(img, repo+":"+version)).To(Succeed())
+			digest, err := img.Digest()
+			Expect(err).ToNot(HaveOccurred())
+
+			placeholder := "quay.io/example/memcached-operator-bundle@sha256:" + strings.Repeat(fmt.Sprint(i+1), 64)
+			catalog = strings.Replace(catalog, placeholder, repo+"@"+digest.String(), 1)
+		}
+	})
+
+	AssertMetaData(NewBundleImagesArePinnedCheck("", false))
+
+	It("should pass if each bundle image is pinned, and exists", func() {
+		ok, err := bundleImagesArePinned.Validate(ctx, catalogRef(catalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(BeEmpty())
+	})
+
+	It("should fail if a bundle image is not pinned", func() {
+		catalog = strings.Replace(catalog, "image: "+repo+"@", "image: "+repo+":v0.0.1\nx-digest: ", 1)
+		ok, err := bundleImagesArePinned.Validate(ctx, catalogRef(catalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()).To(Equal([]string{
+			fmt.Sprintf("the image %s:v0.0.1 of bundle memcached-operator.v0.0.1 of package memcached-operator is not pinned to a digest", repo),
+		}))
+	})
+
+	It("should fail if a bundle image does not exist", func() {
+		catalog = strings.Replace(catalog, repo+"@sha256:", repo+"-missing@sha256:", 1)
+		ok, err := bundleImagesArePinned.Validate(ctx, catalogRef(catalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()).To(ConsistOf(
+			And(ContainSubstring(repo+"-missing@sha256:"), ContainSubstring("of bundle memcached-operator.v0.0.1 of package memcached-operator could not be found")),
+		))
+	})
+
+	It("should not look up the images of bundles without one", func() {
+		ok, err := bundleImagesArePinned.Validate(ctx, catalogRef(strings.Replace(catalog, "image: ", "x-image: ", 1)))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(BeEmpty())
+	})
+})
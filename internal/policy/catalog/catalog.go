@@ -0,0 +1,74 @@
+// Package catalog contains the checks of the catalog policy, which validate a file-based
+// catalog (FBC): the schema of its blobs, the upgrade graph of its channels, and the images
+// of its bundles.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/blang/semver"
+
+	fbc "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+const (
+	// ConfigsLabel is the label of a catalog image whose value is the directory of its catalog.
+	ConfigsLabel = "operators.operatorframework.io.index.configs.v1"
+	// DefaultConfigsDir is the directory of the catalog in a catalog image without ConfigsLabel.
+	DefaultConfigsDir = "/configs"
+)
+
+// catalogFiles are the files the checks of the catalog policy require from a catalog image.
+var catalogFiles = []string{DefaultConfigsDir + "/**"}
+
+// load returns the blobs of the catalog under test. A catalog image keeps its catalog in the
+// directory of its ConfigsLabel, or DefaultConfigsDir, and a catalog on disk, which has no
+// ImageInfo, is the directory at ImageFSPath.
+func load(ref image.ImageReference) (*fbc.Blobs, error) {
+	dir := ref.ImageFSPath
+	if ref.ImageInfo != nil {
+		configsDir := DefaultConfigsDir
+		config, err := ref.ImageInfo.ConfigFile()
+		if err != nil {
+			return nil, fmt.Errorf("could not read the image config: %w", err)
+		}
+		if label := config.Config.Labels[ConfigsLabel]; label != "" {
+			configsDir = label
+		}
+		dir = filepath.Join(ref.ImageFSPath, configsDir)
+	}
+
+	return fbc.Load(dir)
+}
+
+// packageVersion returns the version in the olm.package property of bundle, and whether it has
+// a single one that is valid.
+func packageVersion(bundle fbc.Bundle) (semver.Version, bool) {
+	var version *semver.Version
+	for _, property := range bundle.Properties {
+		if property.Type != fbc.PropertyPackage {
+			continue
+		}
+		if version != nil {
+			return semver.Version{}, false
+		}
+		var value struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(property.Value, &value); err != nil {
+			return semver.Version{}, false
+		}
+		v, err := semver.Parse(value.Version)
+		if err != nil {
+			return semver.Version{}, false
+		}
+		version = &v
+	}
+	if version == nil {
+		return semver.Version{}, false
+	}
+	return *version, true
+}
@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+func TestCatalog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Catalog Suite")
+}
+
+// memcachedCatalog is a valid catalog of a package with a channel of three bundles.
+const memcachedCatalog = `---
+schema: olm.package
+name: memcached-operator
+defaultChannel: stable
+---
+schema: olm.channel
+name: stable
+package: memcached-operator
+entries:
+  - name: memcached-operator.v0.0.1
+  - name: memcached-operator.v0.0.2
+    replaces: memcached-operator.v0.0.1
+  - name: memcached-operator.v0.0.3
+    replaces: memcached-operator.v0.0.2
+    skipRange: ">=0.0.1 <0.0.3"
+---
+schema: olm.bundle
+name: memcached-operator.v0.0.1
+package: memcached-operator
+image: quay.io/example/memcached-operator-bundle@sha256:1111111111111111111111111111111111111111111111111111111111111111
+properties:
+  - type: olm.package
+    value:
+      packageName: memcached-operator
+      version: 0.0.1
+---
+schema: olm.bundle
+name: memcached-operator.v0.0.2
+package: memcached-operator
+image: quay.io/example/memcached-operator-bundle@sha256:2222222222222222222222222222222222222222222222222222222222222222
+properties:
+  - type: olm.package
+    value:
+      packageName: memcached-operator
+      version: 0.0.2
+---
+schema: olm.bundle
+name: memcached-operator.v0.0.3
+package: memcached-operator
+image: quay.io/example/memcached-operator-bundle@sha256:3333333333333333333333333333333333333333333333333333333333333333
+properties:
+  - type: olm.package
+    value:
+      packageName: memcached-operator
+      version: 0.0.3
+`
+
+// catalogRef returns a reference to a catalog on disk, whose catalog.yaml has contents.
+func catalogRef(contents string) image.ImageReference {
+	dir := GinkgoT().TempDir()
+	Expect(os.WriteFile(filepath.Join(dir, "catalog.yaml"), []byte(contents), 0o644)).To(Succeed())
+	return image.ImageReference{ImageURI: dir, ImageFSPath: dir}
+}
+
+var AssertMetaData = func(check check.Check) {
+	Context("When checking metadata", func() {
+		Context("The check name should not be empty", func() {
+			Expect(check.Name()).ToNot(BeEmpty())
+		})
+
+		Context("The metadata keys should not be empty", func() {
+			meta := check.Metadata()
+			Expect(meta.CheckURL).ToNot(BeEmpty())
+			Expect(meta.Description).ToNot(BeEmpty())
+			Expect(meta.KnowledgeBaseURL).ToNot(BeEmpty())
+			// Level is optional.
+		})
+
+		Context("The help text should not be empty", func() {
+			help := check.Help()
+			Expect(help.Message).ToNot(BeEmpty())
+			Expect(help.Suggestion).ToNot(BeEmpty())
+		})
+	})
+}
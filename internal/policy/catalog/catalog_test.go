@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("Loading the catalog under test", func() {
+	var fsPath string
+
+	BeforeEach(func() {
+		fsPath = GinkgoT().TempDir()
+		for _, dir := range []string{"configs", "catalog"} {
+			Expect(os.MkdirAll(filepath.Join(fsPath, dir), 0o755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(fsPath, dir, "catalog.yaml"), []byte("schema: olm.package\nname: "+dir+"\n"), 0o644)).To(Succeed())
+		}
+	})
+
+	It("should load a catalog on disk from its directory", func() {
+		blobs, err := load(image.ImageReference{ImageFSPath: filepath.Join(fsPath, "catalog")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs.Packages[0].Name).To(Equal("catalog"))
+	})
+
+	It("should load the catalog of an image from the default directory", func() {
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+
+		blobs, err := load(image.ImageReference{ImageFSPath: fsPath, ImageInfo: img})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs.Packages[0].Name).To(Equal("configs"))
+	})
+
+	It("should load the catalog of an image from the directory of its label", func() {
+		img, err := random.Image(1024, 1)
+		Expect(err).ToNot(HaveOccurred())
+		config, err := img.ConfigFile()
+		Expect(err).ToNot(HaveOccurred())
+		config.Config.Labels = map[string]string{ConfigsLabel: "/catalog"}
+		img, err = mutate.ConfigFile(img, config)
+		Expect(err).ToNot(HaveOccurred())
+
+		blobs, err := load(image.ImageReference{ImageFSPath: fsPath, ImageInfo: img})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(blobs.Packages[0].Name).To(Equal("catalog"))
+	})
+})
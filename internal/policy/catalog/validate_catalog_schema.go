@@ -0,0 +1,211 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blang/semver"
+	"github.com/go-logr/logr"
+
+	fbc "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+var _ check.Check = &ValidateCatalogSchemaCheck{}
+
+// ValidateCatalogSchemaCheck checks that the olm.package, olm.channel and olm.bundle blobs of
+// the catalog have the fields OLM requires, and that the packages, channels and bundles they
+// reference exist.
+type ValidateCatalogSchemaCheck struct{}
+
+func (p *ValidateCatalogSchemaCheck) Validate(ctx context.Context, ref image.ImageReference) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	blobs, err := load(ref)
+	if err != nil {
+		return false, err
+	}
+	logger.V(log.DBG).Info("loaded catalog", "packages", len(blobs.Packages), "channels", len(blobs.Channels), "bundles", len(blobs.Bundles))
+
+	problems := validateSchema(blobs)
+	check.ReportFindings(ctx, problems...)
+	return len(problems) == 0, nil
+}
+
+// validateSchema returns the problems with the blobs of a catalog.
+func validateSchema(blobs *fbc.Blobs) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(blobs.Packages) == 0 {
+		report("the catalog has no %s blobs", fbc.SchemaPackage)
+	}
+	for _, other := range blobs.Others {
+		if other.Schema == "" {
+			report("a blob in %s has no schema", other.File)
+		}
+	}
+
+	// the channels and bundles of each package, by name.
+	channels := map[string]map[string]bool{}
+	bundles := map[string]map[string]bool{}
+	for _, pkg := range blobs.Packages {
+		switch {
+		case pkg.Name == "":
+			report("an %s blob has no name", fbc.SchemaPackage)
+			continue
+		case channels[pkg.Name] != nil:
+			report("package %s is declared more than once", pkg.Name)
+			continue
+		}
+		channels[pkg.Name] = map[string]bool{}
+		bundles[pkg.Name] = map[string]bool{}
+	}
+
+	for _, bundle := range blobs.Bundles {
+		switch {
+		case bundle.Name == "":
+			report("an %s blob of package %s has no name", fbc.SchemaBundle, bundle.Package)
+			continue
+		case bundle.Package == "":
+			report("bundle %s has no package", bundle.Name)
+			continue
+		case bundles[bundle.Package] == nil:
+			report("bundle %s is of package %s, which is not declared", bundle.Name, bundle.Package)
+			continue
+		case bundles[bundle.Package][bundle.Name]:
+			report("bundle %s of package %s is declared more than once", bundle.Name, bundle.Package)
+			continue
+		}
+		bundles[bundle.Package][bundle.Name] = true
+
+		if bundle.Image == "" {
+			report("bundle %s of package %s has no image", bundle.Name, bundle.Package)
+		}
+		problems = append(problems, validatePackageProperty(bundle)...)
+	}
+
+	// the bundles of each package that are in a channel.
+	inChannel := map[string]map[string]bool{}
+	for _, channel := range blobs.Channels {
+		switch {
+		case channel.Name == "":
+			report("an %s blob of package %s has no name", fbc.SchemaChannel, channel.Package)
+			continue
+		case channel.Package == "":
+			report("channel %s has no package", channel.Name)
+			continue
+		case channels[channel.Package] == nil:
+			report("channel %s is of package %s, which is not declared", channel.Name, channel.Package)
+			continue
+		case channels[channel.Package][channel.Name]:
+			report("channel %s of package %s is declared more than once", channel.Name, channel.Package)
+			continue
+		}
+		channels[channel.Package][channel.Name] = true
+
+		if len(channel.Entries) == 0 {
+			report("channel %s of package %s has no entries", channel.Name, channel.Package)
+		}
+		if inChannel[channel.Package] == nil {
+			inChannel[channel.Package] = map[string]bool{}
+		}
+		entries := map[string]bool{}
+		for _, entry := range channel.Entries {
+			switch {
+			case entry.Name == "":
+				report("an entry of channel %s of package %s has no name", channel.Name, channel.Package)
+			case entries[entry.Name]:
+				report("channel %s of package %s has entry %s more than once", channel.Name, channel.Package, entry.Name)
+			case !bundles[channel.Package][entry.Name]:
+				report("channel %s of package %s has entry %s, which is not a bundle of the package", channel.Name, channel.Package, entry.Name)
+			}
+			entries[entry.Name] = true
+			inChannel[channel.Package][entry.Name] = true
+		}
+	}
+
+	for _, pkg := range blobs.Packages {
+		switch {
+		case pkg.Name == "" || channels[pkg.Name] == nil:
+			continue
+		case pkg.DefaultChannel == "":
+			report("package %s has no defaultChannel", pkg.Name)
+		case !channels[pkg.Name][pkg.DefaultChannel]:
+			report("the default channel %s of package %s does not exist", pkg.DefaultChannel, pkg.Name)
+		}
+	}
+
+	for _, bundle := range blobs.Bundles {
+		if bundles[bundle.Package][bundle.Name] && !inChannel[bundle.Package][bundle.Name] {
+			report("bundle %s of package %s is not in any channel", bundle.Name, bundle.Package)
+		}
+	}
+
+	return problems
+}
+
+// validatePackageProperty returns the problems with the olm.package property of bundle, which
+// it must have one of, with its package and a valid version.
+func validatePackageProperty(bundle fbc.Bundle) []string {
+	var properties []fbc.Property
+	for _, property := range bundle.Properties {
+		if property.Type == fbc.PropertyPackage {
+			properties = append(properties, property)
+		}
+	}
+	switch len(properties) {
+	case 0:
+		return []string{fmt.Sprintf("bundle %s of package %s has no %s property", bundle.Name, bundle.Package, fbc.PropertyPackage)}
+	case 1:
+	default:
+		return []string{fmt.Sprintf("bundle %s of package %s has more than one %s property", bundle.Name, bundle.Package, fbc.PropertyPackage)}
+	}
+
+	var value struct {
+		PackageName string `json:"packageName"`
+		Version     string `json:"version"`
+	}
+	if err := json.Unmarshal(properties[0].Value, &value); err != nil {
+		return []string{fmt.Sprintf("the %s property of bundle %s of package %s is invalid: %v", fbc.PropertyPackage, bundle.Name, bundle.Package, err)}
+	}
+
+	var problems []string
+	if value.PackageName != bundle.Package {
+		problems = append(problems, fmt.Sprintf("the %s property of bundle %s of package %s is of package %q", fbc.PropertyPackage, bundle.Name, bundle.Package, value.PackageName))
+	}
+	if _, err := semver.Parse(value.Version); err != nil {
+		problems = append(problems, fmt.Sprintf("the %s property of bundle %s of package %s has invalid version %q", fbc.PropertyPackage, bundle.Name, bundle.Package, value.Version))
+	}
+	return problems
+}
+
+func (p *ValidateCatalogSchemaCheck) Name() string {
+	return "ValidateCatalogSchema"
+}
+
+func (p *ValidateCatalogSchemaCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Validating the olm.package, olm.channel and olm.bundle blobs of the file-based catalog",
+		Level:            check.LevelBest,
+		KnowledgeBaseURL: "https://olm.operatorframework.io/docs/reference/file-based-catalogs/",
+		CheckURL:         "https://olm.operatorframework.io/docs/reference/file-based-catalogs/#olm-defined-schemas",
+	}
+}
+
+func (p *ValidateCatalogSchemaCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check ValidateCatalogSchema encountered an error. Please review the findings for the blobs that are invalid.",
+		Suggestion: "Correct the blobs of the catalog, e.g. with opm validate, so that each package has a default channel, and each bundle has an image, an olm.package property, and is in a channel.",
+	}
+}
+
+func (p *ValidateCatalogSchemaCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return catalogFiles
+}
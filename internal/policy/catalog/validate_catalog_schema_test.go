@@ -0,0 +1,80 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("ValidateCatalogSchema", func() {
+	var (
+		validateCatalogSchema ValidateCatalogSchemaCheck
+		ctx                   context.Context
+		findings              *check.Findings
+	)
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	AssertMetaData(&validateCatalogSchema)
+
+	It("should pass for a valid catalog", func() {
+		ok, err := validateCatalogSchema.Validate(ctx, catalogRef(memcachedCatalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(BeEmpty())
+	})
+
+	It("should fail if the catalog has no packages", func() {
+		ok, err := validateCatalogSchema.Validate(ctx, catalogRef(""))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()).To(Equal([]string{"the catalog has no olm.package blobs"}))
+	})
+
+	It("should error if the catalog cannot be read", func() {
+		_, err := validateCatalogSchema.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/missing"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("should fail for an invalid catalog",
+		func(old, replacement string, expected ...string) {
+			ok, err := validateCatalogSchema.Validate(ctx, catalogRef(strings.Replace(memcachedCatalog, old, replacement, 1)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(expected))
+		},
+		Entry("a blob without a schema", "---\nschema: olm.package\n", "---\nname: orphan\n---\nschema: olm.package\n",
+			"a blob in catalog.yaml has no schema"),
+		Entry("a package declared twice", "---\nschema: olm.package\n", "---\nschema: olm.package\nname: memcached-operator\ndefaultChannel: stable\n---\nschema: olm.package\n",
+			"package memcached-operator is declared more than once"),
+		Entry("a missing default channel", "defaultChannel: stable", "defaultChannel: fast",
+			"the default channel fast of package memcached-operator does not exist"),
+		Entry("a channel of an undeclared package", "name: stable\npackage: memcached-operator", "name: stable\npackage: etcd",
+			"channel stable is of package etcd, which is not declared",
+			"the default channel stable of package memcached-operator does not exist",
+			"bundle memcached-operator.v0.0.1 of package memcached-operator is not in any channel",
+			"bundle memcached-operator.v0.0.2 of package memcached-operator is not in any channel",
+			"bundle memcached-operator.v0.0.3 of package memcached-operator is not in any channel"),
+		Entry("an entry that is not a bundle", "  - name: memcached-operator.v0.0.1\n", "  - name: memcached-operator.v0.0.1\n  - name: memcached-operator.v0.0.0\n",
+			"channel stable of package memcached-operator has entry memcached-operator.v0.0.0, which is not a bundle of the package"),
+		Entry("an entry listed twice", "  - name: memcached-operator.v0.0.1\n", "  - name: memcached-operator.v0.0.1\n  - name: memcached-operator.v0.0.1\n",
+			"channel stable of package memcached-operator has entry memcached-operator.v0.0.1 more than once"),
+		Entry("a bundle without an image", "image: quay.io/example/memcached-operator-bundle@sha256:1111111111111111111111111111111111111111111111111111111111111111\n", "",
+			"bundle memcached-operator.v0.0.1 of package memcached-operator has no image"),
+		Entry("a bundle without an olm.package property", "properties:\n  - type: olm.package\n", "properties:\n  - type: olm.maxOpenShiftVersion\n",
+			"bundle memcached-operator.v0.0.1 of package memcached-operator has no olm.package property"),
+		Entry("a bundle of another package", "      packageName: memcached-operator\n      version: 0.0.1", "      packageName: etcd\n      version: 0.0.1",
+			`the olm.package property of bundle memcached-operator.v0.0.1 of package memcached-operator is of package "etcd"`),
+		Entry("a bundle with an invalid version", "version: 0.0.1", "version: v1",
+			`the olm.package property of bundle memcached-operator.v0.0.1 of package memcached-operator has invalid version "v1"`),
+		Entry("a bundle that is not in a channel", "  - name: memcached-operator.v0.0.1\n", "",
+			"bundle memcached-operator.v0.0.1 of package memcached-operator is not in any channel"),
+	)
+})
@@ -0,0 +1,182 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/go-logr/logr"
+
+	fbc "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+var _ check.Check = &ValidateUpgradeGraphCheck{}
+
+// ValidateUpgradeGraphCheck checks the upgrade graph of each channel of the catalog: that each
+// entry replaces an entry of the channel, that each skipRange is valid, and that the channel has
+// a single head, which each of its entries can be upgraded to.
+type ValidateUpgradeGraphCheck struct{}
+
+func (p *ValidateUpgradeGraphCheck) Validate(ctx context.Context, ref image.ImageReference) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	blobs, err := load(ref)
+	if err != nil {
+		return false, err
+	}
+
+	// the versions of the bundles of each package, for the skipRange of their entries.
+	versions := map[string]map[string]semver.Version{}
+	for _, bundle := range blobs.Bundles {
+		version, ok := packageVersion(bundle)
+		if !ok {
+			continue
+		}
+		if versions[bundle.Package] == nil {
+			versions[bundle.Package] = map[string]semver.Version{}
+		}
+		versions[bundle.Package][bundle.Name] = version
+	}
+
+	var problems []string
+	for _, channel := range blobs.Channels {
+		logger.V(log.DBG).Info("validating upgrade graph", "package", channel.Package, "channel", channel.Name)
+		problems = append(problems, validateGraph(channel, versions[channel.Package])...)
+	}
+	check.ReportFindings(ctx, problems...)
+	return len(problems) == 0, nil
+}
+
+// validateGraph returns the problems with the upgrade graph of channel, whose bundles have
+// versions.
+func validateGraph(channel fbc.Channel, versions map[string]semver.Version) []string {
+	var problems []string
+	report := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("channel %s of package %s: ", channel.Name, channel.Package)+fmt.Sprintf(format, args...))
+	}
+
+	entries := map[string]fbc.ChannelEntry{}
+	for _, entry := range channel.Entries {
+		entries[entry.Name] = entry
+	}
+
+	// the entries each entry upgrades from, and the entries that are upgraded from.
+	edges := map[string][]string{}
+	replaced := map[string]bool{}
+	for _, entry := range channel.Entries {
+		if entry.Replaces != "" {
+			if _, ok := entries[entry.Replaces]; !ok {
+				report("%s replaces %s, which is not in the channel", entry.Name, entry.Replaces)
+			} else {
+				edges[entry.Name] = append(edges[entry.Name], entry.Replaces)
+			}
+			replaced[entry.Replaces] = true
+		}
+		for _, skip := range entry.Skips {
+			if _, ok := entries[skip]; ok {
+				edges[entry.Name] = append(edges[entry.Name], skip)
+			}
+			replaced[skip] = true
+		}
+		if entry.SkipRange == "" {
+			continue
+		}
+		inRange, err := semver.ParseRange(entry.SkipRange)
+		if err != nil {
+			report("%s has invalid skipRange %q: %v", entry.Name, entry.SkipRange, err)
+			continue
+		}
+		for _, other := range channel.Entries {
+			if version, ok := versions[other.Name]; ok && other.Name != entry.Name && inRange(version) {
+				edges[entry.Name] = append(edges[entry.Name], other.Name)
+			}
+		}
+	}
+
+	// a head is an entry no other entry replaces or skips.
+	var heads []string
+	for _, entry := range channel.Entries {
+		if !replaced[entry.Name] && !slices.Contains(heads, entry.Name) {
+			heads = append(heads, entry.Name)
+		}
+	}
+	switch {
+	case len(channel.Entries) == 0:
+		return problems
+	case len(heads) == 0:
+		report("has no head, as its entries replace or skip each other in a cycle")
+		return problems
+	case len(heads) > 1:
+		slices.Sort(heads)
+		report("has more than one head, %s, so the entries that can only be upgraded to one of them cannot be upgraded to the others", strings.Join(heads, ", "))
+		return problems
+	}
+
+	if cycle := replacesCycle(heads[0], entries); cycle != "" {
+		report("the replaces chain from head %s has a cycle at %s", heads[0], cycle)
+	}
+
+	// each entry must be reachable from the head, i.e. it can be upgraded to the head.
+	reachable := map[string]bool{heads[0]: true}
+	queue := []string{heads[0]}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[name] {
+			if !reachable[next] {
+				reachable[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	for _, entry := range channel.Entries {
+		if !reachable[entry.Name] {
+			report("%s cannot be upgraded to head %s, as no entry on its upgrade path replaces, skips or has a skipRange including it", entry.Name, heads[0])
+			reachable[entry.Name] = true
+		}
+	}
+
+	return problems
+}
+
+// replacesCycle returns the entry at which the replaces chain from head cycles, if it does.
+func replacesCycle(head string, entries map[string]fbc.ChannelEntry) string {
+	seen := map[string]bool{}
+	for name := head; name != ""; name = entries[name].Replaces {
+		if seen[name] {
+			return name
+		}
+		seen[name] = true
+	}
+	return ""
+}
+
+func (p *ValidateUpgradeGraphCheck) Name() string {
+	return "ValidateUpgradeGraph"
+}
+
+func (p *ValidateUpgradeGraphCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Validating the upgrade graph of each channel of the file-based catalog",
+		Level:            check.LevelBest,
+		KnowledgeBaseURL: "https://olm.operatorframework.io/docs/concepts/olm-architecture/operator-catalog/creating-an-update-graph/",
+		CheckURL:         "https://olm.operatorframework.io/docs/reference/file-based-catalogs/#olmchannel",
+	}
+}
+
+func (p *ValidateUpgradeGraphCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check ValidateUpgradeGraph encountered an error. Please review the findings for the channels whose upgrade graph is invalid.",
+		Suggestion: "Make each entry of a channel replace an entry of the channel, fix any invalid skipRange, and make sure each channel has a single head that every entry can be upgraded to through replaces, skips or skipRange.",
+	}
+}
+
+func (p *ValidateUpgradeGraphCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return catalogFiles
+}
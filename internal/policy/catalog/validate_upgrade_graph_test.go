@@ -0,0 +1,69 @@
+package catalog
+
+import (
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+var _ = Describe("ValidateUpgradeGraph", func() {
+	var (
+		validateUpgradeGraph ValidateUpgradeGraphCheck
+		ctx                  context.Context
+		findings             *check.Findings
+	)
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	AssertMetaData(&validateUpgradeGraph)
+
+	It("should pass if each entry can be upgraded to the head", func() {
+		ok, err := validateUpgradeGraph.Validate(ctx, catalogRef(memcachedCatalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+		Expect(findings.List()).To(BeEmpty())
+	})
+
+	It("should pass if an entry is skipped, rather than replaced", func() {
+		catalog := strings.Replace(memcachedCatalog, "    replaces: memcached-operator.v0.0.1\n", "", 1)
+		catalog = strings.Replace(catalog, "    replaces: memcached-operator.v0.0.2\n", "    replaces: memcached-operator.v0.0.2\n    skips:\n      - memcached-operator.v0.0.1\n", 1)
+		ok, err := validateUpgradeGraph.Validate(ctx, catalogRef(catalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	DescribeTable("should fail for an invalid upgrade graph",
+		func(old, replacement string, expected ...string) {
+			ok, err := validateUpgradeGraph.Validate(ctx, catalogRef(strings.Replace(memcachedCatalog, old, replacement, 1)))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(expected))
+		},
+		Entry("a dangling replaces", "replaces: memcached-operator.v0.0.1", "replaces: memcached-operator.v0.0.0",
+			"channel stable of package memcached-operator: memcached-operator.v0.0.2 replaces memcached-operator.v0.0.0, which is not in the channel",
+			"channel stable of package memcached-operator: has more than one head, memcached-operator.v0.0.1, memcached-operator.v0.0.3, so the entries that can only be upgraded to one of them cannot be upgraded to the others"),
+		Entry("an invalid skipRange", `skipRange: ">=0.0.1 <0.0.3"`, `skipRange: "0.0.1 to 0.0.3"`,
+			`channel stable of package memcached-operator: memcached-operator.v0.0.3 has invalid skipRange "0.0.1 to 0.0.3": Could not get version from string: "to"`),
+		Entry("a cycle", "  - name: memcached-operator.v0.0.1\n", "  - name: memcached-operator.v0.0.1\n    replaces: memcached-operator.v0.0.3\n",
+			"channel stable of package memcached-operator: has no head, as its entries replace or skip each other in a cycle"),
+	)
+
+	It("should fail if an entry cannot be upgraded to the head", func() {
+		// v0.0.1 and v0.0.2 replace each other, and neither is on the upgrade path of v0.0.3.
+		catalog := strings.Replace(memcachedCatalog, "    replaces: memcached-operator.v0.0.2\n    skipRange: \">=0.0.1 <0.0.3\"\n", "", 1)
+		catalog = strings.Replace(catalog, "  - name: memcached-operator.v0.0.1\n", "  - name: memcached-operator.v0.0.1\n    replaces: memcached-operator.v0.0.2\n", 1)
+		ok, err := validateUpgradeGraph.Validate(ctx, catalogRef(catalog))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ok).To(BeFalse())
+		Expect(findings.List()).To(Equal([]string{
+			"channel stable of package memcached-operator: memcached-operator.v0.0.1 cannot be upgraded to head memcached-operator.v0.0.3, as no entry on its upgrade path replaces, skips or has a skipRange including it",
+			"channel stable of package memcached-operator: memcached-operator.v0.0.2 cannot be upgraded to head memcached-operator.v0.0.3, as no entry on its upgrade path replaces, skips or has a skipRange including it",
+		}))
+	})
+})
@@ -0,0 +1,140 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// LayerAnalysisFilename is the artifact the full layer analysis is written to.
+const LayerAnalysisFilename = "layer-analysis.json"
+
+// maxDetails is the number of overwritten and duplicated files reported with a threshold
+// they exceed. The artifact lists all of them.
+const maxDetails = 5
+
+var _ check.Check = &HasEfficientLayersCheck{}
+
+// HasEfficientLayersCheck analyzes the bytes each layer of the image adds, removes and
+// overwrites, the package manager caches left in the image, and the files duplicated across
+// layers, and evaluates them against thresholds. The check is a warning unless exceeding a
+// threshold fails.
+type HasEfficientLayersCheck struct {
+	thresholds layers.Thresholds
+}
+
+// NewHasEfficientLayersCheck returns a check evaluating the layer analysis against thresholds.
+func NewHasEfficientLayersCheck(thresholds layers.Thresholds) *HasEfficientLayersCheck {
+	return &HasEfficientLayersCheck{thresholds: thresholds}
+}
+
+func (p *HasEfficientLayersCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	if imgRef.ImageInfo == nil {
+		return false, fmt.Errorf("image reference invalid")
+	}
+
+	analysis, err := layers.Analyze(ctx, imgRef.ImageInfo)
+	if err != nil {
+		return false, fmt.Errorf("could not analyze image layers: %v", err)
+	}
+
+	if err := p.writeAnalysis(ctx, analysis); err != nil {
+		return false, err
+	}
+
+	return p.validate(ctx, analysis)
+}
+
+// writeAnalysis writes the analysis to the artifacts, if there is an artifact writer.
+func (p *HasEfficientLayersCheck) writeAnalysis(ctx context.Context, analysis *layers.Analysis) error {
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(analysis, "", "    ")
+	if err != nil {
+		//coverage:ignore
+		return fmt.Errorf("could not marshal layer analysis: %w", err)
+	}
+	if _, err := artifactWriter.WriteFile(LayerAnalysisFilename, bytes.NewReader(contents)); err != nil {
+		return fmt.Errorf("could not write layer analysis: %w", err)
+	}
+
+	return nil
+}
+
+func (p *HasEfficientLayersCheck) validate(ctx context.Context, analysis *layers.Analysis) (bool, error) {
+	logr.FromContextOrDiscard(ctx).V(log.DBG).Info("layer analysis",
+		"size", analysis.Size, "wastedBytes", analysis.WastedBytes, "cacheBytes", analysis.CacheBytes, "duplicateBytes", analysis.DuplicateBytes)
+
+	passed := true
+	for _, exceeded := range p.thresholds.Evaluate(analysis) {
+		if action.Failed(exceeded.Action, p.thresholds.Fails()) {
+			passed = false
+		}
+		check.ReportFindings(ctx, exceeded.String())
+		check.ReportFindings(ctx, details(exceeded.Name, analysis)...)
+	}
+
+	return passed, nil
+}
+
+// details returns the files responsible for exceeding the threshold with name.
+func details(name string, analysis *layers.Analysis) []string {
+	var details []string
+	switch name {
+	case layers.ThresholdWasted:
+		for _, f := range analysis.OverwrittenFiles[:min(maxDetails, len(analysis.OverwrittenFiles))] {
+			details = append(details, fmt.Sprintf("%s (%s) in layer %d is overwritten by layer %d", f.Path, layers.FormatBytes(f.Size), f.Layer, f.OverwrittenBy))
+		}
+	case layers.ThresholdCaches:
+		for _, c := range analysis.Caches {
+			details = append(details, fmt.Sprintf("%s has %d files, taking %s", c.Path, c.Files, layers.FormatBytes(c.Bytes)))
+		}
+	case layers.ThresholdDuplicates:
+		for _, d := range analysis.Duplicates[:min(maxDetails, len(analysis.Duplicates))] {
+			copies := make([]string, 0, len(d.Copies))
+			for _, c := range d.Copies {
+				copies = append(copies, fmt.Sprintf("%s in layer %d", c.Path, c.Layer))
+			}
+			details = append(details, fmt.Sprintf("%s of identical contents is added as %v", layers.FormatBytes(d.Size), copies))
+		}
+	}
+	return details
+}
+
+func (p *HasEfficientLayersCheck) Name() string {
+	return "HasEfficientLayers"
+}
+
+func (p *HasEfficientLayersCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image's layers do not waste space on files removed or overwritten by later layers, package manager caches, or files duplicated across layers, as oversized images are slower to pull.",
+		Level:            action.Level(p.thresholds.Fails()),
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasEfficientLayersCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    fmt.Sprintf("Check HasEfficientLayers found layers exceeding their thresholds. Please review the findings, or %s in the artifacts directory, for the full analysis.", LayerAnalysisFilename),
+		Suggestion: "Remove files in the same RUN instruction that creates them, clean package manager caches, e.g. with dnf clean all, and use a multi-stage build to copy only what the image needs.",
+	}
+}
+
+func (p *HasEfficientLayersCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return nil
+}
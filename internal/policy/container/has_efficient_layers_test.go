@@ -0,0 +1,118 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
+)
+
+var _ = Describe("HasEfficientLayers", func() {
+	var (
+		ctx          context.Context
+		findings     *check.Findings
+		imgRef       image.ImageReference
+		thresholds   layers.Thresholds
+		artifactsMap *artifacts.MapWriter
+	)
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+		var err error
+		artifactsMap, err = artifacts.NewMapWriter()
+		Expect(err).ToNot(HaveOccurred())
+		ctx = artifacts.ContextWithWriter(ctx, artifactsMap)
+
+		img, err := mutate.AppendLayers(empty.Image,
+			secretsLayer(map[string]string{
+				"opt/app/app.tgz":         strings.Repeat("a", 100),
+				"var/cache/dnf/repo.solv": strings.Repeat("b", 30),
+			}),
+			secretsLayer(map[string]string{"opt/app/.wh.app.tgz": ""}),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		imgRef = image.ImageReference{ImageInfo: img}
+
+		thresholds = layers.DefaultThresholds()
+		thresholds[layers.ThresholdWasted] = layers.Threshold{Limit: 50, Action: action.Warn}
+	})
+
+	AssertMetaData(NewHasEfficientLayersCheck(layers.DefaultThresholds()))
+
+	Context("When no threshold is exceeded", func() {
+		It("should pass, and write the analysis to the artifacts", func() {
+			ok, err := NewHasEfficientLayersCheck(layers.DefaultThresholds()).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+
+			Expect(artifactsMap.Files()).To(HaveKey(LayerAnalysisFilename))
+			contents, err := io.ReadAll(artifactsMap.Files()[LayerAnalysisFilename])
+			Expect(err).ToNot(HaveOccurred())
+			var analysis layers.Analysis
+			Expect(json.Unmarshal(contents, &analysis)).To(Succeed())
+			Expect(analysis.Layers).To(HaveLen(2))
+			Expect(analysis.WastedBytes).To(BeEquivalentTo(100))
+			Expect(analysis.CacheBytes).To(BeEquivalentTo(30))
+		})
+	})
+
+	Context("When a threshold that warns is exceeded", func() {
+		It("should fail at the warn level, and report what exceeded it", func() {
+			hasEfficientLayers := NewHasEfficientLayersCheck(thresholds)
+			Expect(hasEfficientLayers.Metadata().Level).To(Equal(check.LevelWarn))
+
+			ok, err := hasEfficientLayers.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"100 B of files are removed or overwritten by later layers, over the warn limit of 50 B",
+			))
+		})
+	})
+
+	Context("When a threshold that fails is set", func() {
+		BeforeEach(func() {
+			thresholds[layers.ThresholdCaches] = layers.Threshold{Limit: 10, Action: action.Fail}
+		})
+
+		It("should fail if it is exceeded", func() {
+			hasEfficientLayers := NewHasEfficientLayersCheck(thresholds)
+			Expect(hasEfficientLayers.Metadata().Level).To(Equal(check.LevelBest))
+
+			ok, err := hasEfficientLayers.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ContainElements(
+				"package manager caches take 30 B, over the fail limit of 10 B",
+				"/var/cache/dnf has 1 files, taking 30 B",
+			))
+		})
+
+		It("should pass, and still report the thresholds that warn, if it is not exceeded", func() {
+			thresholds[layers.ThresholdCaches] = layers.Threshold{Limit: 100, Action: action.Fail}
+			ok, err := NewHasEfficientLayersCheck(thresholds).Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(ConsistOf(HavePrefix("100 B of files are removed or overwritten")))
+		})
+	})
+
+	Context("When the image reference is invalid", func() {
+		It("should return an error", func() {
+			_, err := NewHasEfficientLayersCheck(thresholds).Validate(ctx, image.ImageReference{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,248 @@
+package container
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+// capabilityXattr is the PAX record holding a file's capabilities.
+const capabilityXattr = "SCHILY.xattr.security.capability"
+
+// DefaultAllowedFileCapabilities are the files that may carry capabilities, as the packages
+// that install them set them.
+var DefaultAllowedFileCapabilities = []string{
+	"usr/bin/arping",
+	"usr/bin/clockdiff",
+	"usr/bin/newgidmap",
+	"usr/bin/newuidmap",
+	"usr/bin/ping",
+	"usr/sbin/arping",
+	"usr/sbin/clockdiff",
+	"usr/sbin/ping",
+}
+
+var _ check.Check = &HasNoRiskyFilePermissionsCheck{}
+
+// HasNoRiskyFilePermissionsCheck evaluates the flattened filesystem of the image for
+// setuid and setgid files not installed by a package, world-writable directories without
+// the sticky bit, and files carrying capabilities that are not allowed. The restricted SCC
+// prevents these permissions from taking effect, and where they do, they are a risk.
+type HasNoRiskyFilePermissionsCheck struct {
+	getPackageList      packageListFunc
+	allowedCapabilities []string
+}
+
+// NewHasNoRiskyFilePermissionsCheck returns a check that allows files matching the globs in
+// allowedCapabilities, in addition to DefaultAllowedFileCapabilities, to carry capabilities.
+func NewHasNoRiskyFilePermissionsCheck(allowedCapabilities ...string) (*HasNoRiskyFilePermissionsCheck, error) {
+	for _, pattern := range allowedCapabilities {
+		if !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid allowed file capabilities pattern %q", pattern)
+		}
+	}
+
+	return &HasNoRiskyFilePermissionsCheck{
+		getPackageList:      rpm.GetPackageList,
+		allowedCapabilities: append(slices.Clone(DefaultAllowedFileCapabilities), allowedCapabilities...),
+	}, nil
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	owned, err := p.packageFiles(ctx, imgRef.ImageFSPath)
+	if err != nil {
+		return false, fmt.Errorf("could not get the files installed by packages: %v", err)
+	}
+
+	findings, err := p.getDataToValidate(ctx, imgRef, owned)
+	if err != nil {
+		return false, fmt.Errorf("could not read image filesystem: %v", err)
+	}
+
+	return p.validate(ctx, findings)
+}
+
+// packageFiles returns the files installed by the image's packages. It is empty if the image
+// has no rpm database.
+func (p *HasNoRiskyFilePermissionsCheck) packageFiles(ctx context.Context, dir string) (map[string]struct{}, error) {
+	owned := map[string]struct{}{}
+
+	pkgList, err := p.getPackageList(ctx, dir)
+	if err != nil {
+		logr.FromContextOrDiscard(ctx).Info("could not get rpm list, so no setuid or setgid files are treated as installed by a package", "reason", err.Error())
+		return owned, nil
+	}
+
+	for _, pkg := range pkgList {
+		files, err := pkg.InstalledFiles()
+		if err != nil {
+			return nil, fmt.Errorf("could not list files of package %s: %w", pkg.Name, err)
+		}
+		for _, file := range files {
+			owned[normalize(file.Path)] = struct{}{}
+		}
+	}
+
+	return owned, nil
+}
+
+// getDataToValidate walks the flattened filesystem of the image, and returns a finding for
+// each file with risky permissions.
+func (p *HasNoRiskyFilePermissionsCheck) getDataToValidate(ctx context.Context, imgRef image.ImageReference, owned map[string]struct{}) ([]string, error) {
+	if imgRef.ImageInfo == nil {
+		return nil, errors.New("image reference invalid")
+	}
+
+	flattened := mutate.Extract(imgRef.ImageInfo)
+	defer flattened.Close()
+
+	var findings []string
+	tr := tar.NewReader(flattened)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		findings = append(findings, p.inspect(header, owned)...)
+	}
+}
+
+// inspect returns the findings for a single file.
+func (p *HasNoRiskyFilePermissionsCheck) inspect(header *tar.Header, owned map[string]struct{}) []string {
+	name := normalize(header.Name)
+	mode := header.FileInfo().Mode()
+
+	var findings []string
+	switch header.Typeflag {
+	case tar.TypeReg:
+		if _, found := owned[name]; !found {
+			if mode&fs.ModeSetuid != 0 {
+				findings = append(findings, fmt.Sprintf("setuid file /%s, owned by uid %d, is not installed by a package", name, header.Uid))
+			}
+			if mode&fs.ModeSetgid != 0 {
+				findings = append(findings, fmt.Sprintf("setgid file /%s, owned by gid %d, is not installed by a package", name, header.Gid))
+			}
+		}
+	case tar.TypeDir:
+		if mode&0o002 != 0 && mode&fs.ModeSticky == 0 {
+			findings = append(findings, fmt.Sprintf("directory /%s is world-writable without the sticky bit", name))
+		}
+	}
+
+	if raw, found := header.PAXRecords[capabilityXattr]; found && !p.capabilitiesAllowed(name) {
+		findings = append(findings, fmt.Sprintf("file /%s has capabilities %s", name, fileCapabilities([]byte(raw))))
+	}
+
+	return findings
+}
+
+// capabilitiesAllowed is true if the file at name may carry capabilities.
+func (p *HasNoRiskyFilePermissionsCheck) capabilitiesAllowed(name string) bool {
+	for _, pattern := range p.allowedCapabilities {
+		if ok, _ := doublestar.Match(strings.TrimPrefix(pattern, "/"), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilityNames are the names of capabilities, indexed by their number.
+var capabilityNames = []string{
+	"cap_chown", "cap_dac_override", "cap_dac_read_search", "cap_fowner", "cap_fsetid",
+	"cap_kill", "cap_setgid", "cap_setuid", "cap_setpcap", "cap_linux_immutable",
+	"cap_net_bind_service", "cap_net_broadcast", "cap_net_admin", "cap_net_raw", "cap_ipc_lock",
+	"cap_ipc_owner", "cap_sys_module", "cap_sys_rawio", "cap_sys_chroot", "cap_sys_ptrace",
+	"cap_sys_pacct", "cap_sys_admin", "cap_sys_boot", "cap_sys_nice", "cap_sys_resource",
+	"cap_sys_time", "cap_sys_tty_config", "cap_mknod", "cap_lease", "cap_audit_write",
+	"cap_audit_control", "cap_setfcap", "cap_mac_override", "cap_mac_admin", "cap_syslog",
+	"cap_wake_alarm", "cap_block_suspend", "cap_audit_read", "cap_perfmon", "cap_bpf",
+	"cap_checkpoint_restore",
+}
+
+// fileCapabilities returns the permitted capabilities in raw, a security.capability xattr,
+// in the form getcap prints them, e.g. cap_net_bind_service=ep.
+func fileCapabilities(raw []byte) string {
+	// the xattr is a vfs_cap_data: a magic number, holding the revision and flags, followed
+	// by the permitted and inheritable sets, split into 32 bit halves.
+	if len(raw) < 12 {
+		return "(unknown)"
+	}
+	magic := binary.LittleEndian.Uint32(raw[0:4])
+	permitted := uint64(binary.LittleEndian.Uint32(raw[4:8]))
+	if magic&0xff000000 != 0x01000000 && len(raw) >= 20 {
+		permitted |= uint64(binary.LittleEndian.Uint32(raw[12:16])) << 32
+	}
+
+	var names []string
+	for i, name := range capabilityNames {
+		if permitted&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "(none)"
+	}
+
+	flags := "p"
+	if magic&0x1 != 0 {
+		flags = "ep"
+	}
+	return strings.Join(names, ",") + "=" + flags
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) validate(ctx context.Context, findings []string) (bool, error) {
+	if len(findings) == 0 {
+		return true, nil
+	}
+
+	logr.FromContextOrDiscard(ctx).V(log.DBG).Info("risky file permissions found", "fileCount", len(findings), "files", findings)
+	check.ReportFindings(ctx, findings...)
+
+	return false, nil
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) Name() string {
+	return "HasNoRiskyFilePermissions"
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image has no setuid or setgid files that are not installed by a package, no world-writable directories without the sticky bit, and no files with capabilities that are not allowed.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoRiskyFilePermissions found files with risky permissions. Please review the findings, or the preflight.log file, for each file.",
+		Suggestion: "Remove the setuid and setgid bits, and capabilities, from files, e.g. with chmod u-s,g-s and setcap -r, as the restricted SCC does not honor them. Set the sticky bit on world-writable directories, or make them group-writable by the root group instead.",
+	}
+}
+
+func (p *HasNoRiskyFilePermissionsCheck) RequiredFilePatterns() []string {
+	return rpm.RpmdbPaths
+}
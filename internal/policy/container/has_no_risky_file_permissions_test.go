@@ -0,0 +1,146 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// headersLayer returns a layer containing an empty entry for each of headers.
+func headersLayer(headers ...*tar.Header) cranev1.Layer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range headers {
+		Expect(tw.WriteHeader(header)).To(Succeed())
+	}
+	Expect(tw.Close()).To(Succeed())
+	return static.NewLayer(buf.Bytes(), types.DockerLayer)
+}
+
+// capabilities returns a revision 2 security.capability xattr, with the permitted set caps,
+// that is effective.
+func capabilities(caps uint32) map[string]string {
+	raw := make([]byte, 20)
+	binary.LittleEndian.PutUint32(raw[0:4], 0x02000001)
+	binary.LittleEndian.PutUint32(raw[4:8], caps)
+	return map[string]string{capabilityXattr: string(raw)}
+}
+
+var _ = Describe("HasNoRiskyFilePermissions", func() {
+	var (
+		hasNoRiskyFilePermissions *HasNoRiskyFilePermissionsCheck
+		ctx                       context.Context
+		findings                  *check.Findings
+	)
+
+	BeforeEach(func() {
+		var err error
+		hasNoRiskyFilePermissions, err = NewHasNoRiskyFilePermissionsCheck("opt/app/bin/*")
+		Expect(err).ToNot(HaveOccurred())
+		hasNoRiskyFilePermissions.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+			return []*rpmdb.PackageInfo{{
+				Name:       "sudo",
+				BaseNames:  []string{"sudo"},
+				DirIndexes: []int32{0},
+				DirNames:   []string{"/usr/bin/"},
+			}}, nil
+		}
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	validate := func(layers ...cranev1.Layer) (bool, error) {
+		img, err := mutate.AppendLayers(empty.Image, layers...)
+		Expect(err).ToNot(HaveOccurred())
+		return hasNoRiskyFilePermissions.Validate(ctx, image.ImageReference{ImageInfo: img, ImageFSPath: GinkgoT().TempDir()})
+	}
+
+	AssertMetaData(&HasNoRiskyFilePermissionsCheck{})
+
+	Context("When the image has no risky file permissions", func() {
+		It("should pass", func() {
+			ok, err := validate(headersLayer(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "tmp/", Mode: 0o1777},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/app/run.sh", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/bin/sudo", Mode: 0o4111},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/bin/ping", Mode: 0o755, Format: tar.FormatPAX, PAXRecords: capabilities(1 << 13)},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/app/bin/server", Mode: 0o755, Format: tar.FormatPAX, PAXRecords: capabilities(1 << 10)},
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+	})
+
+	Context("When the image has risky file permissions", func() {
+		It("should fail, and report each file", func() {
+			ok, err := validate(headersLayer(
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/helper", Mode: 0o4755, Uid: 0},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/mailer", Mode: 0o2755, Gid: 12},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/data/", Mode: 0o777},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/server", Mode: 0o755, Format: tar.FormatPAX, PAXRecords: capabilities(1<<10 | 1<<12)},
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"setuid file /usr/local/bin/helper, owned by uid 0, is not installed by a package",
+				"setgid file /usr/local/bin/mailer, owned by gid 12, is not installed by a package",
+				"directory /opt/app/data is world-writable without the sticky bit",
+				"file /usr/local/bin/server has capabilities cap_net_bind_service,cap_net_admin=ep",
+			))
+		})
+
+		It("should only report the files in the flattened filesystem", func() {
+			ok, err := validate(
+				headersLayer(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/helper", Mode: 0o4755}),
+				headersLayer(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/helper", Mode: 0o755}),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the image has no rpm database", func() {
+		It("should treat every setuid file as not installed by a package", func() {
+			hasNoRiskyFilePermissions.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+				return nil, errors.New("could not find rpm db/packages")
+			}
+
+			ok, err := validate(headersLayer(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/bin/sudo", Mode: 0o4111}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(ContainSubstring("setuid file /usr/bin/sudo")))
+		})
+	})
+
+	Context("When an allowed file capabilities pattern is invalid", func() {
+		It("should return an error", func() {
+			_, err := NewHasNoRiskyFilePermissionsCheck("[a")
+			Expect(err).To(MatchError(ContainSubstring(`invalid allowed file capabilities pattern "[a"`)))
+		})
+	})
+
+	DescribeTable("Capabilities should be printed as getcap prints them",
+		func(raw []byte, expected string) {
+			Expect(fileCapabilities(raw)).To(Equal(expected))
+		},
+		Entry("revision 1", []byte{0x01, 0, 0, 0x01, 0, 0x04, 0, 0, 0, 0, 0, 0}, "cap_net_bind_service=ep"),
+		Entry("revision 2, not effective", []byte{0, 0, 0, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0x80, 0, 0, 0, 0, 0, 0, 0}, "cap_bpf=p"),
+		Entry("no capabilities", make([]byte, 12), "(none)"),
+		Entry("truncated", []byte{0x01}, "(unknown)"),
+	)
+})
@@ -0,0 +1,248 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// opaqueWhiteout marks a directory whose contents in earlier layers are removed.
+const opaqueWhiteout = whiteoutPrefix + whiteoutPrefix + ".opq"
+
+var _ check.Check = &HasNoSecretsCheck{}
+
+// HasNoSecretsCheck scans the contents of every layer of the image for secrets, such as
+// private keys and credentials, including those in files removed by later layers, as they
+// can still be read from the layer that added them.
+type HasNoSecretsCheck struct {
+	ruleset *SecretRuleset
+}
+
+// NewHasNoSecretsCheck returns a check scanning for the secrets described by ruleset.
+func NewHasNoSecretsCheck(ruleset *SecretRuleset) *HasNoSecretsCheck {
+	return &HasNoSecretsCheck{ruleset: ruleset}
+}
+
+// secretFinding is a secret found in a layer.
+type secretFinding struct {
+	layer   string
+	index   int
+	path    string
+	line    int
+	rule    *SecretRule
+	removed bool
+}
+
+func (f secretFinding) String() string {
+	location := "/" + f.path
+	if f.line > 0 {
+		location = fmt.Sprintf("%s line %d", location, f.line)
+	}
+
+	s := fmt.Sprintf("%s (%s) in %s of layer %s", f.rule.Description, f.rule.ID, location, f.layer)
+	if f.removed {
+		s += ", removed by a later layer"
+	}
+	return s
+}
+
+// layerWhiteouts are the paths removed by a layer.
+type layerWhiteouts struct {
+	// files are removed, along with their contents if they are directories.
+	files map[string]struct{}
+	// dirs have their contents removed.
+	dirs map[string]struct{}
+}
+
+func (p *HasNoSecretsCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	layers, err := imgRef.ImageInfo.Layers()
+	if err != nil {
+		return false, fmt.Errorf("could not get image layers: %v", err)
+	}
+
+	findings, err := p.scan(ctx, layers)
+	if err != nil {
+		return false, err
+	}
+
+	return p.validate(ctx, findings)
+}
+
+// scan returns the secrets found in layers.
+func (p *HasNoSecretsCheck) scan(ctx context.Context, layers []cranev1.Layer) ([]secretFinding, error) {
+	var findings []secretFinding
+	whiteouts := make([]layerWhiteouts, len(layers))
+	for i, layer := range layers {
+		digest, err := layer.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("could not get layer digest: %v", err)
+		}
+
+		layerFindings, layerWhiteouts, err := p.scanLayer(ctx, layer)
+		if err != nil {
+			return nil, fmt.Errorf("could not scan layer %s: %w", digest, err)
+		}
+		for _, f := range layerFindings {
+			f.layer = digest.String()
+			f.index = i
+			findings = append(findings, f)
+		}
+		whiteouts[i] = layerWhiteouts
+	}
+
+	for i := range findings {
+		findings[i].removed = removedAfter(whiteouts, findings[i].index, findings[i].path)
+	}
+
+	return findings, nil
+}
+
+// scanLayer returns the secrets found in layer, and the paths it removes.
+func (p *HasNoSecretsCheck) scanLayer(ctx context.Context, layer cranev1.Layer) ([]secretFinding, layerWhiteouts, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+	whiteouts := layerWhiteouts{files: map[string]struct{}{}, dirs: map[string]struct{}{}}
+
+	layerReader, err := layer.Uncompressed()
+	if err != nil {
+		//coverage:ignore
+		return nil, whiteouts, fmt.Errorf("reading layer contents: %w", err)
+	}
+	defer layerReader.Close()
+
+	var findings []secretFinding
+	tarReader := tar.NewReader(layerReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, whiteouts, fmt.Errorf("reading layer contents: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, whiteouts, err
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case base == opaqueWhiteout:
+			whiteouts.dirs[dir] = struct{}{}
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			whiteouts.files[path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))] = struct{}{}
+			continue
+		}
+
+		if header.Typeflag != tar.TypeReg || p.ruleset.allowed(name) {
+			continue
+		}
+
+		contents, err := p.readContents(tarReader, header.Size)
+		if err != nil {
+			return nil, whiteouts, fmt.Errorf("reading %s: %w", name, err)
+		}
+		if contents == nil {
+			logger.V(log.TRC).Info("not scanning contents of large or binary file", "path", name, "size", header.Size)
+		}
+
+		for _, m := range p.ruleset.match(name, contents) {
+			findings = append(findings, secretFinding{path: name, line: m.line, rule: m.rule})
+		}
+	}
+
+	return findings, whiteouts, nil
+}
+
+// readContents returns the contents of the current file, or nil if it is larger than the
+// maximum size or is binary.
+func (p *HasNoSecretsCheck) readContents(r io.Reader, size int64) ([]byte, error) {
+	if size > p.ruleset.MaxFileSize {
+		return nil, nil
+	}
+
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// files with a NUL byte near their start are treated as binary, as git does.
+	if bytes.IndexByte(contents[:min(len(contents), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	return contents, nil
+}
+
+// removedAfter is true if a layer after the one at index removes the file at name.
+func removedAfter(whiteouts []layerWhiteouts, index int, name string) bool {
+	for _, w := range whiteouts[index+1:] {
+		for p := name; p != "." && p != ""; p = path.Dir(p) {
+			if _, ok := w.files[p]; ok {
+				return true
+			}
+			if p != name {
+				if _, ok := w.dirs[p]; ok {
+					return true
+				}
+			}
+		}
+		if _, ok := w.dirs[""]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *HasNoSecretsCheck) validate(ctx context.Context, findings []secretFinding) (bool, error) {
+	if len(findings) == 0 {
+		return true, nil
+	}
+
+	reported := make([]string, 0, len(findings))
+	for _, f := range findings {
+		reported = append(reported, f.String())
+	}
+	logr.FromContextOrDiscard(ctx).V(log.DBG).Info("secrets found", "secretCount", len(findings), "secrets", reported)
+	check.ReportFindings(ctx, reported...)
+
+	return false, nil
+}
+
+func (p *HasNoSecretsCheck) Name() string {
+	return "HasNoSecrets"
+}
+
+func (p *HasNoSecretsCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that no layer of the image contains secrets, such as private keys, cloud credentials, or registry credentials, including in files removed by later layers.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: certDocumentationURL,
+		CheckURL:         certDocumentationURL,
+	}
+}
+
+func (p *HasNoSecretsCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check HasNoSecrets found secrets in the image's layers. Please review the findings, or the preflight.log file, for the layer and path of each.",
+		Suggestion: "Remove secrets from the image. Deleting a file in a later layer does not remove it from the layer that added it, so use build secrets or a multi-stage build instead. Rotate any secret that was published.",
+	}
+}
+
+func (p *HasNoSecretsCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return nil
+}
@@ -2,9 +2,10 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
-	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -14,6 +15,9 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 )
 
 var _ check.Check = &hasUniqueTagCheck{}
@@ -62,11 +66,42 @@ func (p *hasUniqueTagCheck) Validate(ctx context.Context, imgRef image.ImageRefe
 }
 
 func (p *hasUniqueTagCheck) getDataToValidate(ctx context.Context, image string) ([]string, error) {
-	repo, err := name.NewRepository(image)
+	logger := logr.FromContextOrDiscard(ctx)
+
+	registriesConfig := registries.FromContext(ctx)
+	sources, err := registriesConfig.Sources(image)
+	if err != nil {
+		return nil, err
+	}
+
+	// list the tags from the first of the repository's mirrors, or its own registry, that responds.
+	var errs []error
+	for _, source := range sources {
+		tags, err := p.listTags(ctx, source, registriesConfig)
+		if err == nil {
+			return tags, nil
+		}
+
+		logger.V(log.DBG).Info("unable to list tags", "source", source.Reference, "reason", err.Error())
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func (p *hasUniqueTagCheck) listTags(ctx context.Context, source registries.Source, registriesConfig *registries.Config) ([]string, error) {
+	var nameOptions []name.Option
+	if source.Insecure {
+		nameOptions = append(nameOptions, name.Insecure)
+	}
+
+	ref, err := name.ParseReference(source.Reference, nameOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse image name: %v", err)
 	}
+	repo := ref.Context()
 
+	policy := network.FromContext(ctx)
 	options := []remote.Option{
 		remote.WithContext(ctx),
 		remote.WithAuthFromKeychain(authn.PreflightKeychain(ctx, authn.WithDockerConfig(p.dockercfg))),
@@ -74,14 +109,9 @@ func (p *hasUniqueTagCheck) getDataToValidate(ctx context.Context, image string)
 		// A smaller query is fine for evaluating the HasUniqueTag check
 		// https://github.com/redhat-openshift-ecosystem/openshift-preflight/pull/1268#discussion_r2085387116
 		remote.WithPageSize(10),
-
-		remote.WithRetryBackoff(remote.Backoff{
-			Duration: 5 * time.Second,
-			Factor:   1.0,
-			Jitter:   0.1,
-			Steps:    2,
-		}),
 	}
+	rt := registriesConfig.Transport(policy.HTTPTransport(remote.DefaultTransport.(*http.Transport)))
+	options = append(options, option.RemoteOptions(policy, rt)...)
 
 	puller, err := remote.NewPuller(options...)
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 )
 
 var _ = Describe("UniqueTag", func() {
@@ -121,6 +122,20 @@ var _ = Describe("UniqueTag", func() {
 			})
 		})
 
+		Context("When the registry is mirrored", func() {
+			It("should list the tags from the mirror", func() {
+				ctx := registries.ContextWithConfig(context.TODO(), &registries.Config{
+					Registries: []registries.Registry{{
+						Prefix:  "registry.example.invalid/test",
+						Mirrors: []registries.Mirror{{Location: host + "/test", Insecure: true}},
+					}},
+				})
+				ok, err := hasUniqueTagCheck.Validate(ctx, image.ImageReference{ImageRegistry: "registry.example.invalid", ImageRepository: "test/tags", ImageTagOrSha: "sha256:12345"})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			})
+		})
+
 		Context("When the image name is invalid", func() {
 			It("should return a parse error", func() {
 				ok, err := hasUniqueTagCheck.Validate(context.TODO(), image.ImageReference{ImageRegistry: "INVALID@@@", ImageRepository: "!!!bad", ImageTagOrSha: "sha256:12345"})
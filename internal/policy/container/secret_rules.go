@@ -0,0 +1,268 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultSecretMaxFileSize is the size of the largest file whose contents are scanned for
+// secrets.
+const DefaultSecretMaxFileSize = 1 << 20
+
+// SecretRule describes a secret. A file contains the secret if its path matches Path and
+// its contents match Content, of which at least one is set.
+type SecretRule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	// Path is a glob, e.g. **/.docker/config.json, matched against the file's path without
+	// its leading slash.
+	Path string `json:"path,omitempty"`
+	// Content is a regular expression matched against the file's contents.
+	Content string `json:"content,omitempty"`
+	// Entropy, if set, is the minimum Shannon entropy, in bits per character, of the
+	// content's first capture group, or its entire match if it has none. It is used to
+	// tell secrets from placeholders, e.g. changeme.
+	Entropy float64 `json:"entropy,omitempty"`
+
+	content *regexp.Regexp
+}
+
+// SecretRuleset is the rules a secret scan applies.
+type SecretRuleset struct {
+	// Rules are added to the default rules.
+	Rules []SecretRule `json:"rules,omitempty"`
+	// Disable lists the IDs of default rules that are not applied.
+	Disable []string `json:"disable,omitempty"`
+	// Allow lists globs of paths that are not scanned, e.g. usr/share/doc/**.
+	Allow []string `json:"allow,omitempty"`
+	// MaxFileSize is the size of the largest file whose contents are scanned. It is
+	// DefaultSecretMaxFileSize if not set.
+	MaxFileSize int64 `json:"max_file_size,omitempty"`
+}
+
+// defaultSecretRules are the secrets scanned for unless disabled.
+var defaultSecretRules = []SecretRule{
+	{
+		ID:          "private-key",
+		Description: "private key",
+		Content:     `-----BEGIN ((RSA|DSA|EC|OPENSSH|ENCRYPTED|PGP) )?PRIVATE KEY( BLOCK)?-----`,
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Content:     `\b((AKIA|ASIA)[0-9A-Z]{16})\b`,
+	},
+	{
+		ID:          "aws-credentials",
+		Description: "AWS credentials file",
+		Path:        "**/.aws/credentials",
+		Content:     `(?i)aws_secret_access_key\s*=\s*\S+`,
+	},
+	{
+		ID:          "gcp-service-account",
+		Description: "Google Cloud service account key",
+		Path:        "**/*.json",
+		Content:     `"type"\s*:\s*"service_account"`,
+	},
+	{
+		ID:          "azure-storage-key",
+		Description: "Azure storage account key",
+		Content:     `AccountKey=[A-Za-z0-9+/=]{40,}`,
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Content:     `\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	},
+	{
+		ID:          "docker-config",
+		Description: "container registry credentials",
+		Path:        "**/{.docker/config.json,.dockercfg,containers/auth.json}",
+		Content:     `"auth"\s*:\s*"[^"]+"`,
+	},
+	{
+		ID:          "kubeconfig",
+		Description: "kubeconfig credentials",
+		Path:        "**/{.kube/config,kubeconfig,*.kubeconfig}",
+		Content:     `(client-key-data|token|password)\s*:\s*\S+`,
+	},
+	{
+		ID:          "npmrc-token",
+		Description: "npm registry token",
+		Path:        "**/.npmrc",
+		Content:     `_(auth|authToken|password)\s*=\s*\S+`,
+	},
+	{
+		ID:          "pypirc-password",
+		Description: "Python package index password",
+		Path:        "**/.pypirc",
+		Content:     `(?m)^\s*password\s*[:=]\s*\S+`,
+	},
+	{
+		ID:          "high-entropy-string",
+		Description: "high entropy secret",
+		Content:     `(?i)(?:secret|token|passw(?:or)?d|api[_-]?key|access[_-]?key)[\w.-]*["']?\s*[:=]\s*["']?([A-Za-z0-9+/=_\-]{20,})`,
+		Entropy:     4.0,
+	},
+}
+
+// LoadSecretRuleset reads the ruleset at path. The default ruleset is returned if path is
+// empty.
+func LoadSecretRuleset(path string) (*SecretRuleset, error) {
+	ruleset := &SecretRuleset{}
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read secret ruleset: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(raw, ruleset); err != nil {
+			return nil, fmt.Errorf("could not decode secret ruleset %s: %w", path, err)
+		}
+	}
+
+	if err := ruleset.compile(); err != nil {
+		return nil, fmt.Errorf("invalid secret ruleset %s: %w", path, err)
+	}
+
+	return ruleset, nil
+}
+
+// compile validates the ruleset, and combines its rules with the default rules that are not
+// disabled.
+func (r *SecretRuleset) compile() error {
+	if r.MaxFileSize == 0 {
+		r.MaxFileSize = DefaultSecretMaxFileSize
+	}
+	for _, id := range r.Disable {
+		if !slices.ContainsFunc(defaultSecretRules, func(rule SecretRule) bool { return rule.ID == id }) {
+			return fmt.Errorf("cannot disable unknown rule %s", id)
+		}
+	}
+	for _, pattern := range r.Allow {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid allow pattern %q", pattern)
+		}
+	}
+
+	rules := make([]SecretRule, 0, len(defaultSecretRules)+len(r.Rules))
+	for _, rule := range defaultSecretRules {
+		if !slices.Contains(r.Disable, rule.ID) {
+			rules = append(rules, rule)
+		}
+	}
+	rules = append(rules, r.Rules...)
+
+	var ids []string
+	for i := range rules {
+		rule := &rules[i]
+		switch {
+		case rule.ID == "":
+			return errors.New("a rule has no id")
+		case slices.Contains(ids, rule.ID):
+			return fmt.Errorf("rule %s is defined more than once", rule.ID)
+		case rule.Path == "" && rule.Content == "":
+			return fmt.Errorf("rule %s has neither a path nor content", rule.ID)
+		case rule.Path != "" && !doublestar.ValidatePattern(rule.Path):
+			return fmt.Errorf("rule %s has an invalid path %q", rule.ID, rule.Path)
+		}
+		ids = append(ids, rule.ID)
+
+		if rule.Content != "" {
+			re, err := regexp.Compile(rule.Content)
+			if err != nil {
+				return fmt.Errorf("rule %s has invalid content: %w", rule.ID, err)
+			}
+			rule.content = re
+		}
+		if rule.Description == "" {
+			rule.Description = rule.ID
+		}
+	}
+	r.Rules = rules
+
+	return nil
+}
+
+// allowed is true if the file at path is not scanned.
+func (r *SecretRuleset) allowed(path string) bool {
+	for _, pattern := range r.Allow {
+		if ok, _ := doublestar.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// secretMatch is a rule that matched a file, and the line it matched.
+type secretMatch struct {
+	rule *SecretRule
+	line int
+}
+
+// match returns the rules that match the file at path with contents. contents is nil if the
+// file was too large, or binary, to be scanned.
+func (r *SecretRuleset) match(path string, contents []byte) []secretMatch {
+	var matches []secretMatch
+	for i := range r.Rules {
+		rule := &r.Rules[i]
+		if rule.Path != "" {
+			if ok, _ := doublestar.Match(rule.Path, path); !ok {
+				continue
+			}
+		}
+		if rule.content == nil {
+			matches = append(matches, secretMatch{rule: rule})
+			continue
+		}
+		if contents == nil {
+			continue
+		}
+
+		for _, loc := range rule.content.FindAllSubmatchIndex(contents, -1) {
+			secret := contents[loc[0]:loc[1]]
+			if len(loc) > 2 && loc[2] >= 0 {
+				secret = contents[loc[2]:loc[3]]
+			}
+			if rule.Entropy > 0 && entropy(secret) < rule.Entropy {
+				continue
+			}
+
+			line := 1
+			for _, b := range contents[:loc[0]] {
+				if b == '\n' {
+					line++
+				}
+			}
+			matches = append(matches, secretMatch{rule: rule, line: line})
+			break
+		}
+	}
+
+	return matches
+}
+
+// entropy returns the Shannon entropy of s, in bits per character.
+func entropy(s []byte) float64 {
+	if len(s) == 0 {
+		return 0
+	}
+
+	counts := map[byte]int{}
+	for _, b := range s {
+		counts[b]++
+	}
+
+	var bits float64
+	for _, count := range counts {
+		p := float64(count) / float64(len(s))
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
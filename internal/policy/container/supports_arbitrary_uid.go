@@ -0,0 +1,404 @@
+package container
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// systemDirs hold the image's software, rather than its data, so directories within them that
+// are referenced by environment variables, e.g. JAVA_HOME, need not be writable.
+var systemDirs = []string{"bin", "boot", "dev", "etc", "lib", "lib64", "proc", "sbin", "sys", "usr"}
+
+var _ check.Check = &SupportsArbitraryUIDCheck{}
+
+// SupportsArbitraryUIDCheck evaluates that the image can run as the arbitrary UID, in the root
+// group, that OpenShift assigns to containers. The directories the image needs to write to,
+// its WORKDIR, its user's HOME, its VOLUMEs, and those referenced by its environment, must
+// be owned by the root group and group-writable, or be creatable in a directory that is. As an
+// arbitrary UID has no entry in /etc/passwd, an entrypoint that adds one requires /etc/passwd
+// to be group-writable, unless the image uses nss_wrapper to provide it.
+type SupportsArbitraryUIDCheck struct{}
+
+// passwdPath is where the image's users are defined.
+const passwdPath = "/etc/passwd"
+
+// maxScriptSize is the most of an entrypoint script that is read.
+const maxScriptSize = 1 << 20
+
+// nssWrapperLibrary is the prefix of the name of the nss_wrapper library, which provides an
+// entry in a passwd file of its own for a user that is not in /etc/passwd.
+const nssWrapperLibrary = "libnss_wrapper.so"
+
+// writablePath is a directory the image needs to write to, and why.
+type writablePath struct {
+	reason string
+	path   string
+	// ifDir is true if the path is only written to if it is a directory, as the value of an
+	// environment variable may instead name a file.
+	ifDir bool
+}
+
+// passwdEntry is a user in /etc/passwd.
+type passwdEntry struct {
+	name string
+	uid  string
+	home string
+}
+
+// fileEntry is the ownership and mode of a file in the flattened filesystem.
+type fileEntry struct {
+	typeflag byte
+	uid, gid int
+	mode     fs.FileMode
+	linkname string
+}
+
+func (p *SupportsArbitraryUIDCheck) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+	if imgRef.ImageInfo == nil {
+		return false, errors.New("image reference invalid")
+	}
+
+	configFile, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve ConfigFile from Image: %w", err)
+	}
+
+	users, err := p.readPasswd(filepath.Join(imgRef.ImageFSPath, "etc", "passwd"))
+	if err != nil {
+		return false, fmt.Errorf("could not read /etc/passwd: %w", err)
+	}
+
+	paths, findings := p.writablePaths(configFile.Config, users)
+
+	// an inline entrypoint, e.g. sh -c, is checked for /etc/passwd here, and a script when it is read.
+	args := slices.Concat(configFile.Config.Entrypoint, configFile.Config.Cmd)
+	inline := slices.ContainsFunc(args, func(arg string) bool { return strings.Contains(arg, passwdPath) })
+	scripts := entrypointPaths(args, configFile.Config.Env)
+
+	entries, addsEntry, err := p.getDataToValidate(ctx, imgRef.ImageInfo, paths, scripts, users != nil)
+	if err != nil {
+		return false, fmt.Errorf("could not read image filesystem: %v", err)
+	}
+
+	if finding, ok := p.passwdFinding(configFile.Config.Env, entries); ok && (inline || addsEntry) {
+		findings = append(findings, finding)
+	}
+
+	return p.validate(ctx, paths, entries, findings)
+}
+
+// readPasswd returns the users in the passwd file at name. It returns no users if the image
+// has no /etc/passwd.
+func (p *SupportsArbitraryUIDCheck) readPasswd(name string) ([]passwdEntry, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var users []passwdEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 7 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		users = append(users, passwdEntry{name: fields[0], uid: fields[2], home: fields[5]})
+	}
+
+	return users, scanner.Err()
+}
+
+// entrypointPaths returns the paths of the files the container may run with args: each absolute
+// argument, and the first, looked up in the PATH in env if it is only a name.
+func entrypointPaths(args []string, env []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	var paths []string
+	for _, arg := range args {
+		if path.IsAbs(arg) {
+			paths = append(paths, path.Clean(arg))
+		}
+	}
+	if strings.Contains(args[0], "/") {
+		return paths
+	}
+	for _, e := range env {
+		if name, value, _ := strings.Cut(e, "="); name == "PATH" {
+			for _, dir := range strings.Split(value, ":") {
+				if path.IsAbs(dir) {
+					paths = append(paths, path.Join(dir, args[0]))
+				}
+			}
+		}
+	}
+	return paths
+}
+
+// writablePaths returns the directories the image, configured by config, needs to write to,
+// along with findings for configuration that cannot work with an arbitrary UID.
+func (p *SupportsArbitraryUIDCheck) writablePaths(config cranev1.Config, users []passwdEntry) ([]writablePath, []string) {
+	var paths []writablePath
+	var findings []string
+	add := func(reason, name string, ifDir bool) {
+		name = path.Clean(name)
+		if name == "/" || slices.ContainsFunc(paths, func(p writablePath) bool { return p.path == name }) {
+			return
+		}
+		paths = append(paths, writablePath{reason: reason, path: name, ifDir: ifDir})
+	}
+
+	if config.WorkingDir != "" {
+		add("WORKDIR", config.WorkingDir, false)
+	}
+
+	volumes := make([]string, 0, len(config.Volumes))
+	for volume := range config.Volumes {
+		volumes = append(volumes, volume)
+	}
+	slices.Sort(volumes)
+	for _, volume := range volumes {
+		add("VOLUME", volume, false)
+	}
+
+	user, _, _ := strings.Cut(config.User, ":")
+
+	home := ""
+	for _, env := range config.Env {
+		name, value, _ := strings.Cut(env, "=")
+		if name == "HOME" {
+			home = value
+			continue
+		}
+		if name == "PATH" || !strings.HasPrefix(value, "/") || strings.ContainsAny(value, ": ") {
+			continue
+		}
+		if dir, _, _ := strings.Cut(strings.TrimPrefix(path.Clean(value), "/"), "/"); slices.Contains(systemDirs, dir) {
+			continue
+		}
+		add("ENV "+name, value, true)
+	}
+
+	if home != "" {
+		add("HOME", home, false)
+	} else if i := slices.IndexFunc(users, func(u passwdEntry) bool { return user != "" && (u.name == user || u.uid == user) }); i >= 0 && users[i].home != "/" {
+		// an arbitrary UID has no entry in /etc/passwd, so its HOME is /.
+		findings = append(findings, fmt.Sprintf("HOME %s of USER %s is only set in /etc/passwd, which has no entry for an arbitrary UID, so HOME will be /; set it with ENV HOME", users[i].home, user))
+		add("HOME", users[i].home, false)
+	}
+
+	return paths, findings
+}
+
+// getDataToValidate walks the flattened filesystem of img, and returns the directories and
+// symbolic links in it, along with the files in paths. If passwd is true, /etc/passwd and the
+// nss_wrapper library are returned as well, along with whether any of the entrypoint scripts
+// refers to /etc/passwd, to add an entry for an arbitrary UID to it.
+func (p *SupportsArbitraryUIDCheck) getDataToValidate(ctx context.Context, img cranev1.Image, paths []writablePath, scripts []string, passwd bool) (map[string]fileEntry, bool, error) {
+	entries := map[string]fileEntry{}
+	if len(paths) == 0 && !passwd {
+		return entries, false, nil
+	}
+
+	flattened := mutate.Extract(img)
+	defer flattened.Close()
+
+	addsEntry := false
+	tr := tar.NewReader(flattened)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return entries, addsEntry, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		name := "/" + normalize(header.Name)
+		if passwd && header.Typeflag == tar.TypeReg && slices.Contains(scripts, name) {
+			refers, err := refersToPasswd(tr)
+			if err != nil {
+				return nil, false, err
+			}
+			addsEntry = addsEntry || refers
+		}
+		passwdFile := passwd && (name == passwdPath || strings.HasPrefix(path.Base(name), nssWrapperLibrary))
+		if header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeSymlink && !passwdFile &&
+			!slices.ContainsFunc(paths, func(p writablePath) bool { return p.path == name }) {
+			continue
+		}
+		entries[name] = fileEntry{
+			typeflag: header.Typeflag,
+			uid:      header.Uid,
+			gid:      header.Gid,
+			mode:     header.FileInfo().Mode(),
+			linkname: header.Linkname,
+		}
+	}
+}
+
+// refersToPasswd is true if r is a script that refers to /etc/passwd. Executables are not
+// read, as those reading /etc/passwd, e.g. to look up a user, refer to it as well.
+func refersToPasswd(r io.Reader) (bool, error) {
+	contents, err := io.ReadAll(io.LimitReader(r, maxScriptSize))
+	if err != nil {
+		return false, err
+	}
+	return bytes.HasPrefix(contents, []byte("#!")) && bytes.Contains(contents, []byte(passwdPath)), nil
+}
+
+// resolve follows the symbolic links in name, returning the path it refers to.
+func resolve(entries map[string]fileEntry, name string) string {
+	for range 40 {
+		parts := strings.Split(strings.TrimPrefix(name, "/"), "/")
+		resolved := true
+		for i := range parts {
+			prefix := "/" + strings.Join(parts[:i+1], "/")
+			entry, found := entries[prefix]
+			if !found || entry.typeflag != tar.TypeSymlink {
+				continue
+			}
+
+			target := entry.linkname
+			if !path.IsAbs(target) {
+				target = path.Join(path.Dir(prefix), target)
+			}
+			name = path.Join(append([]string{target}, parts[i+1:]...)...)
+			resolved = false
+			break
+		}
+		if resolved {
+			return name
+		}
+	}
+	return name
+}
+
+// ancestor returns the closest directory above name that exists in entries.
+func ancestor(entries map[string]fileEntry, name string) (string, fileEntry, bool) {
+	for dir := path.Dir(name); ; dir = path.Dir(dir) {
+		if entry, found := entries[resolve(entries, dir)]; found {
+			return dir, entry, true
+		}
+		if dir == "/" {
+			return "", fileEntry{}, false
+		}
+	}
+}
+
+// passwdFinding returns a finding if an entry for an arbitrary UID can neither be added to the
+// /etc/passwd in entries, nor be provided by nss_wrapper, either configured in env or installed.
+// It is only reported if the entrypoint adds an entry.
+func (p *SupportsArbitraryUIDCheck) passwdFinding(env []string, entries map[string]fileEntry) (string, bool) {
+	passwd, found := entries[resolve(entries, passwdPath)]
+	if !found || (passwd.gid == 0 && passwd.mode&0o020 != 0) {
+		return "", false
+	}
+
+	for _, e := range env {
+		name, value, _ := strings.Cut(e, "=")
+		if name == "NSS_WRAPPER_PASSWD" || (name == "LD_PRELOAD" && strings.Contains(value, nssWrapperLibrary)) {
+			return "", false
+		}
+	}
+	for name, entry := range entries {
+		if entry.typeflag != tar.TypeDir && strings.HasPrefix(path.Base(name), nssWrapperLibrary) {
+			return "", false
+		}
+	}
+
+	return fmt.Sprintf("%s, owned by %d:%d with mode %04o, is not owned by the root group and group-writable, and the image does not use nss_wrapper, so the entrypoint cannot add an entry for an arbitrary UID",
+		passwdPath, passwd.uid, passwd.gid, passwd.mode.Perm()), true
+}
+
+func (p *SupportsArbitraryUIDCheck) validate(ctx context.Context, paths []writablePath, entries map[string]fileEntry, findings []string) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	for _, wp := range paths {
+		entry, found := entries[resolve(entries, wp.path)]
+		if !found && wp.reason == "VOLUME" {
+			logger.V(log.DBG).Info("volume does not exist in the image, so is created by the container runtime", "path", wp.path)
+			continue
+		}
+		if !found {
+			// the container creates the path when it runs, so the closest directory above it must be writable.
+			dir, parent, found := ancestor(entries, wp.path)
+			if !found || (parent.gid == 0 && parent.mode&0o020 != 0) {
+				logger.V(log.DBG).Info("path does not exist in the image, so is created when the container runs", "reason", wp.reason, "path", wp.path)
+				continue
+			}
+			findings = append(findings, fmt.Sprintf("%s %s does not exist in the image, and %s, owned by %d:%d with mode %04o, is not owned by the root group and group-writable, so it cannot be created",
+				wp.reason, wp.path, dir, parent.uid, parent.gid, parent.mode.Perm()))
+			continue
+		}
+		if wp.ifDir && entry.typeflag != tar.TypeDir {
+			logger.V(log.DBG).Info("path is not a directory, so is not written to", "reason", wp.reason, "path", wp.path)
+			continue
+		}
+		if entry.gid == 0 && entry.mode&0o020 != 0 {
+			continue
+		}
+		findings = append(findings, fmt.Sprintf("%s %s, owned by %d:%d with mode %04o, is not owned by the root group and group-writable",
+			wp.reason, wp.path, entry.uid, entry.gid, entry.mode.Perm()))
+	}
+
+	if len(findings) == 0 {
+		return true, nil
+	}
+
+	logger.V(log.DBG).Info("image may not run as an arbitrary UID", "findings", findings)
+	check.ReportFindings(ctx, findings...)
+
+	return false, nil
+}
+
+func (p *SupportsArbitraryUIDCheck) Name() string {
+	return "SupportsArbitraryUID"
+}
+
+func (p *SupportsArbitraryUIDCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the image can run as the arbitrary UID, in the root group, that OpenShift assigns to containers, by verifying that the directories it needs to write to, and /etc/passwd if its entrypoint adds an entry to it, are owned by the root group and group-writable.",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: "https://docs.openshift.com/container-platform/latest/openshift_images/create-images.html#use-uid_create-images",
+		CheckURL:         "https://docs.openshift.com/container-platform/latest/openshift_images/create-images.html#use-uid_create-images",
+	}
+}
+
+func (p *SupportsArbitraryUIDCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "Check SupportsArbitraryUID found paths that an arbitrary UID cannot write to. Please review the findings, or the preflight.log file, for each path.",
+		Suggestion: "Make the directories the image writes to, or the directories they are created in, owned by the root group and group-writable, e.g. with chgrp -R 0 and chmod -R g=u, and set HOME with ENV. If the entrypoint adds an entry for the arbitrary UID to /etc/passwd, make /etc/passwd group-writable, or use nss_wrapper instead.",
+	}
+}
+
+func (p *SupportsArbitraryUIDCheck) RequiredFilePatterns() []string {
+	return []string{"/etc/passwd"}
+}
@@ -0,0 +1,290 @@
+package container
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+const testPasswd = `root:x:0:0:root:/root:/bin/bash
+# a comment
+app:x:1001:0:app:/home/app:/sbin/nologin
+`
+
+// testEntrypoint adds an entry for the arbitrary UID the container runs as to /etc/passwd.
+const testEntrypoint = `#!/bin/sh
+echo "app:x:$(id -u):0::/home/app:/sbin/nologin" >> /etc/passwd
+exec "$@"
+`
+
+var _ = Describe("SupportsArbitraryUID", func() {
+	var (
+		supportsArbitraryUID SupportsArbitraryUIDCheck
+		ctx                  context.Context
+		findings             *check.Findings
+		fsPath               string
+		config               cranev1.Config
+		entrypoint           string
+	)
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+		fsPath = GinkgoT().TempDir()
+		Expect(os.MkdirAll(filepath.Join(fsPath, "etc"), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(fsPath, "etc", "passwd"), []byte(testPasswd), 0o644)).To(Succeed())
+		config = cranev1.Config{
+			User:       "1001",
+			WorkingDir: "/opt/app",
+			Env:        []string{"PATH=/usr/local/bin:/usr/bin", "HOME=/home/app", "JAVA_HOME=/usr/lib/jvm/jre", "APP_DATA=/var/lib/app"},
+			Volumes:    map[string]struct{}{"/data": {}},
+		}
+		entrypoint = ""
+	})
+
+	// validate validates an image of headers, and of the entrypoint script at
+	// /usr/local/bin/entrypoint.sh, if it is set.
+	validate := func(headers ...*tar.Header) (bool, error) {
+		layers := []cranev1.Layer{headersLayer(headers...)}
+		if entrypoint != "" {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "usr/local/bin/entrypoint.sh", Mode: 0o755, Size: int64(len(entrypoint))})).To(Succeed())
+			_, err := tw.Write([]byte(entrypoint))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+			layers = append(layers, static.NewLayer(buf.Bytes(), types.DockerLayer))
+		}
+		img, err := mutate.AppendLayers(empty.Image, layers...)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, config)
+		Expect(err).ToNot(HaveOccurred())
+		return supportsArbitraryUID.Validate(ctx, image.ImageReference{ImageInfo: img, ImageFSPath: fsPath})
+	}
+
+	AssertMetaData(&supportsArbitraryUID)
+
+	Context("When the paths the image writes to are owned by the root group and group-writable", func() {
+		It("should pass", func() {
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o775, Uid: 1001},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o770, Uid: 1001},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "usr/lib/jvm/jre/", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeSymlink, Name: "var/lib/app", Linkname: "../../data"},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+
+		It("should pass if they do not exist, as they are created when the container runs", func() {
+			ok, err := validate()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the paths the image writes to are not owned by the root group and group-writable", func() {
+		It("should fail, and report each path", func() {
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o755, Uid: 1001, Gid: 1001},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o700, Uid: 1001},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "var/lib/app/", Mode: 0o775, Gid: 1001},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"WORKDIR /opt/app, owned by 1001:1001 with mode 0755, is not owned by the root group and group-writable",
+				"VOLUME /data, owned by 0:0 with mode 0755, is not owned by the root group and group-writable",
+				"ENV APP_DATA /var/lib/app, owned by 0:1001 with mode 0775, is not owned by the root group and group-writable",
+				"HOME /home/app, owned by 1001:0 with mode 0700, is not owned by the root group and group-writable",
+			))
+		})
+	})
+
+	Context("When a path the image writes to does not exist", func() {
+		BeforeEach(func() {
+			config.Env = []string{"HOME=/home/app"}
+			config.Volumes = map[string]struct{}{"/data": {}}
+		})
+
+		It("should pass if the directory it is created in is owned by the root group and group-writable", func() {
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/", Mode: 0o775},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should fail, and report each path, if the directory it is created in is not", func() {
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/", Mode: 0o775, Gid: 1001},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o755},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"WORKDIR /opt/app does not exist in the image, and /opt, owned by 0:0 with mode 0755, is not owned by the root group and group-writable, so it cannot be created",
+				"HOME /home/app does not exist in the image, and /home, owned by 0:1001 with mode 0775, is not owned by the root group and group-writable, so it cannot be created",
+				"VOLUME /data, owned by 0:0 with mode 0755, is not owned by the root group and group-writable",
+			))
+		})
+
+		It("should only check the directory a file referenced by ENV is created in", func() {
+			config.Env = []string{"HOME=/home/app", "SSL_CERT_FILE=/opt/certs/ca.pem"}
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/certs/", Mode: 0o755},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"ENV SSL_CERT_FILE /opt/certs/ca.pem does not exist in the image, and /opt/certs, owned by 0:0 with mode 0755, is not owned by the root group and group-writable, so it cannot be created",
+			))
+		})
+
+		It("should not check the directory a VOLUME is created in, as the container runtime creates it", func() {
+			config.User = ""
+			config.WorkingDir = ""
+			config.Env = nil
+			config.Volumes = map[string]struct{}{"/var/lib/data": {}}
+			ok, err := validate(&tar.Header{Typeflag: tar.TypeDir, Name: "var/lib/", Mode: 0o755})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When an ENV value is a file in the image", func() {
+		It("should pass, as only directories are written to", func() {
+			config.Env = []string{"HOME=/home/app", "SSL_CERT_FILE=/opt/certs/ca.pem"}
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/certs/", Mode: 0o755},
+				&tar.Header{Typeflag: tar.TypeReg, Name: "opt/certs/ca.pem", Mode: 0o644},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When /etc/passwd is in the image", func() {
+		BeforeEach(func() {
+			config.Entrypoint = []string{"/usr/local/bin/entrypoint.sh"}
+			entrypoint = testEntrypoint
+		})
+
+		passwd := func(mode int64, gid int) *tar.Header {
+			return &tar.Header{Typeflag: tar.TypeReg, Name: "etc/passwd", Mode: mode, Gid: gid}
+		}
+		writable := []*tar.Header{
+			{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o775},
+			{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o775},
+			{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o775},
+			{Typeflag: tar.TypeDir, Name: "var/lib/app/", Mode: 0o775},
+		}
+
+		It("should pass if it is owned by the root group and group-writable", func() {
+			ok, err := validate(append(writable, passwd(0o664, 0))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should fail if it is not, as no entry can be added for an arbitrary UID", func() {
+			ok, err := validate(append(writable, passwd(0o664, 1001))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"/etc/passwd, owned by 0:1001 with mode 0664, is not owned by the root group and group-writable, and the image does not use nss_wrapper, so the entrypoint cannot add an entry for an arbitrary UID",
+			))
+		})
+
+		It("should fail if it is not, and the entrypoint is found in PATH", func() {
+			config.Entrypoint = []string{"entrypoint.sh"}
+			ok, err := validate(append(writable, passwd(0o644, 0))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should fail if it is not, and the entrypoint adds an entry inline", func() {
+			config.Entrypoint = []string{"/bin/sh", "-c", `echo "app:x:$(id -u):0::/home/app:/sbin/nologin" >> /etc/passwd && exec app`}
+			ok, err := validate(append(writable, passwd(0o644, 0))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("should pass if it is not, as by default, but the entrypoint does not add an entry", func() {
+			entrypoint = "#!/bin/sh\nexec \"$@\"\n"
+			ok, err := validate(append(writable, passwd(0o644, 0))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should pass if it is not, but nss_wrapper is installed", func() {
+			ok, err := validate(append(writable, passwd(0o644, 0),
+				&tar.Header{Typeflag: tar.TypeReg, Name: "usr/lib64/libnss_wrapper.so", Mode: 0o755})...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should pass if it is not, but nss_wrapper is configured", func() {
+			config.Env = append(config.Env, "NSS_WRAPPER_PASSWD=/tmp/passwd")
+			ok, err := validate(append(writable, passwd(0o644, 0))...)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the user's HOME is only set in /etc/passwd", func() {
+		It("should fail, as an arbitrary UID has no entry in /etc/passwd", func() {
+			config.Env = nil
+			config.Volumes = nil
+			ok, err := validate(&tar.Header{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o775})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(ContainSubstring("HOME /home/app of USER 1001 is only set in /etc/passwd")))
+		})
+	})
+
+	Context("When the user is not numeric", func() {
+		It("should pass, as the arbitrary UID replaces it", func() {
+			config.User = "app:root"
+			ok, err := validate(
+				&tar.Header{Typeflag: tar.TypeDir, Name: "opt/app/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "home/app/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o775},
+				&tar.Header{Typeflag: tar.TypeDir, Name: "var/lib/app/", Mode: 0o775},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("When the image has no /etc/passwd", func() {
+		It("should only check the paths in its config", func() {
+			Expect(os.Remove(filepath.Join(fsPath, "etc", "passwd"))).To(Succeed())
+			config.Env = nil
+			ok, err := validate(&tar.Header{Typeflag: tar.TypeDir, Name: "data/", Mode: 0o775})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -33,22 +33,48 @@ const (
 
 	// imageRegistryService is the service name of the image registry
 	imageRegistryService = "image-registry.openshift-image-registry.svc"
+
+	// catalogArtifactsDir is the directory of the artifacts the catalog built for the bundle is written to
+	catalogArtifactsDir = "catalog"
+
+	// installModeResultFilename is the artifact the result of deploying the operator in an install mode
+	// is written to, in the directory of the install mode
+	installModeResultFilename = "result.json"
+
+	// examplesArtifactsDir is the directory of the artifacts the examples in alm-examples, and the logs
+	// of the operator reconciling them, are written to
+	examplesArtifactsDir = "examples"
+
+	// almExamplesAnnotation is the CSV annotation holding example custom resources for the operator's APIs
+	almExamplesAnnotation = "alm-examples"
+
+	// imageSourcesFilename is the artifact the images pulled by the operator's pods, and their
+	// registries, are written to
+	imageSourcesFilename = "image-sources.json"
+
+	// versionMatrixFilename is the artifact the result of validating the bundle against each OpenShift
+	// version in its range is written to
+	versionMatrixFilename = "version-matrix.json"
+
+	// rbacAnalysisFilename is the artifact the permissions of the operator that are broader than it is
+	// likely to need, and their severity, are written to
+	rbacAnalysisFilename = "rbac-analysis.json"
 )
 
 var (
-	approvedRegistries = map[string]struct{}{
-		"registry.connect.dev.redhat.com":   {},
-		"registry.connect.qa.redhat.com":    {},
-		"registry.connect.stage.redhat.com": {},
-		"registry.connect.redhat.com":       {},
-		"registry.redhat.io":                {},
-		"registry.access.redhat.com":        {},
-	}
-
 	prioritizedInstallModes = []operatorsv1alpha1.InstallModeType{
 		operatorsv1alpha1.InstallModeTypeOwnNamespace,
 		operatorsv1alpha1.InstallModeTypeSingleNamespace,
 		operatorsv1alpha1.InstallModeTypeMultiNamespace,
 		operatorsv1alpha1.InstallModeTypeAllNamespaces,
 	}
+
+	// installModeNamespaces are added to the namespaces of each install mode when the operator is
+	// deployed in all of them, to tell them apart
+	installModeNamespaces = map[operatorsv1alpha1.InstallModeType]string{
+		operatorsv1alpha1.InstallModeTypeOwnNamespace:    "own",
+		operatorsv1alpha1.InstallModeTypeSingleNamespace: "single",
+		operatorsv1alpha1.InstallModeTypeMultiNamespace:  "multi",
+		operatorsv1alpha1.InstallModeTypeAllNamespaces:   "all",
+	}
 )
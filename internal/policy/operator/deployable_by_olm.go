@@ -2,14 +2,14 @@ package operator
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"maps"
 	"os"
+	"path"
 	"path/filepath"
-	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +19,7 @@ import (
 	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	validationerrors "github.com/operator-framework/api/pkg/validation/errors"
+	"go.opentelemetry.io/otel/attribute"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -31,11 +32,14 @@ import (
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/authn"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/catalog"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/telemetry"
 )
 
 type Option func(*DeployableByOlmCheck)
@@ -43,31 +47,51 @@ type Option func(*DeployableByOlmCheck)
 var _ check.Check = &DeployableByOlmCheck{}
 
 type operatorData struct {
+	BundleDir        string
 	CatalogImage     string
+	BundleImage      string
 	Channel          string
 	PackageName      string
 	App              string
 	InstallNamespace string
 	TargetNamespace  string
 	InstallModes     map[operatorsv1alpha1.InstallModeType]operatorsv1alpha1.InstallMode
-	CsvNamespaces    []string
-	InstalledCsv     string
-	DeploymentNames  []string
+	// InstallMode is the install mode the operator is deployed in. If empty, the first
+	// supported install mode in prioritizedInstallModes is used.
+	InstallMode     operatorsv1alpha1.InstallModeType
+	CsvNamespaces   []string
+	InstalledCsv    string
+	DeploymentNames []string
 }
 
 type DeployableByOlmCheck struct {
 	// dockerConfig is optional. If empty, we will not use one.
 	dockerConfig string
-	// indexImage is the catalog containing the operator bundle.
+	// indexImage is the catalog containing the operator bundle. If empty, a catalog
+	// containing only the bundle is built.
 	indexImage string
+	// catalogBaseImage is the opm image the built catalog is served with.
+	catalogBaseImage string
+	// catalogDir and catalogAddress are optional. If set, the built catalog is written to
+	// catalogDir, and served at catalogAddress, rather than from the cluster.
+	catalogDir     string
+	catalogAddress string
 	// channel is optional. If empty, we will introspect.
 	channel string
+	// allInstallModes deploys the operator in every install mode its CSV supports, rather
+	// than only the first in prioritizedInstallModes.
+	allInstallModes bool
+	// almExamples is optional. If set, the examples in the CSV's alm-examples are created once
+	// the operator is installed.
+	almExamples *ReconcilesAlmExamplesCheck
+	// imageSources is optional. If set, the images pulled by the operator's pods while it is
+	// installed are audited by it.
+	imageSources *ImagesFromApprovedRegistriesCheck
 
 	openshiftClient     openshift.Client
 	client              crclient.Client
 	k8sClientset        kubernetes.Interface
 	csvReady            bool
-	validImages         bool
 	csvTimeout          time.Duration
 	subscriptionTimeout time.Duration
 	namespaceSuffix     func(int) string
@@ -144,10 +168,55 @@ func WithSubscriptionTimeout(subscriptionTimeout time.Duration) Option {
 	}
 }
 
+// WithCatalogBaseImage sets the opm image that serves the catalog built for the bundle, when
+// there is no index image.
+func WithCatalogBaseImage(baseImage string) Option {
+	return func(oc *DeployableByOlmCheck) {
+		oc.catalogBaseImage = baseImage
+	}
+}
+
+// WithCatalogDir writes the catalog built for the bundle, when there is no index image, to
+// dir, rather than serving it from the cluster. The catalog must be served at address, e.g.
+// by opm serve, for OLM to install the bundle.
+func WithCatalogDir(dir, address string) Option {
+	return func(oc *DeployableByOlmCheck) {
+		oc.catalogDir = dir
+		oc.catalogAddress = address
+	}
+}
+
+// WithAllInstallModes deploys the operator in each install mode its CSV supports, in a
+// separate set of namespaces, with the artifacts of each written to a subdirectory named
+// after the install mode. The check only passes if the operator deploys in every mode.
+func WithAllInstallModes() Option {
+	return func(oc *DeployableByOlmCheck) {
+		oc.allInstallModes = true
+	}
+}
+
+// WithAlmExamples creates the examples in the alm-examples annotation of the CSV once the
+// operator is installed, and before it is cleaned up, for almExamples to report whether the
+// operator reconciles them.
+func WithAlmExamples(almExamples *ReconcilesAlmExamplesCheck) Option {
+	return func(oc *DeployableByOlmCheck) {
+		oc.almExamples = almExamples
+	}
+}
+
+// WithImageSources audits the images pulled by the operator's pods while it is installed, for
+// imageSources to report whether they are from approved registries.
+func WithImageSources(imageSources *ImagesFromApprovedRegistriesCheck) Option {
+	return func(oc *DeployableByOlmCheck) {
+		oc.imageSources = imageSources
+	}
+}
+
 // NewDeployableByOlmCheck will return a check that validates if an operator
 // is deployable by OLM. An empty dockerConfig value implies that the images
 // in scope are public. An empty channel value implies that the check should
-// introspect the channel from the bundle. indexImage is required.
+// introspect the channel from the bundle. An empty indexImage value implies
+// that the check should build a catalog containing only the bundle.
 func NewDeployableByOlmCheck(
 	indexImage,
 	dockerConfig,
@@ -197,54 +266,180 @@ func (p *DeployableByOlmCheck) Validate(ctx context.Context, bundleRef image.Ima
 		return false, fmt.Errorf("the bundle cannot be deployed because deployment validation has failed: %+v", erroredValidations)
 	}
 
-	// gather the list of registry and pod images
-	beforeOperatorImages, err := p.getImages(ctx)
+	// retrieve the required data
+	operatorData, err := p.operatorMetadata(ctx, bundleRef)
 	if err != nil {
 		//coverage:ignore
 		return false, fmt.Errorf("%v", err)
 	}
 
-	// retrieve the required data
-	operatorData, err := p.operatorMetadata(ctx, bundleRef)
+	logger.V(log.DBG).Info("operator metadata", "metadata", *operatorData)
+
+	var catalogFiles catalog.Files
+	if p.indexImage == "" {
+		catalogFiles, err = p.buildCatalog(ctx, bundleRef, operatorData)
+		if err != nil {
+			return false, fmt.Errorf("%v", err)
+		}
+	}
+
+	if p.allInstallModes {
+		return p.deployInstallModes(ctx, operatorData, catalogFiles)
+	}
+
+	p.csvReady, err = p.deploy(ctx, operatorData, catalogFiles)
+	if err != nil {
+		return false, err
+	}
+	return p.csvReady, nil
+}
+
+// deployInstallModes deploys the operator in each install mode it supports, in turn, and
+// reports the result of each as a finding, and in result.json in the artifacts subdirectory
+// of the install mode.
+func (p *DeployableByOlmCheck) deployInstallModes(ctx context.Context, operatorData *operatorData, catalogFiles catalog.Files) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	var installModes []operatorsv1alpha1.InstallModeType
+	for _, v := range prioritizedInstallModes {
+		if operatorData.InstallModes[v].Supported {
+			installModes = append(installModes, v)
+		}
+	}
+	if len(installModes) == 0 {
+		check.ReportFindings(ctx, "the CSV does not support any install mode")
+		return false, nil
+	}
+
+	p.csvReady = true
+	for _, installMode := range installModes {
+		modeData := *operatorData
+		modeData.InstallMode = installMode
+		modeData.InstallNamespace = p.namespace(operatorData.App, installModeNamespaces[installMode])
+		modeData.TargetNamespace = modeData.InstallNamespace + targetSuffix
+
+		modeCtx := logr.NewContext(ctx, logger.WithValues("installMode", installMode))
+		artifactWriter := artifacts.WriterFromContext(ctx)
+		if artifactWriter != nil {
+			artifactWriter = artifacts.NewSubdirectoryWriter(artifactWriter, string(installMode))
+			modeCtx = artifacts.ContextWithWriter(modeCtx, artifactWriter)
+		}
+
+		csvReady, err := p.deploy(modeCtx, &modeData, catalogFiles)
+		result := installModeResult{
+			InstallMode:      installMode,
+			Passed:           csvReady,
+			InstallNamespace: modeData.InstallNamespace,
+			TargetNamespaces: modeData.CsvNamespaces,
+			InstalledCSV:     modeData.InstalledCsv,
+		}
+		if err != nil {
+			result.Error = err.Error()
+			check.ReportFindings(ctx, fmt.Sprintf("%s: the operator could not be deployed: %v", installMode, err))
+		} else if !csvReady {
+			//coverage:ignore
+			check.ReportFindings(ctx, fmt.Sprintf("%s: the CSV did not succeed", installMode))
+		}
+		logger.V(log.DBG).Info("install mode result", "installMode", installMode, "passed", result.Passed)
+
+		if artifactWriter != nil {
+			if err := writeJSON(artifactWriter, installModeResultFilename, result); err != nil {
+				//coverage:ignore
+				logger.Error(err, "failed to write the install mode result to the artifacts")
+			}
+		}
+
+		p.csvReady = p.csvReady && csvReady
+	}
+
+	return p.csvReady, nil
+}
+
+// installModeResult is the result of deploying the operator in an install mode.
+type installModeResult struct {
+	InstallMode      operatorsv1alpha1.InstallModeType `json:"installMode"`
+	Passed           bool                              `json:"passed"`
+	InstallNamespace string                            `json:"installNamespace"`
+	TargetNamespaces []string                          `json:"targetNamespaces"`
+	InstalledCSV     string                            `json:"installedCSV,omitempty"`
+	Error            string                            `json:"error,omitempty"`
+}
+
+func writeJSON(artifactWriter artifacts.ArtifactWriter, filename string, v any) error {
+	contents, err := json.MarshalIndent(v, "", "    ")
 	if err != nil {
 		//coverage:ignore
-		return false, fmt.Errorf("%v", err)
+		return fmt.Errorf("unable to marshal to json: %w", err)
 	}
+	_, err = artifactWriter.WriteFile(filename, bytes.NewReader(contents))
+	return err
+}
 
-	logger.V(log.DBG).Info("operator metadata", "metadata", *operatorData)
+// deploy deploys the operator with the catalog and install mode of operatorData, waits for
+// its CSV to succeed, and cleans up. It returns whether the CSV succeeded.
+func (p *DeployableByOlmCheck) deploy(ctx context.Context, operatorData *operatorData, catalogFiles catalog.Files) (csvReady bool, err error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	// gather the list of registry and pod images
+	var beforeOperatorImages map[string]struct{}
+	if p.imageSources != nil {
+		beforeOperatorImages, err = p.getImages(ctx)
+		if err != nil {
+			//coverage:ignore
+			return false, fmt.Errorf("%v", err)
+		}
+	}
 
 	// create k8s custom resources for the operator deployment
-	err = p.setUp(ctx, operatorData)
-	defer p.cleanUp(ctx, *operatorData)
+	phaseCtx, span := telemetry.Start(ctx, "olm set up")
+	err = p.setUp(phaseCtx, operatorData, catalogFiles)
+	telemetry.End(span, err)
+
+	cleanUpData := *operatorData
+	defer func() {
+		phaseCtx, span := telemetry.Start(ctx, "olm clean up")
+		defer span.End()
+		p.cleanUp(phaseCtx, cleanUpData)
+	}()
 
 	if err != nil {
 		//coverage:ignore
 		return false, fmt.Errorf("%v", err)
 	}
 
-	installedCSV, err := p.installedCSV(ctx, *operatorData)
+	phaseCtx, span = telemetry.Start(ctx, "olm install")
+	installedCSV, err := p.installedCSV(phaseCtx, *operatorData)
+	telemetry.End(span, err)
 	if err != nil {
 		return false, fmt.Errorf("%v", err)
 	}
 	operatorData.InstalledCsv = installedCSV
 	logger.V(log.TRC).Info("installed CSV", "csv", operatorData.InstalledCsv)
 
-	p.csvReady, err = p.isCSVReady(ctx, *operatorData)
+	phaseCtx, span = telemetry.Start(ctx, "olm wait for csv", attribute.String("olm.csv", installedCSV))
+	csvReady, err = p.isCSVReady(phaseCtx, *operatorData)
+	telemetry.End(span, err)
 	if err != nil {
 		//coverage:ignore
 		return false, fmt.Errorf("%v", err)
 	}
 
-	afterOperatorImages, err := p.getImages(ctx)
-	if err != nil {
-		//coverage:ignore
-		return false, fmt.Errorf("%v", err)
+	if csvReady && p.almExamples != nil {
+		phaseCtx, span = telemetry.Start(ctx, "olm alm-examples")
+		p.almExamples.run(phaseCtx, p.openshiftClient, operatorData.BundleDir, *operatorData)
+		span.End()
 	}
 
-	operatorImages := diffImageList(beforeOperatorImages, afterOperatorImages)
-	p.validImages = checkImageSource(ctx, operatorImages)
+	if p.imageSources != nil {
+		afterOperatorImages, err := p.getImages(ctx)
+		if err != nil {
+			//coverage:ignore
+			return false, fmt.Errorf("%v", err)
+		}
+		p.imageSources.audit(ctx, diffImageList(beforeOperatorImages, afterOperatorImages), *operatorData)
+	}
 
-	return p.csvReady, nil
+	return csvReady, nil
 }
 
 func diffImageList(before, after map[string]struct{}) []string {
@@ -258,28 +453,6 @@ func diffImageList(before, after map[string]struct{}) []string {
 	return operatorImages
 }
 
-func checkImageSource(ctx context.Context, operatorImages []string) bool {
-	logger := logr.FromContextOrDiscard(ctx)
-
-	logger.V(log.DBG).Info("checking that images are from approved sources")
-
-	registries := slices.Collect(maps.Keys(approvedRegistries))
-
-	logger.V(log.DBG).Info("list of approved registries", "registries", registries)
-	allApproved := true
-	for _, image := range operatorImages {
-		userRegistry := strings.Split(image, "/")[0]
-		if _, ok := approvedRegistries[userRegistry]; !ok {
-			logger.Info("warning: unapproved registry found for image", "image", image)
-			allApproved = false
-		}
-	}
-	if allApproved {
-		logger.V(log.DBG).Info("all images are from approved sources")
-	}
-	return allApproved
-}
-
 func (p *DeployableByOlmCheck) operatorMetadata(ctx context.Context, bundleRef image.ImageReference) (*operatorData, error) {
 	logger := logr.FromContextOrDiscard(ctx)
 
@@ -335,13 +508,12 @@ func (p *DeployableByOlmCheck) operatorMetadata(ctx context.Context, bundleRef i
 		deploymentNames = append(deploymentNames, deployment.Name)
 	}
 
-	namespace := appName[:min(len(appName), maxAppNameLen)]
-	if suffix := p.namespaceSuffix(namespaceSuffixLen); suffix != "" {
-		namespace = fmt.Sprintf("%s-%s", namespace, suffix)
-	}
+	namespace := p.namespace(appName, "")
 
 	return &operatorData{
+		BundleDir:        bundleRef.ImageFSPath,
 		CatalogImage:     catalogImage,
+		BundleImage:      bundleRef.ImageURI,
 		Channel:          channel,
 		PackageName:      packageName,
 		App:              appName,
@@ -352,7 +524,59 @@ func (p *DeployableByOlmCheck) operatorMetadata(ctx context.Context, bundleRef i
 	}, nil
 }
 
-func (p *DeployableByOlmCheck) setUp(ctx context.Context, operatorData *operatorData) error {
+// namespace returns the install namespace of appName, with infix, if any, and a random suffix.
+func (p *DeployableByOlmCheck) namespace(appName, infix string) string {
+	maxLen := maxAppNameLen
+	if infix != "" {
+		maxLen -= len(infix) + len("-")
+	}
+	namespace := appName[:min(len(appName), maxLen)]
+	if infix != "" {
+		namespace = fmt.Sprintf("%s-%s", namespace, infix)
+	}
+	if suffix := p.namespaceSuffix(namespaceSuffixLen); suffix != "" {
+		namespace = fmt.Sprintf("%s-%s", namespace, suffix)
+	}
+	return namespace
+}
+
+// buildCatalog returns the files of a catalog containing only the bundle, and writes them to
+// the artifacts.
+func (p *DeployableByOlmCheck) buildCatalog(ctx context.Context, bundleRef image.ImageReference, operatorData *operatorData) (catalog.Files, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	logger.V(log.DBG).Info("building a catalog for the bundle", "package", operatorData.PackageName, "channel", operatorData.Channel)
+	cat, err := catalog.Build(bundleRef.ImageFSPath, operatorData.BundleImage, operatorData.PackageName, operatorData.Channel)
+	if err != nil {
+		return nil, fmt.Errorf("could not build a catalog for the bundle: %w", err)
+	}
+	files, err := cat.Files()
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		for name, contents := range files {
+			if _, err := artifactWriter.WriteFile(path.Join(catalogArtifactsDir, name), bytes.NewReader(contents)); err != nil {
+				//coverage:ignore
+				logger.Error(err, "failed to write the catalog to the artifacts")
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// catalogServer returns the server of the catalog built for the bundle.
+func (p *DeployableByOlmCheck) catalogServer() catalog.Server {
+	if p.catalogDir != "" {
+		return catalog.NewDirServer(p.catalogDir, p.catalogAddress)
+	}
+	return catalog.NewClusterServer(p.openshiftClient, p.catalogBaseImage, secretName)
+}
+
+func (p *DeployableByOlmCheck) setUp(ctx context.Context, operatorData *operatorData, catalogFiles catalog.Files) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
 	if _, err := p.openshiftClient.CreateNamespace(ctx, operatorData.InstallNamespace); err != nil && !errors.Is(err, openshift.ErrAlreadyExists) {
@@ -365,15 +589,33 @@ func (p *DeployableByOlmCheck) setUp(ctx context.Context, operatorData *operator
 		return err
 	}
 
+	var content []byte
 	dockerconfig := p.dockerConfig
 	if len(dockerconfig) != 0 {
 		// the user provided a dockerConfig to pass through for use with scorecard.
-		content, err := p.readFileAsByteArray(dockerconfig)
+		var err error
+		content, err = p.readFileAsByteArray(dockerconfig)
 		if err != nil {
 			//coverage:ignore
 			return err
 		}
-		data := map[string]string{".dockerconfigjson": string(content)}
+	}
+
+	// credentials held by a configured credential helper are added, so that the cluster can
+	// pull the images too.
+	catalogImage := operatorData.CatalogImage
+	if catalogFiles != nil {
+		catalogImage = cmp.Or(p.catalogBaseImage, catalog.DefaultBaseImage)
+	}
+	merged, err := authn.DockerConfigJSON(ctx, content, catalogImage, operatorData.BundleImage)
+	if err != nil {
+		//coverage:ignore
+		logger.Error(err, "could not add credential helper credentials to the pull secret")
+		merged = content
+	}
+
+	if len(dockerconfig) != 0 || len(merged) != 0 {
+		data := map[string]string{".dockerconfigjson": string(merged)}
 		if _, err := p.openshiftClient.CreateSecret(
 			ctx,
 			secretName,
@@ -429,6 +671,13 @@ func (p *DeployableByOlmCheck) setUp(ctx context.Context, operatorData *operator
 		Image:   operatorData.CatalogImage,
 		Secrets: []string{secretName},
 	}
+	if catalogFiles != nil {
+		address, err := p.catalogServer().Serve(ctx, operatorData.App, operatorData.InstallNamespace, catalogFiles)
+		if err != nil {
+			return fmt.Errorf("could not serve the catalog built for the bundle: %w", err)
+		}
+		catalogSourceData.Address = address
+	}
 	if _, err := p.openshiftClient.CreateCatalogSource(
 		ctx,
 		catalogSourceData,
@@ -469,11 +718,13 @@ func (p *DeployableByOlmCheck) setUp(ctx context.Context, operatorData *operator
 func (p *DeployableByOlmCheck) generateOperatorGroupData(ctx context.Context, operatorData *operatorData) openshift.OperatorGroupData {
 	logger := logr.FromContextOrDiscard(ctx)
 
-	var installMode operatorsv1alpha1.InstallModeType
-	for _, v := range prioritizedInstallModes {
-		if operatorData.InstallModes[v].Supported {
-			installMode = operatorData.InstallModes[v].Type
-			break
+	installMode := operatorData.InstallMode
+	if installMode == "" {
+		for _, v := range prioritizedInstallModes {
+			if operatorData.InstallModes[v].Supported {
+				installMode = operatorData.InstallModes[v].Type
+				break
+			}
 		}
 	}
 	logger.V(log.DBG).Info("operator install mode", "installMode", installMode)
@@ -766,6 +1017,9 @@ func (p *DeployableByOlmCheck) cleanUp(ctx context.Context, operatorData operato
 	logger.V(log.TRC).Info("deleting the resources created by DeployableByOLM Check")
 	_ = p.openshiftClient.DeleteSubscription(ctx, operatorData.App, operatorData.InstallNamespace)
 	_ = p.openshiftClient.DeleteCatalogSource(ctx, operatorData.App, operatorData.InstallNamespace)
+	if p.indexImage == "" {
+		p.catalogServer().Stop(ctx, operatorData.App, operatorData.InstallNamespace)
+	}
 	_ = p.openshiftClient.DeleteOperatorGroup(ctx, operatorData.App, operatorData.InstallNamespace)
 	_ = p.openshiftClient.DeleteSecret(ctx, secretName, operatorData.InstallNamespace)
 
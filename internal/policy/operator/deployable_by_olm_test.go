@@ -2,20 +2,26 @@ package operator
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"time"
 
 	fakecranev1 "github.com/google/go-containerregistry/pkg/v1/fake"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	fakecg "k8s.io/client-go/kubernetes/fake"
 	crclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
 	test "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/test"
@@ -149,6 +155,193 @@ var _ = Describe("DeployableByOLMCheck", func() {
 				Expect(ok).To(BeTrue())
 			})
 		})
+		Context("When there is no index image", func() {
+			var artifactsDir string
+			BeforeEach(func() {
+				deployableByOLMCheck.indexImage = ""
+				artifactsDir = artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter).Path()
+			})
+			It("Should build a catalog for the bundle, serve it from the cluster, and pass Validate", func() {
+				ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				contents, err := os.ReadFile(filepath.Join(artifactsDir, "catalog", "testPackage", "catalog.json"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring(`"defaultChannel": "testChannel"`))
+				Expect(string(contents)).To(ContainSubstring(`"name": "memcached-operator.v0.0.1"`))
+
+				contents, err = os.ReadFile(filepath.Join(artifactsDir, "p-testPackage-CatalogSource.json"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring(`"address":"p-testPackage-catalog.p-testPackage-abcde.svc:50051"`))
+
+				// the catalog server is removed when the check cleans up.
+				var configMap corev1.ConfigMap
+				err = deployableByOLMCheck.client.Get(testcontext, crclient.ObjectKey{Name: "p-testPackage-catalog", Namespace: "p-testPackage-abcde"}, &configMap)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+			It("Should write the catalog to the catalog directory if one is set", func() {
+				catalogDir := GinkgoT().TempDir()
+				WithCatalogDir(catalogDir, "localhost:50051")(&deployableByOLMCheck)
+
+				ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(filepath.Join(catalogDir, "testPackage", "catalog.json")).To(BeAnExistingFile())
+
+				contents, err := os.ReadFile(filepath.Join(artifactsDir, "p-testPackage-CatalogSource.json"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring(`"address":"localhost:50051"`))
+			})
+		})
+		Context("When all the install modes are tested", func() {
+			var (
+				findings     *check.Findings
+				artifactsDir string
+				objects      []crclient.Object
+			)
+			BeforeEach(func() {
+				imageRef.ImageFSPath = "./testdata/own_namespace"
+				WithAllInstallModes()(&deployableByOLMCheck)
+				testcontext, findings = check.ContextWithFindings(testcontext)
+				artifactsDir = artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter).Path()
+
+				// OwnNamespace CSVs are applied to the install namespace, and SingleNamespace
+				// CSVs to the target namespace.
+				ownSub := sub.DeepCopy()
+				ownSub.Namespace = "p-testPackage-own-abcde"
+				ownCSV := csv.DeepCopy()
+				ownCSV.Namespace = "p-testPackage-own-abcde"
+				singleSub := sub.DeepCopy()
+				singleSub.Namespace = "p-testPackage-single-abcde"
+				singleCSV := csv.DeepCopy()
+				singleCSV.Namespace = "p-testPackage-single-abcde-target"
+				objects = []crclient.Object{ownSub, ownCSV, singleSub, singleCSV}
+			})
+			JustBeforeEach(func() {
+				scheme := apiruntime.NewScheme()
+				Expect(openshift.AddSchemes(scheme)).To(Succeed())
+				deployableByOLMCheck.client = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(objects...).
+					WithLists(&pods, &isList).
+					Build()
+			})
+			It("Should deploy the operator in each supported install mode, and pass Validate", func() {
+				ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(findings.List()).To(BeEmpty())
+
+				contents, err := os.ReadFile(filepath.Join(artifactsDir, "OwnNamespace", "result.json"))
+				Expect(err).ToNot(HaveOccurred())
+				var result installModeResult
+				Expect(json.Unmarshal(contents, &result)).To(Succeed())
+				Expect(result.Passed).To(BeTrue())
+				Expect(result.InstallNamespace).To(Equal("p-testPackage-own-abcde"))
+				Expect(result.TargetNamespaces).To(Equal([]string{"p-testPackage-own-abcde"}))
+				Expect(result.InstalledCSV).To(Equal("csv-v0.0.0"))
+
+				Expect(filepath.Join(artifactsDir, "SingleNamespace", "result.json")).To(BeAnExistingFile())
+				Expect(filepath.Join(artifactsDir, "SingleNamespace", "p-testPackage-Subscription.json")).To(BeAnExistingFile())
+
+				aw := artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter)
+				Expect(aw.WriteManifest(nil)).To(Succeed())
+				_, err = artifacts.VerifyManifest(artifactsDir, nil)
+				Expect(err).ToNot(HaveOccurred())
+			})
+			Context("When the operator cannot be deployed in an install mode", func() {
+				BeforeEach(func() {
+					objects = objects[:2]
+				})
+				It("Should report the install mode that failed, and fail Validate", func() {
+					ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ok).To(BeFalse())
+					Expect(findings.List()).To(ConsistOf(HavePrefix("SingleNamespace: the operator could not be deployed:")))
+
+					contents, err := os.ReadFile(filepath.Join(artifactsDir, "SingleNamespace", "result.json"))
+					Expect(err).ToNot(HaveOccurred())
+					var result installModeResult
+					Expect(json.Unmarshal(contents, &result)).To(Succeed())
+					Expect(result.Passed).To(BeFalse())
+					Expect(result.Error).ToNot(BeEmpty())
+				})
+			})
+		})
+		Context("When the alm-examples are created", func() {
+			var (
+				almExamples  *ReconcilesAlmExamplesCheck
+				findings     *check.Findings
+				artifactsDir string
+				memcached    *unstructured.Unstructured
+				config       *unstructured.Unstructured
+			)
+			BeforeEach(func() {
+				imageRef.ImageFSPath = "./testdata/alm_examples"
+				almExamples = NewReconcilesAlmExamplesCheck(nil, 1*time.Second)
+				WithAlmExamples(almExamples)(&deployableByOLMCheck)
+				artifactsDir = artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter).Path()
+
+				// the fake client does not run the operator, so the examples are created with the
+				// status it would set.
+				memcached = newExample("Memcached", "memcached-sample", "p-testPackage-abcde-target", "Ready", "True")
+				config = newExample("MemcachedConfig", "memcachedconfig-sample", "", "Available", "True")
+			})
+			JustBeforeEach(func() {
+				scheme := apiruntime.NewScheme()
+				Expect(openshift.AddSchemes(scheme)).To(Succeed())
+				Expect(appsv1.AddToScheme(scheme)).To(Succeed())
+				deployableByOLMCheck.client = fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(&csvDefault, &csvMarketplace, &ns, &secret, &sub, &og, &deployment, &csv, memcached, config).
+					WithLists(&pods, &isList).
+					Build()
+			})
+			It("Should pass both checks if the operator reconciles them, and remove them", func() {
+				ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+
+				var ctx context.Context
+				ctx, findings = check.ContextWithFindings(testcontext)
+				ok, err = almExamples.Validate(ctx, imageRef)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ok).To(BeTrue())
+				Expect(findings.List()).To(BeEmpty())
+
+				Expect(filepath.Join(artifactsDir, "examples", "memcached-sample-Memcached.json")).To(BeAnExistingFile())
+				Expect(filepath.Join(artifactsDir, "examples", "memcachedconfig-sample-MemcachedConfig.json")).To(BeAnExistingFile())
+				Expect(filepath.Join(artifactsDir, "examples", "pod3-cont4.log")).To(BeAnExistingFile())
+
+				aw := artifacts.WriterFromContext(testcontext).(*artifacts.FilesystemWriter)
+				Expect(aw.WriteManifest(nil)).To(Succeed())
+				_, err = artifacts.VerifyManifest(artifactsDir, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = deployableByOLMCheck.client.Get(testcontext, crclient.ObjectKeyFromObject(memcached), memcached.DeepCopy())
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			})
+			Context("When the operator does not reconcile an example", func() {
+				BeforeEach(func() {
+					memcached = newExample("Memcached", "memcached-sample", "p-testPackage-abcde-target", "Ready", "False")
+				})
+				It("Should fail the alm-examples check, but not DeployableByOLM", func() {
+					ok, err := deployableByOLMCheck.Validate(testcontext, imageRef)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ok).To(BeTrue())
+
+					var ctx context.Context
+					ctx, findings = check.ContextWithFindings(testcontext)
+					ok, err = almExamples.Validate(ctx, imageRef)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(ok).To(BeFalse())
+					Expect(findings.List()).To(ContainElement(
+						"the example Memcached p-testPackage-abcde-target/memcached-sample was not reconciled: context deadline exceeded",
+					))
+				})
+			})
+		})
 		Context("When the non-default channel is being tested", func() {
 			BeforeEach(func() {
 				deployableByOLMCheck.channel = "non-default-channel"
@@ -181,21 +374,27 @@ var _ = Describe("DeployableByOLMCheck", func() {
 				Expect(data.TargetNamespace).To(Equal("this-is-a-very-long-package-name-that-exceeds-fift-abcde-target"), "target namespace should append -target to install namespace")
 			})
 		})
+		Context("When the namespace is of an install mode", func() {
+			It("Should add the install mode, and stay within the 63-char DNS label limit", func() {
+				namespace := deployableByOLMCheck.namespace("this-is-a-very-long-package-name-that-exceeds-fifty-characters", installModeNamespaces["SingleNamespace"])
+				Expect(namespace).To(Equal("this-is-a-very-long-package-name-that-excee-single-abcde"))
+				Expect(namespace + targetSuffix).To(HaveLen(63))
+			})
+		})
 	})
 
 	AssertMetaData(&deployableByOLMCheck)
-
-	DescribeTable("Image Registry validation",
-		func(bundleImages []string, expected bool) {
-			ok := checkImageSource(context.Background(), bundleImages)
-			Expect(ok).To(Equal(expected))
-		},
-		Entry("registry.connect.dev.redhat.com", []string{"registry.connect.dev.redhat.com/"}, true),
-		Entry("registry.connect.qa.redhat.com", []string{"registry.connect.qa.redhat.com/"}, true),
-		Entry("registry.connect.stage.redhat.com", []string{"registry.connect.stage.redhat.com/"}, true),
-		Entry("registry.connect.redhat.com", []string{"registry.connect.redhat.com"}, true),
-		Entry("registry.redhat.io", []string{"registry.redhat.io"}, true),
-		Entry("registry.access.redhat.com", []string{"registry.access.redhat.com/ubi8/ubi"}, true),
-		Entry("quay.io", []string{"quay.io/rocrisp/preflight-operator-bundle:v1"}, false),
-	)
 })
+
+// newExample returns an example of kind, with condition set to status.
+func newExample(kind, name, namespace, condition, status string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("cache.example.com/v1alpha1")
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	Expect(unstructured.SetNestedSlice(obj.Object, []any{
+		map[string]any{"type": condition, "status": status},
+	}, "status", "conditions")).To(Succeed())
+	return obj
+}
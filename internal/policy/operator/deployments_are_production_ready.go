@@ -0,0 +1,74 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/operator-framework/api/pkg/manifests"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
+)
+
+var _ check.Check = &DeploymentsAreProductionReadyCheck{}
+
+// DeploymentsAreProductionReadyCheck walks the containers of each deployment in the CSV, and reports
+// missing CPU and memory requests, memory limits, and liveness and readiness probes, digest-pinned
+// images pulled Always, and a missing priorityClassName for a cluster-critical operator. The check
+// is a warning unless the action of a category of findings is to fail.
+type DeploymentsAreProductionReadyCheck struct {
+	actions readiness.Actions
+}
+
+// NewDeploymentsAreProductionReadyCheck returns a check taking actions for each category of
+// findings. If actions is empty, readiness.DefaultActions are used.
+func NewDeploymentsAreProductionReadyCheck(actions readiness.Actions) *DeploymentsAreProductionReadyCheck {
+	if len(actions) == 0 {
+		actions = readiness.DefaultActions()
+	}
+	return &DeploymentsAreProductionReadyCheck{actions: actions}
+}
+
+func (p *DeploymentsAreProductionReadyCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	b, err := manifests.GetBundleFromDir(bundleRef.ImageFSPath)
+	if err != nil {
+		return false, fmt.Errorf("could not get bundle from dir: %s: %v", bundleRef.ImageFSPath, err)
+	}
+
+	passed := true
+	for _, finding := range p.actions.Evaluate(b.CSV) {
+		if action.Failed(finding.Action, p.actions.Fails()) {
+			passed = false
+		}
+		check.ReportFindings(ctx, finding.String())
+	}
+	return passed, nil
+}
+
+func (p *DeploymentsAreProductionReadyCheck) Name() string {
+	return "DeploymentsAreProductionReady"
+}
+
+func (p *DeploymentsAreProductionReadyCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the containers of the deployments in the CSV have resource requests, a memory limit, and probes",
+		Level:            action.Level(p.actions.Fails()),
+		KnowledgeBaseURL: "https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/",
+		CheckURL:         "https://sdk.operatorframework.io/docs/best-practices/best-practices/",
+	}
+}
+
+func (p *DeploymentsAreProductionReadyCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "It is recommended that your operator's pods can be scheduled in namespaces enforcing resource quotas, and are restarted when they are unhealthy",
+		Suggestion: "Set CPU and memory requests, and a memory limit, for each container, and liveness and readiness probes for each container that is not an init container. Use imagePullPolicy IfNotPresent for images pinned to a digest, and set a priorityClassName, e.g. system-cluster-critical, if the operator is cluster-critical.",
+	}
+}
+
+func (p *DeploymentsAreProductionReadyCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return bundle.BundleFiles
+}
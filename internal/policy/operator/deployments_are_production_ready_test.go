@@ -0,0 +1,101 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
+)
+
+var _ = Describe("DeploymentsAreProductionReady", func() {
+	var (
+		ctx       context.Context
+		findings  *check.Findings
+		bundleDir string
+	)
+
+	const (
+		// livenessProbe is the liveness probe of the manager container of the own_namespace bundle.
+		livenessProbe = "                livenessProbe:\n                  httpGet:\n                    path: /healthz\n                    port: 8081\n" +
+			"                  initialDelaySeconds: 15\n                  periodSeconds: 20\n"
+		// cpuLimitAndRequest is the CPU limit and request of the manager container of the own_namespace bundle.
+		cpuLimitAndRequest = "                    cpu: 100m\n"
+	)
+
+	// editCSV replaces old with replacement in the CSV of the bundle, count times.
+	editCSV := func(old, replacement string, count int) {
+		csvPath := filepath.Join(bundleDir, "manifests", "memcached-operator.clusterserviceversion.yaml")
+		contents, err := os.ReadFile(csvPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring(old))
+		Expect(os.WriteFile(csvPath, []byte(strings.Replace(string(contents), old, replacement, count)), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+		bundleDir = GinkgoT().TempDir()
+		Expect(os.CopyFS(bundleDir, os.DirFS("./testdata/own_namespace"))).To(Succeed())
+	})
+
+	AssertMetaData(NewDeploymentsAreProductionReadyCheck(nil))
+
+	It("should warn by default", func() {
+		Expect(NewDeploymentsAreProductionReadyCheck(nil).Metadata().Level).To(Equal(check.LevelWarn))
+		Expect(NewDeploymentsAreProductionReadyCheck(readiness.Actions{readiness.CategoryProbes: action.Fail}).Metadata().Level).To(Equal(check.LevelBest))
+	})
+
+	Context("When each container has resource requests, a memory limit, and probes", func() {
+		It("should pass", func() {
+			ok, err := NewDeploymentsAreProductionReadyCheck(nil).Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+	})
+
+	Context("When a container is missing settings", func() {
+		BeforeEach(func() {
+			editCSV(livenessProbe, "", 1)
+			editCSV(cpuLimitAndRequest, "", -1)
+		})
+
+		It("should report each, and fail so that it is warned of by default", func() {
+			ok, err := NewDeploymentsAreProductionReadyCheck(nil).Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(Equal([]string{
+				"deployment memcached-operator-controller-manager, container manager: has no cpu request",
+				"deployment memcached-operator-controller-manager, container manager: has no liveness probe",
+			}))
+		})
+
+		It("should only fail for the categories whose action is to fail", func() {
+			actions := readiness.DefaultActions()
+			actions[readiness.CategoryLimits] = action.Fail
+			ok, err := NewDeploymentsAreProductionReadyCheck(actions).Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(HaveLen(2))
+
+			actions[readiness.CategoryProbes] = action.Fail
+			ok, err = NewDeploymentsAreProductionReadyCheck(actions).Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("When the bundle cannot be read", func() {
+		It("should error", func() {
+			_, err := NewDeploymentsAreProductionReadyCheck(nil).Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/missing"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
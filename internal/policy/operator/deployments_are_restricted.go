@@ -0,0 +1,220 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ check.Check = &DeploymentsAreRestrictedCheck{}
+
+// DeploymentsAreRestrictedCheck evaluates the pod template of each deployment in the CSV against the
+// OpenShift restricted-v2 SCC, and the Kubernetes restricted Pod Security Standard, which namespaces
+// enforcing Pod Security Admission require. The violations of each container are reported, and the
+// check fails if any is not assumed to be allowed by a custom SCC the CSV grants the use of to the
+// service account of its deployment, as returned by bundle.SecurityContextConstraintsByServiceAccount.
+type DeploymentsAreRestrictedCheck struct{}
+
+// restrictedSCCs allow nothing the restricted-v2 SCC does not, so granting them allows no violation.
+var restrictedSCCs = []string{"restricted", "restricted-v2"}
+
+// postureViolation is a setting of a deployment, or one of its containers, that the restricted-v2
+// SCC or the restricted Pod Security Standard does not allow.
+type postureViolation struct {
+	Deployment string
+	// Container is empty if the setting is of the pod.
+	Container string
+	Reason    string
+	// Defaulted is true if the restricted-v2 SCC sets the setting when it is unset, so that only
+	// the restricted Pod Security Standard is violated.
+	Defaulted bool
+}
+
+// String returns the violation as it is reported.
+func (v postureViolation) String() string {
+	subject := "deployment " + v.Deployment
+	if v.Container != "" {
+		subject += ", container " + v.Container
+	}
+	standards := "the restricted-v2 SCC and the restricted Pod Security Standard"
+	if v.Defaulted {
+		standards = "the restricted Pod Security Standard"
+	}
+	return fmt.Sprintf("%s: %s, which violates %s", subject, v.Reason, standards)
+}
+
+// allowedCapability is the only capability the restricted Pod Security Standard allows containers to add.
+const allowedCapability corev1.Capability = "NET_BIND_SERVICE"
+
+func (p *DeploymentsAreRestrictedCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	b, err := manifests.GetBundleFromDir(bundleRef.ImageFSPath)
+	if err != nil {
+		return false, fmt.Errorf("could not get bundle from dir: %s: %v", bundleRef.ImageFSPath, err)
+	}
+
+	sccs := bundle.SecurityContextConstraintsByServiceAccount(b.CSV)
+
+	passed := true
+	for _, deployment := range b.CSV.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		violations := evaluatePosture(deployment)
+		if len(violations) == 0 {
+			continue
+		}
+
+		serviceAccount := deployment.Spec.Template.Spec.ServiceAccountName
+		custom := slices.DeleteFunc(slices.Clone(sccs[serviceAccount]), func(scc string) bool {
+			return slices.Contains(restrictedSCCs, scc)
+		})
+		for _, violation := range violations {
+			if slices.Contains(custom, rbacv1.ResourceAll) {
+				check.ReportFindings(ctx, fmt.Sprintf("%s, which every SCC, including privileged, granted to service account %s is assumed to allow",
+					violation, serviceAccount))
+				continue
+			}
+			if len(custom) > 0 {
+				check.ReportFindings(ctx, fmt.Sprintf("%s, which the custom SCC %s granted to service account %s is assumed to allow",
+					violation, strings.Join(custom, ", "), serviceAccount))
+				continue
+			}
+			check.ReportFindings(ctx, violation.String())
+			passed = false
+		}
+	}
+
+	return passed, nil
+}
+
+// evaluatePosture returns the settings of the pod template of deployment, and of each of its
+// containers, that the restricted-v2 SCC or the restricted Pod Security Standard does not allow.
+func evaluatePosture(deployment operatorsv1alpha1.StrategyDeploymentSpec) []postureViolation {
+	pod := deployment.Spec.Template.Spec
+
+	var violations []postureViolation
+	report := func(container, reason string, defaulted bool) {
+		violations = append(violations, postureViolation{
+			Deployment: deployment.Name,
+			Container:  container,
+			Reason:     reason,
+			Defaulted:  defaulted,
+		})
+	}
+
+	if pod.HostNetwork {
+		report("", "hostNetwork is true", false)
+	}
+	if pod.HostPID {
+		report("", "hostPID is true", false)
+	}
+	if pod.HostIPC {
+		report("", "hostIPC is true", false)
+	}
+	for _, volume := range pod.Volumes {
+		if volume.HostPath != nil {
+			report("", fmt.Sprintf("volume %s is a hostPath of %s", volume.Name, volume.HostPath.Path), false)
+		}
+	}
+
+	podContext := pod.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+
+	containers := slices.Concat(pod.InitContainers, pod.Containers)
+	for _, container := range containers {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+
+		if sc.Privileged != nil && *sc.Privileged {
+			report(container.Name, "privileged is true", false)
+		}
+
+		switch {
+		case sc.AllowPrivilegeEscalation == nil:
+			report(container.Name, "allowPrivilegeEscalation is not set to false", true)
+		case *sc.AllowPrivilegeEscalation:
+			report(container.Name, "allowPrivilegeEscalation is true", false)
+		}
+
+		// the settings of the container override those of the pod.
+		runAsNonRoot := firstSet(sc.RunAsNonRoot, podContext.RunAsNonRoot)
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			report(container.Name, "runAsNonRoot is not set to true", true)
+		}
+		if runAsUser := firstSet(sc.RunAsUser, podContext.RunAsUser); runAsUser != nil && *runAsUser == 0 {
+			report(container.Name, "runAsUser is 0", false)
+		}
+
+		var capabilities corev1.Capabilities
+		if sc.Capabilities != nil {
+			capabilities = *sc.Capabilities
+		}
+		if !slices.Contains(capabilities.Drop, "ALL") {
+			report(container.Name, "capabilities do not drop ALL", true)
+		}
+		for _, capability := range capabilities.Add {
+			if capability != allowedCapability {
+				report(container.Name, fmt.Sprintf("capability %s is added", capability), false)
+			}
+		}
+
+		seccompProfile := sc.SeccompProfile
+		if seccompProfile == nil {
+			seccompProfile = podContext.SeccompProfile
+		}
+		switch {
+		case seccompProfile == nil:
+			report(container.Name, "seccompProfile is not set to RuntimeDefault or Localhost", true)
+		case seccompProfile.Type == corev1.SeccompProfileTypeUnconfined:
+			report(container.Name, "seccompProfile is Unconfined", false)
+		}
+	}
+
+	return violations
+}
+
+// firstSet returns the first of values that is set.
+func firstSet[T any](values ...*T) *T {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+func (p *DeploymentsAreRestrictedCheck) Name() string {
+	return "DeploymentsAreRestricted"
+}
+
+func (p *DeploymentsAreRestrictedCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the deployments in the CSV meet the restricted-v2 SCC and the restricted Pod Security Standard",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: "https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted",
+		CheckURL:         "https://docs.openshift.com/container-platform/latest/authentication/understanding-and-managing-pod-security-admission.html",
+	}
+}
+
+func (p *DeploymentsAreRestrictedCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "It is recommended that the deployments of your operator run in namespaces enforcing the restricted Pod Security Standard",
+		Suggestion: "Set runAsNonRoot to true, allowPrivilegeEscalation to false, drop ALL capabilities, and set a RuntimeDefault seccompProfile in the securityContext of each container, and do not use privileged containers, hostNetwork, hostPID, hostIPC or hostPath volumes. If the operator requires these, request a custom SCC for its service account in the clusterPermissions of the CSV.",
+	}
+}
+
+func (p *DeploymentsAreRestrictedCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return bundle.BundleFiles
+}
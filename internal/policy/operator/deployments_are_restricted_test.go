@@ -0,0 +1,224 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("DeploymentsAreRestricted", func() {
+	var (
+		deploymentsAreRestricted DeploymentsAreRestrictedCheck
+		ctx                      context.Context
+		findings                 *check.Findings
+		bundleDir                string
+	)
+
+	const (
+		ownNamespaceCSV = "memcached-operator.clusterserviceversion.yaml"
+		// managerContext is the securityContext of the manager container of the own_namespace bundle.
+		managerContext = "                securityContext:\n                  allowPrivilegeEscalation: false\n"
+		// restrictedContext is managerContext, meeting the restricted Pod Security Standard.
+		restrictedContext = managerContext +
+			"                  capabilities:\n                    drop:\n                    - ALL\n" +
+			"                  seccompProfile:\n                    type: RuntimeDefault\n"
+		clusterRules = "      clusterPermissions:\n      - rules:\n"
+		// customSCCRule requests the use of a custom SCC for the service account of the manager.
+		customSCCRule = clusterRules +
+			"        - apiGroups:\n          - security.openshift.io\n          resources:\n          - securitycontextconstraints\n" +
+			"          resourceNames:\n          - custom\n          verbs:\n          - use\n"
+	)
+
+	// editCSV replaces old with replacement in the CSV of the bundle.
+	editCSV := func(old, replacement string) {
+		csvPath := filepath.Join(bundleDir, "manifests", ownNamespaceCSV)
+		contents, err := os.ReadFile(csvPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring(old))
+		Expect(os.WriteFile(csvPath, []byte(strings.Replace(string(contents), old, replacement, 1)), 0o644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+		bundleDir = GinkgoT().TempDir()
+		Expect(os.CopyFS(bundleDir, os.DirFS("./testdata/own_namespace"))).To(Succeed())
+	})
+
+	AssertMetaData(&deploymentsAreRestricted)
+
+	Context("When the deployments meet the restricted Pod Security Standard", func() {
+		It("should pass", func() {
+			editCSV(managerContext, restrictedContext)
+
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+	})
+
+	Context("When a container violates the restricted Pod Security Standard", func() {
+		It("should fail, and report each violation", func() {
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(Equal([]string{
+				"deployment memcached-operator-controller-manager, container manager: capabilities do not drop ALL, which violates the restricted Pod Security Standard",
+				"deployment memcached-operator-controller-manager, container manager: seccompProfile is not set to RuntimeDefault or Localhost, which violates the restricted Pod Security Standard",
+			}))
+		})
+
+		It("should pass, and report each violation the SCC is assumed to allow, if the use of a custom SCC is granted to its service account", func() {
+			editCSV(clusterRules, customSCCRule)
+
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(Equal([]string{
+				"deployment memcached-operator-controller-manager, container manager: capabilities do not drop ALL, which violates the restricted Pod Security Standard, which the custom SCC custom granted to service account memcached-operator-controller-manager is assumed to allow",
+				"deployment memcached-operator-controller-manager, container manager: seccompProfile is not set to RuntimeDefault or Localhost, which violates the restricted Pod Security Standard, which the custom SCC custom granted to service account memcached-operator-controller-manager is assumed to allow",
+			}))
+		})
+
+		It("should pass if the use of every SCC is granted to its service account", func() {
+			editCSV(clusterRules, strings.Replace(customSCCRule, "          resourceNames:\n          - custom\n", "", 1))
+
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(HaveLen(2))
+			for _, finding := range findings.List() {
+				Expect(finding).To(HaveSuffix("which every SCC, including privileged, granted to service account memcached-operator-controller-manager is assumed to allow"))
+			}
+		})
+
+		It("should fail if the SCC granted to its service account is restricted", func() {
+			editCSV(clusterRules, strings.Replace(customSCCRule, "- custom", "- restricted-v2", 1))
+
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(2))
+		})
+
+		It("should fail if the use of the custom SCC is not granted", func() {
+			editCSV(clusterRules, strings.Replace(customSCCRule, "- use", "- get", 1))
+
+			ok, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(HaveLen(2))
+		})
+	})
+
+	Context("When the bundle cannot be read", func() {
+		It("should error", func() {
+			_, err := deploymentsAreRestricted.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/missing"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	// restricted returns a container meeting the restricted Pod Security Standard, changed by edit.
+	restricted := func(name string, edit func(*corev1.SecurityContext)) corev1.Container {
+		sc := &corev1.SecurityContext{
+			RunAsNonRoot:             ptr.To(true),
+			AllowPrivilegeEscalation: ptr.To(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		}
+		if edit != nil {
+			edit(sc)
+		}
+		return corev1.Container{Name: name, SecurityContext: sc}
+	}
+
+	DescribeTable("evaluating the posture of a deployment",
+		func(pod corev1.PodSpec, expected ...string) {
+			deployment := operatorsv1alpha1.StrategyDeploymentSpec{Name: "operator"}
+			deployment.Spec.Template.Spec = pod
+
+			var reported []string
+			for _, violation := range evaluatePosture(deployment) {
+				reported = append(reported, violation.String())
+			}
+			if len(expected) == 0 {
+				Expect(reported).To(BeEmpty())
+				return
+			}
+			Expect(reported).To(Equal(expected))
+		},
+		Entry("restricted containers",
+			corev1.PodSpec{Containers: []corev1.Container{restricted("manager", nil)}}),
+		Entry("settings of the pod",
+			corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{
+					RunAsNonRoot:   ptr.To(true),
+					SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost},
+				},
+				Containers: []corev1.Container{restricted("manager", func(sc *corev1.SecurityContext) {
+					sc.RunAsNonRoot = nil
+					sc.SeccompProfile = nil
+				})},
+			}),
+		Entry("a container without a securityContext",
+			corev1.PodSpec{Containers: []corev1.Container{{Name: "manager"}}},
+			"deployment operator, container manager: allowPrivilegeEscalation is not set to false, which violates the restricted Pod Security Standard",
+			"deployment operator, container manager: runAsNonRoot is not set to true, which violates the restricted Pod Security Standard",
+			"deployment operator, container manager: capabilities do not drop ALL, which violates the restricted Pod Security Standard",
+			"deployment operator, container manager: seccompProfile is not set to RuntimeDefault or Localhost, which violates the restricted Pod Security Standard"),
+		Entry("a privileged container",
+			corev1.PodSpec{Containers: []corev1.Container{restricted("manager", func(sc *corev1.SecurityContext) {
+				sc.Privileged = ptr.To(true)
+				sc.AllowPrivilegeEscalation = ptr.To(true)
+			})}},
+			"deployment operator, container manager: privileged is true, which violates the restricted-v2 SCC and the restricted Pod Security Standard",
+			"deployment operator, container manager: allowPrivilegeEscalation is true, which violates the restricted-v2 SCC and the restricted Pod Security Standard"),
+		Entry("a container running as root",
+			corev1.PodSpec{
+				SecurityContext: &corev1.PodSecurityContext{RunAsUser: ptr.To[int64](0)},
+				Containers: []corev1.Container{restricted("manager", func(sc *corev1.SecurityContext) {
+					sc.RunAsNonRoot = ptr.To(false)
+				})},
+			},
+			"deployment operator, container manager: runAsNonRoot is not set to true, which violates the restricted Pod Security Standard",
+			"deployment operator, container manager: runAsUser is 0, which violates the restricted-v2 SCC and the restricted Pod Security Standard"),
+		Entry("added capabilities",
+			corev1.PodSpec{Containers: []corev1.Container{restricted("manager", func(sc *corev1.SecurityContext) {
+				sc.Capabilities.Add = []corev1.Capability{"NET_BIND_SERVICE", "NET_ADMIN"}
+			})}},
+			"deployment operator, container manager: capability NET_ADMIN is added, which violates the restricted-v2 SCC and the restricted Pod Security Standard"),
+		Entry("an unconfined init container",
+			corev1.PodSpec{
+				InitContainers: []corev1.Container{restricted("init", func(sc *corev1.SecurityContext) {
+					sc.SeccompProfile.Type = corev1.SeccompProfileTypeUnconfined
+				})},
+				Containers: []corev1.Container{restricted("manager", nil)},
+			},
+			"deployment operator, container init: seccompProfile is Unconfined, which violates the restricted-v2 SCC and the restricted Pod Security Standard"),
+		Entry("host namespaces and paths",
+			corev1.PodSpec{
+				HostNetwork: true,
+				HostPID:     true,
+				HostIPC:     true,
+				Volumes: []corev1.Volume{
+					{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{}}},
+					{Name: "docker", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/docker.sock"}}},
+				},
+				Containers: []corev1.Container{restricted("manager", nil)},
+			},
+			"deployment operator: hostNetwork is true, which violates the restricted-v2 SCC and the restricted Pod Security Standard",
+			"deployment operator: hostPID is true, which violates the restricted-v2 SCC and the restricted Pod Security Standard",
+			"deployment operator: hostIPC is true, which violates the restricted-v2 SCC and the restricted Pod Security Standard",
+			"deployment operator: volume docker is a hostPath of /var/run/docker.sock, which violates the restricted-v2 SCC and the restricted Pod Security Standard"),
+	)
+})
@@ -0,0 +1,155 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+var _ check.Check = &ImagesFromApprovedRegistriesCheck{}
+
+// DefaultApprovedRegistries are the registries the images of an operator are approved to be
+// pulled from, if none are given.
+var DefaultApprovedRegistries = []string{
+	"registry.connect.dev.redhat.com",
+	"registry.connect.qa.redhat.com",
+	"registry.connect.stage.redhat.com",
+	"registry.connect.redhat.com",
+	"registry.redhat.io",
+	"registry.access.redhat.com",
+}
+
+// ImagesFromApprovedRegistriesCheck checks that the images pulled by the pods of the operator
+// while DeployableByOLM has it installed, including those of init and ephemeral containers, are
+// from approved registries. It does not deploy the operator itself, so it must be given to
+// DeployableByOLM, with WithImageSources.
+type ImagesFromApprovedRegistriesCheck struct {
+	registries []string
+
+	// ran is set once DeployableByOLM has audited the images of the operator.
+	ran      bool
+	passed   bool
+	findings []string
+	sources  []imageSource
+}
+
+// imageSource is an image pulled by the pods of the operator, and the registry it was pulled from.
+type imageSource struct {
+	// InstallMode is the install mode the operator was deployed in, when it is deployed in each.
+	InstallMode string `json:"installMode,omitempty"`
+	Image       string `json:"image"`
+	Registry    string `json:"registry"`
+	Approved    bool   `json:"approved"`
+}
+
+// NewImagesFromApprovedRegistriesCheck returns a check that the images of the operator are from
+// one of registries. DefaultApprovedRegistries are used if registries is empty.
+func NewImagesFromApprovedRegistriesCheck(registries []string) *ImagesFromApprovedRegistriesCheck {
+	if len(registries) == 0 {
+		registries = DefaultApprovedRegistries
+	}
+	return &ImagesFromApprovedRegistriesCheck{
+		registries: registries,
+		passed:     true,
+	}
+}
+
+// Validate reports the images pulled by the pods of the operator when DeployableByOLM installed it,
+// and writes them to the artifacts.
+func (p *ImagesFromApprovedRegistriesCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	if !p.ran {
+		check.ReportFindings(ctx, "the operator was not installed by DeployableByOLM, so its images were not audited")
+		return false, nil
+	}
+	check.ReportFindings(ctx, p.findings...)
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		if err := writeJSON(artifactWriter, imageSourcesFilename, p.sources); err != nil {
+			//coverage:ignore
+			logger.Error(err, "failed to write the image sources to the artifacts")
+		}
+	}
+	return p.passed, nil
+}
+
+// audit records the registry of each of images, pulled by the pods of the operator deployed with
+// operatorData, and whether it is approved. It is run by DeployableByOLM once the CSV has been
+// waited on, and before it cleans up.
+func (p *ImagesFromApprovedRegistriesCheck) audit(ctx context.Context, images []string, operatorData operatorData) {
+	logger := logr.FromContextOrDiscard(ctx)
+	logger.V(log.DBG).Info("checking that images are from approved sources", "registries", p.registries)
+
+	p.ran = true
+	// the install mode is added to findings when DeployableByOLM deploys the operator in each of them.
+	prefix := ""
+	if operatorData.InstallMode != "" {
+		prefix = string(operatorData.InstallMode) + ": "
+	}
+
+	slices.Sort(images)
+	for _, img := range images {
+		source := imageSource{
+			InstallMode: string(operatorData.InstallMode),
+			Image:       img,
+			Registry:    registry(img),
+		}
+		source.Approved = slices.Contains(p.registries, source.Registry)
+		p.sources = append(p.sources, source)
+
+		if source.Approved {
+			continue
+		}
+		logger.Info("warning: unapproved registry found for image", "image", img, "registry", source.Registry)
+		p.passed = false
+		p.findings = append(p.findings, fmt.Sprintf("%simage %s is from unapproved registry %s", prefix, img, source.Registry))
+	}
+}
+
+// registry returns the registry of img, which is docker.io for images without one.
+func registry(img string) string {
+	ref, err := name.ParseReference(img, name.WeakValidation)
+	if err != nil {
+		return strings.Split(img, "/")[0]
+	}
+	if ref.Context().RegistryStr() == name.DefaultRegistry {
+		return "docker.io"
+	}
+	return ref.Context().RegistryStr()
+}
+
+func (p *ImagesFromApprovedRegistriesCheck) Name() string {
+	return "ImagesFromApprovedRegistries"
+}
+
+func (p *ImagesFromApprovedRegistriesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the images pulled by the operator's pods are from approved registries",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: "https://sdk.operatorframework.io/docs/olm-integration/testing-deployment/",
+		CheckURL:         "https://sdk.operatorframework.io/docs/olm-integration/testing-deployment/",
+	}
+}
+
+func (p *ImagesFromApprovedRegistriesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "It is recommended that the images pulled by your operator are from approved registries",
+		Suggestion: "Publish the operator and operand images to an approved registry, and reference them from there. Each image and its registry is in the artifacts.",
+	}
+}
+
+func (p *ImagesFromApprovedRegistriesCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return bundle.BundleFiles
+}
@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("ImagesFromApprovedRegistries", func() {
+	var (
+		imagesFromApprovedRegistries *ImagesFromApprovedRegistriesCheck
+		ctx                          context.Context
+		findings                     *check.Findings
+	)
+
+	BeforeEach(func() {
+		imagesFromApprovedRegistries = NewImagesFromApprovedRegistriesCheck(nil)
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	AssertMetaData(NewImagesFromApprovedRegistriesCheck(nil))
+
+	It("should approve the default registries if none are given", func() {
+		Expect(imagesFromApprovedRegistries.registries).To(Equal(DefaultApprovedRegistries))
+		Expect(NewImagesFromApprovedRegistriesCheck([]string{"quay.io"}).registries).To(Equal([]string{"quay.io"}))
+	})
+
+	Context("When DeployableByOLM did not install the operator", func() {
+		It("should fail", func() {
+			ok, err := imagesFromApprovedRegistries.Validate(ctx, image.ImageReference{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(ContainSubstring("the operator was not installed by DeployableByOLM")))
+		})
+	})
+
+	Context("When the images of the operator were audited", func() {
+		It("should pass if every image is from an approved registry", func() {
+			imagesFromApprovedRegistries.audit(ctx, []string{"registry.redhat.io/ubi9/ubi:latest", "registry.access.redhat.com/ubi8/ubi"}, operatorData{})
+			ok, err := imagesFromApprovedRegistries.Validate(ctx, image.ImageReference{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+
+		It("should fail, and write each image to the artifacts, if an image is from an unapproved registry", func() {
+			artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+			Expect(err).ToNot(HaveOccurred())
+			ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+			imagesFromApprovedRegistries.audit(ctx, []string{"registry.redhat.io/ubi9/ubi:latest"}, operatorData{InstallMode: "OwnNamespace"})
+			imagesFromApprovedRegistries.audit(ctx, []string{"quay.io/example/operator:v1", "busybox"}, operatorData{InstallMode: "AllNamespaces"})
+			ok, err := imagesFromApprovedRegistries.Validate(ctx, image.ImageReference{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(Equal([]string{
+				"AllNamespaces: image busybox is from unapproved registry docker.io",
+				"AllNamespaces: image quay.io/example/operator:v1 is from unapproved registry quay.io",
+			}))
+			Expect(imagesFromApprovedRegistries.sources).To(ContainElement(imageSource{
+				InstallMode: "OwnNamespace",
+				Image:       "registry.redhat.io/ubi9/ubi:latest",
+				Registry:    "registry.redhat.io",
+				Approved:    true,
+			}))
+			Expect(imagesFromApprovedRegistries.sources).To(ContainElement(imageSource{
+				InstallMode: "AllNamespaces",
+				Image:       "quay.io/example/operator:v1",
+				Registry:    "quay.io",
+			}))
+			Expect(filepath.Join(artifactsWriter.Path(), imageSourcesFilename)).To(BeAnExistingFile())
+		})
+	})
+
+	DescribeTable("Image registry parsing",
+		func(img, expected string) {
+			Expect(registry(img)).To(Equal(expected))
+		},
+		Entry("registry.connect.redhat.com", "registry.connect.redhat.com/foo/bar:v1", "registry.connect.redhat.com"),
+		Entry("registry.redhat.io with a digest", "registry.redhat.io/foo/bar@sha256:"+"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "registry.redhat.io"),
+		Entry("a registry with a port", "localhost:5000/foo/bar:v1", "localhost:5000"),
+		Entry("docker hub", "busybox:latest", "docker.io"),
+		Entry("docker hub with an organization", "library/busybox", "docker.io"),
+	)
+})
@@ -0,0 +1,230 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ check.Check = &RBACFollowsLeastPrivilegeCheck{}
+
+// Severity is how much a permission requested by the operator widens its privileges.
+type Severity string
+
+const (
+	// SeverityHigh permissions give the operator control of the cluster, or let it escalate its privileges.
+	SeverityHigh Severity = "high"
+	// SeverityMedium permissions are broader than the operator is likely to need.
+	SeverityMedium Severity = "medium"
+	// SeverityLow permissions are loosely scoped, but limited.
+	SeverityLow Severity = "low"
+)
+
+// severities are the severities, from the most to the least severe.
+var severities = []Severity{SeverityHigh, SeverityMedium, SeverityLow}
+
+// The scopes of the permissions in a CSV.
+const (
+	scopeCluster   = "cluster"
+	scopeNamespace = "namespace"
+)
+
+// escalatingVerbs are the verbs that let a subject grant itself, or act with, privileges it does not have.
+var escalatingVerbs = []string{"bind", "escalate", "impersonate"}
+
+// RBACFollowsLeastPrivilegeCheck analyzes the rules of the permissions and cluster permissions in
+// the CSV, and reports those that are broader than an operator is likely to need: wildcards, access
+// to secrets in every namespace, the verbs that allow privilege escalation, creating pods and role
+// bindings, and cluster permissions for an operator that only supports the OwnNamespace install mode.
+// Each finding is classified by its Severity, and the check fails if any is high.
+type RBACFollowsLeastPrivilegeCheck struct{}
+
+// rbacFinding is a permission of the operator that is broader than it is likely to need.
+type rbacFinding struct {
+	Severity       Severity `json:"severity"`
+	Scope          string   `json:"scope"`
+	ServiceAccount string   `json:"serviceAccount"`
+	// Rule is the rule granting the permission, if the finding is about a single rule.
+	Rule   *rbacv1.PolicyRule `json:"rule,omitempty"`
+	Reason string             `json:"reason"`
+}
+
+// String returns the finding as it is reported.
+func (f rbacFinding) String() string {
+	if f.Rule == nil {
+		return fmt.Sprintf("%s: %s", f.Severity, f.Reason)
+	}
+	return fmt.Sprintf("%s: %s permissions of service account %s %s: apiGroups=%q resources=%v verbs=%v",
+		f.Severity, f.Scope, f.ServiceAccount, f.Reason, f.Rule.APIGroups, f.Rule.Resources, f.Rule.Verbs)
+}
+
+func (p *RBACFollowsLeastPrivilegeCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	b, err := manifests.GetBundleFromDir(bundleRef.ImageFSPath)
+	if err != nil {
+		return false, fmt.Errorf("could not get bundle from dir: %s: %v", bundleRef.ImageFSPath, err)
+	}
+
+	findings := analyzePermissions(b.CSV)
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		if err := writeJSON(artifactWriter, rbacAnalysisFilename, findings); err != nil {
+			//coverage:ignore
+			logger.Error(err, "failed to write the RBAC analysis to the artifacts")
+		}
+	}
+
+	passed := true
+	for _, finding := range findings {
+		check.ReportFindings(ctx, finding.String())
+		if finding.Severity == SeverityHigh {
+			passed = false
+		}
+	}
+	return passed, nil
+}
+
+// analyzePermissions returns the permissions of csv that are broader than the operator is likely
+// to need, from the most to the least severe.
+func analyzePermissions(csv *operatorsv1alpha1.ClusterServiceVersion) []rbacFinding {
+	strategy := csv.Spec.InstallStrategy.StrategySpec
+
+	var findings []rbacFinding
+	for _, permission := range strategy.ClusterPermissions {
+		findings = append(findings, analyzeRules(scopeCluster, permission)...)
+	}
+	for _, permission := range strategy.Permissions {
+		findings = append(findings, analyzeRules(scopeNamespace, permission)...)
+	}
+
+	if onlyOwnNamespace(csv.Spec.InstallModes) {
+		for _, permission := range strategy.ClusterPermissions {
+			findings = append(findings, rbacFinding{
+				Severity:       SeverityMedium,
+				Scope:          scopeCluster,
+				ServiceAccount: permission.ServiceAccountName,
+				Reason: fmt.Sprintf("the operator only supports the %s install mode, but requests cluster permissions for service account %s",
+					operatorsv1alpha1.InstallModeTypeOwnNamespace, permission.ServiceAccountName),
+			})
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b rbacFinding) int {
+		return slices.Index(severities, a.Severity) - slices.Index(severities, b.Severity)
+	})
+	return findings
+}
+
+// analyzeRules returns the rules of permission, whose scope is cluster or namespace, that are
+// broader than the operator is likely to need.
+func analyzeRules(scope string, permission operatorsv1alpha1.StrategyDeploymentPermissions) []rbacFinding {
+	// the severity of a permission, if it is cluster or namespace scoped.
+	severity := func(cluster, namespace Severity) Severity {
+		if scope == scopeCluster {
+			return cluster
+		}
+		return namespace
+	}
+
+	var findings []rbacFinding
+	for _, rule := range permission.Rules {
+		add := func(severity Severity, reason string) {
+			findings = append(findings, rbacFinding{
+				Severity:       severity,
+				Scope:          scope,
+				ServiceAccount: permission.ServiceAccountName,
+				Rule:           &rule,
+				Reason:         reason,
+			})
+		}
+
+		// rules of non-resource URLs, e.g. /metrics, are not analyzed.
+		if len(rule.Resources) == 0 {
+			continue
+		}
+
+		if slices.Contains(rule.Verbs, rbacv1.VerbAll) {
+			add(severity(SeverityHigh, SeverityMedium), "grant every verb (*)")
+		}
+		if slices.Contains(rule.Resources, rbacv1.ResourceAll) {
+			add(severity(SeverityHigh, SeverityMedium), "grant every resource (*)")
+		}
+		if slices.Contains(rule.APIGroups, rbacv1.APIGroupAll) {
+			add(severity(SeverityMedium, SeverityLow), "grant every API group (*)")
+		}
+
+		core := slices.Contains(rule.APIGroups, "") || slices.Contains(rule.APIGroups, rbacv1.APIGroupAll)
+		if scope == scopeCluster && core && slices.Contains(rule.Resources, "secrets") {
+			add(SeverityHigh, "grant access to secrets in every namespace")
+		}
+
+		for _, verb := range escalatingVerbs {
+			if slices.Contains(rule.Verbs, verb) {
+				add(SeverityHigh, fmt.Sprintf("grant the %s verb, which allows privilege escalation", verb))
+			}
+		}
+
+		if !slices.Contains(rule.Verbs, "create") {
+			continue
+		}
+		if core && slices.Contains(rule.Resources, "pods") {
+			add(severity(SeverityHigh, SeverityMedium), "grant creating pods, which can run as any service account in their namespace")
+		}
+		rbac := slices.Contains(rule.APIGroups, rbacv1.GroupName) || slices.Contains(rule.APIGroups, rbacv1.APIGroupAll)
+		for _, resource := range []string{"rolebindings", "clusterrolebindings"} {
+			if rbac && slices.Contains(rule.Resources, resource) {
+				add(severity(SeverityHigh, SeverityMedium), fmt.Sprintf("grant creating %s, which can bind roles to any subject", resource))
+			}
+		}
+	}
+	return findings
+}
+
+// onlyOwnNamespace returns true if OwnNamespace is the only install mode in installModes that is supported.
+func onlyOwnNamespace(installModes []operatorsv1alpha1.InstallMode) bool {
+	supported := 0
+	ownNamespace := false
+	for _, mode := range installModes {
+		if !mode.Supported {
+			continue
+		}
+		supported++
+		ownNamespace = ownNamespace || mode.Type == operatorsv1alpha1.InstallModeTypeOwnNamespace
+	}
+	return supported == 1 && ownNamespace
+}
+
+func (p *RBACFollowsLeastPrivilegeCheck) Name() string {
+	return "RBACFollowsLeastPrivilege"
+}
+
+func (p *RBACFollowsLeastPrivilegeCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the permissions requested in the CSV follow least privilege",
+		Level:            check.LevelWarn,
+		KnowledgeBaseURL: "https://kubernetes.io/docs/concepts/security/rbac-good-practices/",
+		CheckURL:         "https://sdk.operatorframework.io/docs/building-operators/golang/operator-scope/",
+	}
+}
+
+func (p *RBACFollowsLeastPrivilegeCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "It is recommended that your operator only requests the permissions it needs",
+		Suggestion: "Replace wildcards with the API groups, resources and verbs the operator uses, request namespaced permissions rather than cluster permissions where possible, and avoid the bind, escalate and impersonate verbs, and creating pods and role bindings, unless the operator requires them. Each finding, with its severity, is written to the artifacts.",
+	}
+}
+
+func (p *RBACFollowsLeastPrivilegeCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return bundle.BundleFiles
+}
@@ -0,0 +1,174 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("RBACFollowsLeastPrivilege", func() {
+	var (
+		rbacFollowsLeastPrivilege RBACFollowsLeastPrivilegeCheck
+		ctx                       context.Context
+		findings                  *check.Findings
+	)
+
+	BeforeEach(func() {
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	AssertMetaData(&rbacFollowsLeastPrivilege)
+
+	// rbacCSV returns a CSV supporting installModes, whose service account has the cluster
+	// permissions and permissions of clusterRules and rules.
+	rbacCSV := func(clusterRules, rules []rbacv1.PolicyRule, installModes ...operatorsv1alpha1.InstallModeType) *operatorsv1alpha1.ClusterServiceVersion {
+		csv := &operatorsv1alpha1.ClusterServiceVersion{}
+		strategy := &csv.Spec.InstallStrategy.StrategySpec
+		if clusterRules != nil {
+			strategy.ClusterPermissions = []operatorsv1alpha1.StrategyDeploymentPermissions{{ServiceAccountName: "manager", Rules: clusterRules}}
+		}
+		if rules != nil {
+			strategy.Permissions = []operatorsv1alpha1.StrategyDeploymentPermissions{{ServiceAccountName: "manager", Rules: rules}}
+		}
+		for _, mode := range prioritizedInstallModes {
+			csv.Spec.InstallModes = append(csv.Spec.InstallModes, operatorsv1alpha1.InstallMode{Type: mode, Supported: len(installModes) == 0})
+		}
+		for _, mode := range installModes {
+			for i := range csv.Spec.InstallModes {
+				if csv.Spec.InstallModes[i].Type == mode {
+					csv.Spec.InstallModes[i].Supported = true
+				}
+			}
+		}
+		return csv
+	}
+
+	Context("When the operator requests only the permissions it needs", func() {
+		It("should pass, and write no findings to the artifacts", func() {
+			artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+			Expect(err).ToNot(HaveOccurred())
+			ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+			ok, err := rbacFollowsLeastPrivilege.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/own_namespace"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+
+			contents, err := os.ReadFile(filepath.Join(artifactsWriter.Path(), rbacAnalysisFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("null"))
+		})
+	})
+
+	Context("When the bundle cannot be read", func() {
+		It("should error", func() {
+			_, err := rbacFollowsLeastPrivilege.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/missing"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	DescribeTable("analyzing the permissions of the CSV",
+		func(clusterRules, rules []rbacv1.PolicyRule, expected ...string) {
+			var reported []string
+			for _, finding := range analyzePermissions(rbacCSV(clusterRules, rules)) {
+				reported = append(reported, finding.String())
+			}
+			if len(expected) == 0 {
+				Expect(reported).To(BeEmpty())
+				return
+			}
+			Expect(reported).To(Equal(expected))
+		},
+		Entry("cluster wildcards",
+			[]rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}}, nil,
+			`high: cluster permissions of service account manager grant every verb (*): apiGroups=["*"] resources=[*] verbs=[*]`,
+			`high: cluster permissions of service account manager grant every resource (*): apiGroups=["*"] resources=[*] verbs=[*]`,
+			`medium: cluster permissions of service account manager grant every API group (*): apiGroups=["*"] resources=[*] verbs=[*]`),
+		Entry("namespaced wildcards",
+			nil, []rbacv1.PolicyRule{{APIGroups: []string{"*"}, Resources: []string{"configmaps"}, Verbs: []string{"*"}}},
+			`medium: namespace permissions of service account manager grant every verb (*): apiGroups=["*"] resources=[configmaps] verbs=[*]`,
+			`low: namespace permissions of service account manager grant every API group (*): apiGroups=["*"] resources=[configmaps] verbs=[*]`),
+		Entry("cluster secrets",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}}, nil,
+			`high: cluster permissions of service account manager grant access to secrets in every namespace: apiGroups=[""] resources=[secrets] verbs=[get list]`),
+		Entry("namespaced secrets",
+			nil, []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: []string{"get", "list"}}}),
+		Entry("escalating verbs",
+			nil, []rbacv1.PolicyRule{{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"roles"}, Verbs: []string{"bind", "escalate"}}},
+			`high: namespace permissions of service account manager grant the bind verb, which allows privilege escalation: apiGroups=["rbac.authorization.k8s.io"] resources=[roles] verbs=[bind escalate]`,
+			`high: namespace permissions of service account manager grant the escalate verb, which allows privilege escalation: apiGroups=["rbac.authorization.k8s.io"] resources=[roles] verbs=[bind escalate]`),
+		Entry("impersonation",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"impersonate"}}}, nil,
+			`high: cluster permissions of service account manager grant the impersonate verb, which allows privilege escalation: apiGroups=[""] resources=[serviceaccounts] verbs=[impersonate]`),
+		Entry("creating pods and role bindings",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"create"}}},
+			[]rbacv1.PolicyRule{{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"rolebindings"}, Verbs: []string{"create", "get"}}},
+			`high: cluster permissions of service account manager grant creating pods, which can run as any service account in their namespace: apiGroups=[""] resources=[pods] verbs=[create]`,
+			`medium: namespace permissions of service account manager grant creating rolebindings, which can bind roles to any subject: apiGroups=["rbac.authorization.k8s.io"] resources=[rolebindings] verbs=[create get]`),
+		Entry("reading pods",
+			[]rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}}}, nil),
+		Entry("non-resource URLs",
+			[]rbacv1.PolicyRule{{NonResourceURLs: []string{"*"}, Verbs: []string{"*"}}}, nil),
+	)
+
+	Context("When the operator only supports the OwnNamespace install mode", func() {
+		It("should report its cluster permissions", func() {
+			csv := rbacCSV([]rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}}}, nil,
+				operatorsv1alpha1.InstallModeTypeOwnNamespace)
+			Expect(analyzePermissions(csv)).To(Equal([]rbacFinding{{
+				Severity:       SeverityMedium,
+				Scope:          "cluster",
+				ServiceAccount: "manager",
+				Reason:         "the operator only supports the OwnNamespace install mode, but requests cluster permissions for service account manager",
+			}}))
+		})
+
+		It("should not report cluster permissions if it supports other install modes", func() {
+			csv := rbacCSV([]rbacv1.PolicyRule{{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, Verbs: []string{"get"}}}, nil,
+				operatorsv1alpha1.InstallModeTypeOwnNamespace, operatorsv1alpha1.InstallModeTypeSingleNamespace)
+			Expect(analyzePermissions(csv)).To(BeEmpty())
+		})
+	})
+
+	Context("When the operator requests high severity permissions", func() {
+		It("should fail, and write the findings to the artifacts", func() {
+			bundleDir := GinkgoT().TempDir()
+			Expect(os.CopyFS(bundleDir, os.DirFS("./testdata/own_namespace"))).To(Succeed())
+			csvPath := filepath.Join(bundleDir, "manifests", "memcached-operator.clusterserviceversion.yaml")
+			contents, err := os.ReadFile(csvPath)
+			Expect(err).ToNot(HaveOccurred())
+			contents = []byte(strings.Replace(string(contents), "          - pods\n          verbs:\n          - get\n", "          - secrets\n          verbs:\n          - get\n", 1))
+			Expect(os.WriteFile(csvPath, contents, 0o644)).To(Succeed())
+
+			artifactsWriter, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+			Expect(err).ToNot(HaveOccurred())
+			ctx = artifacts.ContextWithWriter(ctx, artifactsWriter)
+
+			ok, err := rbacFollowsLeastPrivilege.Validate(ctx, image.ImageReference{ImageFSPath: bundleDir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				`high: cluster permissions of service account memcached-operator-controller-manager grant access to secrets in every namespace: apiGroups=[""] resources=[secrets] verbs=[get list watch]`,
+			))
+
+			contents, err = os.ReadFile(filepath.Join(artifactsWriter.Path(), rbacAnalysisFilename))
+			Expect(err).ToNot(HaveOccurred())
+			var written []rbacFinding
+			Expect(json.Unmarshal(contents, &written)).To(Succeed())
+			Expect(written).To(HaveLen(1))
+			Expect(written[0].Severity).To(Equal(SeverityHigh))
+			Expect(written[0].Rule.Resources).To(Equal([]string{"secrets"}))
+		})
+	})
+})
@@ -0,0 +1,338 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/operator-framework/api/pkg/manifests"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/openshift"
+)
+
+var _ check.Check = &ReconcilesAlmExamplesCheck{}
+
+// DefaultExampleConditions are the status conditions, any of which being True means an example
+// has been reconciled.
+var DefaultExampleConditions = []string{"Ready", "Available"}
+
+// ReconcilesAlmExamplesCheck creates the examples in the alm-examples annotation of the CSV once
+// DeployableByOLM has installed the operator, and checks that the operator reconciles each of
+// them, by waiting for one of their status conditions to be True. It does not deploy the
+// operator itself, so it must be given to DeployableByOLM, with WithAlmExamples.
+type ReconcilesAlmExamplesCheck struct {
+	conditions []string
+	timeout    time.Duration
+
+	// ran is set once DeployableByOLM has created the examples.
+	ran      bool
+	passed   bool
+	findings []string
+}
+
+// NewReconcilesAlmExamplesCheck returns a check that waits up to timeout for any of conditions
+// of each example to be True. DefaultExampleConditions are used if conditions is empty.
+func NewReconcilesAlmExamplesCheck(conditions []string, timeout time.Duration) *ReconcilesAlmExamplesCheck {
+	if len(conditions) == 0 {
+		conditions = DefaultExampleConditions
+	}
+	return &ReconcilesAlmExamplesCheck{
+		conditions: conditions,
+		timeout:    timeout,
+		passed:     true,
+	}
+}
+
+// Validate reports the result of the examples created when DeployableByOLM installed the operator.
+func (p *ReconcilesAlmExamplesCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	if !p.ran {
+		check.ReportFindings(ctx, "the operator was not installed by DeployableByOLM, so its examples were not created")
+		return false, nil
+	}
+	check.ReportFindings(ctx, p.findings...)
+	return p.passed, nil
+}
+
+// example is an example of the alm-examples annotation, and the namespace it is created in,
+// which is empty if its kind is cluster-scoped.
+type example struct {
+	obj       *unstructured.Unstructured
+	namespace string
+}
+
+func (e example) String() string {
+	if e.namespace == "" {
+		return fmt.Sprintf("%s %s", e.obj.GetKind(), e.obj.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", e.obj.GetKind(), e.namespace, e.obj.GetName())
+}
+
+// run creates the examples of the bundle in bundleDir in namespace, waits for the operator to
+// reconcile them, writes their status and the logs of the operator's pods to the artifacts, and
+// deletes them. It is run by DeployableByOLM once the CSV has succeeded, and before it cleans up.
+func (p *ReconcilesAlmExamplesCheck) run(ctx context.Context, client openshift.Client, bundleDir string, operatorData operatorData) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	p.ran = true
+	// the install mode is added to findings when DeployableByOLM deploys the operator in each of them.
+	prefix := ""
+	if operatorData.InstallMode != "" {
+		prefix = string(operatorData.InstallMode) + ": "
+	}
+	fail := func(finding string) {
+		p.passed = false
+		p.findings = append(p.findings, prefix+finding)
+	}
+
+	namespace := operatorData.TargetNamespace
+	if len(operatorData.CsvNamespaces) != 0 {
+		namespace = operatorData.CsvNamespaces[0]
+	}
+	examples, err := almExamples(bundleDir, namespace)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	if len(examples) == 0 {
+		logger.Info("the CSV has no alm-examples")
+		return
+	}
+
+	var created []example
+	defer func() {
+		p.deleteExamples(ctx, client, created)
+	}()
+	for _, ex := range examples {
+		if _, err := client.CreateCustomResource(ctx, ex.obj, ex.namespace); err != nil && !errors.Is(err, openshift.ErrAlreadyExists) {
+			fail(fmt.Sprintf("could not create the example %s: %v", ex, err))
+			continue
+		}
+		created = append(created, ex)
+	}
+
+	results := make([]error, len(created))
+	var wg sync.WaitGroup
+	for i, ex := range created {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.waitForExample(ctx, client, ex)
+		}()
+	}
+	wg.Wait()
+
+	for i, ex := range created {
+		if results[i] != nil {
+			fail(fmt.Sprintf("the example %s was not reconciled: %v", ex, results[i]))
+		} else {
+			logger.V(log.DBG).Info("example reconciled", "example", ex.String())
+		}
+		p.writeExample(ctx, client, ex)
+	}
+
+	p.writeOperatorLogs(ctx, client, operatorData)
+}
+
+// waitForExample waits for any of the conditions of ex to be True.
+func (p *ReconcilesAlmExamplesCheck) waitForExample(ctx context.Context, client openshift.Client, ex example) error {
+	reconciled := func(ctx context.Context, client openshift.Client, name, namespace string) (string, bool, error) {
+		obj, err := client.GetCustomResource(ctx, ex.obj.GroupVersionKind(), name, namespace)
+		if err != nil && !errors.Is(err, openshift.ErrNotFound) {
+			//coverage:ignore
+			return "", false, err
+		}
+		if obj == nil {
+			//coverage:ignore
+			return "", false, nil
+		}
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]any)
+			if ok && slices.Contains(p.conditions, fmt.Sprint(condition["type"])) && condition["status"] == "True" {
+				return name, true, nil
+			}
+		}
+		return "", false, nil
+	}
+
+	return p.wait(ctx, client, ex, reconciled)
+}
+
+// wait watches ex with fn, for up to the timeout of the check.
+func (p *ReconcilesAlmExamplesCheck) wait(ctx context.Context, client openshift.Client, ex example, fn watchFunc) error {
+	channel := make(chan string)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go watch(ctx, client, &wg, ex.obj.GetName(), ex.namespace, p.timeout, channel, fn)
+	go func() {
+		wg.Wait()
+		close(channel)
+	}()
+
+	var err error
+	for msg := range channel {
+		if strings.HasPrefix(msg, errorPrefix) {
+			err = errors.New(strings.TrimSpace(strings.TrimPrefix(msg, errorPrefix)))
+		}
+	}
+	return err
+}
+
+// deleteExamples deletes the examples, and waits for them to be removed, so that the operator
+// can process their finalizers before it is uninstalled.
+func (p *ReconcilesAlmExamplesCheck) deleteExamples(ctx context.Context, client openshift.Client, examples []example) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	for _, ex := range slices.Backward(examples) {
+		if err := client.DeleteCustomResource(ctx, ex.obj.GroupVersionKind(), ex.obj.GetName(), ex.namespace); err != nil {
+			if !errors.Is(err, openshift.ErrNotFound) {
+				//coverage:ignore
+				logger.Info(fmt.Sprintf("warning: unable to delete the example: %s", err))
+			}
+			continue
+		}
+		gvk := ex.obj.GroupVersionKind()
+		deleted := func(ctx context.Context, client openshift.Client, name, namespace string) (string, bool, error) {
+			_, err := client.GetCustomResource(ctx, gvk, name, namespace)
+			if errors.Is(err, openshift.ErrNotFound) {
+				return name, true, nil
+			}
+			return "", false, nil
+		}
+		if err := p.wait(ctx, client, ex, deleted); err != nil {
+			//coverage:ignore
+			logger.Info(fmt.Sprintf("warning: the example %s was not removed: %s", ex, err))
+		}
+	}
+}
+
+// writeExample writes ex, with its status, to the artifacts.
+func (p *ReconcilesAlmExamplesCheck) writeExample(ctx context.Context, client openshift.Client, ex example) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return
+	}
+	obj, err := client.GetCustomResource(ctx, ex.obj.GroupVersionKind(), ex.obj.GetName(), ex.namespace)
+	if err != nil {
+		//coverage:ignore
+		logger.Info(fmt.Sprintf("warning: unable to retrieve the example: %s", err))
+		return
+	}
+	filename := path.Join(examplesArtifactsDir, fmt.Sprintf("%s-%s.json", obj.GetName(), obj.GetKind()))
+	if err := writeJSON(artifactWriter, filename, obj); err != nil {
+		//coverage:ignore
+		logger.Error(err, "failed to write the example to the artifacts")
+	}
+}
+
+// writeOperatorLogs writes the logs of the pods of the operator's deployments to the artifacts.
+func (p *ReconcilesAlmExamplesCheck) writeOperatorLogs(ctx context.Context, client openshift.Client, operatorData operatorData) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	artifactWriter := artifacts.WriterFromContext(ctx)
+	if artifactWriter == nil {
+		return
+	}
+	for _, deploymentName := range operatorData.DeploymentNames {
+		pods, err := client.GetDeploymentPods(ctx, deploymentName, operatorData.InstallNamespace)
+		if err != nil {
+			logger.Info(fmt.Sprintf("warning: unable to retrieve deployment pods: %s", err))
+			continue
+		}
+		for _, pod := range pods {
+			logs, err := client.GetPodLogs(ctx, pod.Name, pod.Namespace)
+			if err != nil {
+				//coverage:ignore
+				logger.Info(fmt.Sprintf("warning: unable to retrieve pod logs: %s", err))
+				continue
+			}
+			for container, logContents := range logs {
+				filename := path.Join(examplesArtifactsDir, fmt.Sprintf("%s-%s.log", pod.Name, container))
+				if _, err := artifactWriter.WriteFile(filename, logContents); err != nil {
+					//coverage:ignore
+					logger.Error(err, "failed to write the pod logs to the file")
+				}
+			}
+		}
+	}
+}
+
+// almExamples returns the examples in the alm-examples annotation of the CSV of the bundle in
+// bundleDir, to be created in namespace, unless the bundle's CRD of their kind is cluster-scoped.
+func almExamples(bundleDir, namespace string) ([]example, error) {
+	b, err := manifests.GetBundleFromDir(bundleDir)
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not read the bundle: %w", err)
+	}
+
+	annotation := b.CSV.GetAnnotations()[almExamplesAnnotation]
+	if strings.TrimSpace(annotation) == "" {
+		return nil, nil
+	}
+	var objs []map[string]any
+	if err := json.Unmarshal([]byte(annotation), &objs); err != nil {
+		return nil, fmt.Errorf("could not parse the %s annotation: %w", almExamplesAnnotation, err)
+	}
+
+	clusterScoped := map[schema.GroupKind]bool{}
+	for _, crd := range b.V1CRDs {
+		clusterScoped[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = crd.Spec.Scope == apiextensionsv1.ClusterScoped
+	}
+	for _, crd := range b.V1beta1CRDs {
+		clusterScoped[schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}] = string(crd.Spec.Scope) == string(apiextensionsv1.ClusterScoped)
+	}
+
+	examples := make([]example, 0, len(objs))
+	for _, o := range objs {
+		obj := &unstructured.Unstructured{Object: o}
+		ex := example{obj: obj, namespace: namespace}
+		if clusterScoped[obj.GroupVersionKind().GroupKind()] {
+			ex.namespace = ""
+		}
+		examples = append(examples, ex)
+	}
+	return examples, nil
+}
+
+func (p *ReconcilesAlmExamplesCheck) Name() string {
+	return "ReconcilesAlmExamples"
+}
+
+func (p *ReconcilesAlmExamplesCheck) Metadata() check.Metadata {
+	return check.Metadata{
+		Description:      "Checking that the operator reconciles the examples in the alm-examples annotation of its CSV",
+		Level:            check.LevelBest,
+		KnowledgeBaseURL: "https://olm.operatorframework.io/docs/tasks/creating-operator-manifests/#crd-templates",
+		CheckURL:         "https://olm.operatorframework.io/docs/tasks/creating-operator-manifests/#crd-templates",
+	}
+}
+
+func (p *ReconcilesAlmExamplesCheck) Help() check.HelpText {
+	return check.HelpText{
+		Message:    "It is required that your operator reconciles the examples in the alm-examples annotation of its CSV",
+		Suggestion: "Make sure each example in alm-examples is valid, and that the operator sets one of its status conditions to True once it has reconciled it. The status of each example and the logs of the operator are in the artifacts.",
+	}
+}
+
+func (p *ReconcilesAlmExamplesCheck) RequiredFilePatterns() []string {
+	//coverage:ignore
+	return bundle.BundleFiles
+}
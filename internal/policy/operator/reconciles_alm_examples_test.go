@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+var _ = Describe("ReconcilesAlmExamples", func() {
+	var (
+		reconcilesAlmExamples *ReconcilesAlmExamplesCheck
+		ctx                   context.Context
+		findings              *check.Findings
+	)
+
+	BeforeEach(func() {
+		reconcilesAlmExamples = NewReconcilesAlmExamplesCheck(nil, time.Second)
+		ctx, findings = check.ContextWithFindings(context.Background())
+	})
+
+	AssertMetaData(NewReconcilesAlmExamplesCheck(nil, time.Second))
+
+	It("should wait for the default conditions if none are given", func() {
+		Expect(reconcilesAlmExamples.conditions).To(Equal(DefaultExampleConditions))
+		Expect(NewReconcilesAlmExamplesCheck([]string{"Reconciled"}, time.Second).conditions).To(Equal([]string{"Reconciled"}))
+	})
+
+	Context("When DeployableByOLM did not install the operator", func() {
+		It("should fail", func() {
+			ok, err := reconcilesAlmExamples.Validate(ctx, image.ImageReference{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(ContainSubstring("the operator was not installed by DeployableByOLM")))
+		})
+	})
+
+	Context("When reading the examples of a bundle", func() {
+		It("should create cluster-scoped examples outside of the namespace", func() {
+			examples, err := almExamples("./testdata/alm_examples", "testns")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(examples).To(HaveLen(2))
+			Expect(examples[0].String()).To(Equal("Memcached testns/memcached-sample"))
+			Expect(examples[1].String()).To(Equal("MemcachedConfig memcachedconfig-sample"))
+		})
+
+		It("should return no examples if the CSV has none", func() {
+			examples, err := almExamples("./testdata/all_namespaces", "testns")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(examples).To(BeEmpty())
+		})
+
+		It("should return an error if the annotation is invalid", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.MkdirAll(filepath.Join(dir, "manifests"), 0o755)).To(Succeed())
+			csv, err := os.ReadFile("./testdata/alm_examples/manifests/memcached-operator.clusterserviceversion.yaml")
+			Expect(err).ToNot(HaveOccurred())
+			csv = []byte(strings.Replace(string(csv), `"kind": "Memcached",`, `"kind": Memcached,`, 1))
+			Expect(os.WriteFile(filepath.Join(dir, "manifests", "memcached-operator.clusterserviceversion.yaml"), csv, 0o644)).To(Succeed())
+
+			_, err = almExamples(dir, "testns")
+			Expect(err).To(MatchError(ContainSubstring("could not parse the alm-examples annotation")))
+		})
+	})
+})
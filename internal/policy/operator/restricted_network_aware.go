@@ -2,11 +2,15 @@ package operator
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
 
 	"github.com/go-logr/logr"
 	"github.com/operator-framework/api/pkg/manifests"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	appsv1 "k8s.io/api/apps/v1"
+	mimage "github.com/operator-framework/operator-manifest-tools/pkg/image"
+	"github.com/operator-framework/operator-manifest-tools/pkg/pullspec"
 
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
@@ -16,7 +20,18 @@ import (
 
 var _ check.Check = &FollowsRestrictedNetworkEnablementGuidelines{}
 
-type FollowsRestrictedNetworkEnablementGuidelines struct{}
+// FollowsRestrictedNetworkEnablementGuidelines evaluates that a bundle claiming to support restricted
+// networks pins, and lists in relatedImages, every image it references. Its findings are warnings,
+// unless it is enforced.
+type FollowsRestrictedNetworkEnablementGuidelines struct {
+	enforce bool
+}
+
+// NewFollowsRestrictedNetworkEnablementGuidelinesCheck returns the check, which fails certification
+// if enforce is true.
+func NewFollowsRestrictedNetworkEnablementGuidelinesCheck(enforce bool) FollowsRestrictedNetworkEnablementGuidelines {
+	return FollowsRestrictedNetworkEnablementGuidelines{enforce: enforce}
+}
 
 func (p FollowsRestrictedNetworkEnablementGuidelines) Validate(ctx context.Context, imgRef image.ImageReference) (bool, error) {
 	//coverage:ignore
@@ -56,38 +71,90 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) validate(ctx context.Conte
 
 	if !restrictedNetworkSupport {
 		logger.Info("this operator does not indicate it supports installation into restricted networks. This is safe to ignore if you are not intending to deploy in these environments.")
-		return false, nil
+		return true, nil
+	}
+
+	images, err := p.bundleImages(bundledir)
+	if err != nil {
+		return false, err
+	}
+
+	var findings []string
+	report := func(format string, args ...any) {
+		finding := fmt.Sprintf(format, args...)
+		logger.Info(finding)
+		findings = append(findings, finding)
 	}
 
 	// You must have at least one related image (your controller manager) in order to be considered restricted-network ready
 	if !libcsv.HasRelatedImages(csv) {
-		//coverage:ignore
-		logger.Info("this operator did not have any related images, and at least one is expected")
-		return false, nil
+		report("the CSV has no relatedImages, and at least one is expected")
 	}
 
 	// All related images must be pinned. No tag references.
-	if !libcsv.RelatedImagesArePinned(csv.Spec.RelatedImages) {
-		//coverage:ignore
-		logger.Info("a related image is not pinned to a digest reference of the same image, and this is required.")
-		return false, nil
+	relatedImages := make(map[string]struct{}, len(csv.Spec.RelatedImages))
+	for _, ri := range csv.Spec.RelatedImages {
+		relatedImages[ri.Image] = struct{}{}
+		if !libcsv.IsPinned(ri.Image) {
+			report("related image %s (%s) is not pinned to a digest", ri.Name, ri.Image)
+		}
 	}
 
-	// Some environment variables should be passed into the environment using the RELATED_IMAGE_ prefix.
-	// This isn't the only way to pass this kind of information to the controller, but it is the suggested way in our
-	// documentation.
-	deploymentSpecs := make([]appsv1.DeploymentSpec, 0, len(csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs))
-	for _, ds := range csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
-		deploymentSpecs = append(deploymentSpecs, ds.Spec)
+	// Every image the bundle references must be pinned, and mirrored with the related images.
+	for _, image := range images {
+		if _, ok := relatedImages[image]; ok {
+			continue
+		}
+		if !libcsv.IsPinned(image) {
+			report("image %s is not pinned to a digest", image)
+		}
+		report("image %s is not in relatedImages", image)
 	}
-	relatedImagesInContainerEnvironment := libcsv.RelatedImageReferencesInEnvironment(deploymentSpecs...)
-	if len(relatedImagesInContainerEnvironment) == 0 {
+
+	// Images are passed into the controller's runtime environment in environment variables using the
+	// RELATED_IMAGE_ prefix, which must agree with relatedImages.
+	deployments := csv.Spec.InstallStrategy.StrategySpec.DeploymentSpecs
+	containerImages := map[string]struct{}{}
+	for _, depl := range deployments {
+		for _, container := range slices.Concat(depl.Spec.Template.Spec.InitContainers, depl.Spec.Template.Spec.Containers) {
+			containerImages[container.Image] = struct{}{}
+		}
+	}
+	envImages := map[string]struct{}{}
+	for _, env := range libcsv.RelatedImageEnvironment(deployments...) {
+		envImages[env.Value] = struct{}{}
+		if _, ok := relatedImages[env.Value]; !ok && env.Value != "" {
+			report("environment variable %s of container %s in deployment %s is %s, which is not in relatedImages", env.Name, env.Container, env.Deployment, env.Value)
+		}
+	}
+	for _, ri := range csv.Spec.RelatedImages {
+		_, inEnv := envImages[ri.Image]
+		_, inContainer := containerImages[ri.Image]
+		if !inEnv && !inContainer {
+			report("related image %s (%s) is not run by a deployment, or passed to one in a RELATED_IMAGE_ environment variable", ri.Name, ri.Image)
+		}
+	}
+
+	check.ReportFindings(ctx, findings...)
+	return len(findings) == 0, nil
+}
+
+// bundleImages returns the images referenced in the CSV of the bundle in bundledir, in its
+// deployments, RELATED_IMAGE_ environment variables, relatedImages and annotations.
+func (p FollowsRestrictedNetworkEnablementGuidelines) bundleImages(bundledir string) ([]string, error) {
+	operatorManifests, err := pullspec.FromDirectory(filepath.Join(bundledir, "manifests"), pullspec.DefaultHeuristic)
+	if err != nil {
 		//coverage:ignore
-		logger.Info("no environment variables prefixed with \"RELATED_IMAGE_\" were found in your operator's container definitions. These are expected to pass through values into your controller's runtime environment.")
-		return false, nil
+		return nil, fmt.Errorf("could not read the images of the bundle: %w", err)
 	}
 
-	return true, nil
+	images, err := mimage.Extract(operatorManifests)
+	if err != nil {
+		//coverage:ignore
+		return nil, fmt.Errorf("could not read the images of the bundle: %w", err)
+	}
+	slices.Sort(images)
+	return slices.Compact(images), nil
 }
 
 func (p FollowsRestrictedNetworkEnablementGuidelines) Name() string {
@@ -95,11 +162,13 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) Name() string {
 }
 
 func (p FollowsRestrictedNetworkEnablementGuidelines) Metadata() check.Metadata {
+	level := check.LevelWarn
+	if p.enforce {
+		level = check.LevelBest
+	}
 	return check.Metadata{
-		Description: "Checks for indicators that this bundle has implemented guidelines to indicate readiness for running in a disconnected cluster, or a cluster with a restricted network.",
-		// TODO: If this check is enforced and no longer optional, we need to identify ways to reduce false failures that may be caused by
-		// developers injecting related images in other ways.
-		Level:            "optional",
+		Description:      "Checks that a bundle claiming to support disconnected clusters, or clusters with a restricted network, pins every image it references to a digest, lists each in relatedImages, and passes them to its deployments in RELATED_IMAGE_ environment variables that agree with relatedImages.",
+		Level:            level,
 		KnowledgeBaseURL: "https://access.redhat.com/documentation/en-us/red_hat_software_certification/2026/html-single/red_hat_openshift_software_certification_policy_guide/index#con-operator-requirements_openshift-sw-cert-policy-products-managed",
 		CheckURL:         "https://access.redhat.com/documentation/en-us/red_hat_software_certification/2026/html-single/red_hat_openshift_software_certification_policy_guide/index#con-operator-requirements_openshift-sw-cert-policy-products-managed",
 	}
@@ -108,7 +177,7 @@ func (p FollowsRestrictedNetworkEnablementGuidelines) Metadata() check.Metadata
 func (p FollowsRestrictedNetworkEnablementGuidelines) Help() check.HelpText {
 	return check.HelpText{
 		Message:    "Check for the implementation of guidelines indicating operator readiness for environments with restricted networking.",
-		Suggestion: "If consumers of your operator may need to do so on a restricted network, implement the guidelines outlines in OCP documentation for your cluster version, such as https://docs.openshift.com/container-platform/4.11/operators/operator_sdk/osdk-generating-csvs.html#olm-enabling-operator-for-restricted-network_osdk-generating-csvs for OCP 4.11",
+		Suggestion: "Pin every image to a digest, list each in the relatedImages of the CSV, and pass those not run directly by a deployment to it in RELATED_IMAGE_ environment variables. The findings list each gap. If consumers of your operator may need to do so on a restricted network, implement the guidelines outlines in OCP documentation for your cluster version, such as https://docs.openshift.com/container-platform/4.11/operators/operator_sdk/osdk-generating-csvs.html#olm-enabling-operator-for-restricted-network_osdk-generating-csvs for OCP 4.11",
 	}
 }
 
@@ -2,11 +2,33 @@ package operator
 
 import (
 	"context"
+	"os"
+	"path/filepath"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+)
+
+const (
+	disconnectedCSV = "simple-disconnected-operator.clusterserviceversion.yaml"
+	fedoraImage     = "quay.io/fedora/fedora@sha256:ce08a91085403ecbc637eb2a96bd3554d75537871a12a14030b89243501050f2"
+	managerImage    = "quay.io/opdev/simple-disconnected-operator-cm@sha256:b4d060da584f7f5f7935e8fb78a0b62b1abb829717ad522190ac7747abd9fbd1"
 )
 
+// disconnectedBundle writes a copy of the disconnected_bundle with each of replacements
+// (old, new pairs) applied to its CSV, and returns its directory.
+func disconnectedBundle(replacements ...string) string {
+	dir := GinkgoT().TempDir()
+	Expect(os.MkdirAll(filepath.Join(dir, "manifests"), 0o755)).To(Succeed())
+	csv, err := os.ReadFile(filepath.Join("./testdata/disconnected_bundle/manifests", disconnectedCSV))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(os.WriteFile(filepath.Join(dir, "manifests", disconnectedCSV), []byte(strings.NewReplacer(replacements...).Replace(string(csv))), 0o644)).To(Succeed())
+	return dir
+}
+
 var _ = Describe("RestrictedNetworkGuidelines", func() {
 	var ch FollowsRestrictedNetworkEnablementGuidelines
 	BeforeEach(func() {
@@ -16,6 +38,11 @@ var _ = Describe("RestrictedNetworkGuidelines", func() {
 
 	AssertMetaData(ch)
 
+	It("should only fail certification if it is enforced", func() {
+		Expect(NewFollowsRestrictedNetworkEnablementGuidelinesCheck(false).Metadata().Level).To(Equal(check.LevelWarn))
+		Expect(NewFollowsRestrictedNetworkEnablementGuidelinesCheck(true).Metadata().Level).To(Equal(check.LevelBest))
+	})
+
 	When("Getting the CSV from a bundle", func() {
 		It("Should fail if a CSV is not found", func() {
 			_, err := ch.getBundleCSV(context.TODO(), "./testdata/doesnotexist")
@@ -30,19 +57,61 @@ var _ = Describe("RestrictedNetworkGuidelines", func() {
 	})
 
 	When("Validating that a CSV has attempted to follow the restricted network readiness guidelines.", func() {
-		var bundlepath string
+		var (
+			ctx      context.Context
+			findings *check.Findings
+		)
+		BeforeEach(func() {
+			ctx, findings = check.ContextWithFindings(context.TODO())
+		})
+
 		It("Should succeed with a bundle that has been prepared as expected", func() {
-			bundlepath = "./testdata/disconnected_bundle"
-			passed, err := ch.validate(context.TODO(), bundlepath)
+			passed, err := ch.validate(ctx, "./testdata/disconnected_bundle")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+
+		It("Should succeed with a bundle that does not claim to support disconnected environments", func() {
+			passed, err := ch.validate(ctx, "./testdata/invalid_bundle")
 			Expect(err).ToNot(HaveOccurred())
 			Expect(passed).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+		})
+
+		It("Should fail if the bundle has no related images", func() {
+			passed, err := ch.validate(ctx, disconnectedBundle(
+				"  relatedImages:\n    - image: "+fedoraImage+"\n      name: fedora\n    - image: "+managerImage+"\n      name: manager\n", "",
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeFalse())
+			Expect(findings.List()).To(ContainElements(
+				"the CSV has no relatedImages, and at least one is expected",
+				"image "+managerImage+" is not in relatedImages",
+				"environment variable RELATED_IMAGE_FEDORA of container manager in deployment simple-disconnected-operator-controller-manager is "+fedoraImage+", which is not in relatedImages",
+			))
+		})
+
+		It("Should fail if an image is not pinned to a digest", func() {
+			passed, err := ch.validate(ctx, disconnectedBundle(
+				"value: "+fedoraImage, "value: quay.io/fedora/fedora:latest",
+				"- image: "+fedoraImage, "- image: quay.io/fedora/fedora:latest",
+			))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf("related image fedora (quay.io/fedora/fedora:latest) is not pinned to a digest"))
 		})
 
-		It("Should fail with a bundle that has not been prepared for disconnected environments", func() {
-			bundlepath = "./testdata/invalid_bundle"
-			passed, err := ch.validate(context.TODO(), bundlepath)
+		It("Should fail if an image of a deployment is not a related image", func() {
+			passed, err := ch.validate(ctx, disconnectedBundle(
+				"  image: "+managerImage+"\n", "  image: quay.io/opdev/other@sha256:"+strings.Repeat("0", 64)+"\n",
+			))
 			Expect(err).ToNot(HaveOccurred())
 			Expect(passed).To(BeFalse())
+			Expect(findings.List()).To(ConsistOf(
+				"image quay.io/opdev/other@sha256:"+strings.Repeat("0", 64)+" is not in relatedImages",
+				"related image manager ("+managerImage+") is not run by a deployment, or passed to one in a RELATED_IMAGE_ environment variable",
+			))
 		})
 	})
 })
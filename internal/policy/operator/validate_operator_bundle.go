@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/go-logr/logr"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
@@ -16,13 +18,47 @@ var _ check.Check = &ValidateOperatorBundleCheck{}
 
 // ValidateOperatorBundleCheck evaluates the image and ensures that it passes bundle validation
 // as executed by `operator-sdk bundle validate`
-type ValidateOperatorBundleCheck struct{}
+type ValidateOperatorBundleCheck struct {
+	// versions maps OpenShift versions to the Kubernetes versions the bundle is validated against.
+	// If nil, the bundle.DefaultVersionTable is used.
+	versions *bundle.VersionTable
+	// versionMatrix validates the bundle against every OpenShift version in its declared range,
+	// rather than only the one it targets.
+	versionMatrix bool
+}
+
+// ValidateOperatorBundleOption configures a ValidateOperatorBundleCheck.
+type ValidateOperatorBundleOption func(*ValidateOperatorBundleCheck)
+
+// WithVersionTable validates the bundle against the Kubernetes versions of versions.
+func WithVersionTable(versions *bundle.VersionTable) ValidateOperatorBundleOption {
+	return func(p *ValidateOperatorBundleCheck) {
+		p.versions = versions
+	}
+}
 
-func NewValidateOperatorBundleCheck() *ValidateOperatorBundleCheck {
-	return &ValidateOperatorBundleCheck{}
+// WithVersionMatrix validates the bundle against each OpenShift version in the range of its
+// com.redhat.openshift.versions annotation, reporting the result of each as a finding, and in
+// version-matrix.json in the artifacts.
+func WithVersionMatrix() ValidateOperatorBundleOption {
+	return func(p *ValidateOperatorBundleCheck) {
+		p.versionMatrix = true
+	}
+}
+
+func NewValidateOperatorBundleCheck(opts ...ValidateOperatorBundleOption) *ValidateOperatorBundleCheck {
+	p := &ValidateOperatorBundleCheck{}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func (p *ValidateOperatorBundleCheck) Validate(ctx context.Context, bundleRef image.ImageReference) (bool, error) {
+	if p.versionMatrix {
+		return p.validateVersions(ctx, bundleRef.ImageFSPath)
+	}
+
 	report, err := p.dataToValidate(ctx, bundleRef.ImageFSPath)
 	if err != nil {
 		//coverage:ignore
@@ -33,7 +69,86 @@ func (p *ValidateOperatorBundleCheck) Validate(ctx context.Context, bundleRef im
 }
 
 func (p *ValidateOperatorBundleCheck) dataToValidate(ctx context.Context, imagePath string) (*bundle.Report, error) {
-	return bundle.Validate(ctx, imagePath)
+	return bundle.Validate(ctx, imagePath, p.bundleOptions()...)
+}
+
+func (p *ValidateOperatorBundleCheck) bundleOptions() []bundle.Option {
+	if p.versions == nil {
+		return nil
+	}
+	return []bundle.Option{bundle.WithVersionTable(p.versions)}
+}
+
+// versionResult is the result of validating the bundle against an OpenShift version.
+type versionResult struct {
+	OpenShiftVersion  string   `json:"openshiftVersion"`
+	KubernetesVersion string   `json:"kubernetesVersion"`
+	Passed            bool     `json:"passed"`
+	Errors            []string `json:"errors,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
+}
+
+// validateVersions validates the bundle at imagePath against each OpenShift version in its range,
+// and passes if it is valid for all of them. A bundle that does not declare its range is validated
+// as it is without the version matrix.
+func (p *ValidateOperatorBundleCheck) validateVersions(ctx context.Context, imagePath string) (bool, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	reports, err := bundle.ValidateVersions(ctx, imagePath, p.bundleOptions()...)
+	if err != nil {
+		//coverage:ignore
+		return false, fmt.Errorf("error while executing operator-sdk bundle validate: %v", err)
+	}
+
+	if len(reports) == 0 {
+		check.ReportFindings(ctx, "the bundle does not declare the OpenShift versions it supports in com.redhat.openshift.versions, or none of them are known, so it was validated without one")
+		report, err := p.dataToValidate(ctx, imagePath)
+		if err != nil {
+			//coverage:ignore
+			return false, fmt.Errorf("error while executing operator-sdk bundle validate: %v", err)
+		}
+		return p.validate(ctx, report)
+	}
+
+	passed := true
+	results := make([]versionResult, 0, len(reports))
+	for _, report := range reports {
+		result := versionResult{
+			OpenShiftVersion:  report.OpenShiftVersion,
+			KubernetesVersion: report.KubernetesVersion,
+			Passed:            report.Passed,
+		}
+		for _, output := range report.Results {
+			for _, e := range output.Errors {
+				if !slices.Contains(result.Errors, e.Error()) {
+					result.Errors = append(result.Errors, e.Error())
+				}
+			}
+			for _, w := range output.Warnings {
+				if !slices.Contains(result.Warnings, w.Error()) {
+					result.Warnings = append(result.Warnings, w.Error())
+				}
+			}
+		}
+		results = append(results, result)
+
+		if report.Passed {
+			continue
+		}
+		passed = false
+		version := fmt.Sprintf("OpenShift %s (Kubernetes %s)", report.OpenShiftVersion, report.KubernetesVersion)
+		for _, e := range result.Errors {
+			check.ReportFindings(ctx, fmt.Sprintf("%s: %s", version, e))
+		}
+	}
+
+	if artifactWriter := artifacts.WriterFromContext(ctx); artifactWriter != nil {
+		if err := writeJSON(artifactWriter, versionMatrixFilename, results); err != nil {
+			//coverage:ignore
+			logger.Error(err, "failed to write the version matrix to the artifacts")
+		}
+	}
+	return passed, nil
 }
 
 func (p *ValidateOperatorBundleCheck) validate(ctx context.Context, report *bundle.Report) (bool, error) {
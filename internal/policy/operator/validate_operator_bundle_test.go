@@ -2,10 +2,15 @@ package operator
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
 	test "github.com/redhat-openshift-ecosystem/openshift-preflight/internal/test"
 )
@@ -45,4 +50,50 @@ var _ = Describe("BundleValidateCheck", func() {
 			})
 		})
 	})
+
+	Describe("Operator Bundle Validate with the version matrix", func() {
+		var (
+			findings        *check.Findings
+			artifactsWriter *artifacts.FilesystemWriter
+		)
+
+		BeforeEach(func() {
+			path := filepath.Join(GinkgoT().TempDir(), "versions.yaml")
+			Expect(os.WriteFile(path, []byte("versions:\n  \"4.8\": \"1.21\"\n"), 0o644)).To(Succeed())
+			versions, err := bundle.LoadVersionTable(path)
+			Expect(err).ToNot(HaveOccurred())
+			bundleValidateCheck = *NewValidateOperatorBundleCheck(WithVersionTable(versions), WithVersionMatrix())
+
+			artifactsWriter, err = artifacts.NewFilesystemWriter(artifacts.WithDirectory(GinkgoT().TempDir()))
+			Expect(err).ToNot(HaveOccurred())
+			ctx, findings = check.ContextWithFindings(artifacts.ContextWithWriter(ctx, artifactsWriter))
+		})
+
+		It("Should pass if the bundle is valid for each OpenShift version in its range", func() {
+			ok, err := bundleValidateCheck.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/all_namespaces"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(BeEmpty())
+			Expect(filepath.Join(artifactsWriter.Path(), versionMatrixFilename)).To(BeAnExistingFile())
+		})
+
+		It("Should fail, and report the errors of each OpenShift version, if the bundle is invalid", func() {
+			ok, err := bundleValidateCheck.Validate(ctx, image.ImageReference{ImageFSPath: "./testdata/invalid_bundle"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeFalse())
+			Expect(findings.List()).To(ContainElement(HavePrefix("OpenShift 4.8 (Kubernetes 1.21): ")))
+			Expect(findings.List()).To(ContainElement(HavePrefix("OpenShift 4.9 (Kubernetes 1.22): ")))
+		})
+
+		It("Should validate the bundle without a version if it does not declare its range", func() {
+			dir := GinkgoT().TempDir()
+			Expect(os.CopyFS(dir, os.DirFS("./testdata/all_namespaces"))).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "metadata", "annotations.yaml"), []byte("annotations:\n  operators.operatorframework.io.bundle.package.v1: testPackage\n  operators.operatorframework.io.bundle.channel.default.v1: testChannel\n"), 0o644)).To(Succeed())
+
+			ok, err := bundleValidateCheck.Validate(ctx, image.ImageReference{ImageFSPath: dir})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(findings.List()).To(ConsistOf(ContainSubstring("does not declare the OpenShift versions it supports")))
+		})
+	})
 })
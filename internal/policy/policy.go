@@ -9,4 +9,6 @@ const (
 	PolicyScratchRoot    Policy = "scratch-root"
 	PolicyRoot           Policy = "root"
 	PolicyKonflux        Policy = "konflux"
+	PolicyWebhook        Policy = "webhook"
+	PolicyCatalog        Policy = "catalog"
 )
@@ -0,0 +1,92 @@
+package pyxis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// DryRunRequest is a request that would have been sent to Pyxis.
+type DryRunRequest struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// DryRunClient is an HTTPClient that sends read-only (GET) requests using the
+// wrapped client, and records all other requests instead of sending them.
+// Recorded requests receive a successful response echoing the request body,
+// with a placeholder _id added on create, so that a submission can run to
+// completion without anything in Pyxis being modified.
+type DryRunClient struct {
+	client HTTPClient
+
+	mu       sync.Mutex
+	requests []DryRunRequest
+}
+
+// NewDryRunClient returns a DryRunClient that sends read-only requests using client.
+func NewDryRunClient(client HTTPClient) *DryRunClient {
+	return &DryRunClient{client: client}
+}
+
+var _ HTTPClient = &DryRunClient{}
+
+// Do implements HTTPClient.
+func (c *DryRunClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		return c.client.Do(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		req.Body.Close()
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, DryRunRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Body:   body,
+	})
+	id := fmt.Sprintf("dry-run-%d", len(c.requests))
+	c.mu.Unlock()
+
+	respBody := body
+	statusCode := http.StatusOK
+	if req.Method == http.MethodPost {
+		statusCode = http.StatusCreated
+		var obj map[string]any
+		if err := json.Unmarshal(body, &obj); err == nil {
+			obj["_id"] = id
+			respBody, _ = json.Marshal(obj)
+		}
+	}
+
+	return &http.Response{
+		Status:     http.StatusText(statusCode),
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(respBody)),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns the recorded requests, in the order they were made.
+func (c *DryRunClient) Requests() []DryRunRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	requests := make([]DryRunRequest, len(c.requests))
+	copy(requests, c.requests)
+	return requests
+}
@@ -0,0 +1,75 @@
+package pyxis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DryRunClient", func() {
+	var (
+		ctx      context.Context
+		sent     []string
+		recorder *DryRunClient
+		client   *pyxisClient
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		sent = []string{}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+			sent = append(sent, r.Method+" "+r.URL.Path)
+			mustWrite(w, `{"_id":"my-awesome-project-id","name":"My Project","type":"Containers","container":{"isv_pid":"pid"}}`)
+		})
+		recorder = NewDryRunClient(&http.Client{Transport: localRoundTripper{handler: mux}})
+		client = NewPyxisClient("my.pyxis.host/api", "my-spiffy-api-token", "my-awesome-project-id", recorder)
+	})
+
+	Context("when making a read-only request", func() {
+		It("should send the request", func() {
+			project, err := client.GetProject(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(project.Name).To(Equal("My Project"))
+			Expect(sent).To(HaveLen(1))
+			Expect(recorder.Requests()).To(BeEmpty())
+		})
+	})
+
+	Context("when making a create request", func() {
+		It("should record the request and respond with a placeholder id", func() {
+			image, err := client.createImage(ctx, &CertImage{DockerImageDigest: "sha256:deadb33f"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(image.ID).To(Equal("dry-run-1"))
+			Expect(image.DockerImageDigest).To(Equal("sha256:deadb33f"))
+			Expect(sent).To(BeEmpty())
+
+			requests := recorder.Requests()
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal(http.MethodPost))
+			Expect(requests[0].URL).To(Equal("https://my.pyxis.host/api/v1/images"))
+
+			var body CertImage
+			Expect(json.Unmarshal(requests[0].Body, &body)).To(Succeed())
+			Expect(body.ID).To(BeEmpty())
+		})
+	})
+
+	Context("when making an update request", func() {
+		It("should record the request and echo the body", func() {
+			project, err := client.updateProject(ctx, &CertProject{ID: "my-awesome-project-id", Name: "Renamed"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(project.Name).To(Equal("Renamed"))
+			Expect(sent).To(BeEmpty())
+
+			requests := recorder.Requests()
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal(http.MethodPatch))
+			Expect(strings.HasSuffix(requests[0].URL, "/projects/certification/id/my-awesome-project-id")).To(BeTrue())
+		})
+	})
+})
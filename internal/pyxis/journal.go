@@ -0,0 +1,134 @@
+package pyxis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// The steps of a submission, in the order they are performed by SubmitResults.
+const (
+	StepCreateTestResults = "create-test-results"
+	StepUpdateProject     = "update-project"
+	StepCreateImage       = "create-image"
+	StepCreateRPMManifest = "create-rpm-manifest"
+	StepCreateArtifact    = "create-artifact"
+	StepUpdateTestResults = "update-test-results"
+)
+
+// JournalStep is a completed submission step.
+type JournalStep struct {
+	Step string `json:"step"`
+	// Key distinguishes steps that are performed more than once, such as
+	// the artifact filename for StepCreateArtifact.
+	Key         string    `json:"key,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// SubmissionJournal records the steps of a submission that have completed, along
+// with the Pyxis IDs they returned, so that a submission that fails midway can be
+// resumed from the last successful step.
+//
+// A SubmissionJournal is used by SubmitResults when it is added to the context
+// using ContextWithJournal.
+type SubmissionJournal struct {
+	CertProject       string        `json:"cert_project"`
+	DockerImageDigest string        `json:"docker_image_digest"`
+	Steps             []JournalStep `json:"steps"`
+
+	mu      sync.Mutex
+	persist func(*SubmissionJournal) error
+}
+
+// NewSubmissionJournal returns an empty journal for a submission of the image with
+// imageDigest to the project with projectID. If persist is not nil, it is called
+// each time a step is recorded.
+func NewSubmissionJournal(projectID, imageDigest string, persist func(*SubmissionJournal) error) *SubmissionJournal {
+	return &SubmissionJournal{
+		CertProject:       projectID,
+		DockerImageDigest: imageDigest,
+		Steps:             []JournalStep{},
+		persist:           persist,
+	}
+}
+
+// ReadSubmissionJournal reads a journal previously persisted as JSON from r. If persist
+// is not nil, it is called each time a step is recorded.
+func ReadSubmissionJournal(r io.Reader, persist func(*SubmissionJournal) error) (*SubmissionJournal, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not read submission journal: %w", err)
+	}
+
+	j := &SubmissionJournal{}
+	if err := json.Unmarshal(b, j); err != nil {
+		return nil, fmt.Errorf("could not unmarshal submission journal: %w", err)
+	}
+	j.persist = persist
+
+	return j, nil
+}
+
+// Completed returns the recorded step matching step and key, if it has completed.
+// A nil journal has no completed steps.
+func (j *SubmissionJournal) Completed(step, key string) (JournalStep, bool) {
+	if j == nil {
+		return JournalStep{}, false
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, s := range j.Steps {
+		if s.Step == step && s.Key == key {
+			return s, true
+		}
+	}
+
+	return JournalStep{}, false
+}
+
+// record adds a completed step to the journal and persists it. Recording to a
+// nil journal does nothing.
+func (j *SubmissionJournal) record(step, key, id string) error {
+	if j == nil {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.Steps = append(j.Steps, JournalStep{
+		Step:        step,
+		Key:         key,
+		ID:          id,
+		CompletedAt: time.Now().UTC(),
+	})
+
+	if j.persist == nil {
+		return nil
+	}
+
+	return j.persist(j)
+}
+
+type journalContextKey struct{}
+
+// ContextWithJournal adds j to ctx, so that SubmitResults records its progress in j,
+// and skips steps that j has already recorded as completed.
+func ContextWithJournal(ctx context.Context, j *SubmissionJournal) context.Context {
+	return context.WithValue(ctx, journalContextKey{}, j)
+}
+
+// JournalFromContext returns the journal in ctx, or nil if there is none.
+func JournalFromContext(ctx context.Context) *SubmissionJournal {
+	if j, ok := ctx.Value(journalContextKey{}).(*SubmissionJournal); ok {
+		return j
+	}
+
+	return nil
+}
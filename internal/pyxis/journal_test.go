@@ -0,0 +1,141 @@
+package pyxis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+)
+
+var _ = Describe("SubmissionJournal", func() {
+	var (
+		ctx      context.Context
+		recorder *DryRunClient
+		client   *pyxisClient
+		input    func() *CertificationInput
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		recorder = NewDryRunClient(&http.Client{Transport: localRoundTripper{handler: http.NotFoundHandler()}})
+		client = NewPyxisClient("my.pyxis.host/api", "my-spiffy-api-token", "my-awesome-project-id", recorder)
+		input = func() *CertificationInput {
+			return &CertificationInput{
+				CertProject: &CertProject{ID: "my-awesome-project-id"},
+				CertImage: &CertImage{
+					DockerImageDigest: "sha256:deadb33f",
+					Repositories:      []Repository{{Registry: "quay.io", Repository: "my/repo"}},
+				},
+				TestResults: &TestResults{UserResponse: formatters.UserResponse{Passed: true}},
+				RpmManifest: &RPMManifest{},
+				Artifacts:   []Artifact{{Filename: "preflight.log"}, {Filename: "other.log"}},
+			}
+		}
+	})
+
+	Context("when submitting without a journal in the context", func() {
+		It("should submit every step", func() {
+			_, err := client.SubmitResults(ctx, input())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(recorder.Requests()).To(HaveLen(7))
+		})
+	})
+
+	Context("when submitting with a new journal", func() {
+		It("should record and persist each completed step in order", func() {
+			persisted := 0
+			journal := NewSubmissionJournal("my-awesome-project-id", "sha256:deadb33f", func(*SubmissionJournal) error {
+				persisted++
+				return nil
+			})
+
+			_, err := client.SubmitResults(ContextWithJournal(ctx, journal), input())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(persisted).To(Equal(7))
+
+			steps := []string{}
+			for _, s := range journal.Steps {
+				steps = append(steps, s.Step+"/"+s.Key)
+			}
+			Expect(steps).To(Equal([]string{
+				StepCreateTestResults + "/",
+				StepUpdateProject + "/",
+				StepCreateImage + "/",
+				StepCreateRPMManifest + "/",
+				StepCreateArtifact + "/preflight.log",
+				StepCreateArtifact + "/other.log",
+				StepUpdateTestResults + "/",
+			}))
+
+			step, ok := journal.Completed(StepCreateImage, "")
+			Expect(ok).To(BeTrue())
+			Expect(step.ID).To(Equal("dry-run-3"))
+		})
+
+		It("should not fail the submission when the journal cannot be persisted", func() {
+			journal := NewSubmissionJournal("my-awesome-project-id", "sha256:deadb33f", func(*SubmissionJournal) error {
+				return errors.New("disk full")
+			})
+
+			_, err := client.SubmitResults(ContextWithJournal(ctx, journal), input())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(journal.Steps).To(HaveLen(7))
+		})
+	})
+
+	Context("when resuming from a journal", func() {
+		It("should skip completed steps and reuse their IDs", func() {
+			journal, err := ReadSubmissionJournal(bytes.NewBufferString(`{
+				"cert_project": "my-awesome-project-id",
+				"docker_image_digest": "sha256:deadb33f",
+				"steps": [
+					{"step": "create-test-results", "id": "test-results-id"},
+					{"step": "update-project", "id": "my-awesome-project-id"},
+					{"step": "create-image", "id": "image-id"},
+					{"step": "create-rpm-manifest", "id": "rpm-manifest-id"},
+					{"step": "create-artifact", "key": "preflight.log", "id": "artifact-id"}
+				]
+			}`), nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := client.SubmitResults(ContextWithJournal(ctx, journal), input())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.CertImage.ID).To(Equal("image-id"))
+
+			requests := recorder.Requests()
+			Expect(requests).To(HaveLen(2))
+
+			var artifact Artifact
+			Expect(json.Unmarshal(requests[0].Body, &artifact)).To(Succeed())
+			Expect(artifact.Filename).To(Equal("other.log"))
+			Expect(artifact.ImageID).To(Equal("image-id"))
+
+			Expect(requests[1].Method).To(Equal(http.MethodPatch))
+			Expect(requests[1].URL).To(HaveSuffix("/test-results/id/test-results-id"))
+			var testResults TestResults
+			Expect(json.Unmarshal(requests[1].Body, &testResults)).To(Succeed())
+			Expect(testResults.ImageID).To(Equal("image-id"))
+
+			Expect(journal.Steps).To(HaveLen(7))
+		})
+	})
+
+	Context("when reading an invalid journal", func() {
+		It("should return an error", func() {
+			_, err := ReadSubmissionJournal(bytes.NewBufferString("not json"), nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when there is no journal in the context", func() {
+		It("should return nil", func() {
+			Expect(JournalFromContext(ctx)).To(BeNil())
+		})
+	})
+})
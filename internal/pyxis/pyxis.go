@@ -33,11 +33,23 @@ type pyxisClient struct {
 }
 
 func (p *pyxisClient) getPyxisURL(path string) string {
-	return fmt.Sprintf("https://%s/%s/%s", p.PyxisHost, apiVersion, path)
+	return fmt.Sprintf("%s/%s/%s", p.baseURL(), apiVersion, path)
 }
 
 func (p *pyxisClient) getPyxisGraphqlURL() string {
-	return fmt.Sprintf("https://%s/graphql/", p.PyxisHost)
+	return fmt.Sprintf("%s/graphql/", p.baseURL())
+}
+
+// baseURL returns the PyxisHost as a URL. Hosts are expected to be provided
+// without a scheme, in which case https is used. A host that already includes
+// a scheme (e.g. a local stand-in server on http://127.0.0.1:8080/api) is used
+// as is.
+func (p *pyxisClient) baseURL() string {
+	if strings.HasPrefix(p.PyxisHost, "http://") || strings.HasPrefix(p.PyxisHost, "https://") {
+		return strings.TrimSuffix(p.PyxisHost, "/")
+	}
+
+	return "https://" + p.PyxisHost
 }
 
 func NewPyxisClient(pyxisHost string, apiToken string, projectID string, httpClient HTTPClient) *pyxisClient {
@@ -140,6 +152,10 @@ func (p *pyxisClient) getImage(ctx context.Context, dockerImageDigest string) (*
 		return nil, fmt.Errorf("could not unmarshal body: %s: %w", string(body), err)
 	}
 
+	if len(data.Data) == 0 {
+		return nil, fmt.Errorf("no image found with digest %s", dockerImageDigest)
+	}
+
 	return &data.Data[0], nil
 }
 
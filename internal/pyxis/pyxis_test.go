@@ -94,4 +94,16 @@ var _ = Describe("Pyxis", func() {
 			Expect(result.FileSize).To(Equal(int64(42)))
 		})
 	})
+	Context("building request URLs", func() {
+		It("should default to https when the host has no scheme", func() {
+			client := NewPyxisClient("my.pyxis.host/api", "", "", nil)
+			Expect(client.getPyxisURL("images")).To(Equal("https://my.pyxis.host/api/v1/images"))
+			Expect(client.getPyxisGraphqlURL()).To(Equal("https://my.pyxis.host/api/graphql/"))
+		})
+		It("should use the scheme included in the host", func() {
+			client := NewPyxisClient("http://127.0.0.1:8080/api/", "", "", nil)
+			Expect(client.getPyxisURL("images")).To(Equal("http://127.0.0.1:8080/api/v1/images"))
+			Expect(client.getPyxisGraphqlURL()).To(Equal("http://127.0.0.1:8080/api/graphql/"))
+		})
+	})
 })
@@ -0,0 +1,24 @@
+package pyxistest
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// LoadFixtures reads Fixtures from the JSON or YAML file at path.
+func LoadFixtures(path string) (Fixtures, error) {
+	var f Fixtures
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return f, fmt.Errorf("could not read fixtures file %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return f, fmt.Errorf("could not parse fixtures file %s: %w", path, err)
+	}
+
+	return f, nil
+}
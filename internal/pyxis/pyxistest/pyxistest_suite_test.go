@@ -0,0 +1,13 @@
+package pyxistest
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+)
+
+func TestPyxistest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pyxis Stand-in Server Suite")
+}
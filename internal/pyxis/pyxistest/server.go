@@ -0,0 +1,637 @@
+// Package pyxistest provides an in-memory stand-in for the Pyxis API. It
+// implements the endpoints used by preflight's pyxis client so that the
+// submission flow and policy exception resolution can be exercised without
+// network access or credentials. It is used both as a Go test helper and by
+// the `preflight dev pyxis-server` command.
+package pyxistest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+)
+
+// PathPrefix is the path under which all Pyxis endpoints are served. The pyxis
+// host configured in preflight must include it, e.g. http://127.0.0.1:8080/api.
+const PathPrefix = "/api"
+
+// Route identifies a Pyxis endpoint implemented by the Server.
+type Route string
+
+const (
+	RouteGetProject        Route = "GetProject"
+	RouteUpdateProject     Route = "UpdateProject"
+	RouteCreateTestResults Route = "CreateTestResults"
+	RouteUpdateTestResults Route = "UpdateTestResults"
+	RouteCreateImage       Route = "CreateImage"
+	RouteGetImage          Route = "GetImage"
+	RouteUpdateImage       Route = "UpdateImage"
+	RouteCreateRPMManifest Route = "CreateRPMManifest"
+	RouteGetRPMManifest    Route = "GetRPMManifest"
+	RouteCreateArtifact    Route = "CreateArtifact"
+	RouteGraphQL           Route = "GraphQL"
+)
+
+// Request is a record of a single request received by the Server.
+type Request struct {
+	Route  Route
+	Method string
+	Path   string
+	Body   []byte
+}
+
+// Fixtures are the values a Server is seeded with. Images are typically
+// certified images that checks such as BasedOnUbi, or the operator's
+// certified images check, expect to find.
+type Fixtures struct {
+	Projects []pyxis.CertProject `json:"projects,omitempty"`
+	Images   []pyxis.CertImage   `json:"images,omitempty"`
+}
+
+type injectedError struct {
+	route      Route
+	statusCode int
+	// remaining is the number of requests left to fail. A negative
+	// value fails every request.
+	remaining int
+}
+
+// Server is an http.Handler implementing the Pyxis endpoints used by preflight,
+// backed by in-memory state. It is safe for concurrent use.
+type Server struct {
+	mu sync.Mutex
+
+	apiToken     string
+	projects     map[string]*pyxis.CertProject
+	images       []*pyxis.CertImage
+	rpmManifests map[string]*pyxis.RPMManifest
+	testResults  map[string]*pyxis.TestResults
+	artifacts    []*pyxis.Artifact
+	injected     []*injectedError
+	requests     []Request
+	lastID       int
+
+	mux *http.ServeMux
+}
+
+type Option func(*Server)
+
+// WithProjects seeds the server with projects.
+func WithProjects(projects ...pyxis.CertProject) Option {
+	return func(s *Server) {
+		for _, p := range projects {
+			s.addProject(p)
+		}
+	}
+}
+
+// WithImages seeds the server with images, such as certified base images.
+func WithImages(images ...pyxis.CertImage) Option {
+	return func(s *Server) {
+		for _, i := range images {
+			s.addImage(i)
+		}
+	}
+}
+
+// WithFixtures seeds the server with all values in f.
+func WithFixtures(f Fixtures) Option {
+	return func(s *Server) {
+		WithProjects(f.Projects...)(s)
+		WithImages(f.Images...)(s)
+	}
+}
+
+// WithAPIToken requires authenticated requests to present token. Without
+// this option, any non-empty token is accepted.
+func WithAPIToken(token string) Option {
+	return func(s *Server) {
+		s.apiToken = token
+	}
+}
+
+// NewServer returns a Server configured with opts.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		projects:     map[string]*pyxis.CertProject{},
+		rpmManifests: map[string]*pyxis.RPMManifest{},
+		testResults:  map[string]*pyxis.TestResults{},
+		mux:          http.NewServeMux(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.handle(RouteGetProject, "GET /v1/projects/certification/id/{id}", true, s.getProject)
+	s.handle(RouteUpdateProject, "PATCH /v1/projects/certification/id/{id}", true, s.updateProject)
+	s.handle(RouteCreateTestResults, "POST /v1/projects/certification/id/{id}/test-results", true, s.createTestResults)
+	s.handle(RouteUpdateTestResults, "PATCH /v1/projects/certification/test-results/id/{id}", true, s.updateTestResults)
+	s.handle(RouteGetImage, "GET /v1/projects/certification/id/{id}/images", true, s.getImages)
+	s.handle(RouteCreateImage, "POST /v1/images", true, s.createImage)
+	s.handle(RouteUpdateImage, "PATCH /v1/images/id/{id}", true, s.updateImage)
+	s.handle(RouteCreateRPMManifest, "POST /v1/images/id/{id}/rpm-manifest", true, s.createRPMManifest)
+	s.handle(RouteGetRPMManifest, "GET /v1/images/id/{id}/rpm-manifest", true, s.getRPMManifest)
+	s.handle(RouteCreateArtifact, "POST /v1/projects/certification/id/{id}/artifacts", true, s.createArtifact)
+	// The graphql endpoint is called without an API token.
+	s.handle(RouteGraphQL, "POST /graphql/", false, s.graphql)
+
+	return s
+}
+
+// HostFor returns the pyxis host to configure preflight with for a Server
+// reachable at serverURL, e.g. the URL of an httptest.Server.
+func HostFor(serverURL string) string {
+	return strings.TrimSuffix(serverURL, "/") + PathPrefix
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	http.StripPrefix(PathPrefix, s.mux).ServeHTTP(w, r)
+}
+
+// AddProject stores project, replacing any existing project with the same ID.
+func (s *Server) AddProject(project pyxis.CertProject) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addProject(project)
+}
+
+// AddImage stores image. An ID is generated if image does not have one.
+func (s *Server) AddImage(image pyxis.CertImage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addImage(image)
+}
+
+// InjectError causes the next times requests to route to fail with
+// statusCode, before any other handling takes place. A times value of zero
+// or less fails every request to route until ClearInjectedErrors is called.
+func (s *Server) InjectError(route Route, statusCode int, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if times <= 0 {
+		times = -1
+	}
+	s.injected = append(s.injected, &injectedError{route: route, statusCode: statusCode, remaining: times})
+}
+
+// ClearInjectedErrors removes all errors added with InjectError.
+func (s *Server) ClearInjectedErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.injected = nil
+}
+
+// Requests returns every request received by the server, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// Project returns a copy of the stored project with id.
+func (s *Server) Project(id string) (pyxis.CertProject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[id]
+	if !ok {
+		return pyxis.CertProject{}, false
+	}
+	return *p, true
+}
+
+// Images returns copies of all stored images, in the order they were added.
+func (s *Server) Images() []pyxis.CertImage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	images := make([]pyxis.CertImage, 0, len(s.images))
+	for _, i := range s.images {
+		images = append(images, *i)
+	}
+	return images
+}
+
+// RPMManifest returns a copy of the rpm manifest stored for the image with imageID.
+func (s *Server) RPMManifest(imageID string) (pyxis.RPMManifest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.rpmManifests[imageID]
+	if !ok {
+		return pyxis.RPMManifest{}, false
+	}
+	return *m, true
+}
+
+// TestResults returns a copy of the test results stored with id.
+func (s *Server) TestResults(id string) (pyxis.TestResults, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tr, ok := s.testResults[id]
+	if !ok {
+		return pyxis.TestResults{}, false
+	}
+	return *tr, true
+}
+
+// Artifacts returns copies of all stored artifacts, in the order they were created.
+func (s *Server) Artifacts() []pyxis.Artifact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	artifacts := make([]pyxis.Artifact, 0, len(s.artifacts))
+	for _, a := range s.artifacts {
+		artifacts = append(artifacts, *a)
+	}
+	return artifacts
+}
+
+// handlerFunc handles a request to a route. The server lock is held while it runs,
+// and the request body has already been read into body.
+type handlerFunc func(w http.ResponseWriter, r *http.Request, body []byte)
+
+// handle registers fn for route at pattern, wrapping it with request recording,
+// error injection, and optionally API token validation.
+func (s *Server) handle(route Route, pattern string, authenticated bool, fn handlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("could not read body: %v", err))
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, Request{
+			Route:  route,
+			Method: r.Method,
+			Path:   r.URL.RequestURI(),
+			Body:   body,
+		})
+
+		if statusCode, ok := s.takeInjectedError(route); ok {
+			writeError(w, statusCode, fmt.Sprintf("injected error for %s", route))
+			return
+		}
+
+		if authenticated {
+			token := r.Header.Get("X-API-KEY")
+			if token == "" || (s.apiToken != "" && token != s.apiToken) {
+				writeError(w, http.StatusUnauthorized, "invalid API key")
+				return
+			}
+		}
+
+		fn(w, r, body)
+	})
+}
+
+func (s *Server) takeInjectedError(route Route) (int, bool) {
+	for i, inj := range s.injected {
+		if inj.route != route {
+			continue
+		}
+
+		if inj.remaining > 0 {
+			inj.remaining--
+			if inj.remaining == 0 {
+				s.injected = slices.Delete(s.injected, i, i+1)
+			}
+		}
+		return inj.statusCode, true
+	}
+
+	return 0, false
+}
+
+func (s *Server) newID() string {
+	s.lastID++
+	// Pyxis IDs are 24 character hex strings.
+	return fmt.Sprintf("%024x", s.lastID)
+}
+
+func (s *Server) addProject(project pyxis.CertProject) {
+	if project.ID == "" {
+		project.ID = s.newID()
+	}
+	s.projects[project.ID] = &project
+}
+
+func (s *Server) addImage(image pyxis.CertImage) {
+	if image.ID == "" {
+		image.ID = s.newID()
+	}
+	s.images = append(s.images, &image)
+}
+
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request, _ []byte) {
+	project, ok := s.projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) updateProject(w http.ResponseWriter, r *http.Request, body []byte) {
+	project, ok := s.projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	// Pyxis does not allow the type of a project to be changed.
+	projectType, containerType := project.Type, project.Container.Type
+	updated := *project
+	if err := json.Unmarshal(body, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid project: %v", err))
+		return
+	}
+	if updated.Container.DockerConfigJSON != "" && !json.Valid([]byte(updated.Container.DockerConfigJSON)) {
+		writeError(w, http.StatusBadRequest, "container.docker_config_json is not valid json")
+		return
+	}
+	updated.ID, updated.Type, updated.Container.Type = project.ID, projectType, containerType
+
+	*project = updated
+	writeJSON(w, http.StatusOK, project)
+}
+
+func (s *Server) createTestResults(w http.ResponseWriter, r *http.Request, body []byte) {
+	projectID := r.PathValue("id")
+	if _, ok := s.projects[projectID]; !ok {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	var testResults pyxis.TestResults
+	if err := json.Unmarshal(body, &testResults); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid test results: %v", err))
+		return
+	}
+	testResults.ID = s.newID()
+	testResults.CertProject = projectID
+
+	s.testResults[testResults.ID] = &testResults
+	writeJSON(w, http.StatusCreated, testResults)
+}
+
+func (s *Server) updateTestResults(w http.ResponseWriter, r *http.Request, body []byte) {
+	testResults, ok := s.testResults[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "test results not found")
+		return
+	}
+
+	updated := *testResults
+	if err := json.Unmarshal(body, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid test results: %v", err))
+		return
+	}
+	updated.ID, updated.CertProject = testResults.ID, testResults.CertProject
+
+	*testResults = updated
+	writeJSON(w, http.StatusOK, testResults)
+}
+
+// getImages implements the project images listing, supporting only the
+// docker_image_digest==<digest> filter used by preflight.
+func (s *Server) getImages(w http.ResponseWriter, r *http.Request, _ []byte) {
+	project, ok := s.projects[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "project not found")
+		return
+	}
+
+	digest, _ := strings.CutPrefix(r.URL.Query().Get("filter"), "docker_image_digest==")
+
+	data := []pyxis.CertImage{}
+	for _, image := range s.images {
+		if image.ISVPID != project.Container.ISVPID {
+			continue
+		}
+		if digest != "" && image.DockerImageDigest != digest {
+			continue
+		}
+		data = append(data, *image)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":      data,
+		"page":      0,
+		"page_size": len(data),
+		"total":     len(data),
+	})
+}
+
+func (s *Server) createImage(w http.ResponseWriter, _ *http.Request, body []byte) {
+	var image pyxis.CertImage
+	if err := json.Unmarshal(body, &image); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid image: %v", err))
+		return
+	}
+
+	for _, existing := range s.images {
+		if existing.DockerImageDigest == image.DockerImageDigest && existing.ISVPID == image.ISVPID {
+			writeError(w, http.StatusConflict, "image with this docker_image_digest already exists")
+			return
+		}
+	}
+
+	image.ID = s.newID()
+	s.images = append(s.images, &image)
+	writeJSON(w, http.StatusCreated, image)
+}
+
+func (s *Server) updateImage(w http.ResponseWriter, r *http.Request, body []byte) {
+	image := s.findImage(r.PathValue("id"))
+	if image == nil {
+		writeError(w, http.StatusNotFound, "image not found")
+		return
+	}
+
+	updated := *image
+	if err := json.Unmarshal(body, &updated); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid image: %v", err))
+		return
+	}
+	updated.ID = image.ID
+
+	*image = updated
+	writeJSON(w, http.StatusOK, image)
+}
+
+func (s *Server) createRPMManifest(w http.ResponseWriter, r *http.Request, body []byte) {
+	imageID := r.PathValue("id")
+	if s.findImage(imageID) == nil {
+		writeError(w, http.StatusNotFound, "image not found")
+		return
+	}
+
+	if _, exists := s.rpmManifests[imageID]; exists {
+		writeError(w, http.StatusConflict, "rpm manifest already exists for image")
+		return
+	}
+
+	var manifest pyxis.RPMManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid rpm manifest: %v", err))
+		return
+	}
+	manifest.ID = s.newID()
+	manifest.ImageID = imageID
+
+	s.rpmManifests[imageID] = &manifest
+	writeJSON(w, http.StatusCreated, manifest)
+}
+
+func (s *Server) getRPMManifest(w http.ResponseWriter, r *http.Request, _ []byte) {
+	manifest, ok := s.rpmManifests[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "rpm manifest not found")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, manifest)
+}
+
+func (s *Server) createArtifact(w http.ResponseWriter, r *http.Request, body []byte) {
+	var artifact pyxis.Artifact
+	if err := json.Unmarshal(body, &artifact); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid artifact: %v", err))
+		return
+	}
+	artifact.ID = s.newID()
+	artifact.CertProject = r.PathValue("id")
+
+	s.artifacts = append(s.artifacts, &artifact)
+	writeJSON(w, http.StatusCreated, artifact)
+}
+
+func (s *Server) findImage(id string) *pyxis.CertImage {
+	for _, image := range s.images {
+		if image.ID == id {
+			return image
+		}
+	}
+	return nil
+}
+
+// graphql implements the two find_images queries preflight makes. The query
+// is identified by its variables, and the response only contains the fields
+// requested by the corresponding query in the pyxis package, since the graphql
+// client rejects unexpected fields.
+func (s *Server) graphql(w http.ResponseWriter, _ *http.Request, body []byte) {
+	var query struct {
+		Query     string `json:"query"`
+		Variables struct {
+			Digests         []string `json:"digests"`
+			ContImageLayers []string `json:"contImageLayers"`
+			Registries      []string `json:"registries"`
+		} `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &query); err != nil || !strings.Contains(query.Query, "find_images") {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"errors": []map[string]string{{"message": "unsupported query"}},
+		})
+		return
+	}
+
+	data := []map[string]any{}
+	switch {
+	case strings.Contains(query.Query, "uncompressed_top_layer_id"):
+		for _, image := range s.images {
+			if !slices.Contains(query.Variables.ContImageLayers, image.UncompressedTopLayerID) {
+				continue
+			}
+			if !slices.ContainsFunc(image.Repositories, func(r pyxis.Repository) bool {
+				return slices.Contains(query.Variables.Registries, r.Registry)
+			}) {
+				continue
+			}
+
+			grades := make([]map[string]any, 0, len(image.FreshnessGrades))
+			for _, g := range image.FreshnessGrades {
+				grades = append(grades, map[string]any{
+					"grade":      g.Grade,
+					"start_date": graphqlTime(g.StartDate),
+					"end_date":   graphqlTime(g.EndDate),
+				})
+			}
+			data = append(data, map[string]any{
+				"uncompressed_top_layer_id": image.UncompressedTopLayerID,
+				"_id":                       image.ID,
+				"freshness_grades":          grades,
+			})
+		}
+	default:
+		for _, image := range s.images {
+			if !slices.Contains(query.Variables.Digests, image.DockerImageDigest) {
+				continue
+			}
+			data = append(data, map[string]any{
+				"_id":                 image.ID,
+				"certified":           image.Certified,
+				"docker_image_digest": image.DockerImageDigest,
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"find_images": map[string]any{
+				"error": nil,
+				"total": len(data),
+				"page":  0,
+				"data":  data,
+			},
+		},
+	})
+}
+
+// graphqlTime formats t the way Pyxis does, with a zero time rendered as null.
+func graphqlTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		//coverage:ignore
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("could not marshal response: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeError writes an error body in the same shape Pyxis uses.
+func writeError(w http.ResponseWriter, statusCode int, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"status": statusCode,
+		"detail": detail,
+	})
+}
@@ -0,0 +1,241 @@
+package pyxistest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	. "github.com/onsi/ginkgo/v2/dsl/core"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/formatters"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/pyxis"
+)
+
+const (
+	projectID = "000000000000000000000abc"
+	apiToken  = "my-spiffy-api-token"
+)
+
+// client is the subset of the pyxis client used in these tests.
+type client interface {
+	GetProject(context.Context) (*pyxis.CertProject, error)
+	SubmitResults(context.Context, *pyxis.CertificationInput) (*pyxis.CertificationResults, error)
+	FindImagesByDigest(context.Context, []string) ([]pyxis.CertImage, error)
+	CertifiedImagesContainingLayers(context.Context, []cranev1.Hash) ([]pyxis.CertImage, error)
+}
+
+var _ = Describe("Pyxis stand-in server", func() {
+	var (
+		server *Server
+		ts     *httptest.Server
+		pc     client
+		ctx    context.Context
+	)
+
+	newCertInput := func(digest string) *pyxis.CertificationInput {
+		project, ok := server.Project(projectID)
+		Expect(ok).To(BeTrue())
+		return &pyxis.CertificationInput{
+			CertProject: &project,
+			CertImage: &pyxis.CertImage{
+				DockerImageDigest: digest,
+				ISVPID:            project.Container.ISVPID,
+				Certified:         true,
+				Repositories: []pyxis.Repository{
+					{Registry: "quay.io", Repository: "example/image"},
+				},
+			},
+			TestResults: &pyxis.TestResults{
+				UserResponse: formatters.UserResponse{Image: "quay.io/example/image:latest", Passed: true},
+			},
+			RpmManifest: &pyxis.RPMManifest{
+				RPMS: []pyxis.RPM{{Name: "bash"}},
+			},
+			Artifacts: []pyxis.Artifact{
+				{Filename: "preflight.log", ContentType: "text/plain", Content: "bG9n"},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		server = NewServer(
+			WithAPIToken(apiToken),
+			WithProjects(pyxis.CertProject{
+				ID:                  projectID,
+				Name:                "My Project",
+				CertificationStatus: "Started",
+				Type:                "Containers",
+				Container:           pyxis.Container{ISVPID: "my-isv-pid", Type: "container"},
+			}),
+			WithImages(pyxis.CertImage{
+				ID:                     "000000000000000000000ubi",
+				Certified:              true,
+				DockerImageDigest:      "sha256:ubi",
+				UncompressedTopLayerID: "sha256:ubitoplayer",
+				Repositories:           []pyxis.Repository{{Registry: "registry.access.redhat.com", Repository: "ubi9/ubi"}},
+				FreshnessGrades: []pyxis.FreshnessGrade{
+					{Grade: "A", StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			}),
+		)
+		ts = httptest.NewServer(server)
+		DeferCleanup(ts.Close)
+		pc = pyxis.NewPyxisClient(HostFor(ts.URL), apiToken, projectID, ts.Client())
+	})
+
+	Context("getting a project", func() {
+		It("should return the seeded project", func() {
+			project, err := pc.GetProject(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(project.Name).To(Equal("My Project"))
+		})
+		It("should fail when the project does not exist", func() {
+			pc = pyxis.NewPyxisClient(HostFor(ts.URL), apiToken, "missing", ts.Client())
+			_, err := pc.GetProject(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+		It("should reject an invalid API token", func() {
+			pc = pyxis.NewPyxisClient(HostFor(ts.URL), "wrong", projectID, ts.Client())
+			_, err := pc.GetProject(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("401"))
+		})
+	})
+
+	Context("submitting results", func() {
+		It("should store every submitted asset", func() {
+			results, err := pc.SubmitResults(ctx, newCertInput("sha256:deadb33f"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.CertImage.ID).ToNot(BeEmpty())
+			Expect(results.TestResults.ImageID).To(Equal(results.CertImage.ID))
+
+			project, _ := server.Project(projectID)
+			Expect(project.CertificationStatus).To(Equal("In Progress"))
+			Expect(project.Container.Registry).To(Equal("quay.io"))
+			Expect(project.Container.Repository).To(Equal("example/image"))
+
+			manifest, ok := server.RPMManifest(results.CertImage.ID)
+			Expect(ok).To(BeTrue())
+			Expect(manifest.RPMS).To(HaveLen(1))
+
+			tr, ok := server.TestResults(results.TestResults.ID)
+			Expect(ok).To(BeTrue())
+			Expect(tr.ImageID).To(Equal(results.CertImage.ID))
+
+			Expect(server.Artifacts()).To(HaveLen(1))
+			Expect(server.Artifacts()[0].ImageID).To(Equal(results.CertImage.ID))
+		})
+		It("should reuse an existing image on resubmission", func() {
+			first, err := pc.SubmitResults(ctx, newCertInput("sha256:deadb33f"))
+			Expect(err).ToNot(HaveOccurred())
+
+			second, err := pc.SubmitResults(ctx, newCertInput("sha256:deadb33f"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second.CertImage.ID).To(Equal(first.CertImage.ID))
+			Expect(server.Images()).To(HaveLen(2))
+		})
+		It("should record requests in order", func() {
+			_, err := pc.SubmitResults(ctx, newCertInput("sha256:deadb33f"))
+			Expect(err).ToNot(HaveOccurred())
+
+			routes := []Route{}
+			for _, r := range server.Requests() {
+				routes = append(routes, r.Route)
+			}
+			Expect(routes).To(Equal([]Route{
+				RouteCreateTestResults,
+				RouteUpdateProject,
+				RouteCreateImage,
+				RouteCreateRPMManifest,
+				RouteCreateArtifact,
+				RouteUpdateTestResults,
+			}))
+		})
+	})
+
+	Context("injecting errors", func() {
+		It("should fall back to getting the image on an injected 409", func() {
+			server.InjectError(RouteCreateImage, http.StatusConflict, 1)
+			server.AddImage(pyxis.CertImage{DockerImageDigest: "sha256:deadb33f", ISVPID: "my-isv-pid"})
+
+			_, err := pc.SubmitResults(ctx, newCertInput("sha256:deadb33f"))
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should only fail the requested number of times", func() {
+			server.InjectError(RouteGetProject, http.StatusInternalServerError, 1)
+
+			_, err := pc.GetProject(ctx)
+			Expect(err).To(HaveOccurred())
+			_, err = pc.GetProject(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+		It("should fail until cleared", func() {
+			server.InjectError(RouteGetProject, http.StatusServiceUnavailable, 0)
+
+			for range 3 {
+				_, err := pc.GetProject(ctx)
+				Expect(err).To(HaveOccurred())
+			}
+
+			server.ClearInjectedErrors()
+			_, err := pc.GetProject(ctx)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("graphql queries", func() {
+		It("should find images by digest", func() {
+			images, err := pc.FindImagesByDigest(ctx, []string{"sha256:ubi", "sha256:unknown"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(HaveLen(1))
+			Expect(images[0].Certified).To(BeTrue())
+		})
+		It("should find certified images containing layers", func() {
+			layerClient := pyxis.NewPyxisClient(HostFor(ts.URL), "", "", ts.Client())
+			images, err := layerClient.CertifiedImagesContainingLayers(ctx, []cranev1.Hash{
+				{Algorithm: "sha256", Hex: "ubitoplayer"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(images).To(HaveLen(1))
+			Expect(images[0].FreshnessGrades).To(HaveLen(1))
+			Expect(images[0].FreshnessGrades[0].Grade).To(Equal("A"))
+			Expect(images[0].FreshnessGrades[0].EndDate.IsZero()).To(BeTrue())
+		})
+	})
+
+	Context("loading fixtures", func() {
+		It("should load projects and images from yaml", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "fixtures.yaml")
+			Expect(os.WriteFile(path, []byte(`projects:
+- _id: "000000000000000000000def"
+  name: fixture project
+  project_status: active
+  container:
+    isv_pid: fixture-pid
+images:
+- docker_image_digest: sha256:fixture
+  certified: true
+`), 0o644)).To(Succeed())
+
+			fixtures, err := LoadFixtures(path)
+			Expect(err).ToNot(HaveOccurred())
+
+			s := NewServer(WithFixtures(fixtures))
+			project, ok := s.Project("000000000000000000000def")
+			Expect(ok).To(BeTrue())
+			Expect(project.Container.ISVPID).To(Equal("fixture-pid"))
+			Expect(s.Images()).To(HaveLen(1))
+			Expect(s.Images()[0].ID).ToNot(BeEmpty())
+		})
+		It("should fail when the file does not exist", func() {
+			_, err := LoadFixtures(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/go-logr/logr"
 	"github.com/google/go-containerregistry/pkg/name"
 )
 
@@ -12,9 +13,14 @@ var defaultRegistryAlias = "docker.io"
 
 // SubmitResults takes certInput and sends requests to Pyxis to create or update entries
 // based on certInput.
+//
+// If ctx contains a SubmissionJournal, each completed step is recorded in it, and
+// steps it already records as completed are skipped, reusing the IDs they returned.
 func (p *pyxisClient) SubmitResults(ctx context.Context, certInput *CertificationInput) (*CertificationResults, error) {
 	var err error
 
+	journal := JournalFromContext(ctx)
+
 	certImage := certInput.CertImage
 	// You must have an existing repository.
 	if len(certImage.Repositories) == 0 {
@@ -23,9 +29,14 @@ func (p *pyxisClient) SubmitResults(ctx context.Context, certInput *Certificatio
 
 	// Create the test results, so we can fail fast if version check throws error.
 	testResults := certInput.TestResults
-	testResults, err = p.createTestResults(ctx, testResults)
-	if err != nil {
-		return nil, fmt.Errorf("could not create test results: %v", err)
+	if step, ok := journal.Completed(StepCreateTestResults, ""); ok {
+		testResults.ID = step.ID
+	} else {
+		testResults, err = p.createTestResults(ctx, testResults)
+		if err != nil {
+			return nil, fmt.Errorf("could not create test results: %v", err)
+		}
+		recordStep(ctx, journal, StepCreateTestResults, "", testResults.ID)
 	}
 
 	certProject := certInput.CertProject
@@ -50,9 +61,12 @@ func (p *pyxisClient) SubmitResults(ctx context.Context, certInput *Certificatio
 	// always update the project no matter the status to ensure the dockerconfig preflight used to pull the image
 	// is the dockerfile that resides on the project and other backend processes ie clair use the same file
 	// Note: users no longer have the ability to update their project's dockerconfig in connect
-	certProject, err = p.updateProject(ctx, certProject)
-	if err != nil {
-		return nil, fmt.Errorf("could not update project: %v", err)
+	if _, ok := journal.Completed(StepUpdateProject, ""); !ok {
+		certProject, err = p.updateProject(ctx, certProject)
+		if err != nil {
+			return nil, fmt.Errorf("could not update project: %v", err)
+		}
+		recordStep(ctx, journal, StepUpdateProject, "", certProject.ID)
 	}
 
 	// store the original digest so that we can pull the image later
@@ -66,63 +80,77 @@ func (p *pyxisClient) SubmitResults(ctx context.Context, certInput *Certificatio
 	certImage.Repositories[0].Registry = normalizeDockerRegistry(certImage.Repositories[0].Registry)
 
 	// Create the image, or get it if it already exists.
-	certImage, err = p.createImage(ctx, certImage)
-	if err != nil {
-		if !errors.Is(err, ErrPyxis409StatusCode) {
-			return nil, fmt.Errorf("could not create image: %v", err)
-		}
-		certImage, err = p.getImage(ctx, originalImageDigest)
+	if step, ok := journal.Completed(StepCreateImage, ""); ok {
+		certImage.ID = step.ID
+	} else {
+		certImage, err = p.createImage(ctx, certImage)
 		if err != nil {
-			return nil, fmt.Errorf("could not get image: %v", err)
-		}
-
-		// checking to see if the original value is certified and the previous value is not certified,
-		// this would indicate that a partner is running preflight again, and during the first run there was a timeout/error
-		// in a check that interacts with pyxis and we need to correct the certified value for the image
-		if certified && !certImage.Certified {
-			// change the certified value to `true`
-			certImage.Certified = certified
-
-			certImage, err = p.updateImage(ctx, certImage)
+			if !errors.Is(err, ErrPyxis409StatusCode) {
+				return nil, fmt.Errorf("could not create image: %v", err)
+			}
+			certImage, err = p.getImage(ctx, originalImageDigest)
 			if err != nil {
-				return nil, fmt.Errorf("could not update image: %v", err)
+				return nil, fmt.Errorf("could not get image: %v", err)
+			}
+
+			// checking to see if the original value is certified and the previous value is not certified,
+			// this would indicate that a partner is running preflight again, and during the first run there was a timeout/error
+			// in a check that interacts with pyxis and we need to correct the certified value for the image
+			if certified && !certImage.Certified {
+				// change the certified value to `true`
+				certImage.Certified = certified
+
+				certImage, err = p.updateImage(ctx, certImage)
+				if err != nil {
+					return nil, fmt.Errorf("could not update image: %v", err)
+				}
 			}
 		}
+		recordStep(ctx, journal, StepCreateImage, "", certImage.ID)
 	}
 
-	if !certProject.ScratchProject() {
+	if _, ok := journal.Completed(StepCreateRPMManifest, ""); !ok && !certProject.ScratchProject() {
 		// Create the RPM manifest, or get it if it already exists.
 		rpmManifest := certInput.RpmManifest
 		rpmManifest.ImageID = certImage.ID
-		_, err = p.createRPMManifest(ctx, rpmManifest)
+		created, err := p.createRPMManifest(ctx, rpmManifest)
 		if err != nil {
 			if !errors.Is(err, ErrPyxis409StatusCode) {
 				return nil, fmt.Errorf("could not create rpm manifest: %v", err)
 			}
-			_, err = p.getRPMManifest(ctx, rpmManifest.ImageID)
+			created, err = p.getRPMManifest(ctx, rpmManifest.ImageID)
 			if err != nil {
 				return nil, fmt.Errorf("could not get rpm manifest: %v", err)
 			}
 		}
+		recordStep(ctx, journal, StepCreateRPMManifest, "", created.ID)
 	}
 
 	// Create the artifacts in Pyxis.
 	artifacts := certInput.Artifacts
 	for _, artifact := range artifacts {
 		//coverage:ignore
+		if _, ok := journal.Completed(StepCreateArtifact, artifact.Filename); ok {
+			continue
+		}
 		artifact.ImageID = certImage.ID
-		if _, err := p.createArtifact(ctx, &artifact); err != nil {
+		created, err := p.createArtifact(ctx, &artifact)
+		if err != nil {
 			//coverage:ignore
 			return nil, fmt.Errorf("could not create artifact: %s: %v", artifact.Filename, err)
 		}
+		recordStep(ctx, journal, StepCreateArtifact, artifact.Filename, created.ID)
 	}
 
 	// Update the test results with the certification image id to link the results to the image.
 	testResults.ImageID = certImage.ID
 
-	testResults, err = p.updateTestResults(ctx, testResults)
-	if err != nil {
-		return nil, fmt.Errorf("could not update test results: %v", err)
+	if _, ok := journal.Completed(StepUpdateTestResults, ""); !ok {
+		testResults, err = p.updateTestResults(ctx, testResults)
+		if err != nil {
+			return nil, fmt.Errorf("could not update test results: %v", err)
+		}
+		recordStep(ctx, journal, StepUpdateTestResults, "", testResults.ID)
 	}
 
 	// Return the results with up-to-date information.
@@ -133,6 +161,14 @@ func (p *pyxisClient) SubmitResults(ctx context.Context, certInput *Certificatio
 	}, nil
 }
 
+// recordStep records a completed step in journal. A journal that cannot be persisted
+// does not fail the submission, since the step has already been completed in Pyxis.
+func recordStep(ctx context.Context, journal *SubmissionJournal, step, key, id string) {
+	if err := journal.record(step, key, id); err != nil {
+		logr.FromContextOrDiscard(ctx).Error(err, "could not record submission step in journal", "step", step)
+	}
+}
+
 // normalizeDockerRegistry sets registry to the value we get from certImage from crane and then normalizes
 // index.docker.io to docker.io so project/image info shows properly in the Red Hat Catalog and other backend systems (Clair)
 func normalizeDockerRegistry(registry string) string {
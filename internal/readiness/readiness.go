@@ -0,0 +1,134 @@
+// Package readiness evaluates the deployments in a CSV for the settings an operator needs to run
+// reliably in production: resource requests and limits, probes, image pull policies, and the
+// priority class of cluster-critical operators.
+package readiness
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/csv"
+)
+
+// The names of the categories of findings, as set in the workload_readiness section of the config.
+const (
+	CategoryRequests      = "requests"
+	CategoryLimits        = "limits"
+	CategoryProbes        = "probes"
+	CategoryPullPolicy    = "pull_policy"
+	CategoryPriorityClass = "priority_class"
+)
+
+// CategoryNames are the names of the categories, in the order they are evaluated.
+var CategoryNames = []string{CategoryRequests, CategoryLimits, CategoryProbes, CategoryPullPolicy, CategoryPriorityClass}
+
+// SuggestedNamespaceAnnotation is the annotation of the CSV naming the namespace the operator is
+// installed in. An operator suggesting an openshift- namespace is cluster-critical.
+const SuggestedNamespaceAnnotation = "operatorframework.io/suggested-namespace"
+
+// Actions are the actions taken for the findings of each category, by name.
+type Actions map[string]action.Action
+
+// DefaultActions warns of the findings of every category.
+func DefaultActions() Actions {
+	actions := Actions{}
+	for _, name := range CategoryNames {
+		actions[name] = action.Warn
+	}
+	return actions
+}
+
+// Validate returns an error describing every invalid action in a.
+func (a Actions) Validate() error {
+	var errs []error
+	for name, act := range a {
+		if !slices.Contains(CategoryNames, name) {
+			errs = append(errs, fmt.Errorf("unknown category %s", name))
+			continue
+		}
+		if err := act.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("action of category %s %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Fails is true if the findings of any category in a fail.
+func (a Actions) Fails() bool {
+	return action.Fails(slices.Collect(maps.Values(a))...)
+}
+
+// Finding is a setting missing from a deployment, or one of its containers.
+type Finding struct {
+	Category string
+	// Action is the action taken for the findings of Category.
+	Action     action.Action
+	Deployment string
+	// Container is empty if the setting is of the pod.
+	Container string
+	Message   string
+}
+
+func (f Finding) String() string {
+	subject := "deployment " + f.Deployment
+	if f.Container != "" {
+		subject += ", container " + f.Container
+	}
+	return fmt.Sprintf("%s: %s", subject, f.Message)
+}
+
+// Evaluate returns the findings of each deployment in the CSV, taking the action in a for each.
+func (a Actions) Evaluate(c *operatorsv1alpha1.ClusterServiceVersion) []Finding {
+	var findings []Finding
+	critical := strings.HasPrefix(c.GetAnnotations()[SuggestedNamespaceAnnotation], "openshift-")
+
+	for _, deployment := range c.Spec.InstallStrategy.StrategySpec.DeploymentSpecs {
+		report := func(category, container, message string) {
+			findings = append(findings, Finding{
+				Category:   category,
+				Action:     a[category],
+				Deployment: deployment.Name,
+				Container:  container,
+				Message:    message,
+			})
+		}
+
+		pod := deployment.Spec.Template.Spec
+		for _, container := range slices.Concat(pod.InitContainers, pod.Containers) {
+			for _, resource := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if _, ok := container.Resources.Requests[resource]; !ok {
+					report(CategoryRequests, container.Name, fmt.Sprintf("has no %s request", resource))
+				}
+			}
+			if _, ok := container.Resources.Limits[corev1.ResourceMemory]; !ok {
+				report(CategoryLimits, container.Name, "has no memory limit")
+			}
+			if container.ImagePullPolicy == corev1.PullAlways && csv.IsPinned(container.Image) {
+				report(CategoryPullPolicy, container.Name, fmt.Sprintf("pulls image %s, which is pinned to a digest, with imagePullPolicy Always", container.Image))
+			}
+		}
+
+		// init containers run to completion, and so have no probes.
+		for _, container := range pod.Containers {
+			if container.LivenessProbe == nil {
+				report(CategoryProbes, container.Name, "has no liveness probe")
+			}
+			if container.ReadinessProbe == nil {
+				report(CategoryProbes, container.Name, "has no readiness probe")
+			}
+		}
+
+		if critical && pod.PriorityClassName == "" {
+			report(CategoryPriorityClass, "", fmt.Sprintf("has no priorityClassName, though the operator is cluster-critical, as it is installed in %s",
+				c.GetAnnotations()[SuggestedNamespaceAnnotation]))
+		}
+	}
+	return findings
+}
@@ -0,0 +1,135 @@
+package readiness
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+)
+
+var _ = Describe("Actions", func() {
+	It("should only warn by default", func() {
+		actions := DefaultActions()
+		Expect(actions).To(HaveLen(len(CategoryNames)))
+		Expect(actions.Validate()).To(Succeed())
+		Expect(actions.Fails()).To(BeFalse())
+	})
+
+	It("should fail if the findings of any category fail", func() {
+		actions := DefaultActions()
+		actions[CategoryRequests] = action.Fail
+		Expect(actions.Validate()).To(Succeed())
+		Expect(actions.Fails()).To(BeTrue())
+	})
+
+	It("should reject invalid actions", func() {
+		err := Actions{"probes": "ignore", "quotas": action.Warn}.Validate()
+		Expect(err).To(MatchError(ContainSubstring(`action of category probes must be warn or fail, got "ignore"`)))
+		Expect(err).To(MatchError(ContainSubstring("unknown category quotas")))
+	})
+})
+
+var _ = Describe("Evaluate", func() {
+	var (
+		actions Actions
+		c       *operatorsv1alpha1.ClusterServiceVersion
+	)
+
+	// ready is a container with every setting evaluated.
+	ready := func(name string) corev1.Container {
+		return corev1.Container{
+			Name:  name,
+			Image: "quay.io/example/operator:v0.0.1",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("20Mi")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("30Mi")},
+			},
+			LivenessProbe:  &corev1.Probe{},
+			ReadinessProbe: &corev1.Probe{},
+		}
+	}
+
+	// messages returns the findings of the CSV as they are reported.
+	messages := func() []string {
+		var reported []string
+		for _, finding := range actions.Evaluate(c) {
+			reported = append(reported, finding.String())
+		}
+		return reported
+	}
+
+	BeforeEach(func() {
+		actions = DefaultActions()
+		c = &operatorsv1alpha1.ClusterServiceVersion{}
+		c.Spec.InstallStrategy.StrategySpec.DeploymentSpecs = []operatorsv1alpha1.StrategyDeploymentSpec{{Name: "operator"}}
+	})
+
+	pod := func() *corev1.PodSpec {
+		return &c.Spec.InstallStrategy.StrategySpec.DeploymentSpecs[0].Spec.Template.Spec
+	}
+
+	It("should find nothing if every setting is set", func() {
+		pod().Containers = []corev1.Container{ready("manager")}
+		Expect(actions.Evaluate(c)).To(BeEmpty())
+	})
+
+	It("should report missing requests, limits and probes", func() {
+		pod().Containers = []corev1.Container{{Name: "manager"}}
+		Expect(messages()).To(Equal([]string{
+			"deployment operator, container manager: has no cpu request",
+			"deployment operator, container manager: has no memory request",
+			"deployment operator, container manager: has no memory limit",
+			"deployment operator, container manager: has no liveness probe",
+			"deployment operator, container manager: has no readiness probe",
+		}))
+	})
+
+	It("should not expect probes of init containers", func() {
+		initContainer := ready("init")
+		initContainer.LivenessProbe, initContainer.ReadinessProbe = nil, nil
+		delete(initContainer.Resources.Limits, corev1.ResourceMemory)
+		pod().InitContainers = []corev1.Container{initContainer}
+		pod().Containers = []corev1.Container{ready("manager")}
+		Expect(messages()).To(Equal([]string{"deployment operator, container init: has no memory limit"}))
+	})
+
+	It("should report pulling a pinned image Always", func() {
+		pinned, tagged := ready("manager"), ready("proxy")
+		pinned.Image = "quay.io/example/operator@sha256:b4d060da584f7f5f7935e8fb78a0b62b1abb829717ad522190ac7747abd9fbd1"
+		pinned.ImagePullPolicy = corev1.PullAlways
+		tagged.ImagePullPolicy = corev1.PullAlways
+		pod().Containers = []corev1.Container{pinned, tagged}
+
+		findings := actions.Evaluate(c)
+		Expect(findings).To(HaveLen(1))
+		Expect(findings[0].Category).To(Equal(CategoryPullPolicy))
+		Expect(findings[0].String()).To(Equal("deployment operator, container manager: pulls image " + pinned.Image + ", which is pinned to a digest, with imagePullPolicy Always"))
+	})
+
+	It("should report a cluster-critical operator without a priority class", func() {
+		pod().Containers = []corev1.Container{ready("manager")}
+		Expect(actions.Evaluate(c)).To(BeEmpty())
+
+		c.SetAnnotations(map[string]string{SuggestedNamespaceAnnotation: "openshift-example"})
+		Expect(messages()).To(Equal([]string{"deployment operator: has no priorityClassName, though the operator is cluster-critical, as it is installed in openshift-example"}))
+
+		pod().PriorityClassName = "system-cluster-critical"
+		Expect(actions.Evaluate(c)).To(BeEmpty())
+	})
+
+	It("should take the action of the category of each finding", func() {
+		actions[CategoryProbes] = action.Fail
+		container := ready("manager")
+		container.LivenessProbe = nil
+		delete(container.Resources.Requests, corev1.ResourceCPU)
+		pod().Containers = []corev1.Container{container}
+
+		Expect(actions.Evaluate(c)).To(ConsistOf(
+			Finding{Category: CategoryRequests, Action: action.Warn, Deployment: "operator", Container: "manager", Message: "has no cpu request"},
+			Finding{Category: CategoryProbes, Action: action.Fail, Deployment: "operator", Container: "manager", Message: "has no liveness probe"},
+		))
+	})
+})
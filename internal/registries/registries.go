@@ -0,0 +1,263 @@
+// Package registries resolves where, and how, images are pulled from their registries. It
+// supports the mirrors, blocked registries and insecure settings of the containers
+// registries.conf format (version 2), per-registry certificates laid out like the
+// containers certs.d directory, and per-registry docker credential helpers.
+package registries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ErrBlocked is returned when an image is pulled from a registry that is blocked.
+var ErrBlocked = errors.New("registry is blocked")
+
+// dockerHub is the name used for Docker Hub in registries.conf and credential helpers,
+// rather than the name.DefaultRegistry that references are normalized to.
+const dockerHub = "docker.io"
+
+// Config holds the registry settings used when pulling images. A nil Config is valid,
+// and pulls every image from its own registry with the default settings.
+type Config struct {
+	// Registries are the [[registry]] tables of registries.conf.
+	Registries []Registry `toml:"registry"`
+	// CertsDir contains a directory for each registry host, e.g. quay.io:443, containing
+	// CA certificates (*.crt), and client certificates (*.cert) with their keys (*.key).
+	CertsDir string `toml:"-"`
+	// CredentialHelpers maps a registry host to the name of the docker credential helper,
+	// without the docker-credential- prefix, that holds its credentials.
+	CredentialHelpers map[string]string `toml:"-"`
+}
+
+// Registry is a [[registry]] table of registries.conf.
+type Registry struct {
+	Prefix             string   `toml:"prefix"`
+	Location           string   `toml:"location"`
+	Insecure           bool     `toml:"insecure"`
+	Blocked            bool     `toml:"blocked"`
+	MirrorByDigestOnly bool     `toml:"mirror-by-digest-only"`
+	Mirrors            []Mirror `toml:"mirror"`
+}
+
+// Mirror is a [[registry.mirror]] table of registries.conf.
+type Mirror struct {
+	Location string `toml:"location"`
+	Insecure bool   `toml:"insecure"`
+	// PullFromMirror is one of all, digest-only, or tag-only.
+	PullFromMirror string `toml:"pull-from-mirror"`
+}
+
+// Source is a location an image can be pulled from.
+type Source struct {
+	Reference string
+	// Insecure allows the image to be pulled over plain HTTP, or with an untrusted certificate.
+	Insecure bool
+}
+
+// New returns the Config for the registries.conf file at registriesConf, the certificates
+// directory certsDir, and credentialHelpers. Each may be empty, and nil is returned when
+// they all are.
+func New(registriesConf string, certsDir string, credentialHelpers map[string]string) (*Config, error) {
+	if registriesConf == "" && certsDir == "" && len(credentialHelpers) == 0 {
+		return nil, nil
+	}
+
+	c := &Config{}
+	if registriesConf != "" {
+		b, err := os.ReadFile(registriesConf)
+		if err != nil {
+			return nil, fmt.Errorf("could not read registries configuration: %w", err)
+		}
+		if err := toml.Unmarshal(b, c); err != nil {
+			return nil, fmt.Errorf("could not parse registries configuration %s: %w", registriesConf, err)
+		}
+	}
+
+	for i, r := range c.Registries {
+		if r.Prefix == "" && r.Location == "" {
+			return nil, fmt.Errorf("registries configuration %s: registry %d must set a prefix or a location", registriesConf, i)
+		}
+		if strings.HasPrefix(r.Prefix, "*.") && r.Location != "" {
+			return nil, fmt.Errorf("registries configuration %s: registry %s with a wildcard prefix cannot set a location", registriesConf, r.Prefix)
+		}
+	}
+
+	c.CertsDir = certsDir
+	c.CredentialHelpers = credentialHelpers
+
+	return c, nil
+}
+
+// Sources returns the locations image should be pulled from, in the order they should be
+// tried. Mirrors come first, followed by the image's own registry. ErrBlocked is returned
+// if that registry is blocked.
+func (c *Config) Sources(image string) ([]Source, error) {
+	if c == nil {
+		return []Source{{Reference: image}}, nil
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse image reference %s: %w", image, err)
+	}
+	normalized := normalize(ref)
+
+	r, matched := c.lookup(normalized)
+	if r == nil {
+		return []Source{{Reference: image}}, nil
+	}
+
+	if r.Blocked {
+		return nil, fmt.Errorf("%w: %s", ErrBlocked, image)
+	}
+
+	remainder := strings.TrimPrefix(normalized, matched)
+	byDigest := strings.Contains(normalized, "@")
+
+	sources := make([]Source, 0, len(r.Mirrors)+1)
+	for _, m := range r.Mirrors {
+		pullFrom := m.PullFromMirror
+		if pullFrom == "" && r.MirrorByDigestOnly {
+			pullFrom = "digest-only"
+		}
+		if (pullFrom == "digest-only" && !byDigest) || (pullFrom == "tag-only" && byDigest) {
+			continue
+		}
+		sources = append(sources, Source{Reference: m.Location + remainder, Insecure: m.Insecure})
+	}
+
+	location := matched
+	if r.Location != "" {
+		location = r.Location
+	}
+
+	return append(sources, Source{Reference: location + remainder, Insecure: r.Insecure}), nil
+}
+
+// CredentialHelper returns the name of the credential helper configured for registry, or
+// an empty string.
+func (c *Config) CredentialHelper(registry string) string {
+	if c == nil {
+		return ""
+	}
+	if registry == name.DefaultRegistry {
+		if helper, ok := c.CredentialHelpers[dockerHub]; ok {
+			return helper
+		}
+	}
+
+	return c.CredentialHelpers[registry]
+}
+
+// lookup returns the registry with the longest prefix matching the normalized reference ref,
+// and the portion of ref that it matched. Wildcard prefixes, which only match the host, are
+// used when no other prefix matches.
+func (c *Config) lookup(ref string) (*Registry, string) {
+	host, _, _ := strings.Cut(ref, "/")
+
+	var found, wildcard *Registry
+	for i := range c.Registries {
+		r := &c.Registries[i]
+		prefix := r.prefix()
+
+		if strings.HasPrefix(prefix, "*.") {
+			if strings.HasSuffix(host, prefix[1:]) && (wildcard == nil || len(prefix) > len(wildcard.prefix())) {
+				wildcard = r
+			}
+			continue
+		}
+
+		if ref != prefix && !(strings.HasPrefix(ref, prefix) && strings.ContainsAny(ref[len(prefix):len(prefix)+1], "/:@")) {
+			continue
+		}
+		if found == nil || len(prefix) > len(found.prefix()) {
+			found = r
+		}
+	}
+
+	switch {
+	case found != nil:
+		return found, found.prefix()
+	case wildcard != nil:
+		return wildcard, host
+	default:
+		return nil, ""
+	}
+}
+
+// insecureHost reports whether registries.conf allows insecure connections to host.
+func (c *Config) insecureHost(host string) bool {
+	if c == nil {
+		return false
+	}
+
+	for _, r := range c.Registries {
+		prefix := r.prefix()
+		if r.Insecure {
+			if strings.HasPrefix(prefix, "*.") && strings.HasSuffix(host, prefix[1:]) || hostOf(prefix) == host || r.Location != "" && hostOf(r.Location) == host {
+				return true
+			}
+		}
+		for _, m := range r.Mirrors {
+			if m.Insecure && hostOf(m.Location) == host {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// prefix returns the prefix the registry matches, which defaults to its location.
+func (r *Registry) prefix() string {
+	if r.Prefix != "" {
+		return r.Prefix
+	}
+	return r.Location
+}
+
+// normalize returns ref in the fully qualified form used by registries.conf.
+func normalize(ref name.Reference) string {
+	registry := ref.Context().RegistryStr()
+	if registry == name.DefaultRegistry {
+		registry = dockerHub
+	}
+
+	separator := ":"
+	if _, ok := ref.(name.Digest); ok {
+		separator = "@"
+	}
+
+	return registry + "/" + ref.Context().RepositoryStr() + separator + ref.Identifier()
+}
+
+// hostOf returns the host of location, which may include a namespace and repository.
+func hostOf(location string) string {
+	host, _, _ := strings.Cut(location, "/")
+	if host == dockerHub {
+		return name.DefaultRegistry
+	}
+	return host
+}
+
+// contextKey is a key used to store/retrieve a Config in/from context.Context.
+type contextKey string
+
+const configContextKey contextKey = "RegistriesConfig"
+
+// ContextWithConfig adds the Config c to the context ctx.
+func ContextWithConfig(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, configContextKey, c)
+}
+
+// FromContext returns the Config from the context, or nil.
+func FromContext(ctx context.Context) *Config {
+	c, _ := ctx.Value(configContextKey).(*Config)
+	return c
+}
@@ -0,0 +1,13 @@
+package registries
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRegistries(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Registries Suite")
+}
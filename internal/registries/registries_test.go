@@ -0,0 +1,159 @@
+package registries
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const registriesConf = `
+[[registry]]
+prefix = "quay.io/example"
+location = "quay.io/example"
+
+[[registry.mirror]]
+location = "mirror.example.com/quay/example"
+
+[[registry.mirror]]
+location = "insecure.example.com/example"
+insecure = true
+pull-from-mirror = "digest-only"
+
+[[registry]]
+prefix = "quay.io/example/blocked"
+blocked = true
+
+[[registry]]
+prefix = "*.internal.example.com"
+insecure = true
+
+[[registry]]
+location = "docker.io/library"
+mirror-by-digest-only = true
+
+[[registry.mirror]]
+location = "mirror.example.com/dockerhub"
+
+[[registry]]
+prefix = "registry.example.com/old"
+location = "registry.example.com/new"
+`
+
+const digest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+func writeRegistriesConf(content string) string {
+	p := filepath.Join(GinkgoT().TempDir(), "registries.conf")
+	Expect(os.WriteFile(p, []byte(content), 0o600)).To(Succeed())
+	return p
+}
+
+var _ = Describe("Registries", func() {
+	Context("when nothing is configured", func() {
+		It("should return a nil Config that pulls from the image's own registry", func() {
+			c, err := New("", "", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c).To(BeNil())
+
+			sources, err := c.Sources("quay.io/example/image:latest")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sources).To(Equal([]Source{{Reference: "quay.io/example/image:latest"}}))
+			Expect(c.CredentialHelper("quay.io")).To(BeEmpty())
+		})
+	})
+
+	Context("when the registries configuration is invalid", func() {
+		It("should fail when the file does not exist", func() {
+			_, err := New(filepath.Join(GinkgoT().TempDir(), "missing.conf"), "", nil)
+			Expect(err).To(MatchError(ContainSubstring("could not read registries configuration")))
+		})
+
+		It("should fail when the file is not TOML", func() {
+			_, err := New(writeRegistriesConf("[[registry"), "", nil)
+			Expect(err).To(MatchError(ContainSubstring("could not parse registries configuration")))
+		})
+
+		It("should fail when a registry has neither a prefix nor a location", func() {
+			_, err := New(writeRegistriesConf("[[registry]]\ninsecure = true\n"), "", nil)
+			Expect(err).To(MatchError(ContainSubstring("must set a prefix or a location")))
+		})
+
+		It("should fail when a wildcard prefix sets a location", func() {
+			_, err := New(writeRegistriesConf("[[registry]]\nprefix = \"*.example.com\"\nlocation = \"example.com\"\n"), "", nil)
+			Expect(err).To(MatchError(ContainSubstring("cannot set a location")))
+		})
+	})
+
+	Context("when the registries configuration is valid", func() {
+		var c *Config
+
+		BeforeEach(func() {
+			var err error
+			c, err = New(writeRegistriesConf(registriesConf), "", map[string]string{
+				"quay.io":   "quay",
+				"docker.io": "hub",
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		DescribeTable("resolving the sources of an image",
+			func(image string, expected []Source) {
+				sources, err := c.Sources(image)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(sources).To(Equal(expected))
+			},
+			Entry("an unconfigured registry", "registry.redhat.io/ubi9/ubi:latest", []Source{
+				{Reference: "registry.redhat.io/ubi9/ubi:latest"},
+			}),
+			Entry("a tag with mirrors", "quay.io/example/image:latest", []Source{
+				{Reference: "mirror.example.com/quay/example/image:latest"},
+				{Reference: "quay.io/example/image:latest"},
+			}),
+			Entry("a digest with mirrors", "quay.io/example/image@"+digest, []Source{
+				{Reference: "mirror.example.com/quay/example/image@" + digest},
+				{Reference: "insecure.example.com/example/image@" + digest, Insecure: true},
+				{Reference: "quay.io/example/image@" + digest},
+			}),
+			Entry("a prefix that is not a path boundary", "quay.io/examples/image:latest", []Source{
+				{Reference: "quay.io/examples/image:latest"},
+			}),
+			Entry("a wildcard prefix", "registry.internal.example.com/image:latest", []Source{
+				{Reference: "registry.internal.example.com/image:latest", Insecure: true},
+			}),
+			Entry("a relocated prefix", "registry.example.com/old/image:v1", []Source{
+				{Reference: "registry.example.com/new/image:v1"},
+			}),
+			Entry("a docker hub tag, mirrored by digest only", "busybox:latest", []Source{
+				{Reference: "docker.io/library/busybox:latest"},
+			}),
+			Entry("a docker hub digest", "index.docker.io/library/busybox@"+digest, []Source{
+				{Reference: "mirror.example.com/dockerhub/busybox@" + digest},
+				{Reference: "docker.io/library/busybox@" + digest},
+			}),
+		)
+
+		It("should refuse to pull from a blocked registry", func() {
+			_, err := c.Sources("quay.io/example/blocked:latest")
+			Expect(err).To(MatchError(ErrBlocked))
+		})
+
+		It("should fail to resolve an invalid reference", func() {
+			_, err := c.Sources("quay.io/Example:")
+			Expect(err).To(MatchError(ContainSubstring("could not parse image reference")))
+		})
+
+		It("should return the credential helper of a registry", func() {
+			Expect(c.CredentialHelper("quay.io")).To(Equal("quay"))
+			Expect(c.CredentialHelper(name.DefaultRegistry)).To(Equal("hub"))
+			Expect(c.CredentialHelper("registry.redhat.io")).To(BeEmpty())
+		})
+
+		It("should be stored in, and retrieved from, a context", func() {
+			Expect(FromContext(context.TODO())).To(BeNil())
+			Expect(FromContext(ContextWithConfig(context.TODO(), c))).To(BeIdenticalTo(c))
+		})
+	})
+})
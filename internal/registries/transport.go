@@ -0,0 +1,132 @@
+package registries
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Transport returns an http.RoundTripper that uses the certificates in CertsDir, and skips
+// verification for the hosts registries.conf marks as insecure. Other hosts use base. If
+// base is not an *http.Transport, it is returned as is.
+func (c *Config) Transport(base http.RoundTripper) http.RoundTripper {
+	t, ok := base.(*http.Transport)
+	if c == nil || !ok {
+		return base
+	}
+
+	return &hostTransport{config: c, base: t}
+}
+
+// hostTransport selects a transport with the TLS configuration of each request's host.
+type hostTransport struct {
+	config *Config
+	base   *http.Transport
+
+	mu    sync.Mutex
+	hosts map[string]http.RoundTripper
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt, err := t.forHost(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	return rt.RoundTrip(req)
+}
+
+// forHost returns the transport for host, creating it on first use.
+func (t *hostTransport) forHost(host string) (http.RoundTripper, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if rt, ok := t.hosts[host]; ok {
+		return rt, nil
+	}
+
+	tlsConfig, err := t.config.TLSConfig(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var rt http.RoundTripper = t.base
+	if tlsConfig != nil {
+		clone := t.base.Clone()
+		clone.TLSClientConfig = tlsConfig
+		rt = clone
+	}
+
+	if t.hosts == nil {
+		t.hosts = map[string]http.RoundTripper{}
+	}
+	t.hosts[host] = rt
+
+	return rt, nil
+}
+
+// TLSConfig returns the TLS configuration for host, or nil if the default should be used.
+// CA certificates are added to the system pool, so public registries are still trusted.
+func (c *Config) TLSConfig(host string) (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	var tlsConfig *tls.Config
+	if c.insecureHost(host) {
+		tlsConfig = &tls.Config{
+			InsecureSkipVerify: true, //nolint: gosec
+		}
+	}
+
+	if c.CertsDir == "" {
+		return tlsConfig, nil
+	}
+
+	dir := filepath.Join(c.CertsDir, host)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return tlsConfig, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificates for %s: %w", host, err)
+	}
+
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	for _, entry := range entries {
+		filename := filepath.Join(dir, entry.Name())
+		switch filepath.Ext(entry.Name()) {
+		case ".crt":
+			if tlsConfig.RootCAs == nil {
+				if tlsConfig.RootCAs, err = x509.SystemCertPool(); err != nil {
+					//coverage:ignore
+					tlsConfig.RootCAs = x509.NewCertPool()
+				}
+			}
+			pem, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, fmt.Errorf("could not read CA certificate %s: %w", filename, err)
+			}
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates were found in %s", filename)
+			}
+		case ".cert":
+			keyFile := strings.TrimSuffix(filename, ".cert") + ".key"
+			cert, err := tls.LoadX509KeyPair(filename, keyFile)
+			if err != nil {
+				return nil, fmt.Errorf("could not load client certificate %s: %w", filename, err)
+			}
+			tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+		}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,92 @@
+package registries
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		server   *httptest.Server
+		host     string
+		certsDir string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		DeferCleanup(server.Close)
+
+		u, err := url.Parse(server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		host = u.Host
+
+		certsDir = GinkgoT().TempDir()
+	})
+
+	get := func(c *Config) error {
+		client := &http.Client{Transport: c.Transport(http.DefaultTransport.(*http.Transport).Clone())}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	It("should not trust the registry's certificate by default", func() {
+		Expect(get(nil)).ToNot(Succeed())
+	})
+
+	It("should trust the CA certificates in the registry's certs directory", func() {
+		Expect(os.Mkdir(filepath.Join(certsDir, host), 0o755)).To(Succeed())
+		ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(filepath.Join(certsDir, host, "ca.crt"), ca, 0o600)).To(Succeed())
+
+		c, err := New("", certsDir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(get(c)).To(Succeed())
+	})
+
+	It("should skip verification for an insecure registry", func() {
+		c, err := New(writeRegistriesConf("[[registry]]\nlocation = \""+host+"\"\ninsecure = true\n"), "", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(get(c)).To(Succeed())
+	})
+
+	It("should fail when a CA certificate is invalid", func() {
+		Expect(os.Mkdir(filepath.Join(certsDir, host), 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(certsDir, host, "ca.crt"), []byte("not a certificate"), 0o600)).To(Succeed())
+
+		c, err := New("", certsDir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = c.TLSConfig(host)
+		Expect(err).To(MatchError(ContainSubstring("no certificates were found")))
+	})
+
+	It("should fail when a client certificate has no key", func() {
+		Expect(os.Mkdir(filepath.Join(certsDir, host), 0o755)).To(Succeed())
+		cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		Expect(os.WriteFile(filepath.Join(certsDir, host, "client.cert"), cert, 0o600)).To(Succeed())
+
+		c, err := New("", certsDir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = c.TLSConfig(host)
+		Expect(err).To(MatchError(ContainSubstring("could not load client certificate")))
+	})
+
+	It("should use the default configuration for other registries", func() {
+		c, err := New("", certsDir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		tlsConfig, err := c.TLSConfig("quay.io")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tlsConfig).To(BeNil())
+	})
+})
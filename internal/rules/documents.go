@@ -0,0 +1,226 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	"github.com/operator-framework/api/pkg/manifests"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// packageListFunc is the signature used to retrieve the RPM package list.
+type packageListFunc func(ctx context.Context, dir string) ([]*rpmdb.PackageInfo, error)
+
+// documents loads the documents rules are evaluated against. As the checks of all rules are
+// run against the same image, the documents of the image last loaded are reused.
+type documents struct {
+	mu             sync.Mutex
+	image          image.ImageReference
+	vars           map[string]any
+	getPackageList packageListFunc
+}
+
+// load returns the documents named in names for imgRef.
+func (d *documents) load(ctx context.Context, imgRef image.ImageReference, names []string) (map[string]any, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.vars == nil || d.image.ImageURI != imgRef.ImageURI || d.image.ImageFSPath != imgRef.ImageFSPath {
+		d.image = imgRef
+		d.vars = map[string]any{}
+	}
+
+	for _, name := range names {
+		if _, ok := d.vars[name]; ok {
+			continue
+		}
+
+		var doc any
+		var err error
+		switch name {
+		case DocumentImage:
+			doc, err = imageDocument(imgRef)
+		case DocumentConfig:
+			doc, err = configDocument(imgRef)
+		case DocumentLabels:
+			doc, err = labelsDocument(imgRef)
+		case DocumentUser:
+			doc, err = userDocument(imgRef)
+		case DocumentManifest:
+			doc, err = manifestDocument(imgRef)
+		case DocumentRPMs:
+			doc, err = d.rpmsDocument(ctx, imgRef)
+		case DocumentBundle:
+			doc, err = bundleDocument(imgRef)
+		default:
+			// names also includes the functions and variables local to the expression.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		d.vars[name] = doc
+	}
+
+	return maps.Clone(d.vars), nil
+}
+
+func imageDocument(imgRef image.ImageReference) (map[string]string, error) {
+	doc := map[string]string{
+		"uri":                  imgRef.ImageURI,
+		"registry":             imgRef.ImageRegistry,
+		"repository":           imgRef.ImageRepository,
+		"tag_or_sha":           imgRef.ImageTagOrSha,
+		"manifest_list_digest": imgRef.ManifestListDigest,
+		"digest":               "",
+	}
+	if imgRef.ImageInfo != nil {
+		digest, err := imgRef.ImageInfo.Digest()
+		if err != nil {
+			return nil, err
+		}
+		doc["digest"] = digest.String()
+	}
+
+	return doc, nil
+}
+
+func configDocument(imgRef image.ImageReference) (any, error) {
+	if imgRef.ImageInfo == nil {
+		return nil, fmt.Errorf("no image was loaded")
+	}
+
+	raw, err := imgRef.ImageInfo.RawConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(raw)
+}
+
+func labelsDocument(imgRef image.ImageReference) (map[string]string, error) {
+	if imgRef.ImageInfo == nil {
+		return nil, fmt.Errorf("no image was loaded")
+	}
+
+	config, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	maps.Copy(labels, config.Config.Labels)
+	return labels, nil
+}
+
+func userDocument(imgRef image.ImageReference) (string, error) {
+	if imgRef.ImageInfo == nil {
+		return "", fmt.Errorf("no image was loaded")
+	}
+
+	config, err := imgRef.ImageInfo.ConfigFile()
+	if err != nil {
+		return "", err
+	}
+
+	return config.Config.User, nil
+}
+
+func manifestDocument(imgRef image.ImageReference) (any, error) {
+	if imgRef.ImageInfo == nil {
+		return nil, fmt.Errorf("no image was loaded")
+	}
+
+	raw, err := imgRef.ImageInfo.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return decode(raw)
+}
+
+func (d *documents) rpmsDocument(ctx context.Context, imgRef image.ImageReference) ([]map[string]any, error) {
+	pkgList, err := d.getPackageList(ctx, imgRef.ImageFSPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rpms := make([]map[string]any, 0, len(pkgList))
+	for _, pkg := range pkgList {
+		epoch := 0
+		if pkg.Epoch != nil {
+			epoch = *pkg.Epoch
+		}
+		rpms = append(rpms, map[string]any{
+			"name":       pkg.Name,
+			"version":    pkg.Version,
+			"release":    pkg.Release,
+			"epoch":      epoch,
+			"arch":       pkg.Arch,
+			"source_rpm": pkg.SourceRpm,
+			"vendor":     pkg.Vendor,
+		})
+	}
+
+	return rpms, nil
+}
+
+func bundleDocument(imgRef image.ImageReference) (map[string]any, error) {
+	b, err := manifests.GetBundleFromDir(imgRef.ImageFSPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load bundle: %w", err)
+	}
+
+	doc := map[string]any{}
+
+	if b.CSV != nil {
+		raw, err := json.Marshal(b.CSV)
+		if err != nil {
+			//coverage:ignore
+			return nil, err
+		}
+		if doc["csv"], err = decode(raw); err != nil {
+			//coverage:ignore
+			return nil, err
+		}
+	}
+
+	objects := make([]any, 0, len(b.Objects))
+	for _, obj := range b.Objects {
+		objects = append(objects, obj.Object)
+	}
+	doc["objects"] = objects
+
+	raw, err := os.ReadFile(filepath.Join(imgRef.ImageFSPath, "metadata", "annotations.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle annotations: %w", err)
+	}
+	var annotationsFile struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := yaml.Unmarshal(raw, &annotationsFile); err != nil {
+		return nil, fmt.Errorf("could not decode bundle annotations: %w", err)
+	}
+	annotations := map[string]string{}
+	maps.Copy(annotations, annotationsFile.Annotations)
+	doc["annotations"] = annotations
+
+	return doc, nil
+}
+
+// decode returns the JSON document raw as maps and lists.
+func decode(raw []byte) (any, error) {
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,246 @@
+// Package rules runs checks written as CEL expressions, declared in rules files, against
+// documents describing the image under test.
+//
+// A rule passes if its expression is true. The documents available to an expression are:
+//
+//   - image: the image's uri, registry, repository, tag_or_sha and digest.
+//   - config: the image's config file, e.g. config.config.User.
+//   - labels: the image's labels.
+//   - user: the user the image runs as.
+//   - manifest: the image's manifest, e.g. manifest.layers.
+//   - rpms: the image's packages, each with a name, version, release, epoch, arch,
+//     source_rpm and vendor.
+//   - bundle: the csv, annotations and objects of an operator bundle.
+//
+// The image's rpm database, and the bundle's manifests, are only extracted for rules that
+// refer to rpms or bundle respectively.
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"sigs.k8s.io/yaml"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+// The documents available to rule expressions.
+const (
+	DocumentImage    = "image"
+	DocumentConfig   = "config"
+	DocumentLabels   = "labels"
+	DocumentUser     = "user"
+	DocumentManifest = "manifest"
+	DocumentRPMs     = "rpms"
+	DocumentBundle   = "bundle"
+)
+
+// File is a rules file.
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Rule is a check written as a CEL expression.
+type Rule struct {
+	Name             string         `json:"name"`
+	Level            string         `json:"level,omitempty"`
+	Description      string         `json:"description"`
+	KnowledgeBaseURL string         `json:"knowledge_base_url,omitempty"`
+	CheckURL         string         `json:"check_url,omitempty"`
+	Help             check.HelpText `json:"help"`
+	// Expression is true if the image passes the check.
+	Expression string `json:"expression"`
+	// Findings, if set, is an expression returning a list of strings, e.g. the names of
+	// offending packages, that is reported when the check fails.
+	Findings string `json:"findings,omitempty"`
+}
+
+// Load reads each of the rules files at paths, and returns a check for each of their rules.
+func Load(ctx context.Context, paths []string) ([]check.Check, error) {
+	return load(ctx, paths, &documents{getPackageList: rpm.GetPackageList})
+}
+
+func load(ctx context.Context, paths []string, docs *documents) ([]check.Check, error) {
+	logger := logr.FromContextOrDiscard(ctx)
+
+	env, err := newEnv()
+	if err != nil {
+		//coverage:ignore
+		return nil, err
+	}
+
+	var checks []check.Check
+	var names []string
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read rules file: %w", err)
+		}
+
+		var file File
+		if err := yaml.UnmarshalStrict(raw, &file); err != nil {
+			return nil, fmt.Errorf("could not decode rules file %s: %w", path, err)
+		}
+
+		for i, rule := range file.Rules {
+			c, err := compile(env, docs, rule)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rule %d in %s: %w", i, path, err)
+			}
+			if slices.Contains(names, c.Name()) {
+				return nil, fmt.Errorf("invalid rule %d in %s: a rule named %s is already loaded", i, path, c.Name())
+			}
+			names = append(names, c.Name())
+
+			logger.V(log.DBG).Info("loaded rule", "path", path, "name", c.Name(), "level", c.Metadata().Level)
+			checks = append(checks, c)
+		}
+	}
+
+	return checks, nil
+}
+
+func newEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(DocumentImage, cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable(DocumentConfig, cel.DynType),
+		cel.Variable(DocumentLabels, cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable(DocumentUser, cel.StringType),
+		cel.Variable(DocumentManifest, cel.DynType),
+		cel.Variable(DocumentRPMs, cel.ListType(cel.MapType(cel.StringType, cel.DynType))),
+		cel.Variable(DocumentBundle, cel.DynType),
+		ext.Strings(),
+		ext.Sets(),
+		ext.Lists(),
+	)
+}
+
+// compile returns a check evaluating rule.
+func compile(env *cel.Env, docs *documents, rule Rule) (check.Check, error) {
+	if rule.Name == "" {
+		return nil, errors.New("the rule has no name")
+	}
+	if rule.Level == "" {
+		rule.Level = check.LevelBest
+	}
+	if rule.Level != check.LevelBest && rule.Level != check.LevelWarn && rule.Level != check.LevelOptional {
+		return nil, fmt.Errorf("level %q of rule %s is not one of %s, %s or %s", rule.Level, rule.Name, check.LevelBest, check.LevelWarn, check.LevelOptional)
+	}
+	if rule.Expression == "" {
+		return nil, fmt.Errorf("rule %s has no expression", rule.Name)
+	}
+
+	expression, referenced, err := program(env, rule.Expression, types.BoolKind)
+	if err != nil {
+		return nil, fmt.Errorf("expression of rule %s: %w", rule.Name, err)
+	}
+
+	var findings cel.Program
+	if rule.Findings != "" {
+		var findingsReferenced []string
+		findings, findingsReferenced, err = program(env, rule.Findings, types.ListKind)
+		if err != nil {
+			return nil, fmt.Errorf("findings of rule %s: %w", rule.Name, err)
+		}
+		referenced = append(referenced, findingsReferenced...)
+	}
+
+	var patterns []string
+	if slices.Contains(referenced, DocumentRPMs) {
+		patterns = append(patterns, rpm.RpmdbPaths...)
+	}
+	if slices.Contains(referenced, DocumentBundle) {
+		patterns = append(patterns, bundle.BundleFiles...)
+	}
+
+	validate := func(ctx context.Context, imgRef image.ImageReference) (bool, error) {
+		vars, err := docs.load(ctx, imgRef, referenced)
+		if err != nil {
+			return false, fmt.Errorf("could not load documents for rule %s: %w", rule.Name, err)
+		}
+
+		out, _, err := expression.ContextEval(ctx, vars)
+		if err != nil {
+			return false, fmt.Errorf("could not evaluate rule %s: %w", rule.Name, err)
+		}
+		passed, ok := out.Value().(bool)
+		if !ok {
+			return false, fmt.Errorf("rule %s evaluated to %s, not bool", rule.Name, out.Type().TypeName())
+		}
+		if passed || findings == nil {
+			return passed, nil
+		}
+
+		out, _, err = findings.ContextEval(ctx, vars)
+		if err != nil {
+			return false, fmt.Errorf("could not evaluate findings of rule %s: %w", rule.Name, err)
+		}
+		list, err := out.ConvertToNative(reflect.TypeFor[[]string]())
+		if err != nil {
+			return false, fmt.Errorf("findings of rule %s are not a list of strings: %w", rule.Name, err)
+		}
+		check.ReportFindings(ctx, list.([]string)...)
+
+		return false, nil
+	}
+
+	return check.NewGenericCheck(
+		rule.Name,
+		validate,
+		check.Metadata{
+			Description:      rule.Description,
+			Level:            rule.Level,
+			KnowledgeBaseURL: rule.KnowledgeBaseURL,
+			CheckURL:         rule.CheckURL,
+		},
+		rule.Help,
+		patterns,
+	), nil
+}
+
+// program compiles expression, which must evaluate to kind, and returns it with the
+// documents it refers to.
+func program(env *cel.Env, expression string, kind types.Kind) (cel.Program, []string, error) {
+	ast, issues := env.Compile(expression)
+	if issues.Err() != nil {
+		return nil, nil, issues.Err()
+	}
+
+	out := ast.OutputType()
+	if out.Kind() != kind && out.Kind() != types.DynKind {
+		return nil, nil, fmt.Errorf("evaluates to %s, not %s", out, kindNames[kind])
+	}
+
+	prg, err := env.Program(ast, cel.InterruptCheckFrequency(100))
+	if err != nil {
+		//coverage:ignore
+		return nil, nil, err
+	}
+
+	var referenced []string
+	for _, ref := range ast.NativeRep().ReferenceMap() {
+		if ref.Name != "" && !slices.Contains(referenced, ref.Name) {
+			referenced = append(referenced, ref.Name)
+		}
+	}
+
+	return prg, referenced, nil
+}
+
+var kindNames = map[types.Kind]string{
+	types.BoolKind: "bool",
+	types.ListKind: "list",
+}
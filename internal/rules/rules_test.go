@@ -0,0 +1,217 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	cranev1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/bundle"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/rpm"
+)
+
+// writeRules writes a rules file containing rules, and returns its path.
+func writeRules(rules string) string {
+	path := filepath.Join(GinkgoT().TempDir(), "rules.yaml")
+	Expect(os.WriteFile(path, []byte(rules), 0o644)).To(Succeed())
+	return path
+}
+
+// rule returns a rules file with a single rule named CustomRule, with expression and findings.
+func rule(expression, findings string) string {
+	return writeRules("rules:\n- name: CustomRule\n  expression: '" + expression + "'\n  findings: '" + findings + "'\n")
+}
+
+var _ = Describe("Rules", func() {
+	var (
+		ctx      context.Context
+		imgRef   image.ImageReference
+		packages []*rpmdb.PackageInfo
+		docs     *documents
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		img, err := random.Image(512, 2)
+		Expect(err).ToNot(HaveOccurred())
+		img, err = mutate.Config(img, cranev1.Config{
+			User:   "1001",
+			Labels: map[string]string{"vendor": "Example Corp", "name": "app"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		imgRef = image.ImageReference{
+			ImageURI:        "quay.io/example/app:v1",
+			ImageFSPath:     GinkgoT().TempDir(),
+			ImageInfo:       img,
+			ImageRegistry:   "quay.io",
+			ImageRepository: "example/app",
+			ImageTagOrSha:   "v1",
+		}
+
+		packages = []*rpmdb.PackageInfo{
+			{Name: "bash", Version: "5.1.8", Release: "9.el9", Arch: "x86_64", SourceRpm: "bash-5.1.8-9.el9.src.rpm", Vendor: "Red Hat, Inc."},
+			{Name: "telnet", Version: "0.17", Release: "85.el9", Arch: "x86_64", SourceRpm: "telnet-0.17-85.el9.src.rpm", Vendor: "Red Hat, Inc."},
+		}
+		docs = &documents{getPackageList: func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+			return packages, nil
+		}}
+	})
+
+	// validate loads the rules at path, and validates imgRef with the first.
+	validate := func(path string) (bool, []string, error) {
+		checks, err := load(ctx, []string{path}, docs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(checks).ToNot(BeEmpty())
+
+		ctx, findings := check.ContextWithFindings(ctx)
+		passed, err := checks[0].Validate(ctx, imgRef)
+		return passed, findings.List(), err
+	}
+
+	Context("when loading a rules file", func() {
+		It("should return a check for each rule, with its metadata and help text", func() {
+			checks, err := load(ctx, []string{"testdata/rules.yaml"}, docs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks).To(HaveLen(2))
+
+			Expect(checks[0].Name()).To(Equal("HasVendorLabel"))
+			Expect(checks[0].Metadata().Level).To(Equal(check.LevelBest))
+			Expect(checks[0].Metadata().Description).To(Equal("Checking that the image is labelled with its vendor."))
+			Expect(checks[0].Help().Suggestion).To(Equal("Add a vendor label of Example Corp to the image."))
+			Expect(checks[0].RequiredFilePatterns()).To(BeEmpty())
+
+			Expect(checks[1].Name()).To(Equal("HasNoTelnet"))
+			Expect(checks[1].Metadata().Level).To(Equal(check.LevelWarn))
+			Expect(checks[1].RequiredFilePatterns()).To(Equal(rpm.RpmdbPaths))
+		})
+
+		It("should require the bundle's files for rules that refer to the bundle", func() {
+			checks, err := load(ctx, []string{rule(`bundle.annotations.size() > 0`, ``)}, docs)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checks[0].RequiredFilePatterns()).To(Equal(bundle.BundleFiles))
+		})
+
+		DescribeTable("should reject an invalid rule",
+			func(rules string, expected string) {
+				_, err := load(ctx, []string{writeRules(rules)}, docs)
+				Expect(err).To(MatchError(ContainSubstring(expected)))
+			},
+			Entry("no name", "rules:\n- expression: 'true'\n", "the rule has no name"),
+			Entry("no expression", "rules:\n- name: CustomRule\n", "rule CustomRule has no expression"),
+			Entry("unknown level", "rules:\n- name: CustomRule\n  level: critical\n  expression: 'true'\n", `level "critical" of rule CustomRule is not one of`),
+			Entry("syntax error", "rules:\n- name: CustomRule\n  expression: 'labels.vendor =='\n", "expression of rule CustomRule"),
+			Entry("unknown document", "rules:\n- name: CustomRule\n  expression: 'layers.size() < 40'\n", "undeclared reference to 'layers'"),
+			Entry("expression is not a bool", "rules:\n- name: CustomRule\n  expression: 'user'\n", "evaluates to string, not bool"),
+			Entry("findings are not a list", "rules:\n- name: CustomRule\n  expression: 'true'\n  findings: 'user'\n", "findings of rule CustomRule: evaluates to string, not list"),
+			Entry("unknown field", "rules:\n- name: CustomRule\n  expresion: 'true'\n", `unknown field "expresion"`),
+			Entry("duplicate name", "rules:\n- name: CustomRule\n  expression: 'true'\n- name: CustomRule\n  expression: 'true'\n", "a rule named CustomRule is already loaded"),
+		)
+
+		It("should return an error if the rules file does not exist", func() {
+			_, err := load(ctx, []string{filepath.Join(GinkgoT().TempDir(), "missing.yaml")}, docs)
+			Expect(err).To(MatchError(ContainSubstring("could not read rules file")))
+		})
+	})
+
+	DescribeTable("should evaluate rules against the image's documents",
+		func(expression string, expected bool) {
+			passed, _, err := validate(rule(expression, ``))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(Equal(expected))
+		},
+		Entry("image", `image.repository == "example/app" && image.digest.startsWith("sha256:")`, true),
+		Entry("config", `config.config.User == "1001" && config.rootfs.diff_ids.size() == 2`, true),
+		Entry("labels", `"vendor" in labels && labels.vendor == "Example Corp"`, true),
+		Entry("missing label", `"maintainer" in labels`, false),
+		Entry("user", `user != "" && user != "0" && user != "root"`, true),
+		Entry("manifest", `manifest.layers.size() <= 1`, false),
+		Entry("rpms", `rpms.all(p, p.vendor == "Red Hat, Inc.")`, true),
+		Entry("string extensions", `labels.name.upperAscii() == "APP"`, true),
+	)
+
+	It("should report the findings of a rule that fails", func() {
+		passed, findings, err := validate("testdata/rules.yaml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeTrue())
+		Expect(findings).To(BeEmpty())
+
+		checks, err := load(ctx, []string{"testdata/rules.yaml"}, docs)
+		Expect(err).ToNot(HaveOccurred())
+		ctx, found := check.ContextWithFindings(ctx)
+		passed, err = checks[1].Validate(ctx, imgRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeFalse())
+		Expect(found.List()).To(ConsistOf("telnet-0.17"))
+	})
+
+	It("should evaluate rules against an operator bundle", func() {
+		imgRef.ImageFSPath = "testdata/bundle"
+
+		passed, _, err := validate(rule(
+			`bundle.csv.metadata.annotations.capabilities == "Basic Install" && `+
+				`bundle.annotations["operators.operatorframework.io.bundle.package.v1"] == "testPackage" && `+
+				`bundle.objects.exists(o, o.kind == "ClusterServiceVersion")`,
+			``,
+		))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(passed).To(BeTrue())
+	})
+
+	It("should return an error if the image is not a bundle", func() {
+		_, _, err := validate(rule(`bundle.csv.spec.version != ""`, ``))
+		Expect(err).To(MatchError(ContainSubstring("could not load documents for rule CustomRule: bundle")))
+	})
+
+	It("should return an error if the package list cannot be read", func() {
+		docs.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+			return nil, errors.New("could not find rpm db/packages")
+		}
+
+		_, _, err := validate(rule(`rpms.size() > 0`, ``))
+		Expect(err).To(MatchError(ContainSubstring("rpms: could not find rpm db/packages")))
+	})
+
+	It("should return an error if the expression cannot be evaluated", func() {
+		_, _, err := validate(rule(`labels.maintainer == "me"`, ``))
+		Expect(err).To(MatchError(ContainSubstring("could not evaluate rule CustomRule: no such key: maintainer")))
+	})
+
+	It("should return an error if the findings are not strings", func() {
+		_, _, err := validate(rule(`false`, `rpms.map(p, p.epoch)`))
+		Expect(err).To(MatchError(ContainSubstring("findings of rule CustomRule are not a list of strings")))
+	})
+
+	It("should reuse the documents of the image last loaded", func() {
+		path := rule(`rpms.size() == 2`, ``)
+		calls := 0
+		docs.getPackageList = func(context.Context, string) ([]*rpmdb.PackageInfo, error) {
+			calls++
+			return packages, nil
+		}
+
+		checks, err := load(ctx, []string{path, writeRules("rules:\n- name: OtherRule\n  expression: 'rpms.size() > 0'\n")}, docs)
+		Expect(err).ToNot(HaveOccurred())
+		for _, c := range checks {
+			passed, err := c.Validate(ctx, imgRef)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(passed).To(BeTrue())
+		}
+		Expect(calls).To(Equal(1))
+
+		imgRef.ImageFSPath = GinkgoT().TempDir()
+		_, err = checks[0].Validate(ctx, imgRef)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calls).To(Equal(2))
+	})
+})
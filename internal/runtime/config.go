@@ -1,13 +1,19 @@
 package runtime
 
 import (
+	"fmt"
 	"os"
 	"time"
 
 	"github.com/spf13/viper"
 
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
 )
 
 // Config contains configuration details for running preflight.
@@ -21,20 +27,72 @@ type Config struct {
 	Artifacts      string
 	WriteJUnit     bool
 	TempDir        string
+	SigningKey     string
+	// Registry Fields
+	RegistriesConf    string
+	CertsDir          string
+	CredentialHelpers map[string]string
+	// Network is the retry, timeout and rate limit policy for registry and Pyxis traffic.
+	Network network.Policy
+	// Telemetry Fields
+	OTelEndpoint string
+	TraceFile    bool
+	// Rules are the rules files whose checks are run after the policy's checks.
+	Rules []string
 	// Container-Specific Fields
 	CertificationComponentID string
 	PyxisHost                string
 	PyxisAPIToken            string
 	DockerConfig             string
 	Submit                   bool
+	DryRun                   bool
 	Platform                 string
 	Insecure                 bool
 	Offline                  bool
 	ManifestListDigest       string
 	Konflux                  bool
+	// Plugins are externally supplied checks run after the container policy's checks.
+	Plugins []plugin.Config
+	// ScanSecrets adds a scan of the image's layers for secrets, using the ruleset at
+	// SecretRules, or the default ruleset if it is not set.
+	ScanSecrets bool
+	SecretRules string
+	// AuditFilePermissions adds an audit of the image's file permissions, allowing files
+	// matching AllowedFileCapabilities to carry capabilities.
+	AuditFilePermissions    bool
+	AllowedFileCapabilities []string
+	// CheckArbitraryUID adds a check that the image can run as an arbitrary UID.
+	CheckArbitraryUID bool
+	// AnalyzeLayers adds an analysis of the image's layers, evaluated against LayerThresholds.
+	AnalyzeLayers   bool
+	LayerThresholds layers.Thresholds
 	// Operator-Specific Fields
-	Channel             string
-	IndexImage          string
+	Channel    string
+	IndexImage string
+	// CatalogBaseImage, CatalogDir and CatalogAddress configure the catalog built for the
+	// bundle when IndexImage is empty.
+	CatalogBaseImage string
+	CatalogDir       string
+	CatalogAddress   string
+	// AllInstallModes deploys the operator in every install mode its CSV supports.
+	AllInstallModes bool
+	// ReconcileAlmExamples adds a check that the operator reconciles the examples in its CSV's
+	// alm-examples, waiting up to ExampleTimeout for any of ExampleConditions to be True.
+	ReconcileAlmExamples bool
+	ExampleConditions    []string
+	ExampleTimeout       time.Duration
+	// ApprovedRegistries are the registries the images pulled by the operator's pods are approved from.
+	ApprovedRegistries []string
+	// ReadinessActions are the actions taken for each category of findings of the
+	// DeploymentsAreProductionReady check.
+	ReadinessActions readiness.Actions
+	// EnforceRestrictedNetwork fails certification if a bundle claiming to support restricted
+	// networks does not follow the restricted network guidelines.
+	EnforceRestrictedNetwork bool
+	// VersionMatrix validates the bundle against each OpenShift version in its range, with the
+	// versions in the OpenShiftVersions file added to those known to preflight.
+	VersionMatrix       bool
+	OpenShiftVersions   string
 	Kubeconfig          string
 	CSVTimeout          time.Duration
 	SubscriptionTimeout time.Duration
@@ -58,22 +116,137 @@ func NewConfigFrom(vcfg viper.Viper) (*Config, error) {
 	cfg.Artifacts = vcfg.GetString("artifacts")
 	cfg.WriteJUnit = vcfg.GetBool("junit")
 	cfg.TempDir = vcfg.GetString("tempDir")
+	cfg.SigningKey = vcfg.GetString("signing_key")
+	cfg.RegistriesConf = vcfg.GetString("registries_conf")
+	cfg.CertsDir = vcfg.GetString("certs_dir")
+	cfg.CredentialHelpers = vcfg.GetStringMapString("credential_helpers")
+	cfg.OTelEndpoint = vcfg.GetString("otel_endpoint")
+	cfg.TraceFile = vcfg.GetBool("trace_file")
+	cfg.Rules = vcfg.GetStringSlice("rules")
+	if err := cfg.storeNetworkConfiguration(vcfg); err != nil {
+		return nil, err
+	}
 	cfg.storeContainerPolicyConfiguration(vcfg)
+	if err := cfg.storeLayerAnalysisConfiguration(vcfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.storePluginConfiguration(vcfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.storeReadinessConfiguration(vcfg); err != nil {
+		return nil, err
+	}
 	cfg.storeOperatorPolicyConfiguration(vcfg)
 	return &cfg, nil
 }
 
+// storeNetworkConfiguration reads the network section of the config, applying it
+// over the default network policy, and stores it in Config.
+func (c *Config) storeNetworkConfiguration(vcfg viper.Viper) error {
+	c.Network = network.DefaultPolicy()
+	if vcfg.IsSet("network.attempts") {
+		c.Network.Attempts = vcfg.GetInt("network.attempts")
+	}
+	if vcfg.IsSet("network.backoff") {
+		c.Network.Backoff = vcfg.GetDuration("network.backoff")
+	}
+	if vcfg.IsSet("network.timeout") {
+		c.Network.Timeout = vcfg.GetDuration("network.timeout")
+	}
+	if vcfg.IsSet("network.max_concurrent_layer_pulls") {
+		c.Network.MaxConcurrentLayerPulls = vcfg.GetInt("network.max_concurrent_layer_pulls")
+	}
+	if vcfg.IsSet("network.requests_per_second") {
+		c.Network.RequestsPerSecond = vcfg.GetFloat64("network.requests_per_second")
+	}
+	if vcfg.IsSet("network.max_retry_after") {
+		c.Network.MaxRetryAfter = vcfg.GetDuration("network.max_retry_after")
+	}
+
+	if err := c.Network.Validate(); err != nil {
+		return fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	return nil
+}
+
 // storeContainerPolicyConfiguration reads container-policy-specific config
 // items in viper, normalizes them, and stores them in Config.
 func (c *Config) storeContainerPolicyConfiguration(vcfg viper.Viper) {
 	c.PyxisAPIToken = vcfg.GetString("pyxis_api_token")
 	c.Submit = vcfg.GetBool("submit")
+	c.DryRun = vcfg.GetBool("dry_run")
 	c.PyxisHost = PyxisHostLookup(vcfg.GetString("pyxis_env"), vcfg.GetString("pyxis_host"))
 	c.CertificationComponentID = vcfg.GetString("certification_component_id")
 	c.Platform = vcfg.GetString("platform")
 	c.Insecure = vcfg.GetBool("insecure")
 	c.Offline = vcfg.GetBool("offline")
 	c.Konflux = vcfg.GetBool("konflux")
+	c.ScanSecrets = vcfg.GetBool("scan_secrets")
+	c.SecretRules = vcfg.GetString("secret_rules")
+	c.AuditFilePermissions = vcfg.GetBool("audit_file_permissions")
+	c.AllowedFileCapabilities = vcfg.GetStringSlice("allowed_file_capabilities")
+	c.CheckArbitraryUID = vcfg.GetBool("check_arbitrary_uid")
+	c.AnalyzeLayers = vcfg.GetBool("analyze_layers")
+}
+
+// storeLayerAnalysisConfiguration reads the layer_analysis section of the config, applying it
+// over the default thresholds, and stores it in Config. Every threshold in the section is read,
+// so that a misspelled one is reported by Validate rather than ignored.
+func (c *Config) storeLayerAnalysisConfiguration(vcfg viper.Viper) error {
+	c.LayerThresholds = layers.DefaultThresholds()
+	for name := range vcfg.GetStringMap("layer_analysis") {
+		threshold := c.LayerThresholds[name]
+		if key := "layer_analysis." + name + ".limit"; vcfg.IsSet(key) {
+			limit, err := layers.ParseLimit(vcfg.GetString(key))
+			if err != nil {
+				return fmt.Errorf("invalid layer analysis configuration: threshold %s: %w", name, err)
+			}
+			threshold.Limit = limit
+		}
+		if key := "layer_analysis." + name + ".action"; vcfg.IsSet(key) {
+			threshold.Action = action.Action(vcfg.GetString(key))
+		}
+		c.LayerThresholds[name] = threshold
+	}
+
+	if err := c.LayerThresholds.Validate(); err != nil {
+		return fmt.Errorf("invalid layer analysis configuration: %w", err)
+	}
+
+	return nil
+}
+
+// storeReadinessConfiguration reads the workload_readiness section of the config, applying it
+// over the default actions, and stores it in Config. Every category in the section is read, so
+// that a misspelled one is reported by Validate rather than ignored.
+func (c *Config) storeReadinessConfiguration(vcfg viper.Viper) error {
+	c.ReadinessActions = readiness.DefaultActions()
+	for name, act := range vcfg.GetStringMapString("workload_readiness") {
+		c.ReadinessActions[name] = action.Action(act)
+	}
+
+	if err := c.ReadinessActions.Validate(); err != nil {
+		return fmt.Errorf("invalid workload readiness configuration: %w", err)
+	}
+
+	return nil
+}
+
+// storePluginConfiguration reads the plugins registered in the config and
+// stores them in Config.
+func (c *Config) storePluginConfiguration(vcfg viper.Viper) error {
+	if err := vcfg.UnmarshalKey("plugins", &c.Plugins); err != nil {
+		return fmt.Errorf("invalid plugins configuration: %w", err)
+	}
+
+	for i, p := range c.Plugins {
+		if p.Path == "" {
+			return fmt.Errorf("invalid plugins configuration: plugin %d has no path", i)
+		}
+	}
+
+	return nil
 }
 
 // storeOperatorPolicyConfiguration reads operator-policy-specific config
@@ -82,6 +255,17 @@ func (c *Config) storeOperatorPolicyConfiguration(vcfg viper.Viper) {
 	c.Kubeconfig = os.Getenv("KUBECONFIG")
 	c.Channel = vcfg.GetString("channel")
 	c.IndexImage = vcfg.GetString("indeximage")
+	c.CatalogBaseImage = vcfg.GetString("catalog_base_image")
+	c.CatalogDir = vcfg.GetString("catalog_dir")
+	c.CatalogAddress = vcfg.GetString("catalog_address")
+	c.AllInstallModes = vcfg.GetBool("all_install_modes")
+	c.ReconcileAlmExamples = vcfg.GetBool("reconcile_alm_examples")
+	c.ExampleConditions = vcfg.GetStringSlice("example_conditions")
+	c.ExampleTimeout = vcfg.GetDuration("example_timeout")
+	c.ApprovedRegistries = vcfg.GetStringSlice("approved_registries")
+	c.EnforceRestrictedNetwork = vcfg.GetBool("enforce_restricted_network")
+	c.VersionMatrix = vcfg.GetBool("version_matrix")
+	c.OpenShiftVersions = vcfg.GetString("openshift_versions")
 	c.CSVTimeout = vcfg.GetDuration("csv_timeout")
 	c.SubscriptionTimeout = vcfg.GetDuration("subscription_timeout")
 }
@@ -3,10 +3,17 @@ package runtime
 import (
 	"os"
 	"reflect"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/viper"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/layers"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/network"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/plugin"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
 )
 
 var _ = Describe("Viper to Runtime Config", func() {
@@ -28,11 +35,34 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Artifacts = "artifacts"
 		baseViperCfg.Set("junit", true)
 		expectedRuntimeCfg.WriteJUnit = true
+		baseViperCfg.Set("signing_key", "signing.key")
+		expectedRuntimeCfg.SigningKey = "signing.key"
+		baseViperCfg.Set("registries_conf", "registries.conf")
+		expectedRuntimeCfg.RegistriesConf = "registries.conf"
+		baseViperCfg.Set("certs_dir", "certs.d")
+		expectedRuntimeCfg.CertsDir = "certs.d"
+		baseViperCfg.Set("credential_helpers", map[string]string{"quay.io": "secretservice"})
+		expectedRuntimeCfg.CredentialHelpers = map[string]string{"quay.io": "secretservice"}
+		baseViperCfg.Set("otel_endpoint", "http://localhost:4318")
+		expectedRuntimeCfg.OTelEndpoint = "http://localhost:4318"
+		baseViperCfg.Set("trace_file", true)
+		expectedRuntimeCfg.TraceFile = true
+		baseViperCfg.Set("rules", []string{"/etc/preflight/rules.yaml", "/etc/preflight/bundle-rules.yaml"})
+		expectedRuntimeCfg.Rules = []string{"/etc/preflight/rules.yaml", "/etc/preflight/bundle-rules.yaml"}
+		baseViperCfg.Set("network.attempts", 5)
+		baseViperCfg.Set("network.backoff", "1s")
+		baseViperCfg.Set("network.requests_per_second", 2.5)
+		expectedRuntimeCfg.Network = network.DefaultPolicy()
+		expectedRuntimeCfg.Network.Attempts = 5
+		expectedRuntimeCfg.Network.Backoff = time.Second
+		expectedRuntimeCfg.Network.RequestsPerSecond = 2.5
 
 		baseViperCfg.Set("pyxis_api_token", "apitoken")
 		expectedRuntimeCfg.PyxisAPIToken = "apitoken"
 		baseViperCfg.Set("submit", true)
 		expectedRuntimeCfg.Submit = true
+		baseViperCfg.Set("dry_run", true)
+		expectedRuntimeCfg.DryRun = true
 		baseViperCfg.Set("pyxis_env", "prod")
 		expectedRuntimeCfg.PyxisHost = "catalog.redhat.com/api/containers"
 		baseViperCfg.Set("certification_component_id", "000000000000")
@@ -41,11 +71,62 @@ var _ = Describe("Viper to Runtime Config", func() {
 		expectedRuntimeCfg.Platform = "s390x"
 		baseViperCfg.Set("insecure", true)
 		expectedRuntimeCfg.Insecure = true
+		baseViperCfg.Set("scan_secrets", true)
+		expectedRuntimeCfg.ScanSecrets = true
+		baseViperCfg.Set("secret_rules", "/etc/preflight/secrets.yaml")
+		expectedRuntimeCfg.SecretRules = "/etc/preflight/secrets.yaml"
+		baseViperCfg.Set("audit_file_permissions", true)
+		expectedRuntimeCfg.AuditFilePermissions = true
+		baseViperCfg.Set("allowed_file_capabilities", []string{"opt/app/bin/*"})
+		expectedRuntimeCfg.AllowedFileCapabilities = []string{"opt/app/bin/*"}
+		baseViperCfg.Set("check_arbitrary_uid", true)
+		expectedRuntimeCfg.CheckArbitraryUID = true
+		baseViperCfg.Set("analyze_layers", true)
+		expectedRuntimeCfg.AnalyzeLayers = true
+		baseViperCfg.Set("layer_analysis.image_size.limit", "2Gi")
+		baseViperCfg.Set("layer_analysis.image_size.action", "fail")
+		baseViperCfg.Set("layer_analysis.caches.action", "fail")
+		expectedRuntimeCfg.LayerThresholds = layers.DefaultThresholds()
+		expectedRuntimeCfg.LayerThresholds[layers.ThresholdImageSize] = layers.Threshold{Limit: 2 << 30, Action: action.Fail}
+		expectedRuntimeCfg.LayerThresholds[layers.ThresholdCaches] = layers.Threshold{Limit: 10 << 20, Action: action.Fail}
+		baseViperCfg.Set("plugins", []map[string]any{
+			{"path": "/usr/local/bin/check-ca-bundle", "args": []string{"--strict"}, "timeout": "30s"},
+			{"path": "/opt/checks/banned-binaries.wasm"},
+		})
+		expectedRuntimeCfg.Plugins = []plugin.Config{
+			{Path: "/usr/local/bin/check-ca-bundle", Args: []string{"--strict"}, Timeout: 30 * time.Second},
+			{Path: "/opt/checks/banned-binaries.wasm"},
+		}
 
 		baseViperCfg.Set("channel", "mychannel")
 		expectedRuntimeCfg.Channel = "mychannel"
 		baseViperCfg.Set("indeximage", "myindeximage")
 		expectedRuntimeCfg.IndexImage = "myindeximage"
+		baseViperCfg.Set("catalog_base_image", "registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.18")
+		expectedRuntimeCfg.CatalogBaseImage = "registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.18"
+		baseViperCfg.Set("catalog_dir", "/tmp/catalog")
+		expectedRuntimeCfg.CatalogDir = "/tmp/catalog"
+		baseViperCfg.Set("catalog_address", "localhost:50051")
+		expectedRuntimeCfg.CatalogAddress = "localhost:50051"
+		baseViperCfg.Set("all_install_modes", true)
+		expectedRuntimeCfg.AllInstallModes = true
+		baseViperCfg.Set("reconcile_alm_examples", true)
+		expectedRuntimeCfg.ReconcileAlmExamples = true
+		baseViperCfg.Set("example_conditions", []string{"Reconciled"})
+		expectedRuntimeCfg.ExampleConditions = []string{"Reconciled"}
+		baseViperCfg.Set("example_timeout", DefaultExampleTimeout)
+		expectedRuntimeCfg.ExampleTimeout = DefaultExampleTimeout
+		baseViperCfg.Set("approved_registries", []string{"quay.io"})
+		expectedRuntimeCfg.ApprovedRegistries = []string{"quay.io"}
+		baseViperCfg.Set("workload_readiness.probes", "fail")
+		expectedRuntimeCfg.ReadinessActions = readiness.DefaultActions()
+		expectedRuntimeCfg.ReadinessActions[readiness.CategoryProbes] = action.Fail
+		baseViperCfg.Set("enforce_restricted_network", true)
+		expectedRuntimeCfg.EnforceRestrictedNetwork = true
+		baseViperCfg.Set("version_matrix", true)
+		expectedRuntimeCfg.VersionMatrix = true
+		baseViperCfg.Set("openshift_versions", "/tmp/versions.yaml")
+		expectedRuntimeCfg.OpenShiftVersions = "/tmp/versions.yaml"
 		baseViperCfg.Set("csv_timeout", DefaultCSVTimeout)
 		expectedRuntimeCfg.CSVTimeout = DefaultCSVTimeout
 		baseViperCfg.Set("subscription_timeout", DefaultSubscriptionTimeout)
@@ -60,12 +141,59 @@ var _ = Describe("Viper to Runtime Config", func() {
 		})
 	})
 
-	It("should only have 24 struct keys for tests to be valid", func() {
+	Context("With an invalid network configuration", func() {
+		It("should return an error", func() {
+			baseViperCfg.Set("network.attempts", 0)
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid network configuration")))
+		})
+	})
+
+	Context("With an invalid layer analysis configuration", func() {
+		It("should return an error if a limit is invalid", func() {
+			baseViperCfg.Set("layer_analysis.wasted.limit", "lots")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid layer analysis configuration: threshold wasted")))
+		})
+		It("should return an error if an action is invalid", func() {
+			baseViperCfg.Set("layer_analysis.wasted.action", "error")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid layer analysis configuration")))
+		})
+		It("should return an error if a threshold is unknown", func() {
+			baseViperCfg.Set("layer_analysis.wastd.limit", "1Gi")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid layer analysis configuration: unknown threshold wastd")))
+		})
+	})
+
+	Context("With an invalid workload readiness configuration", func() {
+		It("should return an error if an action is invalid", func() {
+			baseViperCfg.Set("workload_readiness.limits", "error")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid workload readiness configuration: action of category limits")))
+		})
+		It("should return an error if a category is unknown", func() {
+			baseViperCfg.Set("workload_readiness.probs", "fail")
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("invalid workload readiness configuration: unknown category probs")))
+		})
+	})
+
+	Context("With a plugin that has no path", func() {
+		It("should return an error", func() {
+			baseViperCfg.Set("plugins", []map[string]any{{"args": []string{"--strict"}}})
+			_, err := NewConfigFrom(*baseViperCfg)
+			Expect(err).To(MatchError(ContainSubstring("plugin 0 has no path")))
+		})
+	})
+
+	It("should only have 53 struct keys for tests to be valid", func() {
 		// If this test fails, it means a developer has added or removed
 		// keys from runtime.Config, and so these tests may no longer be
 		// accurate in confirming that the derived configuration from viper
 		// matches.
 		keys := reflect.TypeOf(Config{}).NumField()
-		Expect(keys).To(Equal(24), "runtime.Config field count changed; update this test and the viper mapping tests above")
+		Expect(keys).To(Equal(53), "runtime.Config field count changed; update this test and the viper mapping tests above")
 	})
 })
@@ -5,4 +5,5 @@ import "time"
 var (
 	DefaultCSVTimeout          = 180 * time.Second
 	DefaultSubscriptionTimeout = 180 * time.Second
+	DefaultExampleTimeout      = 180 * time.Second
 )
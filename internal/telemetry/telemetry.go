@@ -0,0 +1,202 @@
+// Package telemetry configures OpenTelemetry tracing and metrics for preflight, and provides
+// the tracer and instruments used throughout. Until Setup is called, the global no-op
+// providers are used, and recording telemetry has no cost.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/version"
+)
+
+const (
+	// InstrumentationName identifies the tracer and meter used by preflight.
+	InstrumentationName = "github.com/redhat-openshift-ecosystem/openshift-preflight"
+
+	// TraceFilename is the artifact the spans are written to when a trace file is requested.
+	TraceFilename = "traces.json"
+	// MetricsFilename is the artifact the metrics are written to when a trace file is requested.
+	MetricsFilename = "metrics.json"
+)
+
+// Config configures where telemetry is exported.
+type Config struct {
+	// Endpoint is the URL of an OTLP/HTTP collector, e.g. http://localhost:4318. The
+	// standard OTEL_EXPORTER_OTLP_* environment variables, e.g. for headers, also apply.
+	Endpoint string
+	// TraceFile writes the spans and metrics as JSON artifacts when telemetry is shut down.
+	TraceFile bool
+}
+
+// ShutdownFunc flushes and stops the exporters configured by Setup.
+type ShutdownFunc func(context.Context) error
+
+// Setup configures the global tracer and meter providers to export to the destinations in
+// cfg. Nothing is configured if cfg has no destinations. The returned ShutdownFunc must be
+// called to flush the telemetry, and writes the trace file artifacts to the artifacts writer
+// in ctx, if any.
+func Setup(ctx context.Context, cfg Config) (ShutdownFunc, error) {
+	if cfg.Endpoint == "" && !cfg.TraceFile {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res := resource.NewSchemaless(
+		semconv.ServiceName("preflight"),
+		semconv.ServiceVersion(version.Version.Version),
+	)
+
+	traceOptions := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	meterOptions := []sdkmetric.Option{sdkmetric.WithResource(res)}
+
+	if cfg.Endpoint != "" {
+		if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid OTLP endpoint %q: must be an http or https URL", cfg.Endpoint)
+		}
+
+		traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("could not create OTLP trace exporter: %w", err)
+		}
+		metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(cfg.Endpoint))
+		if err != nil {
+			return nil, fmt.Errorf("could not create OTLP metric exporter: %w", err)
+		}
+		traceOptions = append(traceOptions, sdktrace.WithBatcher(traceExporter))
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)))
+	}
+
+	var traceBuf, metricsBuf bytes.Buffer
+	if cfg.TraceFile {
+		traceExporter, err := stdouttrace.New(stdouttrace.WithWriter(&traceBuf), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not create trace file exporter: %w", err)
+		}
+		metricExporter, err := stdoutmetric.New(stdoutmetric.WithWriter(&metricsBuf), stdoutmetric.WithPrettyPrint())
+		if err != nil {
+			//coverage:ignore
+			return nil, fmt.Errorf("could not create metrics file exporter: %w", err)
+		}
+		traceOptions = append(traceOptions, sdktrace.WithBatcher(traceExporter))
+		// metrics are only exported once, when the provider is shut down.
+		meterOptions = append(meterOptions, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(24*time.Hour))))
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(traceOptions...)
+	meterProvider := sdkmetric.NewMeterProvider(meterOptions...)
+	previousTracerProvider, previousMeterProvider := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		err := errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+		otel.SetTracerProvider(previousTracerProvider)
+		otel.SetMeterProvider(previousMeterProvider)
+
+		if cfg.TraceFile {
+			if aw := artifacts.WriterFromContext(ctx); aw != nil {
+				if _, werr := aw.WriteFile(TraceFilename, &traceBuf); werr != nil {
+					err = errors.Join(err, fmt.Errorf("could not write trace file: %w", werr))
+				}
+				if _, werr := aw.WriteFile(MetricsFilename, &metricsBuf); werr != nil {
+					err = errors.Join(err, fmt.Errorf("could not write metrics file: %w", werr))
+				}
+			}
+		}
+
+		return err
+	}, nil
+}
+
+// Tracer returns the tracer used by preflight.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Start starts a span named name, as a child of any span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err, if any, on span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Check outcomes recorded by RecordCheck.
+const (
+	OutcomePassed  = "passed"
+	OutcomeFailed  = "failed"
+	OutcomeWarned  = "warned"
+	OutcomeErrored = "errored"
+)
+
+// RecordCheck records the duration and outcome of a check.
+func RecordCheck(ctx context.Context, name string, outcome string, elapsed time.Duration) {
+	histogram, err := meter().Float64Histogram("preflight.check.duration",
+		metric.WithDescription("The duration of each check's validation."),
+		metric.WithUnit("s"))
+	if err != nil {
+		//coverage:ignore
+		otel.Handle(err)
+		return
+	}
+	histogram.Record(ctx, elapsed.Seconds(), metric.WithAttributes(
+		attribute.String("check.name", name),
+		attribute.String("check.outcome", outcome),
+	))
+}
+
+// RecordBytesPulled records n bytes of image layers pulled from a registry.
+func RecordBytesPulled(ctx context.Context, n int64) {
+	counter, err := meter().Int64Counter("preflight.image.pulled",
+		metric.WithDescription("The uncompressed bytes of image layers pulled."),
+		metric.WithUnit("By"))
+	if err != nil {
+		//coverage:ignore
+		otel.Handle(err)
+		return
+	}
+	counter.Add(ctx, n)
+}
+
+// RecordRetry records that a request, or an operation such as a layer pull, of the given
+// kind was retried.
+func RecordRetry(ctx context.Context, kind string) {
+	counter, err := meter().Int64Counter("preflight.retries",
+		metric.WithDescription("The number of retried requests and operations."))
+	if err != nil {
+		//coverage:ignore
+		otel.Handle(err)
+		return
+	}
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("retry.kind", kind)))
+}
+
+func meter() metric.Meter {
+	return otel.Meter(InstrumentationName)
+}
@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/artifacts"
+)
+
+var _ = Describe("Telemetry", func() {
+	Context("when no destination is configured", func() {
+		It("should not record any spans", func() {
+			shutdown, err := Setup(context.Background(), Config{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, span := Start(context.Background(), "unrecorded")
+			Expect(span.IsRecording()).To(BeFalse())
+			End(span, nil)
+
+			Expect(shutdown(context.Background())).To(Succeed())
+		})
+	})
+
+	Context("when a trace file is requested", func() {
+		var (
+			ctx context.Context
+			dir string
+		)
+
+		BeforeEach(func() {
+			dir = GinkgoT().TempDir()
+			aw, err := artifacts.NewFilesystemWriter(artifacts.WithDirectory(dir))
+			Expect(err).ToNot(HaveOccurred())
+			ctx = artifacts.ContextWithWriter(context.Background(), aw)
+		})
+
+		It("should write the spans and metrics as artifacts on shutdown", func() {
+			shutdown, err := Setup(ctx, Config{TraceFile: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			spanCtx, parent := Start(ctx, "check container")
+			Expect(parent.IsRecording()).To(BeTrue())
+			_, child := Start(spanCtx, "check HasLicense")
+			End(child, errors.New("license not found"))
+			End(parent, nil)
+
+			RecordCheck(ctx, "HasLicense", OutcomeFailed, time.Second)
+			RecordBytesPulled(ctx, 1024)
+			RecordRetry(ctx, "layer")
+
+			Expect(shutdown(ctx)).To(Succeed())
+
+			traces, err := os.ReadFile(filepath.Join(dir, TraceFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(traces)).To(ContainSubstring(`"Name": "check container"`))
+			Expect(string(traces)).To(ContainSubstring(`"Name": "check HasLicense"`))
+			Expect(string(traces)).To(ContainSubstring("license not found"))
+
+			metrics, err := os.ReadFile(filepath.Join(dir, MetricsFilename))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(metrics)).To(ContainSubstring("preflight.check.duration"))
+			Expect(string(metrics)).To(ContainSubstring("preflight.image.pulled"))
+			Expect(string(metrics)).To(ContainSubstring("preflight.retries"))
+		})
+
+		It("should stop recording once shut down", func() {
+			shutdown, err := Setup(ctx, Config{TraceFile: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(shutdown(ctx)).To(Succeed())
+
+			_, span := Start(ctx, "after shutdown")
+			Expect(span.IsRecording()).To(BeFalse())
+		})
+	})
+
+	Context("when an OTLP endpoint is configured", func() {
+		It("should fail to set up with an invalid endpoint", func() {
+			_, err := Setup(context.Background(), Config{Endpoint: "://not a url"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
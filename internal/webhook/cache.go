@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"sync"
+	"time"
+)
+
+// verdictCache holds the verdict for each image digest for a fixed time, so that images are
+// not evaluated on every admission.
+type verdictCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	verdict string
+	expires time.Time
+}
+
+func newVerdictCache(ttl time.Duration) *verdictCache {
+	return &verdictCache{
+		ttl:     ttl,
+		entries: map[string]cacheEntry{},
+		now:     time.Now,
+	}
+}
+
+// get returns the verdict for ref, if it has not expired.
+func (c *verdictCache) get(ref string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[ref]
+	if !ok || !c.now().Before(entry.expires) {
+		return "", false
+	}
+
+	return entry.verdict, true
+}
+
+// put stores the verdict for ref, and drops any that have expired.
+func (c *verdictCache) put(ref string, verdict string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.entries[ref] = cacheEntry{verdict: verdict, expires: now.Add(c.ttl)}
+}
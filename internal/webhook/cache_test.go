@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("verdictCache", func() {
+	var (
+		cache *verdictCache
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		cache = newVerdictCache(time.Minute)
+		cache.now = func() time.Time { return now }
+	})
+
+	It("should return a verdict until it expires", func() {
+		cache.put("quay.io/example/app@sha256:abc", "failed")
+
+		verdict, ok := cache.get("quay.io/example/app@sha256:abc")
+		Expect(ok).To(BeTrue())
+		Expect(verdict).To(Equal("failed"))
+
+		now = now.Add(time.Minute)
+		_, ok = cache.get("quay.io/example/app@sha256:abc")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should cache passing verdicts", func() {
+		cache.put("quay.io/example/app@sha256:abc", "")
+
+		verdict, ok := cache.get("quay.io/example/app@sha256:abc")
+		Expect(ok).To(BeTrue())
+		Expect(verdict).To(BeEmpty())
+	})
+
+	It("should drop expired verdicts when another is stored", func() {
+		cache.put("quay.io/example/app@sha256:abc", "")
+		now = now.Add(2 * time.Minute)
+		cache.put("quay.io/example/app@sha256:def", "")
+
+		Expect(cache.entries).To(HaveLen(1))
+		Expect(cache.entries).To(HaveKey("quay.io/example/app@sha256:def"))
+	})
+
+	It("should not cache anything when the ttl is zero", func() {
+		cache = newVerdictCache(0)
+		cache.put("quay.io/example/app@sha256:abc", "")
+
+		_, ok := cache.get("quay.io/example/app@sha256:abc")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/option"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+)
+
+// NewResolver returns a Resolver that looks up the digest of an image in the first of its
+// sources, i.e. its mirrors or its own registry, that responds, using the docker config,
+// platform and insecure settings of cfg. Images already referenced by digest are not looked up.
+func NewResolver(cfg runtime.Config) Resolver {
+	return func(ctx context.Context, image string) (string, error) {
+		logger := logr.FromContextOrDiscard(ctx)
+
+		ref, err := name.ParseReference(image)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse image name: %w", err)
+		}
+		if digest, ok := ref.(name.Digest); ok {
+			return digest.String(), nil
+		}
+
+		sources, err := registries.FromContext(ctx).Sources(ref.Name())
+		if err != nil {
+			return "", err
+		}
+
+		var errs []error
+		for _, source := range sources {
+			options := option.GenerateCraneOptions(ctx, &cfg)
+			if source.Insecure {
+				options = append(options, crane.Insecure)
+			}
+
+			digest, err := crane.Digest(source.Reference, options...)
+			if err == nil {
+				return ref.Context().Digest(digest).String(), nil
+			}
+
+			logger.V(log.DBG).Info("unable to resolve image digest", "source", source.Reference, "reason", err.Error())
+			errs = append(errs, err)
+		}
+
+		return "", errors.Join(errs...)
+	}
+}
+
+// NewEvaluator returns an Evaluator that runs the webhook policy's checks named in checkNames,
+// or all of them if checkNames is empty, using the docker config, platform and insecure
+// settings of cfg.
+func NewEvaluator(ctx context.Context, cfg runtime.Config, checkNames []string) (Evaluator, error) {
+	available := engine.WebhookContainerPolicy(ctx)
+	for _, n := range checkNames {
+		if !slices.Contains(available, n) {
+			return nil, fmt.Errorf("check %s is not in the webhook policy, which includes: %v", n, available)
+		}
+	}
+
+	return func(ctx context.Context, ref string) (certification.Results, error) {
+		// checks are created for each evaluation, as evaluations run concurrently.
+		checks, err := engine.InitializeContainerChecks(ctx, policy.PolicyWebhook, engine.ContainerCheckConfig{
+			DockerConfig: cfg.DockerConfig,
+		})
+		if err != nil {
+			//coverage:ignore
+			return certification.Results{}, err
+		}
+		if len(checkNames) > 0 {
+			checks = slices.DeleteFunc(checks, func(c check.Check) bool {
+				return !slices.Contains(checkNames, c.Name())
+			})
+		}
+
+		eng, err := engine.New(ctx, checks, nil, runtime.Config{
+			Image:        ref,
+			DockerConfig: cfg.DockerConfig,
+			Platform:     cfg.Platform,
+			Insecure:     cfg.Insecure,
+		})
+		if err != nil {
+			//coverage:ignore
+			return certification.Results{}, err
+		}
+
+		if err := eng.ExecuteChecks(ctx); err != nil {
+			return certification.Results{}, err
+		}
+
+		return eng.Results(ctx), nil
+	}, nil
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
+)
+
+var _ = Describe("Evaluating images", func() {
+	var (
+		src    string
+		digest string
+		cfg    runtime.Config
+	)
+
+	BeforeEach(func() {
+		s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", log.Ldate))))
+		DeferCleanup(s.Close)
+
+		u, err := url.Parse(s.URL)
+		Expect(err).ToNot(HaveOccurred())
+		src = fmt.Sprintf("%s/test/webhook:v1", u.Host)
+
+		img, err := random.Image(1024, 2)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crane.Push(img, src)).To(Succeed())
+
+		d, err := img.Digest()
+		Expect(err).ToNot(HaveOccurred())
+		digest = d.String()
+
+		cfg = runtime.Config{Platform: "amd64"}
+	})
+
+	Context("when resolving an image", func() {
+		It("should resolve a tag to its digest", func() {
+			ref, err := NewResolver(cfg)(context.Background(), src)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref).To(HaveSuffix("/test/webhook@" + digest))
+		})
+
+		It("should not look up an image referenced by digest", func() {
+			ref, err := NewResolver(cfg)(context.Background(), "quay.io/example/app@sha256:0000000000000000000000000000000000000000000000000000000000000000")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref).To(Equal("quay.io/example/app@sha256:0000000000000000000000000000000000000000000000000000000000000000"))
+		})
+
+		It("should fail for an image that does not exist", func() {
+			_, err := NewResolver(cfg)(context.Background(), src+"-missing")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail for an invalid image name", func() {
+			_, err := NewResolver(cfg)(context.Background(), "Not A Valid/Image")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse image name")))
+		})
+	})
+
+	Context("when evaluating an image", func() {
+		It("should run only the selected checks", func() {
+			evaluate, err := NewEvaluator(context.Background(), cfg, []string{"HasLicense"})
+			Expect(err).ToNot(HaveOccurred())
+
+			ref, err := NewResolver(cfg)(context.Background(), src)
+			Expect(err).ToNot(HaveOccurred())
+
+			results, err := evaluate(context.Background(), ref)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(results.PassedOverall).To(BeFalse())
+			Expect(checkNames(results.Failed)).To(ConsistOf("HasLicense"))
+			Expect(results.Passed).To(BeEmpty())
+		})
+
+		It("should fail to create an evaluator for a check that is not in the webhook policy", func() {
+			_, err := NewEvaluator(context.Background(), cfg, []string{"BasedOnUbi"})
+			Expect(err).To(MatchError(ContainSubstring("check BasedOnUbi is not in the webhook policy")))
+		})
+	})
+})
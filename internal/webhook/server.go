@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"net/http"
+
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// Path is the path the validating webhook is served on.
+	Path = "/validate"
+	// HealthPath is the path that responds once the server is serving.
+	HealthPath = "/healthz"
+)
+
+// NewServer returns a TLS server for h, configured by opts, that serves the validating webhook
+// on Path. The serving certificate is reloaded whenever it changes in opts.CertDir.
+func NewServer(h admission.Handler, opts crwebhook.Options) crwebhook.Server {
+	srv := crwebhook.NewServer(opts)
+	srv.Register(Path, &admission.Webhook{Handler: h})
+	srv.Register(HealthPath, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return srv
+}
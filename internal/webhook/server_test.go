@@ -0,0 +1,164 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// startServer serves h with the serving certificate prepared by opts, until the spec ends.
+func startServer(h *handler, opts *envtest.WebhookInstallOptions) {
+	srv := NewServer(h, crwebhook.Options{
+		Host:    opts.LocalServingHost,
+		Port:    opts.LocalServingPort,
+		CertDir: opts.LocalServingCertDir,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer GinkgoRecover()
+		defer close(done)
+		Expect(srv.Start(ctx)).To(Succeed())
+	}()
+	DeferCleanup(func() {
+		cancel()
+		<-done
+	})
+
+	Eventually(srv.StartedChecker()).WithArguments(&http.Request{}).Should(Succeed())
+}
+
+var _ = Describe("Server", func() {
+	var (
+		evaluations atomic.Int32
+		opts        *envtest.WebhookInstallOptions
+	)
+
+	BeforeEach(func() {
+		evaluations.Store(0)
+		opts = &envtest.WebhookInstallOptions{LocalServingHost: "127.0.0.1"}
+		Expect(opts.PrepWithoutInstalling()).To(Succeed())
+		DeferCleanup(opts.Cleanup)
+	})
+
+	It("should answer admission reviews over TLS", func() {
+		startServer(NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1")), opts)
+
+		pool := x509.NewCertPool()
+		Expect(pool.AppendCertsFromPEM(opts.LocalServingCAData)).To(BeTrue())
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+		url := fmt.Sprintf("https://%s:%d", opts.LocalServingHost, opts.LocalServingPort)
+
+		resp, err := httpClient.Get(url + HealthPath)
+		Expect(err).ToNot(HaveOccurred())
+		resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		req := podRequest("quay.io/example/bad:v1")
+		req.UID = "0000-1111"
+		review := admissionv1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+			Request:  &req.AdmissionRequest,
+		}
+		body, err := json.Marshal(review)
+		Expect(err).ToNot(HaveOccurred())
+
+		resp, err = httpClient.Post(url+Path, "application/json", bytes.NewReader(body))
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		var answer admissionv1.AdmissionReview
+		Expect(json.NewDecoder(resp.Body).Decode(&answer)).To(Succeed())
+		Expect(answer.Response.UID).To(BeEquivalentTo("0000-1111"))
+		Expect(answer.Response.Allowed).To(BeFalse())
+		Expect(answer.Response.Result.Message).To(ContainSubstring("failed HasLicense"))
+	})
+
+	// This runs against a local API server and etcd, the binaries of which are found in the
+	// directory named by KUBEBUILDER_ASSETS, e.g. as installed by setup-envtest.
+	Context("with an API server", func() {
+		var k8sClient client.Client
+
+		BeforeEach(func() {
+			if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+				Skip("KUBEBUILDER_ASSETS is not set")
+			}
+
+			path := Path
+			failurePolicy := admissionregistrationv1.Fail
+			sideEffects := admissionregistrationv1.SideEffectClassNone
+			scope := admissionregistrationv1.NamespacedScope
+			opts.ValidatingWebhooks = []*admissionregistrationv1.ValidatingWebhookConfiguration{{
+				ObjectMeta: metav1.ObjectMeta{Name: "preflight"},
+				Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+					Name:                    "preflight.openshift.io",
+					AdmissionReviewVersions: []string{"v1"},
+					SideEffects:             &sideEffects,
+					FailurePolicy:           &failurePolicy,
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service: &admissionregistrationv1.ServiceReference{Name: "preflight", Namespace: "default", Path: &path},
+					},
+					Rules: []admissionregistrationv1.RuleWithOperations{{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"pods"},
+							Scope:       &scope,
+						},
+					}},
+				}},
+			}}
+			// the definitions were prepared before there were any to point at this server.
+			Expect(opts.ModifyWebhookDefinitions()).To(Succeed())
+
+			env := &envtest.Environment{WebhookInstallOptions: *opts}
+			cfg, err := env.Start()
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(env.Stop)
+
+			startServer(NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1")), &env.WebhookInstallOptions)
+
+			k8sClient, err = client.New(cfg, client.Options{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("should deny a pod with an image that does not comply", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "bad", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "quay.io/example/bad:v1"}}},
+			}
+			err := k8sClient.Create(context.Background(), pod)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue(), "expected the pod to be denied, got %v", err)
+			Expect(err.Error()).To(ContainSubstring("failed HasLicense"))
+		})
+
+		It("should admit a pod whose images comply", func() {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "good", Namespace: "default"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "quay.io/example/app:v1"}}},
+			}
+			Expect(k8sClient.Create(context.Background(), pod)).To(Succeed())
+			Expect(evaluations.Load()).To(BeEquivalentTo(1))
+		})
+	})
+})
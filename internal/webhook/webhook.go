@@ -0,0 +1,236 @@
+// Package webhook implements a validating admission webhook that runs preflight's checks
+// against the images of the workloads being admitted, and denies, or warns about, those
+// whose images do not comply.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/singleflight"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/log"
+)
+
+// Enforcement is what the webhook does when an image does not comply.
+type Enforcement = string
+
+const (
+	// EnforcementDeny denies admission.
+	EnforcementDeny Enforcement = "deny"
+	// EnforcementWarn admits the workload, and returns a warning to the client.
+	EnforcementWarn Enforcement = "warn"
+)
+
+const (
+	// DefaultCacheTTL is how long the verdict for an image digest is reused.
+	DefaultCacheTTL = time.Hour
+	// DefaultEvaluationTimeout bounds how long the evaluation of a single image may take.
+	DefaultEvaluationTimeout = 10 * time.Minute
+)
+
+// Resolver resolves image to a reference by digest, e.g. quay.io/example/app@sha256:...
+type Resolver func(ctx context.Context, image string) (string, error)
+
+// Evaluator runs the checks against the image at ref, a reference by digest.
+type Evaluator func(ctx context.Context, ref string) (certification.Results, error)
+
+type Option = func(*handler)
+
+// NewHandler returns an admission handler for Pods and Deployments, which resolves each of
+// their container images with resolve, and evaluates those not already evaluated with
+// evaluate.
+func NewHandler(resolve Resolver, evaluate Evaluator, opts ...Option) *handler {
+	h := &handler{
+		resolve:           resolve,
+		evaluate:          evaluate,
+		enforcement:       EnforcementDeny,
+		evaluationTimeout: DefaultEvaluationTimeout,
+		cache:             newVerdictCache(DefaultCacheTTL),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// WithEnforcement sets what is done when an image does not comply. The default is
+// EnforcementDeny.
+func WithEnforcement(enforcement Enforcement) Option {
+	return func(h *handler) {
+		h.enforcement = enforcement
+	}
+}
+
+// WithCacheTTL sets how long the verdict for an image digest is reused before the image is
+// evaluated again.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(h *handler) {
+		h.cache = newVerdictCache(ttl)
+	}
+}
+
+// WithEvaluationTimeout sets how long the evaluation of a single image may take.
+func WithEvaluationTimeout(timeout time.Duration) Option {
+	return func(h *handler) {
+		h.evaluationTimeout = timeout
+	}
+}
+
+type handler struct {
+	resolve           Resolver
+	evaluate          Evaluator
+	enforcement       Enforcement
+	evaluationTimeout time.Duration
+	cache             *verdictCache
+	inflight          singleflight.Group
+}
+
+var _ admission.Handler = &handler{}
+
+// Handle admits the workload in req if all of its images comply.
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := logr.FromContextOrDiscard(ctx).WithValues("kind", req.Kind.Kind, "namespace", req.Namespace, "name", req.Name)
+	ctx = logr.NewContext(ctx, logger)
+
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	images, err := imagesFor(req)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var problems []string
+	for _, image := range images {
+		if problem := h.verdict(ctx, image); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	if len(problems) == 0 {
+		logger.V(log.DBG).Info("admitted", "images", images)
+		return admission.Allowed("")
+	}
+
+	logger.Info("images do not comply", "enforcement", h.enforcement, "problems", problems)
+	if h.enforcement == EnforcementWarn {
+		return admission.Allowed("").WithWarnings(problems...)
+	}
+
+	return admission.Denied(strings.Join(problems, "; "))
+}
+
+// verdict returns why image does not comply, or an empty string if it does.
+func (h *handler) verdict(ctx context.Context, image string) string {
+	ref, err := h.resolve(ctx, image)
+	if err != nil {
+		return fmt.Sprintf("image %s could not be resolved: %v", image, err)
+	}
+
+	if verdict, ok := h.cache.get(ref); ok {
+		return verdict
+	}
+
+	// the evaluation is not tied to this request, so that it is cached even if the request
+	// times out, and concurrent requests for the same image share it.
+	ch := h.inflight.DoChan(ref, func() (any, error) {
+		evalCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.evaluationTimeout)
+		defer cancel()
+
+		results, err := h.evaluate(evalCtx, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		verdict := verdictFor(ref, results)
+		h.cache.put(ref, verdict)
+		return verdict, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return fmt.Sprintf("image %s is still being evaluated, try again shortly", image)
+	case res := <-ch:
+		if res.Err != nil {
+			return fmt.Sprintf("image %s could not be evaluated: %v", image, res.Err)
+		}
+		return res.Val.(string)
+	}
+}
+
+// verdictFor returns why the image at ref does not comply given its results, or an empty
+// string if it does.
+func verdictFor(ref string, results certification.Results) string {
+	if results.PassedOverall {
+		return ""
+	}
+
+	var reasons []string
+	if len(results.Failed) > 0 {
+		reasons = append(reasons, "failed "+strings.Join(checkNames(results.Failed), ", "))
+	}
+	if len(results.Errors) > 0 {
+		reasons = append(reasons, "errored "+strings.Join(checkNames(results.Errors), ", "))
+	}
+
+	return fmt.Sprintf("image %s does not comply with preflight policy: %s", ref, strings.Join(reasons, "; "))
+}
+
+func checkNames(results []certification.Result) []string {
+	names := make([]string, 0, len(results))
+	for _, result := range results {
+		names = append(names, result.Name())
+	}
+	return names
+}
+
+// imagesFor returns the unique images of all containers in the workload in req. Workloads
+// other than Pods and Deployments have no images.
+func imagesFor(req admission.Request) ([]string, error) {
+	var spec corev1.PodSpec
+	switch {
+	case req.Kind.Group == "" && req.Kind.Kind == "Pod":
+		var pod corev1.Pod
+		if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+			return nil, fmt.Errorf("could not decode pod: %w", err)
+		}
+		spec = pod.Spec
+	case req.Kind.Group == "apps" && req.Kind.Kind == "Deployment":
+		var deployment appsv1.Deployment
+		if err := json.Unmarshal(req.Object.Raw, &deployment); err != nil {
+			return nil, fmt.Errorf("could not decode deployment: %w", err)
+		}
+		spec = deployment.Spec.Template.Spec
+	default:
+		return nil, nil
+	}
+
+	var images []string
+	for _, c := range spec.InitContainers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.Containers {
+		images = append(images, c.Image)
+	}
+	for _, c := range spec.EphemeralContainers {
+		images = append(images, c.Image)
+	}
+
+	slices.Sort(images)
+	return slices.Compact(images), nil
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/certification"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/image"
+)
+
+// fakeResolver resolves every image to a digest reference in the same repository.
+func fakeResolver(_ context.Context, image string) (string, error) {
+	return image + "@sha256:0000000000000000000000000000000000000000000000000000000000000000", nil
+}
+
+var failingCheck = check.NewGenericCheck(
+	"HasLicense",
+	func(context.Context, image.ImageReference) (bool, error) { return false, nil },
+	check.Metadata{},
+	check.HelpText{},
+	nil,
+)
+
+// fakeEvaluator fails any image in failing, and counts its evaluations.
+func fakeEvaluator(evaluations *atomic.Int32, failing ...string) Evaluator {
+	return func(_ context.Context, ref string) (certification.Results, error) {
+		evaluations.Add(1)
+		for _, f := range failing {
+			if ref == f+"@sha256:0000000000000000000000000000000000000000000000000000000000000000" {
+				return certification.Results{Failed: []certification.Result{{Check: failingCheck}}}, nil
+			}
+		}
+		return certification.Results{PassedOverall: true}, nil
+	}
+}
+
+func podRequest(images ...string) admission.Request {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "certified"}}
+	for _, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "c", Image: image})
+	}
+	raw, err := json.Marshal(pod)
+	Expect(err).ToNot(HaveOccurred())
+
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+		Namespace: "certified",
+		Name:      "app",
+		Object:    kruntime.RawExtension{Raw: raw},
+	}}
+}
+
+var _ = Describe("Handler", func() {
+	var evaluations atomic.Int32
+
+	BeforeEach(func() {
+		evaluations.Store(0)
+	})
+
+	It("should admit a pod whose images comply", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations))
+		resp := h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(BeEmpty())
+	})
+
+	It("should deny a pod with an image that does not comply", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1"))
+		resp := h.Handle(context.Background(), podRequest("quay.io/example/app:v1", "quay.io/example/bad:v1"))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("quay.io/example/bad:v1@sha256"))
+		Expect(resp.Result.Message).To(ContainSubstring("failed HasLicense"))
+		Expect(resp.Result.Message).ToNot(ContainSubstring("quay.io/example/app:v1"))
+	})
+
+	It("should admit with a warning when enforcement is warn", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1"), WithEnforcement(EnforcementWarn))
+		resp := h.Handle(context.Background(), podRequest("quay.io/example/bad:v1"))
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Warnings).To(ConsistOf(ContainSubstring("failed HasLicense")))
+	})
+
+	It("should evaluate each image digest once", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations))
+		h.Handle(context.Background(), podRequest("quay.io/example/app:v1", "quay.io/example/app:v1"))
+		h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		Expect(evaluations.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should evaluate an image again once its verdict expires", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations), WithCacheTTL(0))
+		h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		Expect(evaluations.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should deny a pod whose image cannot be resolved", func() {
+		resolve := func(context.Context, string) (string, error) { return "", errors.New("manifest unknown") }
+		h := NewHandler(resolve, fakeEvaluator(&evaluations))
+		resp := h.Handle(context.Background(), podRequest("quay.io/example/missing:v1"))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("could not be resolved: manifest unknown"))
+	})
+
+	It("should not cache an evaluation that errors", func() {
+		evaluate := func(context.Context, string) (certification.Results, error) {
+			evaluations.Add(1)
+			return certification.Results{}, errors.New("failed to pull remote container")
+		}
+		h := NewHandler(fakeResolver, evaluate)
+		resp := h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("could not be evaluated"))
+
+		h.Handle(context.Background(), podRequest("quay.io/example/app:v1"))
+		Expect(evaluations.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should finish an evaluation after the request times out, and use it for later requests", func() {
+		release := make(chan struct{})
+		evaluate := func(context.Context, string) (certification.Results, error) {
+			evaluations.Add(1)
+			<-release
+			return certification.Results{PassedOverall: true}, nil
+		}
+		h := NewHandler(fakeResolver, evaluate)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		DeferCleanup(cancel)
+		resp := h.Handle(ctx, podRequest("quay.io/example/app:v1"))
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Message).To(ContainSubstring("still being evaluated"))
+
+		close(release)
+		Eventually(func() bool {
+			return h.Handle(context.Background(), podRequest("quay.io/example/app:v1")).Allowed
+		}).Should(BeTrue())
+		Expect(evaluations.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("should check the init containers and containers of a deployment", func() {
+		deployment := appsv1.Deployment{}
+		deployment.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "quay.io/example/bad:v1"}}
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "quay.io/example/app:v1"}}
+		raw, err := json.Marshal(deployment)
+		Expect(err).ToNot(HaveOccurred())
+
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1"))
+		resp := h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Kind:      metav1.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			Object:    kruntime.RawExtension{Raw: raw},
+		}})
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(evaluations.Load()).To(BeEquivalentTo(2))
+	})
+
+	It("should admit other kinds of objects without evaluating them", func() {
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations))
+		resp := h.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Kind:      metav1.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			Object:    kruntime.RawExtension{Raw: []byte(`{}`)},
+		}})
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(evaluations.Load()).To(BeZero())
+	})
+
+	It("should admit deletions without evaluating them", func() {
+		req := podRequest("quay.io/example/bad:v1")
+		req.Operation = admissionv1.Delete
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations, "quay.io/example/bad:v1"))
+		Expect(h.Handle(context.Background(), req).Allowed).To(BeTrue())
+		Expect(evaluations.Load()).To(BeZero())
+	})
+
+	It("should return an error for an object that cannot be decoded", func() {
+		req := podRequest()
+		req.Object.Raw = []byte(`{"spec": "not a pod spec"}`)
+		h := NewHandler(fakeResolver, fakeEvaluator(&evaluations))
+		resp := h.Handle(context.Background(), req)
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(resp.Result.Code).To(BeEquivalentTo(http.StatusBadRequest))
+	})
+})
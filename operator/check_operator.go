@@ -11,12 +11,15 @@ import (
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/check"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/engine"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/policy"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/registries"
 	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/runtime"
 )
 
 type Option = func(*operatorCheck)
 
-// NewCheck is a check runner that executes the Operator Policy.
+// NewCheck is a check runner that executes the Operator Policy. If indeximage is empty, a
+// catalog containing only the bundle is built, and served to OLM from the cluster.
 func NewCheck(image, indeximage string, kubeconfig []byte, opts ...Option) *operatorCheck {
 	c := &operatorCheck{
 		image:               image,
@@ -24,6 +27,7 @@ func NewCheck(image, indeximage string, kubeconfig []byte, opts ...Option) *oper
 		indeximage:          indeximage,
 		csvTimeout:          runtime.DefaultCSVTimeout,
 		subscriptionTimeout: runtime.DefaultSubscriptionTimeout,
+		exampleTimeout:      runtime.DefaultExampleTimeout,
 	}
 
 	for _, opt := range opts {
@@ -40,6 +44,14 @@ func (c operatorCheck) Run(ctx context.Context) (certification.Results, error) {
 		return certification.Results{}, err
 	}
 
+	registriesConfig, err := registries.New(c.registriesConf, c.certsDir, c.credentialHelpers)
+	if err != nil {
+		return certification.Results{}, err
+	}
+	if registriesConfig != nil {
+		ctx = registries.ContextWithConfig(ctx, registriesConfig)
+	}
+
 	cfg := runtime.Config{
 		//coverage:ignore
 		Image:        c.image,
@@ -82,18 +94,29 @@ func (c *operatorCheck) resolve(ctx context.Context) error {
 		return preflighterr.ErrImageEmpty
 	case c.kubeconfig == nil:
 		return preflighterr.ErrKubeconfigEmpty
-	case c.indeximage == "":
-		return preflighterr.ErrIndexImageEmpty
 	}
 
 	c.policy = policy.PolicyOperator
 	newChecks, err := engine.InitializeOperatorChecks(ctx, c.policy, engine.OperatorCheckConfig{
-		IndexImage:          c.indeximage,
-		DockerConfig:        c.dockerConfigFilePath,
-		Channel:             c.operatorChannel,
-		Kubeconfig:          c.kubeconfig,
-		CSVTimeout:          c.csvTimeout,
-		SubscriptionTimeout: c.subscriptionTimeout,
+		IndexImage:               c.indeximage,
+		DockerConfig:             c.dockerConfigFilePath,
+		Channel:                  c.operatorChannel,
+		Kubeconfig:               c.kubeconfig,
+		CSVTimeout:               c.csvTimeout,
+		SubscriptionTimeout:      c.subscriptionTimeout,
+		Rules:                    c.rules,
+		CatalogBaseImage:         c.catalogBaseImage,
+		CatalogDir:               c.catalogDir,
+		CatalogAddress:           c.catalogAddress,
+		AllInstallModes:          c.allInstallModes,
+		ReconcileAlmExamples:     c.reconcileAlmExamples,
+		ExampleConditions:        c.exampleConditions,
+		ExampleTimeout:           c.exampleTimeout,
+		ApprovedRegistries:       c.approvedRegistries,
+		ReadinessActions:         c.readinessActions,
+		EnforceRestrictedNetwork: c.enforceRestrictedNetwork,
+		VersionMatrix:            c.versionMatrix,
+		OpenShiftVersions:        c.openshiftVersions,
 	})
 	if err != nil {
 		//coverage:ignore
@@ -133,6 +156,19 @@ func WithInsecureConnection() Option {
 	}
 }
 
+// WithRegistriesConfig configures how images are pulled from their registries. registriesConf
+// is a containers registries.conf file (version 2), whose mirrors, blocked registries, and
+// insecure settings are used. certsDir contains the certificates for each registry, laid out
+// like /etc/containers/certs.d. credentialHelpers maps a registry to the docker credential
+// helper holding its credentials, e.g. quay.io to secretservice. Each may be empty.
+func WithRegistriesConfig(registriesConf string, certsDir string, credentialHelpers map[string]string) Option {
+	return func(oc *operatorCheck) {
+		oc.registriesConf = registriesConf
+		oc.certsDir = certsDir
+		oc.credentialHelpers = credentialHelpers
+	}
+}
+
 // WithCSVTimeout customizes how long to wait for a ClusterServiceVersion to become healthy.
 func WithCSVTimeout(csvTimeout time.Duration) Option {
 	//coverage:ignore
@@ -151,18 +187,122 @@ func WithSubscriptionTimeout(subscriptionTimeout time.Duration) Option {
 	}
 }
 
+// WithRules adds the checks of the rules in the rules files at paths to those of the
+// operator policy. See the rules package for the documents rules are evaluated against.
+func WithRules(paths ...string) Option {
+	return func(oc *operatorCheck) {
+		oc.rules = append(oc.rules, paths...)
+	}
+}
+
+// WithCatalogBaseImage sets the opm image that serves the catalog built for the bundle, when
+// there is no index image.
+func WithCatalogBaseImage(baseImage string) Option {
+	return func(oc *operatorCheck) {
+		oc.catalogBaseImage = baseImage
+	}
+}
+
+// WithCatalogDir writes the catalog built for the bundle, when there is no index image, to
+// dir, rather than serving it from the cluster. The catalog must be served at address, e.g.
+// by opm serve, for OLM to install the bundle.
+func WithCatalogDir(dir, address string) Option {
+	return func(oc *operatorCheck) {
+		oc.catalogDir = dir
+		oc.catalogAddress = address
+	}
+}
+
+// WithAllInstallModes deploys the operator in every install mode its CSV supports, rather than
+// only the first of OwnNamespace, SingleNamespace, MultiNamespace and AllNamespaces.
+func WithAllInstallModes() Option {
+	return func(oc *operatorCheck) {
+		oc.allInstallModes = true
+	}
+}
+
+// WithAlmExamples adds a check that creates the examples in the alm-examples annotation of the
+// CSV once the operator is installed, and waits up to timeout for any of conditions of each
+// to be True. If conditions is empty, the Ready and Available conditions are used. If timeout
+// is zero, the default of 180s is used.
+func WithAlmExamples(conditions []string, timeout time.Duration) Option {
+	return func(oc *operatorCheck) {
+		oc.reconcileAlmExamples = true
+		oc.exampleConditions = conditions
+		if timeout != 0 {
+			oc.exampleTimeout = timeout
+		}
+	}
+}
+
+// WithApprovedRegistries approves the images pulled by the operator's pods from registries,
+// rather than from the default Red Hat registries.
+func WithApprovedRegistries(registries []string) Option {
+	return func(oc *operatorCheck) {
+		oc.approvedRegistries = registries
+	}
+}
+
+// WithReadinessActions takes actions, warn or fail, for the categories of findings of the
+// DeploymentsAreProductionReady check, rather than only warning of each.
+func WithReadinessActions(actions readiness.Actions) Option {
+	return func(oc *operatorCheck) {
+		oc.readinessActions = actions
+	}
+}
+
+// WithRestrictedNetworkEnforced fails certification if a bundle claiming to support restricted
+// networks does not follow the restricted network guidelines, rather than warning of each gap.
+func WithRestrictedNetworkEnforced() Option {
+	return func(oc *operatorCheck) {
+		oc.enforceRestrictedNetwork = true
+	}
+}
+
+// WithVersionMatrix validates the bundle against each OpenShift version in the range of its
+// com.redhat.openshift.versions annotation, rather than only the one it targets.
+func WithVersionMatrix() Option {
+	return func(oc *operatorCheck) {
+		oc.versionMatrix = true
+	}
+}
+
+// WithOpenShiftVersions adds the OpenShift versions in the file at path, and the Kubernetes
+// version each is validated against, to those known to preflight.
+func WithOpenShiftVersions(path string) Option {
+	return func(oc *operatorCheck) {
+		oc.openshiftVersions = path
+	}
+}
+
 type operatorCheck struct {
 	// required
 	image      string
 	kubeconfig []byte
 	indeximage string
 	// optional
-	operatorChannel      string
-	dockerConfigFilePath string
-	insecure             bool
-	checks               []check.Check
-	resolved             bool
-	policy               policy.Policy
-	csvTimeout           time.Duration
-	subscriptionTimeout  time.Duration
+	operatorChannel          string
+	dockerConfigFilePath     string
+	insecure                 bool
+	registriesConf           string
+	certsDir                 string
+	credentialHelpers        map[string]string
+	checks                   []check.Check
+	resolved                 bool
+	policy                   policy.Policy
+	csvTimeout               time.Duration
+	subscriptionTimeout      time.Duration
+	rules                    []string
+	catalogBaseImage         string
+	catalogDir               string
+	catalogAddress           string
+	allInstallModes          bool
+	reconcileAlmExamples     bool
+	exampleConditions        []string
+	exampleTimeout           time.Duration
+	approvedRegistries       []string
+	readinessActions         readiness.Actions
+	enforceRestrictedNetwork bool
+	versionMatrix            bool
+	openshiftVersions        string
 }
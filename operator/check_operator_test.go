@@ -2,11 +2,14 @@ package operator
 
 import (
 	"context"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	preflighterr "github.com/redhat-openshift-ecosystem/openshift-preflight/errors"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/action"
+	"github.com/redhat-openshift-ecosystem/openshift-preflight/internal/readiness"
 )
 
 var _ = Describe("Operator Check initialization", func() {
@@ -22,6 +25,15 @@ var _ = Describe("Operator Check initialization", func() {
 				WithOperatorChannel(operatorChannel),
 				WithDockerConfigJSONFromFile(dockerConfigFilePath),
 				WithInsecureConnection(),
+				WithCatalogBaseImage("registry.example.com/opm:latest"),
+				WithCatalogDir("/tmp/catalog", "localhost:50051"),
+				WithAllInstallModes(),
+				WithAlmExamples([]string{"Reconciled"}, time.Minute),
+				WithApprovedRegistries([]string{"quay.io"}),
+				WithReadinessActions(readiness.Actions{readiness.CategoryProbes: action.Fail}),
+				WithRestrictedNetworkEnforced(),
+				WithVersionMatrix(),
+				WithOpenShiftVersions("/tmp/versions.yaml"),
 			)
 			Expect(c.image).To(Equal(image))
 			Expect(c.kubeconfig).To(Equal(kubeconfig))
@@ -30,6 +42,18 @@ var _ = Describe("Operator Check initialization", func() {
 			Expect(c.operatorChannel).To(Equal(operatorChannel))
 			Expect(c.dockerConfigFilePath).To(Equal(dockerConfigFilePath))
 			Expect(c.insecure).To(BeTrue(), "insecure flag should be true when WithInsecureConnection is used")
+			Expect(c.catalogBaseImage).To(Equal("registry.example.com/opm:latest"))
+			Expect(c.catalogDir).To(Equal("/tmp/catalog"))
+			Expect(c.catalogAddress).To(Equal("localhost:50051"))
+			Expect(c.allInstallModes).To(BeTrue())
+			Expect(c.reconcileAlmExamples).To(BeTrue())
+			Expect(c.exampleConditions).To(Equal([]string{"Reconciled"}))
+			Expect(c.exampleTimeout).To(Equal(time.Minute))
+			Expect(c.approvedRegistries).To(Equal([]string{"quay.io"}))
+			Expect(c.readinessActions).To(Equal(readiness.Actions{readiness.CategoryProbes: action.Fail}))
+			Expect(c.enforceRestrictedNetwork).To(BeTrue())
+			Expect(c.versionMatrix).To(BeTrue())
+			Expect(c.openshiftVersions).To(Equal("/tmp/versions.yaml"))
 		})
 	})
 })
@@ -48,7 +72,7 @@ var _ = Describe("Operator Check Execution", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(chk.policy).To(Equal("operator"))
 			Expect(chk.resolved).To(Equal(true))
-			Expect(len(chk.checks)).To(Equal(7), "resolve should populate chk.checks with all operator policy checks")
+			Expect(len(chk.checks)).To(Equal(11), "resolve should populate chk.checks with all operator policy checks")
 		})
 
 		It("Should return nil on second resolve (already resolved)", func() {
@@ -67,7 +91,7 @@ var _ = Describe("Operator Check Execution", func() {
 			policy, checks, err := chk.List(ctx)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(policy).To(Equal("operator"))
-			Expect(len(checks)).To(Equal(7), "chk.List should return all operator policy checks")
+			Expect(len(checks)).To(Equal(11), "chk.List should return all operator policy checks")
 		})
 	})
 
@@ -84,10 +108,10 @@ var _ = Describe("Operator Check Execution", func() {
 			Expect(err).To(MatchError(preflighterr.ErrKubeconfigEmpty))
 		})
 
-		It("should fail if you passed an empty index image", func() {
+		It("should build a catalog for the bundle if you passed an empty index image", func() {
 			chk := NewCheck("image", "", []byte{})
-			_, err := chk.Run(context.TODO())
-			Expect(err).To(MatchError(preflighterr.ErrIndexImageEmpty))
+			Expect(chk.resolve(context.TODO())).To(Succeed())
+			Expect(chk.checks).ToNot(BeEmpty())
 		})
 	})
 })